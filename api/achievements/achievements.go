@@ -0,0 +1,225 @@
+package achievements
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/segmentio/ksuid"
+)
+
+// Service awards children the achievements they earned once their points are settled
+type Service struct {
+	achievementDB storage.IAchievementStorage
+	events        eventbus.Publisher
+	pointsDB      storage.IPointsStorage
+	userDB        storage.IUserStorage
+}
+
+// NewService returns an achievements service. Earned achievements and bonus points are published
+// to the given publisher.
+func NewService(achievementDB storage.IAchievementStorage, pointsDB storage.IPointsStorage, userDB storage.IUserStorage, events eventbus.Publisher) *Service {
+	return &Service{
+		achievementDB: achievementDB,
+		events:        events,
+		pointsDB:      pointsDB,
+		userDB:        userDB,
+	}
+}
+
+// Rules returns the default rules and the rules of the given families
+func Rules(ctx context.Context, achievementDB storage.IAchievementStorage, familyIDs []string) ([]models.AchievementRule, error) {
+	rules := append([]models.AchievementRule{}, models.DefaultAchievementRules...)
+
+	for _, familyID := range familyIDs {
+		familyRules, err := achievementDB.GetAchievementRulesByFamilyID(ctx, familyID)
+		if err != nil {
+			return rules, fmt.Errorf("failed to get achievement rules: %w", err)
+		}
+		rules = append(rules, familyRules...)
+	}
+
+	return rules, nil
+}
+
+// HandleEvent evaluates the child's achievements when a request of theirs was decided, or their
+// balance changed otherwise (i.e. points awarded by a parent)
+func (s *Service) HandleEvent(ctx context.Context, evt eventbus.Event) {
+	if evt.Type != eventbus.EventTypePointsDecided && evt.Type != eventbus.EventTypeBalanceChanged {
+		return
+	}
+
+	if _, err := s.Evaluate(ctx, evt.UserID, time.Now().UTC()); err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":    err.Error(),
+			"event_id": evt.ID,
+			"user_id":  evt.UserID,
+		}).Errorf("failed to evaluate achievements")
+	}
+}
+
+// Evaluate awards the child every achievement they earned but don't have yet, along with the
+// achievement's bonus points. Returns the newly earned achievements.
+func (s *Service) Evaluate(ctx context.Context, userID string, now time.Time) ([]models.Achievement, error) {
+	earned := []models.Achievement{}
+
+	user, err := s.userDB.GetUserByID(ctx, userID)
+	if err != nil {
+		return earned, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.IsChild() {
+		return earned, nil
+	}
+
+	rules, err := Rules(ctx, s.achievementDB, user.FamilyIDs)
+	if err != nil {
+		return earned, err
+	}
+
+	achievements, err := s.achievementDB.GetAchievementsByUserID(ctx, userID)
+	if err != nil {
+		return earned, fmt.Errorf("failed to get achievements: %w", err)
+	}
+
+	has := map[string]bool{}
+	for _, a := range achievements {
+		has[a.RuleID] = true
+	}
+
+	pending := []models.AchievementRule{}
+	for _, r := range rules {
+		if !has[r.ID] {
+			pending = append(pending, r)
+		}
+	}
+
+	if len(pending) == 0 {
+		return earned, nil
+	}
+
+	points, err := s.pointsDB.GetPointsByUserID(ctx, userID, models.QueryPointsFilter{
+		Statuses: []models.PointStatus{models.PointStatusSettled},
+		Attributes: []string{
+			"id",
+			"updated_on",
+			"points",
+			"balance",
+			"point_type_id",
+			"status",
+			"request.achievement_rule_id",
+			"request.decision",
+			"request.reason",
+			"request.type",
+		},
+	})
+	if err != nil {
+		return earned, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	// achievements are earned, and bonus points awarded, in points of the default type
+	points = models.PointsOfType(points, models.DefaultPointTypeID)
+
+	for _, r := range pending {
+		if !r.IsMet(points) {
+			continue
+		}
+
+		achievement := r.NewAchievement(userID, now)
+		added, err := s.achievementDB.AddAchievement(ctx, achievement)
+		if err != nil {
+			return earned, fmt.Errorf("failed to add achievement %s: %w", r.ID, err)
+		}
+
+		// earned by a concurrent evaluation
+		if !added {
+			continue
+		}
+
+		earned = append(earned, achievement)
+		s.events.Publish(ctx, eventbus.Event{
+			Type:   eventbus.EventTypeAchievementEarned,
+			UserID: userID,
+			Data:   achievement,
+		})
+
+		if r.BonusPoints > 0 {
+			if err := s.awardBonus(ctx, userID, r, now); err != nil {
+				return earned, err
+			}
+		}
+	}
+
+	return earned, nil
+}
+
+// awardBonus adds the rule's bonus points to the child's balance. The balance is re-read if someone
+// else changed it in the meantime.
+func (s *Service) awardBonus(ctx context.Context, userID string, r models.AchievementRule, now time.Time) error {
+	nowStr := util.ToFormatted(now)
+
+	var point models.Point
+
+	err := storage.RetryOnConflict(ctx, func(ctx context.Context) error {
+		balance, err := s.pointsDB.GetPointBalance(ctx, userID, models.DefaultPointTypeID)
+		if err != nil {
+			return fmt.Errorf("failed to get balance: %w", err)
+		}
+		balance.Balance += r.BonusPoints
+
+		point = models.Point{
+			ID:      ksuid.New().String(),
+			UserID:  userID,
+			Status:  models.PointStatusSettled,
+			Points:  r.BonusPoints,
+			Balance: &balance.Balance,
+			Request: models.PointRequest{
+				AchievementRuleID: r.ID,
+				DecidedOnStr:      nowStr,
+				Decision:          models.PointRequestDecisionApprove,
+				Reason:            fmt.Sprintf("Achievement: %s", r.Name),
+				Type:              models.PointRequestTypeAdd,
+			},
+			CreatedOnStr: nowStr,
+			UpdatedOnStr: nowStr,
+		}
+
+		return s.pointsDB.SavePoints(ctx, []models.Point{point}, []models.PointBalance{balance})
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to save bonus points of achievement %s: %w", r.ID, err)
+	}
+
+	point.ParseTimes()
+	s.events.Publish(ctx, eventbus.Event{
+		Type:   eventbus.EventTypeBalanceChanged,
+		UserID: userID,
+		Data:   point.ToPointSummary(),
+	})
+
+	return nil
+}
+
+// Publisher publishes events to the next publisher (i.e. the event bus) and evaluates achievements for them
+type Publisher struct {
+	next    eventbus.Publisher
+	service *Service
+}
+
+func NewPublisher(next eventbus.Publisher, service *Service) *Publisher {
+	return &Publisher{
+		next:    next,
+		service: service,
+	}
+}
+
+func (p *Publisher) Publish(ctx context.Context, evt eventbus.Event) {
+	p.next.Publish(ctx, evt)
+	p.service.HandleEvent(ctx, evt)
+}
@@ -0,0 +1,185 @@
+package achievements_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sebboness/yektaspoints/achievements"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+var child = models.User{UserID: "1", Name: "Yekta", FamilyIDs: []string{"456"}, Roles: []string{models.RoleChild}}
+
+func bal(v int) *int {
+	return &v
+}
+
+func Test_Rules(t *testing.T) {
+	mockAchievementDB := mocks.NewMockIAchievementStorage(t)
+	mockAchievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{{FamilyID: "456", ID: "r1"}}, nil).Once()
+	mockAchievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "789").Return(nil, errFail).Once()
+
+	rules, err := achievements.Rules(context.Background(), mockAchievementDB, []string{"456"})
+	assert.Nil(t, err)
+	assert.Len(t, rules, len(models.DefaultAchievementRules)+1)
+	assert.Equal(t, "r1", rules[len(rules)-1].ID)
+
+	_, err = achievements.Rules(context.Background(), mockAchievementDB, []string{"789"})
+	tests.AssertError(t, err, "failed to get achievement rules: fail")
+}
+
+func Test_Service_Evaluate(t *testing.T) {
+	type state struct {
+		user           models.User
+		alreadyEarned  bool
+		earnedByOthers bool
+		errGetUser     error
+		errPoints      error
+		errSavePoints  error
+	}
+	type want struct {
+		err    string
+		met    []string
+		earned []string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{user: child}, want{"", []string{"first-cashout", "r1"}, []string{"first-cashout", "r1"}}},
+		{"happy path - already earned", state{user: child, alreadyEarned: true}, want{"", []string{}, []string{}}},
+		{"happy path - earned by a concurrent evaluation", state{user: child, earnedByOthers: true}, want{"", []string{"first-cashout", "r1"}, []string{"first-cashout"}}},
+		{"happy path - parents don't earn achievements", state{user: models.User{UserID: "1", Roles: []string{models.RoleParent}}}, want{"", []string{}, []string{}}},
+		{"fail - get user", state{errGetUser: errFail}, want{"failed to get user: fail", []string{}, []string{}}},
+		{"fail - get points", state{user: child, errPoints: errFail}, want{"failed to get points: fail", []string{}, []string{}}},
+		{"fail - save bonus points", state{user: child, errSavePoints: errFail}, want{"failed to save bonus points of achievement r1: fail", []string{"first-cashout", "r1"}, []string{"first-cashout", "r1"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAchievementDB := mocks.NewMockIAchievementStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			now := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+			familyRule := models.AchievementRule{FamilyID: "456", ID: "r1", Name: "Dishwasher", Type: models.AchievementRuleTypeTotal, Threshold: 10, Category: "dishes", BonusPoints: 5}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(c.state.user, c.state.errGetUser).Once()
+
+			if c.state.user.IsChild() {
+				mockAchievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{familyRule}, nil).Once()
+
+				earned := []models.Achievement{}
+				if c.state.alreadyEarned {
+					for _, r := range append(models.DefaultAchievementRules, familyRule) {
+						earned = append(earned, models.Achievement{UserID: "1", RuleID: r.ID})
+					}
+				}
+				mockAchievementDB.EXPECT().GetAchievementsByUserID(mock.Anything, "1").Return(earned, nil).Once()
+
+				if !c.state.alreadyEarned {
+					mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "1", mock.MatchedBy(func(f models.QueryPointsFilter) bool {
+						return len(f.Statuses) == 1 && f.Statuses[0] == models.PointStatusSettled
+					})).Return([]models.Point{
+						{Status: models.PointStatusSettled, PointTypeID: "st", Points: 100, Balance: bal(100), UpdatedOn: now, Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+						{Status: models.PointStatusSettled, Points: -2, Balance: bal(10), UpdatedOn: now, Request: models.PointRequest{Type: models.PointRequestTypeCashout}},
+						{Status: models.PointStatusSettled, Points: 12, Balance: bal(12), UpdatedOn: now.AddDate(0, 0, -1), Request: models.PointRequest{Type: models.PointRequestTypeAdd, Reason: "Dishes"}},
+					}, c.state.errPoints).Once()
+				}
+			}
+
+			for _, id := range c.want.met {
+				id := id
+				added := !(c.state.earnedByOthers && id == "r1")
+				mockAchievementDB.EXPECT().AddAchievement(mock.Anything, mock.MatchedBy(func(a models.Achievement) bool {
+					return a.RuleID == id && a.UserID == "1" && a.EarnedOn.Equal(now)
+				})).Return(added, nil).Once()
+
+				if added {
+					mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+						return evt.Type == eventbus.EventTypeAchievementEarned && evt.Data.(models.Achievement).RuleID == id
+					})).Once()
+				}
+			}
+
+			if slices.Contains(c.want.earned, "r1") {
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "1", models.DefaultPointTypeID).
+					Return(models.PointBalance{UserID: "1", PointTypeID: models.DefaultPointTypeID, Balance: 10, Version: 2}, nil).Once()
+				mockPointsDB.EXPECT().SavePoints(mock.Anything, mock.MatchedBy(func(points []models.Point) bool {
+					p := points[0]
+					return len(points) == 1 && p.Points == 5 && *p.Balance == 15 && p.Status == models.PointStatusSettled &&
+						p.Request.AchievementRuleID == "r1" && p.Request.Reason == "Achievement: Dishwasher" &&
+						p.UpdatedOnStr == util.ToFormatted(now)
+				}), []models.PointBalance{{UserID: "1", PointTypeID: models.DefaultPointTypeID, Balance: 15, Version: 2}}).
+					Return(c.state.errSavePoints).Once()
+
+				if c.state.errSavePoints == nil {
+					mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+						return evt.Type == eventbus.EventTypeBalanceChanged && evt.Data.(models.PointSummary).Points == 5
+					})).Once()
+				}
+			}
+
+			svc := achievements.NewService(mockAchievementDB, mockPointsDB, mockUserDB, mockEvents)
+			earned, err := svc.Evaluate(context.Background(), "1", now)
+			tests.AssertError(t, err, c.want.err)
+
+			ids := []string{}
+			for _, a := range earned {
+				ids = append(ids, a.RuleID)
+			}
+			assert.Equal(t, c.want.earned, ids)
+
+			mockAchievementDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Publisher_Publish(t *testing.T) {
+	mockNext := evtmocks.NewMockPublisher(t)
+	mockAchievementDB := mocks.NewMockIAchievementStorage(t)
+	mockPointsDB := mocks.NewMockIPointsStorage(t)
+	mockUserDB := mocks.NewMockIUserStorage(t)
+
+	svc := achievements.NewService(mockAchievementDB, mockPointsDB, mockUserDB, mockNext)
+	p := achievements.NewPublisher(mockNext, svc)
+
+	// achievements are only evaluated once points are settled
+	requested := eventbus.Event{Type: eventbus.EventTypePointsRequested, UserID: "1"}
+	mockNext.EXPECT().Publish(mock.Anything, requested).Once()
+	p.Publish(context.Background(), requested)
+
+	// evaluation errors are only logged
+	decided := eventbus.Event{Type: eventbus.EventTypePointsDecided, UserID: "1"}
+	mockNext.EXPECT().Publish(mock.Anything, decided).Once()
+	mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{}, errFail).Once()
+	p.Publish(context.Background(), decided)
+
+	balanceChanged := eventbus.Event{Type: eventbus.EventTypeBalanceChanged, UserID: "1"}
+	mockNext.EXPECT().Publish(mock.Anything, balanceChanged).Once()
+	mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{}, errFail).Once()
+	p.Publish(context.Background(), balanceChanged)
+
+	mockNext.AssertExpectations(t)
+	mockUserDB.AssertExpectations(t)
+}
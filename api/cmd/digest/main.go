@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	awslambda "github.com/aws/aws-lambda-go/lambda"
+	"github.com/sebboness/yektaspoints/digest"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/sebboness/yektaspoints/util/notify"
+)
+
+var logger *log.Logger
+
+// Sends the weekly digest to the parents of every family. Runs as a lambda on a schedule, or
+// locally with the same environment variables as the lambda (ENV, NOTIFY_EMAIL_FROM, etc.)
+//
+// Usage: go run ./cmd/digest [-family <family_id> [-format text|html] [-lang en]]
+//
+// With -family, the family's digest is only rendered to stdout for previewing.
+func main() {
+	familyID := flag.String("family", "", "render the digest of this family to stdout instead of sending digests")
+	format := flag.String("format", "text", "format of the rendered digest (text or html)")
+	lang := flag.String("lang", notify.DefaultLanguage, "language of the rendered digest")
+	flag.Parse()
+
+	logger = log.NewLogger("mypoints_digest")
+
+	if env.GetEnv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		awslambda.Start(Handler)
+		return
+	}
+
+	ctx := context.Background()
+
+	if *familyID != "" {
+		preview(ctx, *familyID, *format, *lang)
+		return
+	}
+
+	report, err := run(ctx)
+	if err != nil {
+		logger.Fatalf("failed to send digests: %v", err)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Fatalf("failed to marshal report: %v", err)
+	}
+
+	fmt.Println(string(out))
+}
+
+// Handler is the entry point for the scheduled lambda
+func Handler(ctx context.Context, evt events.CloudWatchEvent) (digest.Report, error) {
+	logger.WithContext(ctx).WithField("event_id", evt.ID).Infof("sending weekly digests")
+	return run(ctx)
+}
+
+func run(ctx context.Context) (digest.Report, error) {
+	notifier, err := notify.NewNotifierFromEnv(ctx)
+	if err != nil {
+		return digest.Report{}, fmt.Errorf("failed to initialize notifier: %w", err)
+	}
+
+	g, err := digest.NewGenerator(ctx, env.GetEnv("ENV"), notifier)
+	if err != nil {
+		return digest.Report{}, fmt.Errorf("failed to initialize digest generator: %w", err)
+	}
+
+	return g.Run(ctx, time.Now().UTC())
+}
+
+func preview(ctx context.Context, familyID, format, lang string) {
+	g, err := digest.NewGenerator(ctx, env.GetEnv("ENV"), &notify.LogNotifier{})
+	if err != nil {
+		logger.Fatalf("failed to initialize digest generator: %v", err)
+	}
+
+	d, err := g.Build(ctx, familyID, time.Now().UTC())
+	if err != nil {
+		logger.Fatalf("failed to build digest: %v", err)
+	}
+
+	msg, err := digest.Render(d, lang)
+	if err != nil {
+		logger.Fatalf("failed to render digest: %v", err)
+	}
+
+	switch format {
+	case "html":
+		fmt.Println(msg.HTML)
+	default:
+		fmt.Println(msg.Subject)
+		fmt.Println()
+		fmt.Println(msg.Text)
+	}
+}
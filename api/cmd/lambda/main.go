@@ -7,21 +7,38 @@ import (
 	awslambda "github.com/aws/aws-lambda-go/lambda"
 	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
 	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/achievements"
 	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/handlers/admin"
 	"github.com/sebboness/yektaspoints/handlers/family"
 	"github.com/sebboness/yektaspoints/handlers/points"
 	userHandlers "github.com/sebboness/yektaspoints/handlers/user"
 	"github.com/sebboness/yektaspoints/handlers/userauth"
+	"github.com/sebboness/yektaspoints/middleware"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/attachment"
 	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/eventbus"
 	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/sebboness/yektaspoints/util/webhook"
 )
 
+var adminCtrl *admin.AdminController
 var authCtrl *userauth.UserAuthController
 var familyCtrl *family.FamilyController
 var lambdaCtrl *handlers.LambdaController
 var pointsCtrl *points.PointsController
 var userCtrl *userHandlers.UserController
 
+var stores storage.Storages
+var attachmentStore attachment.Store
+var eventBroker eventbus.Broker
+var achievementService *achievements.Service
+var notifyService *notify.Service
+var webhookDispatcher *webhook.Dispatcher
+var webhookQueue webhook.Queue
+
 var ginLambda *ginadapter.GinLambda
 var logger *log.Logger
 
@@ -43,6 +60,53 @@ func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.API
 		"authorizer":       req.RequestContext.Authorizer,
 	}).Infof("starting lambda")
 
+	initialize(ctx, _env)
+
+	if ginLambda == nil {
+		logger.Infof("gin cold start")
+		r := gin.Default()
+
+		RegisterRoutes(r)
+
+		ginLambda = ginadapter.New(r)
+	}
+
+	// prepare context with authorizer info provided in lambda event
+	ctx = handlers.PrepareAuthorizedContext(ctx, req)
+
+	return ginLambda.ProxyWithContext(ctx, req)
+}
+
+// initialize creates the controllers and storage that haven't been created yet
+func initialize(ctx context.Context, _env string) {
+	// initialize storages, which are shared by all controllers and services
+	if stores.Core == nil {
+		logger.Infof("initializing new storages")
+		cfg, err := storage.ConfigFromEnv(_env)
+		if err != nil {
+			logger.Fatalf("failed to read storage config: %v", err)
+		}
+
+		_s, err := storage.NewStorages(cfg)
+		if err != nil {
+			logger.Fatalf("failed to initialize storages: %v", err)
+		}
+
+		stores = _s
+		middleware.UseUserStorage(stores.Core)
+	}
+
+	// initialize admin controller
+	if adminCtrl == nil {
+		logger.Infof("initializing new admin controller")
+		_c, err := admin.NewAdminController(ctx, stores)
+		if err != nil {
+			logger.Fatalf("failed to initialize admin controller: %v", err)
+		}
+
+		adminCtrl = _c
+	}
+
 	// initialize auth user controller
 	if authCtrl == nil {
 		logger.Infof("initializing new user controller")
@@ -54,21 +118,41 @@ func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.API
 		authCtrl = _c
 	}
 
+	// initialize attachment store, which keeps files attached to point requests
+	if attachmentStore == nil {
+		logger.Infof("initializing new attachment store")
+		_s, err := attachment.NewStoreFromEnv(ctx)
+		if err != nil {
+			logger.Fatalf("failed to initialize attachment store: %v", err)
+		}
+
+		attachmentStore = _s
+	}
+
+	// initialize event broker. Lambda runs in many containers at once, so events are kept in
+	// DynamoDB where every container sees them. The standalone server sets the in-memory bus instead.
+	if eventBroker == nil {
+		logger.Infof("initializing new event store")
+		eventBroker = eventbus.NewStore(stores.DynamoDb, stores.Core)
+	}
+
 	// initialize family controller
 	if familyCtrl == nil {
 		logger.Infof("initializing new family controller")
-		_c, err := family.NewFamilyController(ctx, _env)
+		_c, err := family.NewFamilyController(ctx, stores)
 		if err != nil {
 			logger.Fatalf("failed to initialize family controller: %v", err)
 		}
 
 		familyCtrl = _c
+		familyCtrl.UseAttachmentStore(attachmentStore)
+		familyCtrl.UseEventSubscriber(eventBroker)
 	}
 
 	// intialize catchall lambda controller
 	if lambdaCtrl == nil {
 		logger.Infof("initializing new lambda controller")
-		_c, err := handlers.NewLambdaController(ctx, _env)
+		_c, err := handlers.NewLambdaController(ctx, stores)
 		if err != nil {
 			logger.Fatalf("failed to initialize lambda controller: %v", err)
 		}
@@ -76,42 +160,86 @@ func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.API
 		lambdaCtrl = _c
 	}
 
+	// initialize notification service, which notifies users about point requests and decisions
+	if notifyService == nil {
+		logger.Infof("initializing new notification service")
+		notifier, err := notify.NewNotifierFromEnv(ctx)
+		if err != nil {
+			logger.Fatalf("failed to initialize notifier: %v", err)
+		}
+
+		notifyService = notify.NewService(stores.Core, stores.Core, notifier)
+	}
+
+	// initialize webhook dispatcher, which delivers events to family webhooks and sends their
+	// notifications. Lambda only uses it through the webhook worker (see below).
+	if webhookDispatcher == nil {
+		logger.Infof("initializing new webhook dispatcher")
+		webhookDispatcher = webhook.NewDispatcher(stores.Core, stores.DynamoDb, webhook.DefaultConfig)
+		webhookDispatcher.UseEventHandler(notifyService)
+	}
+
+	// initialize webhook queue. Lambda freezes once the response is sent, so events are sent to
+	// the queue of the webhook worker lambda there, which delivers them and sends notifications.
+	// The standalone server does both itself.
+	if webhookQueue == nil {
+		logger.Infof("initializing new webhook queue")
+		_q, err := webhook.NewQueueFromEnv(ctx, webhookDispatcher)
+		if err != nil {
+			logger.Fatalf("failed to initialize webhook queue: %v", err)
+		}
+
+		webhookQueue = _q
+	}
+
+	// initialize achievements service, which awards achievements once points are settled
+	if achievementService == nil {
+		logger.Infof("initializing new achievements service")
+		achievementService = achievements.NewService(stores.DynamoDb, stores.Core, stores.Core, newAchievementPublisher(eventBroker, webhookQueue))
+	}
+
 	if pointsCtrl == nil {
 		logger.Infof("initializing new points controller")
-		_c, err := points.NewPointsController(ctx, _env)
+		_c, err := points.NewPointsController(ctx, stores)
 		if err != nil {
 			logger.Fatalf("failed to initialize points controller: %v", err)
 		}
 
 		pointsCtrl = _c
+		pointsCtrl.UseAttachmentStore(attachmentStore)
+		pointsCtrl.UseEventPublisher(newPointsPublisher(eventBroker, webhookQueue, achievementService))
 	}
 
 	// initialize user controller
 	if userCtrl == nil {
 		logger.Infof("initializing new user controller")
-		_c, err := userHandlers.NewUserController(ctx, _env)
+		_c, err := userHandlers.NewUserController(ctx, stores)
 		if err != nil {
 			logger.Fatalf("failed to initialize user controller: %v", err)
 		}
 
 		userCtrl = _c
 	}
+}
 
-	if ginLambda == nil {
-		logger.Infof("gin cold start")
-		r := gin.Default()
-
-		RegisterRoutes(r)
-
-		ginLambda = ginadapter.New(r)
+func main() {
+	// run as a standalone server if an address to listen on is given, i.e. SERVER_ADDR=:8080
+	if addr := env.GetEnv("SERVER_ADDR"); addr != "" {
+		runServer(addr)
+		return
 	}
 
-	// prepare context with authorizer info provided in lambda event
-	ctx = handlers.PrepareAuthorizedContext(ctx, req)
+	awslambda.Start(Handler)
+}
 
-	return ginLambda.ProxyWithContext(ctx, req)
+// newPointsPublisher returns the publisher of point events. Events go to the broker, are evaluated
+// for achievements and queued for webhooks and notifications, so requests don't wait for emails.
+func newPointsPublisher(broker eventbus.Publisher, queue webhook.Queue, achievementService *achievements.Service) eventbus.Publisher {
+	return webhook.NewPublisher(achievements.NewPublisher(broker, achievementService), queue)
 }
 
-func main() {
-	awslambda.Start(Handler)
+// newAchievementPublisher returns the publisher of earned achievements and their bonus points.
+// They aren't evaluated again, the service awards everything that was earned at once.
+func newAchievementPublisher(broker eventbus.Publisher, queue webhook.Queue) eventbus.Publisher {
+	return webhook.NewPublisher(broker, queue)
 }
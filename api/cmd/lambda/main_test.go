@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sebboness/yektaspoints/achievements"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	webhookmocks "github.com/sebboness/yektaspoints/mocks/webhook"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_newPointsPublisher(t *testing.T) {
+	type state struct {
+		evt eventbus.Event
+	}
+	type want struct {
+		events []eventbus.EventType // published to the broker, and queued for webhooks and notifications
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cashout := models.PointSummary{ID: "p1", UserID: "1", Points: -5, Reason: "Ice cream", Type: models.PointRequestTypeCashout,
+		DecidedByUserID: "2", Decision: models.PointRequestDecisionApprove}
+	award := models.PointSummary{ID: "p2", UserID: "1", Points: 3, Reason: "Helped with dishes", Type: models.PointRequestTypeAdd,
+		DecidedByUserID: "2", Decision: models.PointRequestDecisionApprove}
+
+	cases := []test{
+		{"points decided", state{eventbus.Event{Type: eventbus.EventTypePointsDecided, UserID: "1", Data: cashout}},
+			want{[]eventbus.EventType{eventbus.EventTypePointsDecided, eventbus.EventTypeAchievementEarned}}},
+		{"balance changed", state{eventbus.Event{Type: eventbus.EventTypeBalanceChanged, UserID: "1", Data: award}},
+			want{[]eventbus.EventType{eventbus.EventTypeBalanceChanged, eventbus.EventTypeAchievementEarned}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAchievementDB := mocks.NewMockIAchievementStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockBroker := evtmocks.NewMockPublisher(t)
+			mockQueue := webhookmocks.NewMockQueue(t)
+
+			child := models.User{UserID: "1", Name: "Yekta", FamilyIDs: []string{"456"}, Roles: []string{models.RoleChild}}
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(child, nil)
+
+			// every default achievement but the first cashout was earned already
+			earned := []models.Achievement{}
+			for _, r := range models.DefaultAchievementRules {
+				if r.ID != "first-cashout" {
+					earned = append(earned, models.Achievement{UserID: "1", RuleID: r.ID})
+				}
+			}
+			mockAchievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{}, nil).Once()
+			mockAchievementDB.EXPECT().GetAchievementsByUserID(mock.Anything, "1").Return(earned, nil).Once()
+			mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "1", mock.Anything).Return([]models.Point{
+				{Status: models.PointStatusSettled, Points: -5, Balance: bal(5), Request: models.PointRequest{Type: models.PointRequestTypeCashout}},
+			}, nil).Once()
+			mockAchievementDB.EXPECT().AddAchievement(mock.Anything, mock.MatchedBy(func(a models.Achievement) bool {
+				return a.RuleID == "first-cashout" && a.UserID == "1"
+			})).Return(true, nil).Once()
+
+			for _, evtType := range c.want.events {
+				evtType := evtType
+				isType := func(evt eventbus.Event) bool {
+					return evt.Type == evtType && evt.UserID == "1" && evt.ID != ""
+				}
+				mockBroker.EXPECT().Publish(mock.Anything, mock.MatchedBy(isType)).Once()
+				mockQueue.EXPECT().Enqueue(mock.Anything, mock.MatchedBy(isType)).Return(nil).Once()
+			}
+
+			achievementService := achievements.NewService(mockAchievementDB, mockPointsDB, mockUserDB, newAchievementPublisher(mockBroker, mockQueue))
+
+			p := newPointsPublisher(mockBroker, mockQueue, achievementService)
+			p.Publish(context.Background(), c.state.evt)
+
+			mockAchievementDB.AssertExpectations(t)
+			mockBroker.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockQueue.AssertExpectations(t)
+		})
+	}
+}
+
+func bal(v int) *int {
+	return &v
+}
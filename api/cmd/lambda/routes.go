@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/sebboness/yektaspoints/middleware"
+	"github.com/sebboness/yektaspoints/models"
 )
 
 func RegisterRoutes(r *gin.Engine) *gin.Engine {
@@ -18,23 +19,76 @@ func RegisterRoutes(r *gin.Engine) *gin.Engine {
 	// User registration
 	r.POST("/v1/user/register", userCtrl.UserRegisterHandler)
 	r.POST("/v1/user/register/confirm", userCtrl.UserRegisterConfirmHandler)
+	r.POST("/v1/user/register/resend", userCtrl.UserRegisterResendHandler)
+
+	// a changed email is unverified until the user verifies it, so verifying must not require a verified email
+	r.POST("/v1/user/email/verify", middleware.WithAuthenticatedUser(), userCtrl.UserEmailVerifyHandler)
 
 	authedUserRoutes := r.Group("/v1")
 	authedUserRoutes.Use(middleware.WithAuthorizedUser())
 	{
-		r.GET("/v1/health", lambdaCtrl.HealthCheckHandler)
+		authedUserRoutes.GET("/health", lambdaCtrl.HealthCheckHandler)
+
+		// events
+		authedUserRoutes.GET("/events", middleware.RequireRole(models.RoleParent, models.RoleChild), familyCtrl.GetEventsHandler)
 
 		// family
-		r.GET("/v1/family", familyCtrl.GetFamilyHandler)
+		authedUserRoutes.GET("/family", middleware.RequireRole(models.RoleParent, models.RoleChild), familyCtrl.GetFamilyHandler)
+		authedUserRoutes.DELETE("/family/:family_id", middleware.RequireRole(models.RoleParent), familyCtrl.DeleteFamilyHandler)
+		authedUserRoutes.GET("/family/:family_id/achievement-rules", middleware.RequireRole(models.RoleParent, models.RoleChild), familyCtrl.GetAchievementRulesHandler)
+		authedUserRoutes.POST("/family/:family_id/achievement-rules", middleware.RequireRole(models.RoleParent), familyCtrl.CreateAchievementRuleHandler)
+		authedUserRoutes.DELETE("/family/:family_id/achievement-rules/:rule_id", middleware.RequireRole(models.RoleParent), familyCtrl.DeleteAchievementRuleHandler)
+		authedUserRoutes.GET("/family/:family_id/export", middleware.RequireRole(models.RoleParent), familyCtrl.ExportFamilyHandler)
+		authedUserRoutes.GET("/family/:family_id/points", middleware.RequireRole(models.RoleParent, models.RoleChild), familyCtrl.GetFamilyPointsHandler)
+		authedUserRoutes.GET("/family/:family_id/point-types", middleware.RequireRole(models.RoleParent, models.RoleChild), familyCtrl.GetPointTypesHandler)
+		authedUserRoutes.POST("/family/:family_id/point-types", middleware.RequireRole(models.RoleParent), familyCtrl.CreatePointTypeHandler)
+		authedUserRoutes.PUT("/family/:family_id/point-types/:point_type_id", middleware.RequireRole(models.RoleParent), familyCtrl.UpdatePointTypeHandler)
+		authedUserRoutes.GET("/family/:family_id/settings", middleware.RequireRole(models.RoleParent, models.RoleChild), familyCtrl.GetFamilySettingsHandler)
+		authedUserRoutes.PUT("/family/:family_id/settings", middleware.RequireRole(models.RoleParent), familyCtrl.UpdateFamilySettingsHandler)
+		authedUserRoutes.GET("/family/:family_id/webhooks", middleware.RequireRole(models.RoleParent), familyCtrl.GetWebhooksHandler)
+		authedUserRoutes.POST("/family/:family_id/webhooks", middleware.RequireRole(models.RoleParent), familyCtrl.CreateWebhookHandler)
+		authedUserRoutes.DELETE("/family/:family_id/webhooks/:webhook_id", middleware.RequireRole(models.RoleParent), familyCtrl.DeleteWebhookHandler)
+		authedUserRoutes.GET("/family/:family_id/webhooks/:webhook_id/deliveries", middleware.RequireRole(models.RoleParent), familyCtrl.GetWebhookDeliveriesHandler)
 
 		// Points
-		r.GET("/v1/points/:point_id", pointsCtrl.GetUserPointsHandler)
-		r.GET("/v1/points/summary/:user_id", pointsCtrl.GetPointsSummaryHandler)
-		r.GET("/v1/points/user/:user_id", pointsCtrl.GetUserPointsHandler)
-		r.POST("/v1/points", pointsCtrl.RequestPointsHandler)
+		pointsRoutes := authedUserRoutes.Group("/points")
+		pointsRoutes.Use(middleware.RequireRole(models.RoleParent, models.RoleChild))
+		{
+			pointsRoutes.GET("/:point_id", pointsCtrl.GetUserPointsHandler)
+			pointsRoutes.GET("/summary/:user_id", pointsCtrl.GetPointsSummaryHandler)
+			pointsRoutes.GET("/analytics/:user_id", pointsCtrl.GetPointsAnalyticsHandler)
+			pointsRoutes.GET("/user/:user_id", pointsCtrl.GetUserPointsHandler)
+			pointsRoutes.GET("/user/:user_id/export", pointsCtrl.ExportUserPointsHandler)
+			pointsRoutes.GET("/user/:user_id/:point_id", pointsCtrl.GetPointHandler)
+			pointsRoutes.PUT("/user/:user_id/:point_id", middleware.RequireRole(models.RoleChild), pointsCtrl.AmendPointsHandler)
+			pointsRoutes.POST("/user/:user_id/:point_id/decision", middleware.RequireRole(models.RoleParent), pointsCtrl.DecidePointsHandler)
+			pointsRoutes.POST("/user/:user_id/:point_id/attachments", middleware.RequireRole(models.RoleChild), pointsCtrl.AddPointAttachmentHandler)
+			pointsRoutes.GET("/user/:user_id/:point_id/comments", pointsCtrl.GetPointCommentsHandler)
+			pointsRoutes.POST("/user/:user_id/:point_id/comments", pointsCtrl.AddPointCommentHandler)
+			pointsRoutes.POST("", middleware.RequireRole(models.RoleChild), pointsCtrl.RequestPointsHandler)
+			pointsRoutes.POST("/cashout", middleware.RequireRole(models.RoleChild), pointsCtrl.RequestCashoutHandler)
+			pointsRoutes.POST("/award", middleware.RequireRole(models.RoleParent), pointsCtrl.AwardPointsHandler)
+		}
 
 		// User
-		r.GET("/v1/user", userCtrl.GetUserHandler)
+		authedUserRoutes.GET("/user", userCtrl.GetUserHandler)
+		authedUserRoutes.PATCH("/user", userCtrl.UpdateUserHandler)
+		authedUserRoutes.GET("/user/achievements", userCtrl.GetUserAchievementsHandler)
+		authedUserRoutes.PATCH("/user/:user_id", middleware.RequireRole(models.RoleParent), userCtrl.UpdateChildUserHandler)
+		authedUserRoutes.POST("/user/mfa/totp/associate", middleware.RequireRole(models.RoleParent), userCtrl.UserMfaAssociateHandler)
+		authedUserRoutes.POST("/user/mfa/totp/verify", middleware.RequireRole(models.RoleParent), userCtrl.UserMfaVerifyHandler)
+
+		// Admin
+		adminRoutes := authedUserRoutes.Group("/admin")
+		adminRoutes.Use(middleware.RequireRole(models.RoleAdmin))
+		{
+			adminRoutes.GET("/users", adminCtrl.GetUsersHandler)
+			adminRoutes.GET("/users/:user_id/audit", adminCtrl.GetUserAuditHandler)
+			adminRoutes.GET("/users/:user_id/points", adminCtrl.GetUserPointsHandler)
+			adminRoutes.PUT("/users/:user_id/family", adminCtrl.UpdateUserFamilyHandler)
+			adminRoutes.PUT("/users/:user_id/roles", adminCtrl.UpdateUserRolesHandler)
+			adminRoutes.PUT("/users/:user_id/status", adminCtrl.UpdateUserStatusHandler)
+		}
 	}
 
 	return r
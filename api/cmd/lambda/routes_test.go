@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RegisterRoutes_requireAuth(t *testing.T) {
+	type test struct {
+		method string
+		path   string
+	}
+
+	// routes that set up a user's account must only be reached by the user
+	cases := []test{
+		{"POST", "/v1/user/mfa/totp/associate"},
+		{"POST", "/v1/user/mfa/totp/verify"},
+		{"PATCH", "/v1/user"},
+	}
+
+	r := RegisterRoutes(gin.New())
+
+	for _, c := range cases {
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/middleware"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	"github.com/sebboness/yektaspoints/util/auth"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// runServer runs the API as a standalone server instead of a lambda. Without API gateway in front,
+// ID tokens are verified by the server itself, and events can be streamed to clients.
+func runServer(addr string) {
+	ctx := context.Background()
+
+	logger = log.NewLogger("mypoints_server")
+
+	_env := env.GetEnv("ENV")
+
+	logger.WithContext(ctx).WithFields(map[string]any{
+		"addr": addr,
+		"env":  _env,
+	}).Infof("starting server")
+
+	// a single server sees all events, so they don't need to be stored
+	eventBroker = eventbus.Get()
+
+	initialize(ctx, _env)
+
+	familyCtrl.EnableStreaming()
+
+	r := gin.Default()
+	r.Use(middleware.WithTokenAuthorizer(auth.NewTokenVerifier()))
+
+	RegisterRoutes(r)
+
+	// without a bucket, the server serves attachments itself
+	if local, ok := attachmentStore.(*attachment.LocalStore); ok {
+		r.GET(attachment.LocalPathPrefix+"*key", gin.WrapH(local))
+		r.PUT(attachment.LocalPathPrefix+"*key", gin.WrapH(local))
+	}
+
+	if err := r.Run(addr); err != nil {
+		logger.Fatalf("failed to run server: %v", err)
+	}
+}
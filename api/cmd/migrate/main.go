@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/sebboness/yektaspoints/migrate"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// Creates the tables of an environment and applies the migrations that haven't been applied to
+// it yet. Uses the same environment variables as the lambda (ENV, AWS_REGION, DYNAMODB_*, etc.)
+//
+// Usage: go run ./cmd/migrate [-dry-run] [-endpoint http://localhost:8000]
+//
+// With -endpoint (or DYNAMODB_ENDPOINT), migrations run against that DynamoDB endpoint (i.e.
+// DynamoDB Local) instead of AWS. DynamoDB Local accepts any credentials, but the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_REGION variables still have to be set.
+//
+// With STORAGE_BACKEND=sqlite or postgres, the SQL migrations of points, users and families run
+// against the database at STORAGE_DSN instead. Run it again with STORAGE_BACKEND=dynamodb for the
+// tables that are always kept in DynamoDB.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report pending migrations without applying them")
+	endpoint := flag.String("endpoint", "", "DynamoDB endpoint to use instead of AWS")
+	flag.Parse()
+
+	ctx := context.Background()
+	logger := log.NewLogger("mypoints_migrate")
+
+	_env := env.GetEnv("ENV")
+
+	cfg, err := storage.ConfigFromEnv(_env)
+	if err != nil {
+		logger.Fatalf("failed to read storage config: %v", err)
+	}
+
+	if *endpoint != "" {
+		cfg.Endpoint = *endpoint
+	}
+
+	var m interface {
+		Run(ctx context.Context, dryRun bool) (migrate.Report, error)
+	}
+
+	switch cfg.Backend {
+	case storage.BackendPostgres, storage.BackendSQLite:
+		s, err := storage.NewSQLStorage(cfg)
+		if err != nil {
+			logger.Fatalf("failed to initialize %s storage: %v", cfg.Backend, err)
+		}
+		defer s.Close()
+
+		m = migrate.NewSQLMigrator(s, migrate.SQLMigrations)
+	default:
+		_m, err := migrate.NewMigrator(ctx, cfg)
+		if err != nil {
+			logger.Fatalf("failed to initialize migrator: %v", err)
+		}
+
+		m = _m
+	}
+
+	report, err := m.Run(ctx, *dryRun)
+	if err != nil {
+		logger.Fatalf("failed to migrate: %v", err)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Fatalf("failed to marshal report: %v", err)
+	}
+
+	fmt.Println(string(out))
+}
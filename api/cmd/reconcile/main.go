@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/sebboness/yektaspoints/reconcile"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// Finds users that only exist in either cognito or the user table and repairs them.
+// Uses the same environment variables as the lambda (ENV, COGNITO_USER_POOL_ID, etc.)
+//
+// Usage: go run ./cmd/reconcile [-dry-run]
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report repairs without applying them")
+	flag.Parse()
+
+	ctx := context.Background()
+	logger := log.NewLogger("mypoints_reconcile")
+
+	_env := env.GetEnv("ENV")
+
+	r, err := reconcile.NewReconciler(ctx, _env)
+	if err != nil {
+		logger.Fatalf("failed to initialize reconciler: %v", err)
+	}
+
+	report, err := r.Run(ctx, *dryRun)
+	if err != nil {
+		logger.Fatalf("failed to reconcile users: %v", err)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Fatalf("failed to marshal report: %v", err)
+	}
+
+	fmt.Println(string(out))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	awslambda "github.com/aws/aws-lambda-go/lambda"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/sebboness/yektaspoints/util/webhook"
+)
+
+var dispatcher *webhook.Dispatcher
+var logger *log.Logger
+
+// Delivers the events the main lambda sends to the webhook queue to the webhooks of the event's
+// families, and sends their notifications. Runs as a lambda that receives messages from the queue.
+func main() {
+	logger = log.NewLogger("mypoints_webhook")
+	awslambda.Start(Handler)
+}
+
+// Handler delivers the events of the queue's messages and sends their notifications. Messages of events whose webhooks couldn't
+// be looked up are reported as failed, so the queue retries them (and eventually moves them to the
+// dead-letter queue). Webhooks that don't respond are retried by the dispatcher, and recorded as
+// failed deliveries once they run out of attempts.
+func Handler(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	resp := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+
+	if dispatcher == nil {
+		d, err := newDispatcher(ctx, env.GetEnv("ENV"))
+		if err != nil {
+			return resp, err
+		}
+		dispatcher = d
+	}
+
+	for _, msg := range sqsEvent.Records {
+		if err := deliver(ctx, msg); err != nil {
+			logger.WithContext(ctx).WithFields(map[string]any{
+				"error":      err.Error(),
+				"message_id": msg.MessageId,
+			}).Errorf("failed to deliver event")
+
+			resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+		}
+	}
+
+	return resp, nil
+}
+
+func deliver(ctx context.Context, msg events.SQSMessage) error {
+	var evt eventbus.Event
+	if err := json.Unmarshal([]byte(msg.Body), &evt); err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	logger.WithContext(ctx).WithFields(map[string]any{
+		"event_id":   evt.ID,
+		"event_type": evt.Type,
+		"message_id": msg.MessageId,
+	}).Infof("delivering event")
+
+	return dispatcher.Process(ctx, evt)
+}
+
+func newDispatcher(ctx context.Context, _env string) (*webhook.Dispatcher, error) {
+	cfg, err := storage.ConfigFromEnv(_env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage config: %w", err)
+	}
+
+	stores, err := storage.NewStorages(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	notifier, err := notify.NewNotifierFromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// users are kept in the backend of the storage config, webhooks always in DynamoDB
+	d := webhook.NewDispatcher(stores.Core, stores.DynamoDb, webhook.DefaultConfig)
+	d.UseEventHandler(notify.NewService(stores.Core, stores.Core, notifier))
+
+	return d, nil
+}
@@ -0,0 +1,249 @@
+package digest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/sebboness/yektaspoints/util/notify"
+)
+
+// Period is how far back a digest looks
+const Period = 7 * 24 * time.Hour
+
+// ChildDigest sums up a child's points over the digest period. Lost and cashed out points are positive.
+type ChildDigest struct {
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	Earned    int    `json:"earned"`
+	Lost      int    `json:"lost"`
+	CashedOut int    `json:"cashed_out"`
+	Pending   int    `json:"pending"`
+}
+
+type Digest struct {
+	FamilyID string        `json:"family_id"`
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	Children []ChildDigest `json:"children"`
+
+	// Parents the digest is sent to
+	Parents []models.User `json:"-"`
+}
+
+type Report struct {
+	Families int      `json:"families"`
+	Sent     int      `json:"sent"`
+	Errors   []string `json:"errors"`
+}
+
+// Generator builds weekly family digests and sends them to parents
+type Generator struct {
+	familyDB storage.IFamilyStorage
+	notifier notify.Notifier
+	pointsDB storage.IPointsStorage
+	userDB   storage.IUserStorage
+}
+
+func NewGenerator(ctx context.Context, env string, notifier notify.Notifier) (*Generator, error) {
+	storageCfg, err := storage.ConfigFromEnv(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage config: %w", err)
+	}
+
+	db, err := storage.NewStorage(storageCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize db: %w", err)
+	}
+
+	return &Generator{
+		familyDB: db,
+		notifier: notifier,
+		pointsDB: db,
+		userDB:   db,
+	}, nil
+}
+
+// Build builds the digest of the family for the period before the given time
+func (g *Generator) Build(ctx context.Context, familyID string, now time.Time) (Digest, error) {
+	familyUsers, err := g.familyDB.GetFamilyUsers(ctx, familyID)
+	if err != nil {
+		return Digest{}, fmt.Errorf("failed to get family users: %w", err)
+	}
+
+	members := []models.User{}
+	for _, fu := range familyUsers {
+		user, err := g.userDB.GetUserByID(ctx, fu.UserID)
+		if err != nil {
+			if errors.Is(err, apierr.NotFound) {
+				continue
+			}
+			return Digest{}, fmt.Errorf("failed to get user: %w", err)
+		}
+		members = append(members, user)
+	}
+
+	return g.build(ctx, familyID, members, now)
+}
+
+// Run sends the digest of every family to its parents. Families are taken from a scan of all
+// users, so this is only meant to run as a scheduled job.
+func (g *Generator) Run(ctx context.Context, now time.Time) (Report, error) {
+	report := Report{Errors: []string{}}
+	logger := log.Get().WithContext(ctx)
+
+	users, err := g.userDB.GetAllUsers(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	families := map[string][]models.User{}
+	for _, u := range users {
+		for _, fid := range u.FamilyIDs {
+			families[fid] = append(families[fid], u)
+		}
+	}
+
+	familyIDs := make([]string, 0, len(families))
+	for fid := range families {
+		familyIDs = append(familyIDs, fid)
+	}
+	slices.Sort(familyIDs)
+
+	report.Families = len(familyIDs)
+
+	for _, fid := range familyIDs {
+		d, err := g.build(ctx, fid, families[fid], now)
+		if err == nil {
+			var sent int
+			sent, err = g.Send(ctx, d)
+			report.Sent += sent
+		}
+
+		if err != nil {
+			logger.WithFields(map[string]any{
+				"error":     err.Error(),
+				"family_id": fid,
+			}).Errorf("failed to send digest")
+			report.Errors = append(report.Errors, fmt.Sprintf("family %s: %s", fid, err.Error()))
+		}
+	}
+
+	return report, nil
+}
+
+// Send renders the digest in each parent's language and sends it to them. Returns the number
+// of parents the digest was sent to.
+func (g *Generator) Send(ctx context.Context, d Digest) (int, error) {
+	if len(d.Children) == 0 {
+		return 0, nil
+	}
+
+	sent := 0
+	errs := []error{}
+
+	for _, parent := range d.Parents {
+		if !parent.Notifications.WeeklyDigestEnabled() {
+			continue
+		}
+
+		msg, err := Render(d, parent.Notifications.Language)
+		if err != nil {
+			return sent, fmt.Errorf("failed to render digest: %w", err)
+		}
+
+		msg.Recipient = parent
+		msg.Data = map[string]string{
+			"family_id": d.FamilyID,
+			"type":      "weekly_digest",
+		}
+
+		if err := g.notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify user %s: %w", parent.UserID, err))
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+func (g *Generator) build(ctx context.Context, familyID string, members []models.User, now time.Time) (Digest, error) {
+	from := now.Add(-Period)
+
+	d := Digest{
+		FamilyID: familyID,
+		From:     from,
+		To:       now,
+		Children: []ChildDigest{},
+		Parents:  []models.User{},
+	}
+
+	filter := models.QueryPointsFilter{
+		UpdatedOn: *models.NewDateFilter().WithRange(from, now),
+		Statuses: []models.PointStatus{
+			models.PointStatusSettled,
+			models.PointStatusWaiting,
+		},
+		Types: []models.PointRequestType{
+			models.PointRequestTypeCashout,
+			models.PointRequestTypeAdd,
+			models.PointRequestTypeSubtract,
+		},
+		Attributes: []string{
+			"id",
+			"updated_on",
+			"points",
+			"balance",
+			"point_type_id",
+			"status",
+			"request.type",
+		},
+	}
+
+	for _, u := range members {
+		if u.Status == models.UserStatusDeleted {
+			continue
+		}
+
+		if u.IsParent() {
+			d.Parents = append(d.Parents, u)
+			continue
+		}
+
+		if !u.IsChild() {
+			continue
+		}
+
+		points, err := g.pointsDB.GetPointsByUserID(ctx, u.UserID, filter)
+		if err != nil {
+			return d, fmt.Errorf("failed to get points of user %s: %w", u.UserID, err)
+		}
+
+		// the digest only sums up points of the default type
+		up := models.UserPoints{}
+		up.Summarize(from, models.PointsOfType(points, models.DefaultPointTypeID))
+
+		d.Children = append(d.Children, ChildDigest{
+			UserID:    u.UserID,
+			Name:      u.Name,
+			Earned:    up.PointsLast7Days - up.PointsLostLast7Days,
+			Lost:      -up.PointsLostLast7Days,
+			CashedOut: -up.PointsCashedOutLast7Days,
+			Pending:   up.PointsPendingLast7Days,
+		})
+	}
+
+	slices.SortFunc(d.Children, func(a, b ChildDigest) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return d, nil
+}
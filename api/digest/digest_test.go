@@ -0,0 +1,194 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+var (
+	now  = time.Date(2024, 3, 10, 17, 0, 0, 0, time.UTC)
+	off  = false
+	mom  = models.User{UserID: "p1", Name: "Jane", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}, Email: "mom@example.com"}
+	dad  = models.User{UserID: "p2", Name: "John", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}, Notifications: models.NotificationPreferences{WeeklyDigest: &off}}
+	kid1 = models.User{UserID: "c1", Name: "Yekta", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+	kid2 = models.User{UserID: "c2", Name: "Sara", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+	gone = models.User{UserID: "c3", Name: "Gone", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}, Status: models.UserStatusDeleted}
+)
+
+func bal(v int) *int {
+	return &v
+}
+
+func testPoints() []models.Point {
+	return []models.Point{
+		{ID: "1", Status: "WAITING", Points: 2, UpdatedOn: now.AddDate(0, 0, -1), Request: models.PointRequest{Type: "ADD"}},
+		{ID: "2", Status: "SETTLED", Points: 5, Balance: bal(10), UpdatedOn: now.AddDate(0, 0, -2), Request: models.PointRequest{Type: "ADD"}},
+		{ID: "3", Status: "SETTLED", Points: -3, Balance: bal(5), UpdatedOn: now.AddDate(0, 0, -3), Request: models.PointRequest{Type: "CASHOUT"}},
+		{ID: "4", Status: "SETTLED", Points: -1, Balance: bal(8), UpdatedOn: now.AddDate(0, 0, -4), Request: models.PointRequest{Type: "SUBTRACT"}},
+		{ID: "5", Status: "SETTLED", Points: 4, Balance: bal(9), UpdatedOn: now.AddDate(0, 0, -5), Request: models.PointRequest{Type: "ADD"}},
+		{ID: "6", Status: "SETTLED", PointTypeID: "st", Points: 30, Balance: bal(30), UpdatedOn: now.AddDate(0, 0, -1), Request: models.PointRequest{Type: "ADD"}},
+	}
+}
+
+func Test_Generator_Build(t *testing.T) {
+	type state struct {
+		errFamily error
+		errUser   error
+		errPoints error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"happy path - user not found", state{errUser: apierr.New(apierr.NotFound)}, want{}},
+		{"fail - get family users", state{errFamily: errFail}, want{"failed to get family users: fail"}},
+		{"fail - get user", state{errUser: errFail}, want{"failed to get user: fail"}},
+		{"fail - get points", state{errPoints: errFail}, want{"failed to get points of user c2: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockFamilyDB := mocks.NewMockIFamilyStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			mockFamilyDB.EXPECT().GetFamilyUsers(mock.Anything, "f1").Return([]models.FamilyUser{
+				{FamilyID: "f1", UserID: "p1"},
+				{FamilyID: "f1", UserID: "c1"},
+				{FamilyID: "f1", UserID: "c2"},
+				{FamilyID: "f1", UserID: "c4"},
+			}, c.state.errFamily).Once()
+
+			if c.state.errFamily == nil {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "p1").Return(mom, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c1").Return(kid1, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c2").Return(kid2, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c4").Return(models.User{}, c.state.errUser).Once()
+			}
+
+			if c.state.errFamily == nil && (c.state.errUser == nil || errors.Is(c.state.errUser, apierr.NotFound)) {
+				mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "c1", mock.MatchedBy(func(f models.QueryPointsFilter) bool {
+					return f.UpdatedOn.From.Equal(now.Add(-Period)) && f.UpdatedOn.To.Equal(now)
+				})).Return(testPoints(), nil).Once()
+				mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "c2", mock.Anything).Return([]models.Point{}, c.state.errPoints).Once()
+			}
+
+			g := Generator{familyDB: mockFamilyDB, pointsDB: mockPointsDB, userDB: mockUserDB}
+
+			d, err := g.Build(context.Background(), "f1", now)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, "f1", d.FamilyID)
+				assert.Equal(t, []models.User{mom}, d.Parents)
+				assert.Equal(t, []ChildDigest{
+					{UserID: "c2", Name: "Sara"},
+					{UserID: "c1", Name: "Yekta", Earned: 9, Lost: 1, CashedOut: 3, Pending: 2},
+				}, d.Children)
+			}
+
+			mockFamilyDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Generator_Run(t *testing.T) {
+	type state struct {
+		errUsers  error
+		errPoints error
+	}
+	type want struct {
+		report Report
+		err    string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{Report{Families: 2, Sent: 1, Errors: []string{}}, ""}},
+		{"fail - get points", state{errPoints: errFail}, want{Report{Families: 2, Sent: 0, Errors: []string{"family f1: failed to get points of user c1: fail"}}, ""}},
+		{"fail - get users", state{errUsers: errFail}, want{Report{Errors: []string{}}, "failed to get users: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			notifier := &notify.MemoryNotifier{}
+
+			// f2 only has a parent, so there is nothing to send
+			lonely := models.User{UserID: "p3", Name: "Lonely", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleParent}}
+
+			mockUserDB.EXPECT().GetAllUsers(mock.Anything).Return([]models.User{mom, dad, kid1, gone, lonely}, c.state.errUsers).Once()
+			if c.state.errUsers == nil {
+				mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "c1", mock.Anything).Return(testPoints(), c.state.errPoints).Once()
+			}
+
+			g := Generator{notifier: notifier, pointsDB: mockPointsDB, userDB: mockUserDB}
+
+			report, err := g.Run(context.Background(), now)
+			tests.AssertError(t, err, c.want.err)
+			assert.Equal(t, c.want.report, report)
+
+			// dad turned the digest off
+			msgs := notifier.Messages()
+			assert.Len(t, msgs, c.want.report.Sent)
+			for _, msg := range msgs {
+				assert.Equal(t, "p1", msg.Recipient.UserID)
+				assert.Equal(t, "f1", msg.Data["family_id"])
+			}
+
+			mockUserDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Render(t *testing.T) {
+	d := Digest{
+		FamilyID: "f1",
+		From:     now.Add(-Period),
+		To:       now,
+		Children: []ChildDigest{{UserID: "c1", Name: "<Yekta>", Earned: 9, Lost: 1, CashedOut: 3, Pending: 2}},
+	}
+
+	msg, err := Render(d, "en")
+	assert.Nil(t, err)
+	assert.Equal(t, "Your family's week: Mar 3 - Mar 10", msg.Subject)
+	assert.Contains(t, msg.Text, "<Yekta>\n  Earned:      9\n  Lost:        1\n  Cashed out:  3\n  Pending:     2\n")
+	assert.Contains(t, msg.HTML, "<tr><td>&lt;Yekta&gt;</td><td>9</td><td>1</td><td>3</td><td>2</td></tr>")
+
+	msg, err = Render(d, "es")
+	assert.Nil(t, err)
+	assert.Equal(t, "La semana de tu familia: 03/03 - 10/03", msg.Subject)
+
+	// unsupported languages fall back to english
+	msg, err = Render(d, "xx")
+	assert.Nil(t, err)
+	assert.Equal(t, "Your family's week: Mar 3 - Mar 10", msg.Subject)
+}
@@ -0,0 +1,101 @@
+package digest
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"text/template"
+
+	"github.com/sebboness/yektaspoints/util/notify"
+)
+
+type templateSource struct {
+	subject string
+	text    string
+	html    string
+}
+
+var templateSources = map[string]templateSource{
+	"en": {
+		subject: `Your family's week: {{.From.Format "Jan 2"}} - {{.To.Format "Jan 2"}}`,
+		text: `Here's how your family did from {{.From.Format "Jan 2"}} to {{.To.Format "Jan 2"}}:
+{{range .Children}}
+{{.Name}}
+  Earned:      {{.Earned}}
+  Lost:        {{.Lost}}
+  Cashed out:  {{.CashedOut}}
+  Pending:     {{.Pending}}
+{{end}}`,
+		html: `<p>Here's how your family did from {{.From.Format "Jan 2"}} to {{.To.Format "Jan 2"}}:</p>
+<table>
+<tr><th></th><th>Earned</th><th>Lost</th><th>Cashed out</th><th>Pending</th></tr>
+{{range .Children}}<tr><td>{{.Name}}</td><td>{{.Earned}}</td><td>{{.Lost}}</td><td>{{.CashedOut}}</td><td>{{.Pending}}</td></tr>
+{{end}}</table>`,
+	},
+	"es": {
+		subject: `La semana de tu familia: {{.From.Format "02/01"}} - {{.To.Format "02/01"}}`,
+		text: `Así le fue a tu familia del {{.From.Format "02/01"}} al {{.To.Format "02/01"}}:
+{{range .Children}}
+{{.Name}}
+  Ganados:     {{.Earned}}
+  Perdidos:    {{.Lost}}
+  Canjeados:   {{.CashedOut}}
+  Pendientes:  {{.Pending}}
+{{end}}`,
+		html: `<p>Así le fue a tu familia del {{.From.Format "02/01"}} al {{.To.Format "02/01"}}:</p>
+<table>
+<tr><th></th><th>Ganados</th><th>Perdidos</th><th>Canjeados</th><th>Pendientes</th></tr>
+{{range .Children}}<tr><td>{{.Name}}</td><td>{{.Earned}}</td><td>{{.Lost}}</td><td>{{.CashedOut}}</td><td>{{.Pending}}</td></tr>
+{{end}}</table>`,
+	},
+}
+
+type templateSet struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[string]templateSet {
+	sets := map[string]templateSet{}
+	for lang, src := range templateSources {
+		sets[lang] = templateSet{
+			subject: template.Must(template.New("subject").Parse(src.subject)),
+			text:    template.Must(template.New("text").Parse(src.text)),
+			html:    htmltemplate.Must(htmltemplate.New("html").Parse(src.html)),
+		}
+	}
+	return sets
+}
+
+// Render renders the digest as a message in the given language. Unsupported languages fall back
+// to the default language.
+func Render(d Digest, lang string) (notify.Message, error) {
+	if _, ok := templates[lang]; !ok {
+		lang = notify.DefaultLanguage
+	}
+
+	msg := notify.Message{}
+	set := templates[lang]
+
+	var buf bytes.Buffer
+	if err := set.subject.Execute(&buf, d); err != nil {
+		return msg, err
+	}
+	msg.Subject = buf.String()
+
+	buf.Reset()
+	if err := set.text.Execute(&buf, d); err != nil {
+		return msg, err
+	}
+	msg.Text = buf.String()
+
+	buf.Reset()
+	if err := set.html.Execute(&buf, d); err != nil {
+		return msg, err
+	}
+	msg.HTML = buf.String()
+
+	return msg, nil
+}
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/auth"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/segmentio/ksuid"
+)
+
+type AdminController struct {
+	auth     auth.AuthController
+	auditDB  storage.IAuditStorage
+	familyDB storage.IFamilyStorage
+	pointsDB storage.IPointsStorage
+	userDB   storage.IUserStorage
+}
+
+func NewAdminController(ctx context.Context, stores storage.Storages) (*AdminController, error) {
+	authController, err := auth.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth controller: %w", err)
+	}
+
+	return &AdminController{
+		auth:     authController,
+		auditDB:  stores.DynamoDb,
+		familyDB: stores.Core,
+		pointsDB: stores.Core,
+		userDB:   stores.Core,
+	}, nil
+}
+
+// audit records an admin action in the audit trail.
+// The action itself already happened at this point, so a failure to record it is logged but not returned.
+func (c *AdminController) audit(ctx context.Context, actorUserID, userID string, action models.AuditAction, details map[string]string) {
+	entry := models.AuditEntry{
+		ID:           ksuid.New().String(),
+		UserID:       userID,
+		ActorUserID:  actorUserID,
+		Action:       action,
+		Details:      details,
+		CreatedOnStr: util.ToFormattedUTC(time.Now()),
+	}
+
+	if err := c.auditDB.SaveAuditEntry(ctx, entry); err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"action":        action,
+			"actor_user_id": actorUserID,
+			"error":         err.Error(),
+			"user_id":       userID,
+		}).Errorf("failed to save audit entry")
+	}
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+func Test_Controller_New(t *testing.T) {
+	stores, err := storage.NewStorages(storage.Config{Env: "local"})
+	assert.Nil(t, err)
+
+	c, err := NewAdminController(context.Background(), stores)
+	tests.AssertError(t, err, "")
+	assert.NotNil(t, c)
+}
+
+func Test_Controller_audit(t *testing.T) {
+	type state struct {
+		errSave error
+	}
+	type test struct {
+		name string
+		state
+	}
+
+	cases := []test{
+		{"happy path", state{}},
+		{"save error is not returned", state{errSave: errFail}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			auditDB := mocks.NewMockIAuditStorage(t)
+			auditDB.EXPECT().SaveAuditEntry(mock.Anything, mock.MatchedBy(func(e models.AuditEntry) bool {
+				return e.ID != "" && e.UserID == "2" && e.ActorUserID == "1" && e.Action == models.AuditActionUserStatus
+			})).Return(c.state.errSave).Once()
+
+			ctrl := AdminController{
+				auditDB: auditDB,
+			}
+
+			ctrl.audit(context.Background(), "1", "2", models.AuditActionUserStatus, nil)
+
+			auditDB.AssertExpectations(t)
+		})
+	}
+}
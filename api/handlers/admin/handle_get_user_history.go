@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getUserHistoryRequest struct {
+	ActorUserID string `json:"-"`
+	UserID      string `json:"-"`
+}
+
+type getUserPointsResponse struct {
+	Points []models.Point `json:"points"`
+}
+
+type getUserAuditResponse struct {
+	Entries []models.AuditEntry `json:"entries"`
+}
+
+// GetUserPointsHandler returns the full points ledger of a user
+func (c *AdminController) GetUserPointsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+
+	req := &getUserHistoryRequest{
+		ActorUserID: authInfo.GetUserID(),
+		UserID:      cgin.Param("user_id"),
+	}
+
+	resp, err := c.handleGetUserPoints(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+// GetUserAuditHandler returns the admin actions recorded for a user, latest first
+func (c *AdminController) GetUserAuditHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+
+	req := &getUserHistoryRequest{
+		ActorUserID: authInfo.GetUserID(),
+		UserID:      cgin.Param("user_id"),
+	}
+
+	resp, err := c.handleGetUserAudit(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *AdminController) handleGetUserPoints(ctx context.Context, req *getUserHistoryRequest) (getUserPointsResponse, error) {
+	resp := getUserPointsResponse{}
+
+	if err := validateGetUserHistory(req); err != nil {
+		return resp, err
+	}
+
+	points, err := c.pointsDB.GetPointsByUserID(ctx, req.UserID, models.QueryPointsFilter{})
+	if err != nil {
+		return resp, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	c.audit(ctx, req.ActorUserID, req.UserID, models.AuditActionUserPoints, nil)
+
+	resp.Points = points
+	return resp, nil
+}
+
+func (c *AdminController) handleGetUserAudit(ctx context.Context, req *getUserHistoryRequest) (getUserAuditResponse, error) {
+	resp := getUserAuditResponse{}
+
+	if err := validateGetUserHistory(req); err != nil {
+		return resp, err
+	}
+
+	entries, err := c.auditDB.GetAuditEntriesByUserID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	resp.Entries = entries
+	return resp, nil
+}
+
+func validateGetUserHistory(req *getUserHistoryRequest) error {
+	if req.ActorUserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing user_id")
+	}
+
+	return nil
+}
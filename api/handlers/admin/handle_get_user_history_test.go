@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetUserPointsHandler(t *testing.T) {
+	type state struct {
+		missingUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - missing user", state{missingUser: true}, want{"missing user_id", http.StatusBadRequest}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get points: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			auditDB := mocks.NewMockIAuditStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+
+			if !c.state.missingUser {
+				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "2", models.QueryPointsFilter{}).Return([]models.Point{{ID: "1", UserID: "2"}}, c.state.err).Once()
+
+				if c.state.err == nil {
+					auditDB.EXPECT().SaveAuditEntry(mock.Anything, mock.MatchedBy(func(e models.AuditEntry) bool {
+						return e.Action == models.AuditActionUserPoints
+					})).Return(nil).Once()
+				}
+			}
+
+			ctrl := AdminController{
+				auditDB:  auditDB,
+				pointsDB: pointsDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			if !c.state.missingUser {
+				cgin.AddParam("user_id", "2")
+			}
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetUserPointsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			auditDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_GetUserAuditHandler(t *testing.T) {
+	type state struct {
+		missingUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - missing user", state{missingUser: true}, want{"missing user_id", http.StatusBadRequest}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get audit entries: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			auditDB := mocks.NewMockIAuditStorage(t)
+
+			if !c.state.missingUser {
+				auditDB.EXPECT().GetAuditEntriesByUserID(mock.Anything, "2").Return([]models.AuditEntry{{ID: "1", UserID: "2"}}, c.state.err).Once()
+			}
+
+			ctrl := AdminController{
+				auditDB: auditDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			if !c.state.missingUser {
+				cgin.AddParam("user_id", "2")
+			}
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetUserAuditHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			auditDB.AssertExpectations(t)
+		})
+	}
+}
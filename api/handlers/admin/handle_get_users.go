@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+type getUsersRequest struct {
+	Email    string `json:"-"`
+	Username string `json:"-"`
+
+	// Set in code
+	ActorUserID string `json:"-"`
+}
+
+type getUsersResponse struct {
+	Users []models.User `json:"users"`
+}
+
+// GetUsersHandler looks up users by their username or email
+func (c *AdminController) GetUsersHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+
+	req := &getUsersRequest{
+		Email:       cgin.Query("email"),
+		Username:    cgin.Query("username"),
+		ActorUserID: authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetUsers(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *AdminController) handleGetUsers(ctx context.Context, req *getUsersRequest) (getUsersResponse, error) {
+	resp := getUsersResponse{
+		Users: []models.User{},
+	}
+
+	if err := validateGetUsers(req); err != nil {
+		return resp, err
+	}
+
+	attribute, value := "username", req.Username
+	if req.Email != "" {
+		attribute, value = "email", req.Email
+	}
+
+	userIDs, err := c.auth.ListUserIDsByAttribute(ctx, attribute, value)
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"attribute": attribute,
+			"error":     err.Error(),
+		}).Errorf("failed to list users")
+		return resp, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		user, err := c.userDB.GetUserByID(ctx, userID)
+		if err != nil {
+			return resp, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		c.audit(ctx, req.ActorUserID, user.UserID, models.AuditActionUserLookup, map[string]string{
+			attribute: value,
+		})
+
+		resp.Users = append(resp.Users, user)
+	}
+
+	return resp, nil
+}
+
+func validateGetUsers(req *getUsersRequest) error {
+	if req.ActorUserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	if req.Email == "" && req.Username == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing username or email")
+	}
+
+	if req.Email != "" && req.Username != "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("provide either username or email, not both")
+	}
+
+	return nil
+}
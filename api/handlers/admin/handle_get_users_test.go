@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetUsersHandler(t *testing.T) {
+	type state struct {
+		query      string
+		errList    error
+		errGetUser error
+	}
+	type want struct {
+		err   string
+		code  int
+		users int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - username", state{query: "?username=john"}, want{"", http.StatusOK, 1}},
+		{"happy path - email", state{query: "?email=john@info.co"}, want{"", http.StatusOK, 1}},
+		{"fail - missing query", state{}, want{"missing username or email", http.StatusBadRequest, 0}},
+		{"fail - list error", state{query: "?username=john", errList: errFail}, want{"failed to list users: fail", http.StatusInternalServerError, 0}},
+		{"fail - get user error", state{query: "?username=john", errGetUser: errFail}, want{"failed to get user: fail", http.StatusInternalServerError, 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			auditDB := mocks.NewMockIAuditStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			if c.state.query != "" {
+				mockAuther.EXPECT().ListUserIDsByAttribute(mock.Anything, mock.Anything, mock.Anything).Return([]string{"2"}, c.state.errList).Once()
+
+				if c.state.errList == nil {
+					userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(models.User{UserID: "2"}, c.state.errGetUser).Once()
+
+					if c.state.errGetUser == nil {
+						auditDB.EXPECT().SaveAuditEntry(mock.Anything, mock.Anything).Return(nil).Once()
+					}
+				}
+			}
+
+			ctrl := AdminController{
+				auth:    mockAuther,
+				auditDB: auditDB,
+				userDB:  userDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("GET", "/"+c.state.query, nil).WithContext(ctx)
+
+			ctrl.GetUsersHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				data := result.Data.(map[string]any)
+				assert.Len(t, data["users"], c.want.users)
+			}
+
+			mockAuther.AssertExpectations(t)
+			auditDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateGetUsers(t *testing.T) {
+	type test struct {
+		name string
+		req  getUsersRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", getUsersRequest{ActorUserID: "1", Username: "john"}, ""},
+		{"fail - missing actor", getUsersRequest{Username: "john"}, "unauthorized: missing user ID"},
+		{"fail - missing username and email", getUsersRequest{ActorUserID: "1"}, "missing username or email"},
+		{"fail - both username and email", getUsersRequest{ActorUserID: "1", Username: "john", Email: "john@info.co"}, "provide either username or email, not both"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateGetUsers(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
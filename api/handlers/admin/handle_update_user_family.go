@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type updateUserFamilyRequest struct {
+	FromFamilyID string `json:"from_family_id"`
+	ToFamilyID   string `json:"to_family_id"`
+
+	// Set in code
+	ActorUserID string `json:"-"`
+	UserID      string `json:"-"`
+}
+
+// UpdateUserFamilyHandler moves a user from one family to another. Either family may be omitted
+// to only remove a user from a family, or to only add them to one.
+func (c *AdminController) UpdateUserFamilyHandler(cgin *gin.Context) {
+
+	var req updateUserFamilyRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.ActorUserID = authInfo.GetUserID()
+	req.UserID = cgin.Param("user_id")
+
+	err = c.handleUpdateUserFamily(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(nil))
+}
+
+func (c *AdminController) handleUpdateUserFamily(ctx context.Context, req *updateUserFamilyRequest) error {
+
+	if err := validateUpdateUserFamily(req); err != nil {
+		return err
+	}
+
+	// the user and both families are updated together, so a failure can't leave the user in no family
+	err := c.familyDB.MoveFamilyUser(ctx, req.UserID, req.FromFamilyID, req.ToFamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to move user to family: %w", err)
+	}
+
+	c.audit(ctx, req.ActorUserID, req.UserID, models.AuditActionUserFamily, map[string]string{
+		"from": req.FromFamilyID,
+		"to":   req.ToFamilyID,
+	})
+
+	return nil
+}
+
+func validateUpdateUserFamily(req *updateUserFamilyRequest) error {
+	if req.ActorUserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.UserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if req.FromFamilyID == "" && req.ToFamilyID == "" {
+		apierr.AppendError("missing from_family_id or to_family_id")
+	} else if req.FromFamilyID == req.ToFamilyID {
+		apierr.AppendError("from_family_id and to_family_id must be different")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_handleUpdateUserFamily(t *testing.T) {
+	type state struct {
+		from    string
+		to      string
+		errMove error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	errNotInFamily := apierr.New(apierr.NotFound).WithError("user (id=2) in family (family_id=f1)")
+
+	cases := []test{
+		{"happy path - move", state{from: "f1", to: "f2"}, want{}},
+		{"happy path - remove only", state{from: "f1"}, want{}},
+		{"happy path - add only", state{to: "f2"}, want{}},
+		{"fail - same family", state{from: "f1", to: "f1"}, want{"from_family_id and to_family_id must be different"}},
+		{"fail - not in from family", state{from: "f1", to: "f2", errMove: errNotInFamily}, want{"failed to move user to family: resource not found: user (id=2) in family (family_id=f1)"}},
+		{"fail - move error", state{from: "f1", to: "f2", errMove: errFail}, want{"failed to move user to family: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			auditDB := mocks.NewMockIAuditStorage(t)
+			familyDB := mocks.NewMockIFamilyStorage(t)
+
+			if c.state.from != c.state.to {
+				familyDB.EXPECT().MoveFamilyUser(mock.Anything, "2", c.state.from, c.state.to).Return(c.state.errMove).Once()
+
+				if c.state.errMove == nil {
+					auditDB.EXPECT().SaveAuditEntry(mock.Anything, mock.MatchedBy(func(e models.AuditEntry) bool {
+						return e.Action == models.AuditActionUserFamily && e.Details["from"] == c.state.from && e.Details["to"] == c.state.to
+					})).Return(nil).Once()
+				}
+			}
+
+			ctrl := AdminController{
+				auditDB:  auditDB,
+				familyDB: familyDB,
+			}
+
+			err := ctrl.handleUpdateUserFamily(context.Background(), &updateUserFamilyRequest{
+				ActorUserID:  "1",
+				UserID:       "2",
+				FromFamilyID: c.state.from,
+				ToFamilyID:   c.state.to,
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			auditDB.AssertExpectations(t)
+			familyDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateUpdateUserFamily(t *testing.T) {
+	type test struct {
+		name string
+		req  updateUserFamilyRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", updateUserFamilyRequest{ActorUserID: "1", UserID: "2", ToFamilyID: "f2"}, ""},
+		{"fail - missing actor", updateUserFamilyRequest{UserID: "2", ToFamilyID: "f2"}, "unauthorized: missing user ID"},
+		{"fail - missing user", updateUserFamilyRequest{ActorUserID: "1", ToFamilyID: "f2"}, "missing user_id"},
+		{"fail - missing families", updateUserFamilyRequest{ActorUserID: "1", UserID: "2"}, "missing from_family_id or to_family_id"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateUpdateUserFamily(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+var supportedRoles = []string{models.RoleAdmin, models.RoleChild, models.RoleParent}
+
+type updateUserRolesRequest struct {
+	Roles []string `json:"roles"`
+
+	// Set in code
+	ActorUserID string `json:"-"`
+	UserID      string `json:"-"`
+}
+
+type updateUserRolesResponse struct {
+	User models.User `json:"user"`
+}
+
+// UpdateUserRolesHandler replaces the roles of a user, both in cognito and in the user table
+func (c *AdminController) UpdateUserRolesHandler(cgin *gin.Context) {
+
+	var req updateUserRolesRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.ActorUserID = authInfo.GetUserID()
+	req.UserID = cgin.Param("user_id")
+
+	resp, err := c.handleUpdateUserRoles(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *AdminController) handleUpdateUserRoles(ctx context.Context, req *updateUserRolesRequest) (updateUserRolesResponse, error) {
+	resp := updateUserRolesResponse{}
+
+	if err := validateUpdateUserRoles(req); err != nil {
+		return resp, err
+	}
+
+	user, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	for _, role := range req.Roles {
+		if slices.Contains(user.Roles, role) {
+			continue
+		}
+
+		if err := c.auth.AssignUserToRole(ctx, user.Username, role); err != nil {
+			log.Get().WithContext(ctx).WithFields(map[string]any{
+				"error":   err.Error(),
+				"role":    role,
+				"user_id": req.UserID,
+			}).Errorf("failed to assign user to role")
+			return resp, fmt.Errorf("failed to assign user to role: %w", err)
+		}
+	}
+
+	for _, role := range user.Roles {
+		if slices.Contains(req.Roles, role) {
+			continue
+		}
+
+		if err := c.auth.RemoveUserFromRole(ctx, user.Username, role); err != nil {
+			log.Get().WithContext(ctx).WithFields(map[string]any{
+				"error":   err.Error(),
+				"role":    role,
+				"user_id": req.UserID,
+			}).Errorf("failed to remove user from role")
+			return resp, fmt.Errorf("failed to remove user from role: %w", err)
+		}
+	}
+
+	err = c.userDB.UpdateUserRoles(ctx, req.UserID, req.Roles)
+	if err != nil {
+		return resp, fmt.Errorf("failed to update user roles: %w", err)
+	}
+
+	c.audit(ctx, req.ActorUserID, req.UserID, models.AuditActionUserRoles, map[string]string{
+		"from": strings.Join(user.Roles, ","),
+		"to":   strings.Join(req.Roles, ","),
+	})
+
+	user.Roles = req.Roles
+	resp.User = user
+	return resp, nil
+}
+
+func validateUpdateUserRoles(req *updateUserRolesRequest) error {
+	if req.ActorUserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.UserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if len(req.Roles) == 0 {
+		apierr.AppendError("missing roles")
+	}
+
+	for _, role := range req.Roles {
+		if !slices.Contains(supportedRoles, role) {
+			apierr.AppendErrorf("invalid role: %s", role)
+		}
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
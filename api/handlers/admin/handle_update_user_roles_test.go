@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_handleUpdateUserRoles(t *testing.T) {
+	type state struct {
+		errGetUser error
+		errAssign  error
+		errRemove  error
+		errUpdate  error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - get user error", state{errGetUser: errFail}, want{"failed to get user: fail"}},
+		{"fail - assign error", state{errAssign: errFail}, want{"failed to assign user to role: fail"}},
+		{"fail - remove error", state{errRemove: errFail}, want{"failed to remove user from role: fail"}},
+		{"fail - update error", state{errUpdate: errFail}, want{"failed to update user roles: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			auditDB := mocks.NewMockIAuditStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			// user goes from [child parent] to [parent admin]
+			user := models.User{UserID: "2", Username: "jane", Roles: []string{models.RoleChild, models.RoleParent}}
+			newRoles := []string{models.RoleParent, models.RoleAdmin}
+
+			userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(user, c.state.errGetUser).Once()
+
+			if c.state.errGetUser == nil {
+				mockAuther.EXPECT().AssignUserToRole(mock.Anything, "jane", models.RoleAdmin).Return(c.state.errAssign).Once()
+
+				if c.state.errAssign == nil {
+					mockAuther.EXPECT().RemoveUserFromRole(mock.Anything, "jane", models.RoleChild).Return(c.state.errRemove).Once()
+
+					if c.state.errRemove == nil {
+						userDB.EXPECT().UpdateUserRoles(mock.Anything, "2", newRoles).Return(c.state.errUpdate).Once()
+
+						if c.state.errUpdate == nil {
+							auditDB.EXPECT().SaveAuditEntry(mock.Anything, mock.MatchedBy(func(e models.AuditEntry) bool {
+								return e.Details["from"] == "child,parent" && e.Details["to"] == "parent,admin"
+							})).Return(nil).Once()
+						}
+					}
+				}
+			}
+
+			ctrl := AdminController{
+				auth:    mockAuther,
+				auditDB: auditDB,
+				userDB:  userDB,
+			}
+
+			resp, err := ctrl.handleUpdateUserRoles(context.Background(), &updateUserRolesRequest{
+				ActorUserID: "1",
+				UserID:      "2",
+				Roles:       newRoles,
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, newRoles, resp.User.Roles)
+			}
+
+			mockAuther.AssertExpectations(t)
+			auditDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateUpdateUserRoles(t *testing.T) {
+	type test struct {
+		name string
+		req  updateUserRolesRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", updateUserRolesRequest{ActorUserID: "1", UserID: "2", Roles: []string{"parent"}}, ""},
+		{"fail - missing actor", updateUserRolesRequest{UserID: "2", Roles: []string{"parent"}}, "unauthorized: missing user ID"},
+		{"fail - missing user", updateUserRolesRequest{ActorUserID: "1", Roles: []string{"parent"}}, "missing user_id"},
+		{"fail - missing roles", updateUserRolesRequest{ActorUserID: "1", UserID: "2"}, "missing roles"},
+		{"fail - invalid role", updateUserRolesRequest{ActorUserID: "1", UserID: "2", Roles: []string{"boss"}}, "invalid role: boss"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateUpdateUserRoles(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
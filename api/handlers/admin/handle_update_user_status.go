@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+type updateUserStatusRequest struct {
+	Status models.UserStatus `json:"status"`
+
+	// Set in code
+	ActorUserID string `json:"-"`
+	UserID      string `json:"-"`
+}
+
+type updateUserStatusResponse struct {
+	User models.User `json:"user"`
+}
+
+// UpdateUserStatusHandler activates or deactivates a user. Inactive and deleted users are also
+// disabled in cognito, so they can no longer log in.
+func (c *AdminController) UpdateUserStatusHandler(cgin *gin.Context) {
+
+	var req updateUserStatusRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.ActorUserID = authInfo.GetUserID()
+	req.UserID = cgin.Param("user_id")
+
+	resp, err := c.handleUpdateUserStatus(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *AdminController) handleUpdateUserStatus(ctx context.Context, req *updateUserStatusRequest) (updateUserStatusResponse, error) {
+	resp := updateUserStatusResponse{}
+
+	if err := validateUpdateUserStatus(req); err != nil {
+		return resp, err
+	}
+
+	user, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if req.Status == models.UserStatusActive {
+		err = c.auth.EnableUser(ctx, user.Username)
+	} else {
+		err = c.auth.DisableUser(ctx, user.Username)
+	}
+
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":   err.Error(),
+			"status":  req.Status,
+			"user_id": req.UserID,
+		}).Errorf("failed to update user status in cognito")
+		return resp, fmt.Errorf("failed to update user status in cognito: %w", err)
+	}
+
+	err = c.userDB.UpdateUserStatus(ctx, req.UserID, req.Status)
+	if err != nil {
+		return resp, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	c.audit(ctx, req.ActorUserID, req.UserID, models.AuditActionUserStatus, map[string]string{
+		"from": string(user.Status),
+		"to":   string(req.Status),
+	})
+
+	user.Status = req.Status
+	resp.User = user
+	return resp, nil
+}
+
+func validateUpdateUserStatus(req *updateUserStatusRequest) error {
+	if req.ActorUserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.UserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	switch req.Status {
+	case models.UserStatusActive, models.UserStatusDeleted, models.UserStatusInactive:
+	case "":
+		apierr.AppendError("missing status")
+	default:
+		apierr.AppendErrorf("invalid status: %s", req.Status)
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
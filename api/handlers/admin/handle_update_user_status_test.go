@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_UpdateUserStatusHandler(t *testing.T) {
+	type state struct {
+		invalidBody bool
+		status      models.UserStatus
+		errGetUser  error
+		errAuth     error
+		errUpdate   error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - activate", state{status: models.UserStatusActive}, want{"", http.StatusOK}},
+		{"happy path - deactivate", state{status: models.UserStatusInactive}, want{"", http.StatusOK}},
+		{"happy path - delete", state{status: models.UserStatusDeleted}, want{"", http.StatusOK}},
+		{"fail - invalid body", state{invalidBody: true}, want{"failed to unmarshal json body", http.StatusBadRequest}},
+		{"fail - invalid status", state{status: models.UserStatusUnverified}, want{"invalid status: UNVERIFIED", http.StatusBadRequest}},
+		{"fail - get user error", state{status: models.UserStatusActive, errGetUser: errFail}, want{"failed to get user: fail", http.StatusInternalServerError}},
+		{"fail - cognito error", state{status: models.UserStatusInactive, errAuth: errFail}, want{"failed to update user status in cognito: fail", http.StatusInternalServerError}},
+		{"fail - update error", state{status: models.UserStatusActive, errUpdate: errFail}, want{"failed to update user status: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			evtBody, _ := json.Marshal(updateUserStatusRequest{Status: c.state.status})
+			evtBodyStr := string(evtBody)
+			if c.state.invalidBody {
+				evtBodyStr = `{"status":`
+			}
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			auditDB := mocks.NewMockIAuditStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			isValid := !c.state.invalidBody && c.state.status != models.UserStatusUnverified
+
+			if isValid {
+				user := models.User{UserID: "2", Username: "jane", Status: models.UserStatusActive}
+				userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(user, c.state.errGetUser).Once()
+
+				if c.state.errGetUser == nil {
+					if c.state.status == models.UserStatusActive {
+						mockAuther.EXPECT().EnableUser(mock.Anything, "jane").Return(c.state.errAuth).Once()
+					} else {
+						mockAuther.EXPECT().DisableUser(mock.Anything, "jane").Return(c.state.errAuth).Once()
+					}
+
+					if c.state.errAuth == nil {
+						userDB.EXPECT().UpdateUserStatus(mock.Anything, "2", c.state.status).Return(c.state.errUpdate).Once()
+
+						if c.state.errUpdate == nil {
+							auditDB.EXPECT().SaveAuditEntry(mock.Anything, mock.MatchedBy(func(e models.AuditEntry) bool {
+								return e.UserID == "2" && e.ActorUserID == "123" && e.Details["to"] == string(c.state.status)
+							})).Return(nil).Once()
+						}
+					}
+				}
+			}
+
+			ctrl := AdminController{
+				auth:    mockAuther,
+				auditDB: auditDB,
+				userDB:  userDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("user_id", "2")
+			cgin.Request = httptest.NewRequest("PUT", "/", bytes.NewReader([]byte(evtBodyStr))).WithContext(ctx)
+
+			ctrl.UpdateUserStatusHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockAuther.AssertExpectations(t)
+			auditDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateUpdateUserStatus(t *testing.T) {
+	type test struct {
+		name string
+		req  updateUserStatusRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", updateUserStatusRequest{ActorUserID: "1", UserID: "2", Status: models.UserStatusInactive}, ""},
+		{"fail - missing actor", updateUserStatusRequest{UserID: "2", Status: models.UserStatusInactive}, "unauthorized: missing user ID"},
+		{"fail - missing user", updateUserStatusRequest{ActorUserID: "1", Status: models.UserStatusInactive}, "missing user_id"},
+		{"fail - missing status", updateUserStatusRequest{ActorUserID: "1", UserID: "2"}, "missing status"},
+		{"fail - invalid status", updateUserStatusRequest{ActorUserID: "1", UserID: "2", Status: "NOPE"}, "invalid status: NOPE"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateUpdateUserStatus(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
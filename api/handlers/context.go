@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/gin-gonic/gin"
@@ -43,6 +45,12 @@ func PrepareAuthorizedContext(ctx context.Context, req events.APIGatewayProxyReq
 	return context.WithValue(ctx, ctxKeyAuthInfo, authorizer)
 }
 
+// PrepareAuthorizedContextWithClaims puts the given token claims into the context the same way
+// PrepareAuthorizedContext does with the claims API gateway passes on
+func PrepareAuthorizedContextWithClaims(ctx context.Context, claims map[string]any) context.Context {
+	return context.WithValue(ctx, ctxKeyAuthInfo, AuthorizerInfo{Claims: claims})
+}
+
 func GetAuthorizerInfo(c *gin.Context) AuthorizerInfo {
 	if c.Request != nil {
 		ctx := c.Request.Context()
@@ -81,9 +89,34 @@ func (i AuthorizerInfo) GetEmail() string {
 	return i.ValueOrEmpty(claimKeyEmail)
 }
 
+// GetGroups returns the cognito groups the user belongs to.
+// API Gateway passes the groups claim on as a string, either comma separated ("parent,admin")
+// or as a bracketed list ("[parent admin]"), so both formats are handled here.
 func (i AuthorizerInfo) GetGroups() []string {
-	groupStr := i.ValueOrEmpty(claimKeyGroups)
-	return strings.Split(groupStr, ",")
+	groupStr := strings.TrimSpace(i.ValueOrEmpty(claimKeyGroups))
+	groupStr = strings.TrimPrefix(groupStr, "[")
+	groupStr = strings.TrimSuffix(groupStr, "]")
+
+	groups := []string{}
+	for _, g := range strings.FieldsFunc(groupStr, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		if g = strings.Trim(g, `"'`); g != "" {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups
+}
+
+// HasGroup returns true if the user belongs to any of the given cognito groups
+func (i AuthorizerInfo) HasGroup(groups ...string) bool {
+	for _, g := range i.GetGroups() {
+		if slices.Contains(groups, g) {
+			return true
+		}
+	}
+	return false
 }
 
 func (i AuthorizerInfo) GetName() string {
@@ -62,6 +62,23 @@ func Test_PrepareAuthorizedContext(t *testing.T) {
 	}
 }
 
+func Test_PrepareAuthorizedContextWithClaims(t *testing.T) {
+	ctx := PrepareAuthorizedContextWithClaims(context.Background(), map[string]any{
+		"sub":            "123",
+		"email_verified": true,
+		"cognito:groups": []any{"parent"},
+	})
+
+	w := httptest.NewRecorder()
+	cgin, _ := gin.CreateTestContext(w)
+	cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	info := GetAuthorizerInfo(cgin)
+	assert.Equal(t, "123", info.GetUserID())
+	assert.True(t, info.IsEmailVerified())
+	assert.True(t, info.HasGroup("parent"))
+}
+
 func Test_GetAuthorizerInfo(t *testing.T) {
 	type state struct {
 		setupCtxWithInfo bool
@@ -102,3 +119,59 @@ func Test_GetAuthorizerInfo(t *testing.T) {
 		})
 	}
 }
+
+func Test_AuthorizerInfo_GetGroups(t *testing.T) {
+	type state struct {
+		groups any
+	}
+	type want struct {
+		groups []string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"no groups claim", state{}, want{[]string{}}},
+		{"empty", state{groups: ""}, want{[]string{}}},
+		{"one group", state{groups: "parent"}, want{[]string{"parent"}}},
+		{"comma separated", state{groups: "parent,admin"}, want{[]string{"parent", "admin"}}},
+		{"comma separated with spaces", state{groups: "parent, admin"}, want{[]string{"parent", "admin"}}},
+		{"bracketed list", state{groups: "[parent admin]"}, want{[]string{"parent", "admin"}}},
+		{"bracketed list with commas", state{groups: "[parent, admin]"}, want{[]string{"parent", "admin"}}},
+		{"bracketed list with quotes", state{groups: `["parent","admin"]`}, want{[]string{"parent", "admin"}}},
+		{"empty bracketed list", state{groups: "[]"}, want{[]string{}}},
+		{"slice", state{groups: []any{"parent", "admin"}}, want{[]string{"parent", "admin"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			info := AuthorizerInfo{
+				Claims: map[string]any{
+					"sub": "123",
+				},
+			}
+
+			if c.state.groups != nil {
+				info.Claims["cognito:groups"] = c.state.groups
+			}
+
+			assert.Equal(t, c.want.groups, info.GetGroups())
+		})
+	}
+}
+
+func Test_AuthorizerInfo_HasGroup(t *testing.T) {
+	info := AuthorizerInfo{
+		Claims: map[string]any{
+			"cognito:groups": "[parent admin]",
+		},
+	}
+
+	assert.True(t, info.HasGroup("parent"))
+	assert.True(t, info.HasGroup("child", "admin"))
+	assert.False(t, info.HasGroup("child"))
+	assert.False(t, info.HasGroup())
+}
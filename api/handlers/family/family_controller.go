@@ -5,21 +5,90 @@ import (
 	"fmt"
 
 	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	"github.com/sebboness/yektaspoints/util/auth"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
 )
 
 type FamilyController struct {
-	familyDB storage.IFamilyStorage
-}
+	achievementDB  storage.IAchievementStorage
+	attachments    attachment.Store
+	auth           auth.AuthController
+	events         eventbus.Subscriber
+	familyDB       storage.IFamilyStorage
+	pointCommentDB storage.IPointCommentStorage
+	pointTypeDB    storage.IPointTypeStorage
+	pointsDB       storage.IPointsStorage
+	userDB         storage.IUserStorage
+	webhookDB      storage.IWebhookStorage
 
-func NewFamilyController(ctx context.Context, env string) (*FamilyController, error) {
-	storageCfg := storage.Config{Env: env}
+	// Whether events can be streamed to clients. Lambda buffers the whole response, so it can only long-poll.
+	streaming bool
+}
 
-	familyDB, err := storage.NewDynamoDbStorage(storageCfg)
+func NewFamilyController(ctx context.Context, stores storage.Storages) (*FamilyController, error) {
+	authController, err := auth.New(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize family db: %w", err)
+		return nil, fmt.Errorf("failed to initialize auth controller: %w", err)
 	}
 
 	return &FamilyController{
-		familyDB: familyDB,
+		achievementDB:  stores.DynamoDb,
+		auth:           authController,
+		events:         eventbus.Get(),
+		familyDB:       stores.Core,
+		pointCommentDB: stores.DynamoDb,
+		pointTypeDB:    stores.DynamoDb,
+		pointsDB:       stores.Core,
+		userDB:         stores.Core,
+		webhookDB:      stores.DynamoDb,
 	}, nil
 }
+
+// EnableStreaming lets the events handler stream events to clients that accept server-sent events
+func (c *FamilyController) EnableStreaming() {
+	c.streaming = true
+}
+
+// UseEventSubscriber sets where the events handler gets events from
+func (c *FamilyController) UseEventSubscriber(s eventbus.Subscriber) {
+	c.events = s
+}
+
+// UseAttachmentStore sets the store files attached to point requests are kept in
+func (c *FamilyController) UseAttachmentStore(s attachment.Store) {
+	c.attachments = s
+}
+
+// getFamilyUserIDs returns the IDs of all users in the family, or an access denied error
+// if the requesting user is not part of it
+func (c *FamilyController) getFamilyUserIDs(ctx context.Context, familyID, userID string) ([]string, error) {
+	logger := log.Get().AddFields(map[string]any{
+		"user_id":   userID,
+		"family_id": familyID,
+	})
+
+	familyUsers, err := c.familyDB.GetFamilyUsers(ctx, familyID)
+	if err != nil {
+		logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to get family users")
+		return nil, fmt.Errorf("failed to get family users: %w", err)
+	}
+
+	userIsPartOfFamily := false
+	userIds := make([]string, len(familyUsers))
+	for idx, fu := range familyUsers {
+		userIds[idx] = fu.UserID
+		if userID == fu.UserID {
+			userIsPartOfFamily = true
+		}
+	}
+
+	if !userIsPartOfFamily {
+		logger.Errorf("user is not part of family")
+		return nil, apierr.New(apierr.AccessDenied).WithError("user is not part of family")
+	}
+
+	return userIds, nil
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"testing"
 
+	"github.com/sebboness/yektaspoints/storage"
 	"github.com/sebboness/yektaspoints/util/tests"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_Controller_New(t *testing.T) {
-	c, err := NewFamilyController(context.Background(), "local")
+	stores, err := storage.NewStorages(storage.Config{Env: "local"})
+	assert.Nil(t, err)
+
+	c, err := NewFamilyController(context.Background(), stores)
 	tests.AssertError(t, err, "")
 	assert.NotNil(t, c)
 }
@@ -0,0 +1,149 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/segmentio/ksuid"
+)
+
+const maxAchievementRulesPerFamily = 50
+const maxAchievementBonusPoints = 1000
+
+type createAchievementRuleHandlerRequest struct {
+	BonusPoints int                        `json:"bonus_points"`
+	Category    string                     `json:"category"`
+	Description string                     `json:"description"`
+	FamilyID    string                     `json:"-"`
+	Name        string                     `json:"name"`
+	Threshold   int                        `json:"threshold"`
+	Type        models.AchievementRuleType `json:"type"`
+	UserID      string                     `json:"-"`
+}
+
+type createAchievementRuleHandlerResponse struct {
+	Rule models.AchievementRule `json:"rule"`
+}
+
+// CreateAchievementRuleHandler adds a rule children of the family can earn an achievement by
+func (c *FamilyController) CreateAchievementRuleHandler(cgin *gin.Context) {
+
+	var req createAchievementRuleHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.FamilyID = cgin.Param("family_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleCreateAchievementRule(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusCreated, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleCreateAchievementRule(ctx context.Context, req *createAchievementRuleHandlerRequest) (createAchievementRuleHandlerResponse, error) {
+	resp := createAchievementRuleHandlerResponse{}
+
+	if err := validateCreateAchievementRule(req); err != nil {
+		return resp, err
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	rules, err := c.achievementDB.GetAchievementRulesByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get achievement rules: %w", err)
+	}
+
+	if len(rules) >= maxAchievementRulesPerFamily {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("a family can have at most %d achievement rules", maxAchievementRulesPerFamily))
+	}
+
+	rule := models.AchievementRule{
+		FamilyID:        req.FamilyID,
+		ID:              ksuid.New().String(),
+		Name:            strings.TrimSpace(req.Name),
+		Description:     strings.TrimSpace(req.Description),
+		Type:            req.Type,
+		Threshold:       req.Threshold,
+		BonusPoints:     req.BonusPoints,
+		CreatedByUserID: req.UserID,
+		CreatedOnStr:    util.ToFormattedUTC(time.Now()),
+	}
+
+	// categories are matched against the normalized reason of points
+	if req.Category != "" {
+		rule.Category = models.PointCategory(models.Point{Request: models.PointRequest{Reason: req.Category}})
+	}
+
+	if err := c.achievementDB.SaveAchievementRule(ctx, rule); err != nil {
+		return resp, fmt.Errorf("failed to save achievement rule: %w", err)
+	}
+
+	rule.ParseTimes()
+	resp.Rule = rule
+
+	return resp, nil
+}
+
+func validateCreateAchievementRule(req *createAchievementRuleHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.FamilyID == "" {
+		apierr.AppendError("missing family_id")
+	}
+
+	if name := strings.TrimSpace(req.Name); name == "" || len(name) > 50 {
+		apierr.AppendError("name must be between 1 and 50 characters")
+	}
+
+	if len(req.Description) > 200 {
+		apierr.AppendError("description must not be longer than 200 characters")
+	}
+
+	if !req.Type.IsValid() {
+		apierr.AppendErrorf("unsupported rule type '%s'", req.Type)
+	} else if req.Type != models.AchievementRuleTypeFirstCashout && req.Threshold <= 0 {
+		apierr.AppendError("threshold must be a positive integer")
+	}
+
+	if req.BonusPoints < 0 || req.BonusPoints > maxAchievementBonusPoints {
+		apierr.AppendErrorf("bonus_points must be between 0 and %d", maxAchievementBonusPoints)
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package family
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_handleCreateAchievementRule(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		tooMany     bool
+		errGetRules error
+		errSave     error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family"}},
+		{"fail - too many rules", state{tooMany: true}, want{"a family can have at most 50 achievement rules"}},
+		{"fail - get rules", state{errGetRules: errFail}, want{"failed to get achievement rules: fail"}},
+		{"fail - save rule", state{errSave: errFail}, want{"failed to save achievement rule: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			achievementDB := mocks.NewMockIAchievementStorage(t)
+			familyDB := mocks.NewMockIFamilyStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "1"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			rules := []models.AchievementRule{}
+			if c.state.tooMany {
+				rules = make([]models.AchievementRule, maxAchievementRulesPerFamily)
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				achievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return(rules, c.state.errGetRules).Once()
+			}
+			if !c.state.invalidUser && !c.state.tooMany && c.state.errGetRules == nil {
+				achievementDB.EXPECT().SaveAchievementRule(mock.Anything, mock.MatchedBy(func(r models.AchievementRule) bool {
+					return r.FamilyID == "456" && r.ID != "" && r.Name == "Dishwasher" && r.Category == "empty the dishwasher" &&
+						r.CreatedByUserID == "1" && r.CreatedOnStr != ""
+				})).Return(c.state.errSave).Once()
+			}
+
+			ctrl := FamilyController{
+				achievementDB: achievementDB,
+				familyDB:      familyDB,
+			}
+
+			req := &createAchievementRuleHandlerRequest{
+				BonusPoints: 5,
+				Category:    " Empty the  Dishwasher",
+				FamilyID:    "456",
+				Name:        "Dishwasher ",
+				Threshold:   10,
+				Type:        models.AchievementRuleTypeTotal,
+				UserID:      "1",
+			}
+
+			res, err := ctrl.handleCreateAchievementRule(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.NotEmpty(t, res.Rule.ID)
+				assert.False(t, res.Rule.CreatedOn.IsZero())
+			}
+
+			achievementDB.AssertExpectations(t)
+			familyDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateCreateAchievementRule(t *testing.T) {
+	type state struct {
+		userID      string
+		familyID    string
+		name        string
+		ruleType    models.AchievementRuleType
+		threshold   int
+		bonusPoints int
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{"1", "456", "Dishwasher", models.AchievementRuleTypeTotal, 10, 5}, want{}},
+		{"happy path - first cashout without threshold", state{"1", "456", "First", models.AchievementRuleTypeFirstCashout, 0, 0}, want{}},
+		{"fail - missing user", state{"", "456", "Dishwasher", models.AchievementRuleTypeTotal, 10, 5}, want{"unauthorized: missing user ID"}},
+		{"fail - missing family", state{"1", "", "Dishwasher", models.AchievementRuleTypeTotal, 10, 5}, want{"missing family_id"}},
+		{"fail - missing name", state{"1", "456", " ", models.AchievementRuleTypeTotal, 10, 5}, want{"name must be between 1 and 50 characters"}},
+		{"fail - unsupported type", state{"1", "456", "Dishwasher", "blah", 10, 5}, want{"unsupported rule type 'blah'"}},
+		{"fail - missing threshold", state{"1", "456", "Dishwasher", models.AchievementRuleTypeStreak, 0, 5}, want{"threshold must be a positive integer"}},
+		{"fail - too many bonus points", state{"1", "456", "Dishwasher", models.AchievementRuleTypeTotal, 10, 1001}, want{"bonus_points must be between 0 and 1000"}},
+		{"fail - negative bonus points", state{"1", "456", "Dishwasher", models.AchievementRuleTypeTotal, 10, -1}, want{"bonus_points must be between 0 and 1000"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := &createAchievementRuleHandlerRequest{
+				BonusPoints: c.state.bonusPoints,
+				FamilyID:    c.state.familyID,
+				Name:        c.state.name,
+				Threshold:   c.state.threshold,
+				Type:        c.state.ruleType,
+				UserID:      c.state.userID,
+			}
+
+			err := validateCreateAchievementRule(req)
+			tests.AssertError(t, err, c.want.err)
+		})
+	}
+}
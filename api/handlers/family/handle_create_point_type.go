@@ -0,0 +1,163 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/segmentio/ksuid"
+)
+
+// includes the default type
+const maxPointTypesPerFamily = 10
+
+type createPointTypeHandlerRequest struct {
+	Cashout  *models.CashoutRule `json:"cashout"`
+	FamilyID string              `json:"-"`
+	Name     string              `json:"name"`
+	Unit     string              `json:"unit"`
+	UserID   string              `json:"-"`
+}
+
+type createPointTypeHandlerResponse struct {
+	PointType models.PointType `json:"point_type"`
+}
+
+// CreatePointTypeHandler adds a type of points the family tracks with its own balance
+func (c *FamilyController) CreatePointTypeHandler(cgin *gin.Context) {
+
+	var req createPointTypeHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.FamilyID = cgin.Param("family_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleCreatePointType(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusCreated, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleCreatePointType(ctx context.Context, req *createPointTypeHandlerRequest) (createPointTypeHandlerResponse, error) {
+	resp := createPointTypeHandlerResponse{}
+
+	if err := validateCreatePointType(req); err != nil {
+		return resp, err
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	pointTypes, err := c.getPointTypes(ctx, req.FamilyID)
+	if err != nil {
+		return resp, err
+	}
+
+	if len(pointTypes) >= maxPointTypesPerFamily {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("a family can have at most %d point types", maxPointTypesPerFamily))
+	}
+
+	if err := checkPointTypeName(pointTypes, "", req.Name); err != nil {
+		return resp, err
+	}
+
+	now := util.ToFormattedUTC(time.Now())
+
+	pointType := models.PointType{
+		FamilyID:        req.FamilyID,
+		ID:              ksuid.New().String(),
+		Name:            strings.TrimSpace(req.Name),
+		Unit:            strings.TrimSpace(req.Unit),
+		Cashout:         req.Cashout,
+		CreatedByUserID: req.UserID,
+		CreatedOnStr:    now,
+		UpdatedOnStr:    now,
+	}
+
+	if err := c.pointTypeDB.SavePointType(ctx, pointType); err != nil {
+		return resp, fmt.Errorf("failed to save point type: %w", err)
+	}
+
+	pointType.ParseTimes()
+	resp.PointType = pointType
+
+	return resp, nil
+}
+
+func validateCreatePointType(req *createPointTypeHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.FamilyID == "" {
+		apierr.AppendError("missing family_id")
+	}
+
+	validatePointTypeFields(apierr, req.Name, req.Unit, req.Cashout)
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
+
+// validatePointTypeFields appends the errors of the fields parents can set on a point type
+func validatePointTypeFields(apiErr *apierr.ApiError, name, unit string, cashout *models.CashoutRule) {
+	if name := strings.TrimSpace(name); name == "" || len(name) > 30 {
+		apiErr.AppendError("name must be between 1 and 30 characters")
+	}
+
+	if len(strings.TrimSpace(unit)) > 20 {
+		apiErr.AppendError("unit must not be longer than 20 characters")
+	}
+
+	if cashout != nil && !cashout.IsValid() {
+		apiErr.AppendError("cashout points and value must be positive integers, and minimum must not be negative")
+	}
+}
+
+// checkPointTypeName returns an error if another type of the family (than the one with the
+// given ID) has the same name
+func checkPointTypeName(pointTypes []models.PointType, id, name string) error {
+	name = strings.TrimSpace(name)
+
+	taken := slices.ContainsFunc(pointTypes, func(t models.PointType) bool {
+		return t.ID != id && strings.EqualFold(t.Name, name)
+	})
+
+	if taken {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("a point type named '%s' already exists", name))
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package family
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_handleCreatePointType(t *testing.T) {
+	type state struct {
+		name        string
+		invalidUser bool
+		tooMany     bool
+		errGetTypes error
+		errSave     error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{name: "Money "}, want{}},
+		{"fail - invalid user", state{name: "Money", invalidUser: true}, want{"access denied: user is not part of family"}},
+		{"fail - too many types", state{name: "Money", tooMany: true}, want{"a family can have at most 10 point types"}},
+		{"fail - name taken", state{name: "screen time"}, want{"a point type named 'screen time' already exists"}},
+		{"fail - name of default type taken", state{name: "Points"}, want{"a point type named 'Points' already exists"}},
+		{"fail - get types", state{name: "Money", errGetTypes: errFail}, want{"failed to get point types: fail"}},
+		{"fail - save type", state{name: "Money", errSave: errFail}, want{"failed to save point type: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "1"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			pointTypes := []models.PointType{{FamilyID: "456", ID: "st", Name: "Screen time"}}
+			if c.state.tooMany {
+				pointTypes = make([]models.PointType, maxPointTypesPerFamily)
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				pointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return(pointTypes, c.state.errGetTypes).Once()
+			}
+			if c.want.err == "" || c.state.errSave != nil {
+				pointTypeDB.EXPECT().SavePointType(mock.Anything, mock.MatchedBy(func(pt models.PointType) bool {
+					return pt.FamilyID == "456" && pt.ID != "" && pt.Name == "Money" && pt.Unit == "cents" &&
+						pt.Cashout.Value == 25 && pt.CreatedByUserID == "1" && pt.CreatedOnStr != ""
+				})).Return(c.state.errSave).Once()
+			}
+
+			ctrl := FamilyController{
+				familyDB:    familyDB,
+				pointTypeDB: pointTypeDB,
+			}
+
+			req := &createPointTypeHandlerRequest{
+				Cashout:  &models.CashoutRule{Points: 1, Value: 25},
+				FamilyID: "456",
+				Name:     c.state.name,
+				Unit:     "cents",
+				UserID:   "1",
+			}
+
+			res, err := ctrl.handleCreatePointType(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.NotEmpty(t, res.PointType.ID)
+				assert.False(t, res.PointType.CreatedOn.IsZero())
+			}
+
+			familyDB.AssertExpectations(t)
+			pointTypeDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateCreatePointType(t *testing.T) {
+	type test struct {
+		name string
+		req  createPointTypeHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", createPointTypeHandlerRequest{UserID: "1", FamilyID: "456", Name: "Money"}, ""},
+		{"happy path - cashout rule", createPointTypeHandlerRequest{UserID: "1", FamilyID: "456", Name: "Money", Cashout: &models.CashoutRule{Points: 10, Value: 1, Minimum: 20}}, ""},
+		{"fail - missing user", createPointTypeHandlerRequest{FamilyID: "456", Name: "Money"}, "unauthorized: missing user ID"},
+		{"fail - missing family", createPointTypeHandlerRequest{UserID: "1", Name: "Money"}, "missing family_id"},
+		{"fail - missing name", createPointTypeHandlerRequest{UserID: "1", FamilyID: "456", Name: " "}, "name must be between 1 and 30 characters"},
+		{"fail - unit too long", createPointTypeHandlerRequest{UserID: "1", FamilyID: "456", Name: "Money", Unit: "abcdefghijklmnopqrstu"}, "unit must not be longer than 20 characters"},
+		{"fail - invalid cashout rule", createPointTypeHandlerRequest{UserID: "1", FamilyID: "456", Name: "Money", Cashout: &models.CashoutRule{Points: 0, Value: 1}}, "cashout points and value must be positive integers"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateCreatePointType(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package family
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/webhook"
+	"github.com/segmentio/ksuid"
+)
+
+const maxWebhooksPerFamily = 10
+
+type createWebhookHandlerRequest struct {
+	EventTypes []string `json:"event_types"`
+	FamilyID   string   `json:"-"`
+	URL        string   `json:"url"`
+	UserID     string   `json:"-"`
+}
+
+type createWebhookHandlerResponse struct {
+	Webhook models.Webhook `json:"webhook"`
+
+	// The secret deliveries are signed with. This is the only time it is returned.
+	Secret string `json:"secret"`
+}
+
+// CreateWebhookHandler registers a URL that the family's events of the selected types are sent to
+func (c *FamilyController) CreateWebhookHandler(cgin *gin.Context) {
+
+	var req createWebhookHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.FamilyID = cgin.Param("family_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleCreateWebhook(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusCreated, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleCreateWebhook(ctx context.Context, req *createWebhookHandlerRequest) (createWebhookHandlerResponse, error) {
+	resp := createWebhookHandlerResponse{}
+
+	if err := validateCreateWebhook(req); err != nil {
+		return resp, err
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	webhooks, err := c.webhookDB.GetWebhooksByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	if len(webhooks) >= maxWebhooksPerFamily {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("a family can have at most %d webhooks", maxWebhooksPerFamily))
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return resp, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	webhook := models.Webhook{
+		FamilyID:        req.FamilyID,
+		ID:              ksuid.New().String(),
+		URL:             req.URL,
+		EventTypes:      req.EventTypes,
+		CreatedByUserID: req.UserID,
+		CreatedOnStr:    util.ToFormattedUTC(time.Now()),
+		Secret:          secret,
+	}
+
+	if err := c.webhookDB.SaveWebhook(ctx, webhook); err != nil {
+		return resp, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	webhook.ParseTimes()
+	resp.Webhook = webhook
+	resp.Secret = secret
+
+	return resp, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func validateCreateWebhook(req *createWebhookHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.FamilyID == "" {
+		apierr.AppendError("missing family_id")
+	}
+
+	// users choose the url, so it mustn't reach the services next to the API
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		apierr.AppendError(err.Error())
+	}
+
+	if len(req.EventTypes) == 0 {
+		apierr.AppendError("event_types must not be empty")
+	}
+
+	for _, t := range req.EventTypes {
+		if !slices.Contains(eventbus.EventTypes, eventbus.EventType(t)) {
+			apierr.AppendErrorf("unsupported event type '%s'", t)
+		}
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package family
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_CreateWebhookHandler(t *testing.T) {
+	type state struct {
+		invalidBody bool
+		errSave     error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusCreated}},
+		{"fail - invalid body", state{invalidBody: true}, want{"failed to unmarshal json body", http.StatusBadRequest}},
+		{"fail - validation error", state{errSave: apierr.New(apierr.InvalidInput)}, want{"invalid input", http.StatusBadRequest}},
+		{"fail - internal server error", state{errSave: errFail}, want{"failed to save webhook: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			req := &createWebhookHandlerRequest{
+				URL:        "https://example.com/hook",
+				EventTypes: []string{"points_requested"},
+			}
+
+			evtBody, _ := json.Marshal(req)
+			evtBodyStr := string(evtBody)
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			if !c.state.invalidBody {
+				familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{{FamilyID: "456", UserID: "123"}}, nil).Once()
+				webhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{}, nil).Once()
+				webhookDB.EXPECT().SaveWebhook(mock.Anything, mock.Anything).Return(c.state.errSave).Once()
+			} else {
+				evtBodyStr = `{"url":`
+			}
+
+			ctrl := FamilyController{
+				familyDB:  familyDB,
+				webhookDB: webhookDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(evtBodyStr))).WithContext(ctx)
+
+			ctrl.CreateWebhookHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			familyDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleCreateWebhook(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		tooMany     bool
+		errGetHooks error
+		errSave     error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family"}},
+		{"fail - too many webhooks", state{tooMany: true}, want{"a family can have at most 10 webhooks"}},
+		{"fail - get webhooks", state{errGetHooks: errFail}, want{"failed to get webhooks: fail"}},
+		{"fail - save webhook", state{errSave: errFail}, want{"failed to save webhook: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "1"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			webhooks := []models.Webhook{}
+			if c.state.tooMany {
+				webhooks = make([]models.Webhook, maxWebhooksPerFamily)
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				webhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return(webhooks, c.state.errGetHooks).Once()
+			}
+			if !c.state.invalidUser && !c.state.tooMany && c.state.errGetHooks == nil {
+				webhookDB.EXPECT().SaveWebhook(mock.Anything, mock.MatchedBy(func(w models.Webhook) bool {
+					return w.FamilyID == "456" && w.URL == "https://example.com/hook" && w.CreatedByUserID == "1" && len(w.Secret) == 64
+				})).Return(c.state.errSave).Once()
+			}
+
+			ctrl := FamilyController{
+				familyDB:  familyDB,
+				webhookDB: webhookDB,
+			}
+
+			req := &createWebhookHandlerRequest{
+				FamilyID:   "456",
+				URL:        "https://example.com/hook",
+				UserID:     "1",
+				EventTypes: []string{"points_requested"},
+			}
+
+			res, err := ctrl.handleCreateWebhook(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.NotEmpty(t, res.Webhook.ID)
+				assert.Equal(t, res.Webhook.Secret, res.Secret)
+			}
+
+			familyDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateCreateWebhook(t *testing.T) {
+	type state struct {
+		userID     string
+		familyID   string
+		url        string
+		eventTypes []string
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{"1", "456", "https://example.com/hook", []string{"points_requested", "balance_changed"}}, want{}},
+		{"fail - missing user", state{"", "456", "https://example.com/hook", []string{"points_requested"}}, want{"unauthorized: missing user ID"}},
+		{"fail - missing family", state{"1", "", "https://example.com/hook", []string{"points_requested"}}, want{"missing family_id"}},
+		{"fail - invalid url", state{"1", "456", "example", []string{"points_requested"}}, want{"url must be a valid https url"}},
+		{"fail - http url", state{"1", "456", "http://example.com/hook", []string{"points_requested"}}, want{"url must be a valid https url"}},
+		{"fail - metadata address", state{"1", "456", "https://169.254.169.254/latest/meta-data", []string{"points_requested"}}, want{"url must not point to a local or private address"}},
+		{"fail - localhost", state{"1", "456", "https://localhost:8443/hook", []string{"points_requested"}}, want{"url must not point to a local or private address"}},
+		{"fail - no event types", state{"1", "456", "https://example.com/hook", nil}, want{"event_types must not be empty"}},
+		{"fail - unsupported event type", state{"1", "456", "https://example.com/hook", []string{"blah"}}, want{"unsupported event type 'blah'"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := &createWebhookHandlerRequest{
+				FamilyID:   c.state.familyID,
+				URL:        c.state.url,
+				UserID:     c.state.userID,
+				EventTypes: c.state.eventTypes,
+			}
+
+			err := validateCreateWebhook(req)
+			tests.AssertError(t, err, c.want.err)
+		})
+	}
+}
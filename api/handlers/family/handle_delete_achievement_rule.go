@@ -0,0 +1,66 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type deleteAchievementRuleHandlerRequest struct {
+	FamilyID string
+	RuleID   string
+	UserID   string
+}
+
+// DeleteAchievementRuleHandler removes a family's achievement rule. Achievements that were
+// already earned by the rule are kept.
+func (c *FamilyController) DeleteAchievementRuleHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &deleteAchievementRuleHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		RuleID:   cgin.Param("rule_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	err := c.handleDeleteAchievementRule(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(nil))
+}
+
+func (c *FamilyController) handleDeleteAchievementRule(ctx context.Context, req *deleteAchievementRuleHandlerRequest) error {
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return err
+	}
+
+	rules, err := c.achievementDB.GetAchievementRulesByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to get achievement rules: %w", err)
+	}
+
+	// makes sure the rule exists and belongs to the family
+	if !slices.ContainsFunc(rules, func(r models.AchievementRule) bool { return r.ID == req.RuleID }) {
+		return apierr.New(apierr.NotFound).WithError(fmt.Sprintf("achievement rule (id=%s)", req.RuleID))
+	}
+
+	if err := c.achievementDB.DeleteAchievementRule(ctx, req.FamilyID, req.RuleID); err != nil {
+		return fmt.Errorf("failed to delete achievement rule: %w", err)
+	}
+
+	return nil
+}
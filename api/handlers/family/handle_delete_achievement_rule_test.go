@@ -0,0 +1,76 @@
+package family
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_handleDeleteAchievementRule(t *testing.T) {
+	type state struct {
+		ruleID      string
+		invalidUser bool
+		errGetRules error
+		errDelete   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{ruleID: "r1"}, want{}},
+		{"fail - invalid user", state{ruleID: "r1", invalidUser: true}, want{"access denied: user is not part of family"}},
+		{"fail - rule not found", state{ruleID: "r2"}, want{"not found: achievement rule (id=r2)"}},
+		{"fail - get rules", state{ruleID: "r1", errGetRules: errFail}, want{"failed to get achievement rules: fail"}},
+		{"fail - delete rule", state{ruleID: "r1", errDelete: errFail}, want{"failed to delete achievement rule: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			achievementDB := mocks.NewMockIAchievementStorage(t)
+			familyDB := mocks.NewMockIFamilyStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "1"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				achievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{
+					{FamilyID: "456", ID: "r1"},
+				}, c.state.errGetRules).Once()
+			}
+			if !c.state.invalidUser && c.state.errGetRules == nil && c.state.ruleID == "r1" {
+				achievementDB.EXPECT().DeleteAchievementRule(mock.Anything, "456", "r1").Return(c.state.errDelete).Once()
+			}
+
+			ctrl := FamilyController{
+				achievementDB: achievementDB,
+				familyDB:      familyDB,
+			}
+
+			req := &deleteAchievementRuleHandlerRequest{
+				FamilyID: "456",
+				RuleID:   c.state.ruleID,
+				UserID:   "1",
+			}
+
+			err := ctrl.handleDeleteAchievementRule(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			achievementDB.AssertExpectations(t)
+			familyDB.AssertExpectations(t)
+		})
+	}
+}
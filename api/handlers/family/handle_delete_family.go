@@ -0,0 +1,204 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+type deleteFamilyHandlerRequest struct {
+	FamilyID string
+	UserID   string
+}
+
+type deleteFamilyHandlerResponse struct {
+	DeletedUserIDs []string `json:"deleted_user_ids"`
+	RemovedUserIDs []string `json:"removed_user_ids"`
+}
+
+// DeleteFamilyHandler deletes the accounts of all users in a family. Users are disabled in cognito,
+// marked as deleted, and their personally identifiable information is scrubbed. Point amounts
+// are kept, so ledger totals remain intact. The family's webhooks are deleted with their deliveries. Users who also belong to other families keep their
+// accounts and are only removed from the family.
+func (c *FamilyController) DeleteFamilyHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &deleteFamilyHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleDeleteFamily(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleDeleteFamily(ctx context.Context, req *deleteFamilyHandlerRequest) (deleteFamilyHandlerResponse, error) {
+	resp := deleteFamilyHandlerResponse{
+		DeletedUserIDs: []string{},
+		RemovedUserIDs: []string{},
+	}
+
+	if req.UserID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	if req.FamilyID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id")
+	}
+
+	userIds, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID)
+	if err != nil {
+		return resp, err
+	}
+
+	logger := log.Get().WithContext(ctx).AddFields(map[string]any{
+		"family_id":  req.FamilyID,
+		"request_by": req.UserID,
+	})
+
+	staying := map[string]bool{}
+
+	for _, userID := range userIds {
+		user, err := c.userDB.GetUserByID(ctx, userID)
+		if err != nil {
+			return resp, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		// already deleted by a previous (possibly partially failed) request
+		if user.Status == models.UserStatusDeleted {
+			continue
+		}
+
+		if slices.ContainsFunc(user.FamilyIDs, func(fid string) bool { return fid != req.FamilyID }) {
+			staying[userID] = true
+			continue
+		}
+
+		if err := c.deleteAttachments(ctx, userID); err != nil {
+			return resp, err
+		}
+
+		if err := c.pointsDB.ScrubPoints(ctx, userID); err != nil {
+			return resp, fmt.Errorf("failed to scrub points: %w", err)
+		}
+
+		if err := c.pointCommentDB.DeletePointComments(ctx, userID); err != nil {
+			return resp, fmt.Errorf("failed to delete point comments: %w", err)
+		}
+
+		// the username is removed when scrubbing the user, so cognito has to go first
+		if err := c.auth.DisableUser(ctx, user.Username); err != nil {
+			logger.WithFields(map[string]any{"error": err.Error(), "user_id": userID}).Errorf("failed to disable user")
+			return resp, fmt.Errorf("failed to disable user: %w", err)
+		}
+
+		if err := c.userDB.ScrubUser(ctx, userID); err != nil {
+			return resp, fmt.Errorf("failed to scrub user: %w", err)
+		}
+
+		logger.WithField("user_id", userID).Infof("user deleted")
+		resp.DeletedUserIDs = append(resp.DeletedUserIDs, userID)
+	}
+
+	if err := c.familyDB.DeleteFamilySettings(ctx, req.FamilyID); err != nil {
+		return resp, fmt.Errorf("failed to delete family settings: %w", err)
+	}
+
+	rules, err := c.achievementDB.GetAchievementRulesByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get achievement rules: %w", err)
+	}
+
+	for _, r := range rules {
+		if err := c.achievementDB.DeleteAchievementRule(ctx, req.FamilyID, r.ID); err != nil {
+			return resp, fmt.Errorf("failed to delete achievement rule: %w", err)
+		}
+	}
+
+	pointTypes, err := c.pointTypeDB.GetPointTypesByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get point types: %w", err)
+	}
+
+	for _, t := range pointTypes {
+		if err := c.pointTypeDB.DeletePointType(ctx, req.FamilyID, t.ID); err != nil {
+			return resp, fmt.Errorf("failed to delete point type: %w", err)
+		}
+	}
+
+	// webhooks hold the family's target URLs and secrets, and would keep receiving its events
+	webhooks, err := c.webhookDB.GetWebhooksByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	for _, w := range webhooks {
+		if err := c.webhookDB.DeleteWebhookDeliveries(ctx, w.ID); err != nil {
+			return resp, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+		}
+
+		if err := c.webhookDB.DeleteWebhook(ctx, req.FamilyID, w.ID); err != nil {
+			return resp, fmt.Errorf("failed to delete webhook: %w", err)
+		}
+	}
+
+	// memberships are removed last, and the requesting user's last of all, so that a partially
+	// failed request can still be retried by them
+	userIds = slices.DeleteFunc(userIds, func(uid string) bool { return uid == req.UserID })
+	userIds = append(userIds, req.UserID)
+
+	for _, userID := range userIds {
+		if staying[userID] {
+			if err := c.familyDB.MoveFamilyUser(ctx, userID, req.FamilyID, ""); err != nil {
+				return resp, fmt.Errorf("failed to remove user from family: %w", err)
+			}
+
+			logger.WithField("user_id", userID).Infof("user removed from family")
+			resp.RemovedUserIDs = append(resp.RemovedUserIDs, userID)
+			continue
+		}
+
+		if err := c.familyDB.RemoveFamilyUser(ctx, models.FamilyUser{FamilyID: req.FamilyID, UserID: userID}); err != nil {
+			return resp, fmt.Errorf("failed to remove family user: %w", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// deleteAttachments deletes the files attached to the user's points
+func (c *FamilyController) deleteAttachments(ctx context.Context, userID string) error {
+	points, err := c.pointsDB.GetPointsByUserID(ctx, userID, models.QueryPointsFilter{
+		Attributes: []string{"id", "user_id", "attachments"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get points: %w", err)
+	}
+
+	for _, p := range points {
+		for _, a := range p.Attachments {
+			if err := c.attachments.Delete(ctx, p.AttachmentKey(a.ID)); err != nil {
+				return fmt.Errorf("failed to delete attachment: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
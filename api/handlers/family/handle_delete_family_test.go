@@ -0,0 +1,304 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	attmocks "github.com/sebboness/yektaspoints/mocks/attachment"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_DeleteFamilyHandler(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get user: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			achievementDB := mocks.NewMockIAchievementStorage(t)
+			attachments := attmocks.NewMockStore(t)
+			mockAuther := authmocks.NewMockAuthController(t)
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointCommentDB := mocks.NewMockIPointCommentStorage(t)
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "1"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+
+			if !c.state.invalidUser {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", Username: "john"}, c.state.err).Once()
+
+				if c.state.err == nil {
+					pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "1", mock.Anything).Return([]models.Point{}, nil).Once()
+					pointsDB.EXPECT().ScrubPoints(mock.Anything, "1").Return(nil).Once()
+					pointCommentDB.EXPECT().DeletePointComments(mock.Anything, "1").Return(nil).Once()
+					mockAuther.EXPECT().DisableUser(mock.Anything, "john").Return(nil).Once()
+					userDB.EXPECT().ScrubUser(mock.Anything, "1").Return(nil).Once()
+					familyDB.EXPECT().DeleteFamilySettings(mock.Anything, "456").Return(nil).Once()
+					achievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{}, nil).Once()
+					pointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{}, nil).Once()
+					webhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{}, nil).Once()
+					familyDB.EXPECT().RemoveFamilyUser(mock.Anything, models.FamilyUser{FamilyID: "456", UserID: "1"}).Return(nil).Once()
+				}
+			}
+
+			ctrl := FamilyController{
+				achievementDB:  achievementDB,
+				attachments:    attachments,
+				auth:           mockAuther,
+				familyDB:       familyDB,
+				pointCommentDB: pointCommentDB,
+				pointTypeDB:    pointTypeDB,
+				pointsDB:       pointsDB,
+				userDB:         userDB,
+				webhookDB:      webhookDB,
+			}
+
+			evt := events.APIGatewayProxyRequest{
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Authorizer: map[string]interface{}{
+						"claims": map[string]interface{}{
+							"sub": "1",
+						},
+					},
+				},
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), evt)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("DELETE", "/", nil).WithContext(ctx)
+
+			ctrl.DeleteFamilyHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			achievementDB.AssertExpectations(t)
+			attachments.AssertExpectations(t)
+			mockAuther.AssertExpectations(t)
+			familyDB.AssertExpectations(t)
+			pointCommentDB.AssertExpectations(t)
+			pointTypeDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleDeleteFamily(t *testing.T) {
+	type state struct {
+		alreadyDeleted bool
+		otherFamily    bool
+		errAttachment  error
+		errScrubPoints error
+		errComments    error
+		errDisable     error
+		errScrubUser   error
+		errSettings    error
+		errRules       error
+		errPointTypes  error
+		errWebhooks    error
+		errDeliveries  error
+		errWebhook     error
+		errRemove      error
+	}
+	type want struct {
+		err     string
+		deleted int
+		removed int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 2, 0}},
+		{"happy path - skip already deleted user", state{alreadyDeleted: true}, want{"", 1, 0}},
+		{"happy path - only remove user of other families", state{otherFamily: true}, want{"", 1, 1}},
+		{"fail - delete attachment error", state{errAttachment: errFail}, want{"failed to delete attachment: fail", 0, 0}},
+		{"fail - scrub points error", state{errScrubPoints: errFail}, want{"failed to scrub points: fail", 0, 0}},
+		{"fail - delete point comments error", state{errComments: errFail}, want{"failed to delete point comments: fail", 0, 0}},
+		{"fail - disable user error", state{errDisable: errFail}, want{"failed to disable user: fail", 0, 0}},
+		{"fail - scrub user error", state{errScrubUser: errFail}, want{"failed to scrub user: fail", 0, 0}},
+		{"fail - delete settings error", state{errSettings: errFail}, want{"failed to delete family settings: fail", 0, 0}},
+		{"fail - delete achievement rule error", state{errRules: errFail}, want{"failed to delete achievement rule: fail", 0, 0}},
+		{"fail - delete point type error", state{errPointTypes: errFail}, want{"failed to delete point type: fail", 0, 0}},
+		{"fail - get webhooks error", state{errWebhooks: errFail}, want{"failed to get webhooks: fail", 0, 0}},
+		{"fail - delete webhook deliveries error", state{errDeliveries: errFail}, want{"failed to delete webhook deliveries: fail", 0, 0}},
+		{"fail - delete webhook error", state{errWebhook: errFail}, want{"failed to delete webhook: fail", 0, 0}},
+		{"fail - remove family user error", state{errRemove: errFail}, want{"failed to remove family user: fail", 0, 0}},
+		{"fail - remove user of other families error", state{otherFamily: true, errRemove: errFail}, want{"failed to remove user from family: fail", 0, 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			achievementDB := mocks.NewMockIAchievementStorage(t)
+			attachments := attmocks.NewMockStore(t)
+			mockAuther := authmocks.NewMockAuthController(t)
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointCommentDB := mocks.NewMockIPointCommentStorage(t)
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{
+				{FamilyID: "456", UserID: "1"},
+				{FamilyID: "456", UserID: "2"},
+			}, nil).Once()
+
+			child := models.User{UserID: "2", Username: "jane", Status: models.UserStatusActive, FamilyIDs: []string{"456"}}
+			if c.state.alreadyDeleted {
+				child.Status = models.UserStatusDeleted
+			}
+			if c.state.otherFamily {
+				child.FamilyIDs = append(child.FamilyIDs, "789")
+			}
+
+			userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", Username: "john"}, nil).Once()
+			pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "1", mock.Anything).Return([]models.Point{
+				{ID: "p1", UserID: "1", Attachments: []models.Attachment{{ID: "a1"}}},
+			}, nil).Once()
+			attachments.EXPECT().Delete(mock.Anything, "1/p1/a1").Return(c.state.errAttachment).Once()
+
+			if c.state.errAttachment == nil {
+				pointsDB.EXPECT().ScrubPoints(mock.Anything, "1").Return(c.state.errScrubPoints).Once()
+			}
+
+			if c.state.errAttachment == nil && c.state.errScrubPoints == nil {
+				pointCommentDB.EXPECT().DeletePointComments(mock.Anything, "1").Return(c.state.errComments).Once()
+			}
+
+			if c.state.errAttachment == nil && c.state.errScrubPoints == nil && c.state.errComments == nil {
+				mockAuther.EXPECT().DisableUser(mock.Anything, "john").Return(c.state.errDisable).Once()
+
+				if c.state.errDisable == nil {
+					userDB.EXPECT().ScrubUser(mock.Anything, "1").Return(c.state.errScrubUser).Once()
+
+					if c.state.errScrubUser == nil {
+						userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(child, nil).Once()
+
+						if !c.state.alreadyDeleted && !c.state.otherFamily {
+							pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "2", mock.Anything).Return([]models.Point{}, nil).Once()
+							pointsDB.EXPECT().ScrubPoints(mock.Anything, "2").Return(nil).Once()
+							pointCommentDB.EXPECT().DeletePointComments(mock.Anything, "2").Return(nil).Once()
+							mockAuther.EXPECT().DisableUser(mock.Anything, "jane").Return(nil).Once()
+							userDB.EXPECT().ScrubUser(mock.Anything, "2").Return(nil).Once()
+						}
+
+						familyDB.EXPECT().DeleteFamilySettings(mock.Anything, "456").Return(c.state.errSettings).Once()
+
+						if c.state.errSettings == nil {
+							achievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{{FamilyID: "456", ID: "r1"}}, nil).Once()
+							achievementDB.EXPECT().DeleteAchievementRule(mock.Anything, "456", "r1").Return(c.state.errRules).Once()
+
+							if c.state.errRules == nil {
+								pointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{{FamilyID: "456", ID: "st"}}, nil).Once()
+								pointTypeDB.EXPECT().DeletePointType(mock.Anything, "456", "st").Return(c.state.errPointTypes).Once()
+							}
+
+							typesDeleted := c.state.errRules == nil && c.state.errPointTypes == nil
+							if typesDeleted {
+								webhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{{FamilyID: "456", ID: "w1"}}, c.state.errWebhooks).Once()
+							}
+							if typesDeleted && c.state.errWebhooks == nil {
+								webhookDB.EXPECT().DeleteWebhookDeliveries(mock.Anything, "w1").Return(c.state.errDeliveries).Once()
+							}
+							if typesDeleted && c.state.errWebhooks == nil && c.state.errDeliveries == nil {
+								webhookDB.EXPECT().DeleteWebhook(mock.Anything, "456", "w1").Return(c.state.errWebhook).Once()
+							}
+
+							// the requesting user leaves the family last
+							if typesDeleted && c.state.errWebhooks == nil && c.state.errDeliveries == nil && c.state.errWebhook == nil {
+								if c.state.otherFamily {
+									familyDB.EXPECT().MoveFamilyUser(mock.Anything, "2", "456", "").Return(c.state.errRemove).Once()
+								} else {
+									familyDB.EXPECT().RemoveFamilyUser(mock.Anything, models.FamilyUser{FamilyID: "456", UserID: "2"}).Return(c.state.errRemove).Once()
+								}
+
+								if c.state.errRemove == nil {
+									familyDB.EXPECT().RemoveFamilyUser(mock.Anything, models.FamilyUser{FamilyID: "456", UserID: "1"}).Return(nil).Once()
+								}
+							}
+						}
+					}
+				}
+			}
+
+			ctrl := FamilyController{
+				achievementDB:  achievementDB,
+				attachments:    attachments,
+				auth:           mockAuther,
+				familyDB:       familyDB,
+				pointCommentDB: pointCommentDB,
+				pointTypeDB:    pointTypeDB,
+				pointsDB:       pointsDB,
+				userDB:         userDB,
+				webhookDB:      webhookDB,
+			}
+
+			res, err := ctrl.handleDeleteFamily(context.Background(), &deleteFamilyHandlerRequest{
+				FamilyID: "456",
+				UserID:   "1",
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res.DeletedUserIDs, c.want.deleted)
+				assert.Len(t, res.RemovedUserIDs, c.want.removed)
+			}
+
+			achievementDB.AssertExpectations(t)
+			attachments.AssertExpectations(t)
+			mockAuther.AssertExpectations(t)
+			familyDB.AssertExpectations(t)
+			pointCommentDB.AssertExpectations(t)
+			pointTypeDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type deleteWebhookHandlerRequest struct {
+	FamilyID  string
+	UserID    string
+	WebhookID string
+}
+
+// DeleteWebhookHandler removes a family's webhook, so no more events are sent to it
+func (c *FamilyController) DeleteWebhookHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &deleteWebhookHandlerRequest{
+		FamilyID:  cgin.Param("family_id"),
+		UserID:    authInfo.GetUserID(),
+		WebhookID: cgin.Param("webhook_id"),
+	}
+
+	err := c.handleDeleteWebhook(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(nil))
+}
+
+func (c *FamilyController) handleDeleteWebhook(ctx context.Context, req *deleteWebhookHandlerRequest) error {
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return err
+	}
+
+	// makes sure the webhook exists and belongs to the family
+	if _, err := c.webhookDB.GetWebhookByID(ctx, req.FamilyID, req.WebhookID); err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if err := c.webhookDB.DeleteWebhookDeliveries(ctx, req.WebhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	if err := c.webhookDB.DeleteWebhook(ctx, req.FamilyID, req.WebhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_DeleteWebhookHandler(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		errGetHook  error
+		errDeliv    error
+		errDelete   error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - webhook not found", state{errGetHook: apierr.New(apierr.NotFound)}, want{"resource not found", http.StatusNotFound}},
+		{"fail - delete deliveries", state{errDeliv: errFail}, want{"failed to delete webhook deliveries: fail", http.StatusInternalServerError}},
+		{"fail - internal server error", state{errDelete: errFail}, want{"failed to delete webhook: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				webhookDB.EXPECT().GetWebhookByID(mock.Anything, "456", "1").Return(models.Webhook{FamilyID: "456", ID: "1"}, c.state.errGetHook).Once()
+			}
+			if !c.state.invalidUser && c.state.errGetHook == nil {
+				webhookDB.EXPECT().DeleteWebhookDeliveries(mock.Anything, "1").Return(c.state.errDeliv).Once()
+			}
+			if !c.state.invalidUser && c.state.errGetHook == nil && c.state.errDeliv == nil {
+				webhookDB.EXPECT().DeleteWebhook(mock.Anything, "456", "1").Return(c.state.errDelete).Once()
+			}
+
+			ctrl := FamilyController{
+				familyDB:  familyDB,
+				webhookDB: webhookDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.AddParam("webhook_id", "1")
+			cgin.Request = httptest.NewRequest("DELETE", "/", nil).WithContext(ctx)
+
+			ctrl.DeleteWebhookHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			familyDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
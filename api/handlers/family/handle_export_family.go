@@ -0,0 +1,168 @@
+package family
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+const exportFormatJson = "json"
+const exportFormatZip = "zip"
+
+type exportFamilyHandlerRequest struct {
+	FamilyID string
+	Format   string
+	UserID   string
+}
+
+// familyExport is the bundle of all data stored for a family
+type familyExport struct {
+	FamilyID    string                    `json:"family_id"`
+	ExportedOn  time.Time                 `json:"exported_on"`
+	FamilyUsers []models.FamilyUser       `json:"family_users"`
+	Users       []models.User             `json:"users"`
+	Points      map[string][]models.Point `json:"points"`
+}
+
+// ExportFamilyHandler returns all data of a family (users, family membership and
+// full points history) as a downloadable json file or zip archive
+func (c *FamilyController) ExportFamilyHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &exportFamilyHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		Format:   cgin.DefaultQuery("format", exportFormatJson),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	export, err := c.handleExportFamily(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	contentType := "application/json"
+	data, err := json.Marshal(export)
+	if req.Format == exportFormatZip {
+		contentType = "application/zip"
+		data, err = export.toZip()
+	}
+
+	if err != nil {
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(fmt.Errorf("failed to write export: %w", err)))
+		return
+	}
+
+	filename := fmt.Sprintf("family-%s-%s.%s", req.FamilyID, export.ExportedOn.Format("20060102"), req.Format)
+	cgin.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	cgin.Data(http.StatusOK, contentType, data)
+}
+
+func (c *FamilyController) handleExportFamily(ctx context.Context, req *exportFamilyHandlerRequest) (familyExport, error) {
+	export := familyExport{
+		FamilyID:    req.FamilyID,
+		ExportedOn:  time.Now().UTC(),
+		FamilyUsers: []models.FamilyUser{},
+		Users:       []models.User{},
+		Points:      map[string][]models.Point{},
+	}
+
+	if err := validateExportFamily(req); err != nil {
+		return export, err
+	}
+
+	userIds, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID)
+	if err != nil {
+		return export, err
+	}
+
+	for _, userID := range userIds {
+		user, err := c.userDB.GetUserByID(ctx, userID)
+		if err != nil {
+			return export, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		points, err := c.pointsDB.GetPointsByUserID(ctx, userID, models.QueryPointsFilter{})
+		if err != nil {
+			return export, fmt.Errorf("failed to get points: %w", err)
+		}
+
+		export.FamilyUsers = append(export.FamilyUsers, models.FamilyUser{FamilyID: req.FamilyID, UserID: userID})
+		export.Users = append(export.Users, user)
+		export.Points[userID] = points
+	}
+
+	return export, nil
+}
+
+// toZip writes the export to a zip archive with a family.json, a users.json and one points file per user
+func (e familyExport) toZip() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	files := map[string]any{
+		"family.json": map[string]any{
+			"family_id":    e.FamilyID,
+			"exported_on":  e.ExportedOn,
+			"family_users": e.FamilyUsers,
+		},
+		"users.json": e.Users,
+	}
+
+	for userID, points := range e.Points {
+		files[fmt.Sprintf("points/%s.json", userID)] = points
+	}
+
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+
+		if err := json.NewEncoder(f).Encode(content); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close zip: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func validateExportFamily(req *exportFamilyHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.FamilyID == "" {
+		apierr.AppendError("missing family_id")
+	}
+
+	if req.Format != exportFormatJson && req.Format != exportFormatZip {
+		apierr.AppendErrorf("invalid format: %s", req.Format)
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
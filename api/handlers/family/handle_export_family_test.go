@@ -0,0 +1,193 @@
+package family
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_ExportFamilyHandler(t *testing.T) {
+	type state struct {
+		format     string
+		errGetUser error
+	}
+	type want struct {
+		err         string
+		code        int
+		contentType string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - json", state{format: "json"}, want{"", http.StatusOK, "application/json"}},
+		{"happy path - zip", state{format: "zip"}, want{"", http.StatusOK, "application/zip"}},
+		{"fail - invalid format", state{format: "xml"}, want{"invalid format: xml", http.StatusBadRequest, ""}},
+		{"fail - get user error", state{format: "json", errGetUser: errFail}, want{"failed to get user: fail", http.StatusInternalServerError, ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			if c.state.format != "xml" {
+				familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{{FamilyID: "456", UserID: "1"}}, nil).Once()
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", Name: "John"}, c.state.errGetUser).Once()
+
+				if c.state.errGetUser == nil {
+					pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "1", models.QueryPointsFilter{}).Return([]models.Point{{ID: "p1", UserID: "1", Points: 5}}, nil).Once()
+				}
+			}
+
+			ctrl := FamilyController{
+				familyDB: familyDB,
+				pointsDB: pointsDB,
+				userDB:   userDB,
+			}
+
+			evt := events.APIGatewayProxyRequest{
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Authorizer: map[string]interface{}{
+						"claims": map[string]interface{}{
+							"sub": "1",
+						},
+					},
+				},
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), evt)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("GET", "/?format="+c.state.format, nil).WithContext(ctx)
+
+			ctrl.ExportFamilyHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+
+			if c.want.code != http.StatusOK {
+				result := tests.AssertResult(t, w.Body)
+				tests.AssertResultError(t, result, c.want.err)
+			} else {
+				assert.Contains(t, w.Header().Get("Content-Type"), c.want.contentType)
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=\"family-456-")
+
+				if c.state.format == "json" {
+					var export familyExport
+					err := json.Unmarshal(w.Body.Bytes(), &export)
+					assert.Nil(t, err)
+					assert.Equal(t, "456", export.FamilyID)
+					assert.Len(t, export.Users, 1)
+					assert.Len(t, export.Points["1"], 1)
+				} else {
+					zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+					assert.Nil(t, err)
+
+					names := []string{}
+					for _, f := range zr.File {
+						names = append(names, f.Name)
+					}
+					assert.ElementsMatch(t, []string{"family.json", "users.json", "points/1.json"}, names)
+				}
+			}
+
+			familyDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleExportFamily(t *testing.T) {
+	type state struct {
+		isInvalidUser bool
+		errGetPoints  error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - user mismatch", state{isInvalidUser: true}, want{"user is not part of family"}},
+		{"fail - get points error", state{errGetPoints: errFail}, want{"failed to get points: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{
+				{FamilyID: "456", UserID: "1"},
+				{FamilyID: "456", UserID: "2"},
+			}, nil).Once()
+
+			if !c.state.isInvalidUser {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1"}, nil).Once()
+				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "1", mock.Anything).Return([]models.Point{}, c.state.errGetPoints).Once()
+
+				if c.state.errGetPoints == nil {
+					userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(models.User{UserID: "2"}, nil).Once()
+					pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "2", mock.Anything).Return([]models.Point{}, nil).Once()
+				}
+			}
+
+			ctrl := FamilyController{
+				familyDB: familyDB,
+				pointsDB: pointsDB,
+				userDB:   userDB,
+			}
+
+			req := &exportFamilyHandlerRequest{
+				FamilyID: "456",
+				Format:   exportFormatJson,
+				UserID:   "1",
+			}
+
+			if c.state.isInvalidUser {
+				req.UserID = "3"
+			}
+
+			res, err := ctrl.handleExportFamily(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res.FamilyUsers, 2)
+				assert.Len(t, res.Users, 2)
+				assert.Len(t, res.Points, 2)
+			}
+
+			familyDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
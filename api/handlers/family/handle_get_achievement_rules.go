@@ -0,0 +1,65 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getAchievementRulesHandlerRequest struct {
+	FamilyID string
+	UserID   string
+}
+
+type getAchievementRulesHandlerResponse struct {
+	Rules []models.AchievementRule `json:"rules"`
+
+	// Rules every family has
+	DefaultRules []models.AchievementRule `json:"default_rules"`
+}
+
+// GetAchievementRulesHandler returns the achievement rules the family defined
+func (c *FamilyController) GetAchievementRulesHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getAchievementRulesHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetAchievementRules(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleGetAchievementRules(ctx context.Context, req *getAchievementRulesHandlerRequest) (getAchievementRulesHandlerResponse, error) {
+	resp := getAchievementRulesHandlerResponse{
+		DefaultRules: models.DefaultAchievementRules,
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	rules, err := c.achievementDB.GetAchievementRulesByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get achievement rules: %w", err)
+	}
+
+	resp.Rules = rules
+	return resp, nil
+}
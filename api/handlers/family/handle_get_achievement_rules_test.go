@@ -0,0 +1,85 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetAchievementRulesHandler(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get achievement rules: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			achievementDB := mocks.NewMockIAchievementStorage(t)
+			familyDB := mocks.NewMockIFamilyStorage(t)
+
+			ctrl := FamilyController{
+				achievementDB: achievementDB,
+				familyDB:      familyDB,
+			}
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "1"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				achievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{
+					{FamilyID: "456", ID: "r1", Name: "Dishwasher"},
+				}, c.state.err).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetAchievementRulesHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				data := result.Data.(map[string]any)
+				assert.Len(t, data["rules"], 1)
+				assert.Len(t, data["default_rules"], len(models.DefaultAchievementRules))
+			}
+
+			achievementDB.AssertExpectations(t)
+			familyDB.AssertExpectations(t)
+		})
+	}
+}
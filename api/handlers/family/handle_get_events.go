@@ -0,0 +1,214 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// How long a long-polling request waits for new events. API gateway and the main lambda time out after 29 seconds.
+var pollTimeout = 25 * time.Second
+
+// How often new events are looked for while waiting
+var pollInterval = time.Second
+
+// How often a comment is sent on an idle stream, so proxies don't close the connection
+var keepAliveInterval = 15 * time.Second
+
+type getEventsHandlerRequest struct {
+	FamilyID    string
+	LastEventID string
+	UserID      string
+}
+
+type getEventsHandlerResponse struct {
+	Events      []eventbus.Event `json:"events"`
+	LastEventID string           `json:"last_event_id"`
+}
+
+// GetEventsHandler sends events of the family's members to the client. Clients that accept
+// text/event-stream get a stream of server-sent events if streaming is enabled, all others
+// (and everyone in lambda) get a long-polling response.
+func (c *FamilyController) GetEventsHandler(cgin *gin.Context) {
+
+	familyID, _ := cgin.GetQuery("family_id")
+	if familyID == "" {
+		apiErr := apierr.New(apierr.InvalidInput).WithError("family_id is a required query parameter")
+		cgin.JSON(apiErr.StatusCode(), handlers.ErrorResult(apiErr))
+		return
+	}
+
+	// browsers send the Last-Event-ID header when reconnecting to a stream,
+	// long-polling clients pass the last_event_id of the previous response
+	lastEventID := cgin.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = cgin.Query("last_event_id")
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getEventsHandlerRequest{
+		FamilyID:    familyID,
+		LastEventID: lastEventID,
+		UserID:      authInfo.GetUserID(),
+	}
+
+	ctx := cgin.Request.Context()
+
+	userIds, err := c.getVisibleUserIDs(ctx, req.FamilyID, req.UserID)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	if c.streaming && strings.Contains(cgin.GetHeader("Accept"), "text/event-stream") {
+		c.streamEvents(cgin, req.FamilyID, userIds, req.LastEventID)
+		return
+	}
+
+	resp, err := c.pollEvents(ctx, req.FamilyID, userIds, req.LastEventID)
+	if err != nil {
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(fmt.Errorf("failed to get events: %w", err)))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+// getVisibleUserIDs returns the IDs of the family's users whose events the requesting user may see.
+// Like the family's points, children don't see their siblings' events if rankings are hidden from them.
+func (c *FamilyController) getVisibleUserIDs(ctx context.Context, familyID, userID string) ([]string, error) {
+	userIds, err := c.getFamilyUserIDs(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	family, err := c.familyDB.GetFamilyMembersByUserIDs(ctx, familyID, userIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+
+	settings, err := c.familyDB.GetFamilySettings(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family settings: %w", err)
+	}
+
+	_, isParent := family.Parents[userID]
+	if isParent || !settings.HideRankingsFromChildren {
+		return userIds, nil
+	}
+
+	visible := []string{}
+	for _, id := range userIds {
+		if _, isChild := family.Children[id]; !isChild || id == userID {
+			visible = append(visible, id)
+		}
+	}
+
+	return visible, nil
+}
+
+// pollEvents returns the events published since lastEventID. If there are none yet,
+// it keeps looking for new events until pollTimeout passes.
+func (c *FamilyController) pollEvents(ctx context.Context, familyID string, userIds []string, lastEventID string) (getEventsHandlerResponse, error) {
+	resp := getEventsHandlerResponse{
+		Events:      []eventbus.Event{},
+		LastEventID: lastEventID,
+	}
+
+	timer := time.NewTimer(pollTimeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := c.events.Since(ctx, familyID, userIds, lastEventID)
+		if err != nil {
+			return resp, err
+		}
+
+		if len(events) > 0 {
+			resp.Events = events
+			resp.LastEventID = events[len(events)-1].Cursor()
+			return resp, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return resp, nil
+		case <-ctx.Done():
+			return resp, nil
+		}
+	}
+}
+
+// streamEvents sends the events published since lastEventID, and then all new events
+// as server-sent events until the client disconnects
+func (c *FamilyController) streamEvents(cgin *gin.Context, familyID string, userIds []string, lastEventID string) {
+	ctx := cgin.Request.Context()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	// sends the events since the last sent event, and remembers the last one
+	sendEvents := func() {
+		events, err := c.events.Since(ctx, familyID, userIds, lastEventID)
+		if err != nil {
+			log.Get().WithContext(ctx).WithField("error", err.Error()).Errorf("failed to get events")
+			return
+		}
+
+		for _, evt := range events {
+			renderEvent(cgin, evt)
+			lastEventID = evt.Cursor()
+		}
+	}
+
+	cgin.Header("Cache-Control", "no-cache")
+	cgin.Header("Connection", "keep-alive")
+	cgin.Header("X-Accel-Buffering", "no")
+	cgin.Status(http.StatusOK)
+
+	sendEvents()
+	cgin.Writer.Flush()
+
+	cgin.Stream(func(w io.Writer) bool {
+		select {
+		case <-ticker.C:
+			sendEvents()
+			return true
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+func renderEvent(cgin *gin.Context, evt eventbus.Event) {
+	cgin.Render(-1, sse.Event{
+		Id:    evt.Cursor(),
+		Event: string(evt.Type),
+		Data:  evt,
+	})
+}
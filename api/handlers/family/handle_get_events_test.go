@@ -0,0 +1,250 @@
+package family
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetEventsHandler(t *testing.T) {
+	type state struct {
+		familyIdMissing bool
+		invalidUser     bool
+		userID          string
+		hideRankings    bool
+		errFamilyUsers  error
+		errFamily       error
+		errSettings     error
+		lastEventID     string
+		publishLater    bool
+		errEvents       error
+	}
+	type want struct {
+		err    string
+		code   int
+		events []string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - all events", state{}, want{"", 200, []string{"s", "a", "b"}}},
+		{"happy path - parents see all events if rankings are hidden", state{hideRankings: true}, want{"", 200, []string{"s", "a", "b"}}},
+		{"happy path - children see all events if rankings aren't hidden", state{userID: "2"}, want{"", 200, []string{"s", "a", "b"}}},
+		{"happy path - children don't see siblings' events if rankings are hidden", state{userID: "2", hideRankings: true}, want{"", 200, []string{"a", "b"}}},
+		{"happy path - events since last event", state{lastEventID: "a"}, want{"", 200, []string{"b"}}},
+		{"happy path - wait for next event", state{lastEventID: "b", publishLater: true}, want{"", 200, []string{"c"}}},
+		{"happy path - no new events", state{lastEventID: "b"}, want{"", 200, []string{}}},
+		{"fail - missing family_id", state{familyIdMissing: true}, want{"invalid input: family_id is a required query parameter", http.StatusBadRequest, nil}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden, nil}},
+		{"fail - internal server error", state{errFamilyUsers: errFail}, want{"failed to get family users: fail", http.StatusInternalServerError, nil}},
+		{"fail - get family", state{errFamily: errFail}, want{"failed to get family: fail", http.StatusInternalServerError, nil}},
+		{"fail - get family settings", state{errSettings: errFail}, want{"failed to get family settings: fail", http.StatusInternalServerError, nil}},
+		{"fail - get events", state{errEvents: errFail}, want{"failed to get events: fail", http.StatusInternalServerError, nil}},
+	}
+
+	pollTimeout = 50 * time.Millisecond
+	pollInterval = 5 * time.Millisecond
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			bus := eventbus.New(10)
+
+			ctrl := FamilyController{
+				events:   bus,
+				familyDB: familyDB,
+			}
+
+			if c.state.errEvents != nil {
+				subscriber := evtmocks.NewMockSubscriber(t)
+				subscriber.EXPECT().Since(mock.Anything, "456", []string{"1", "2", "4"}, "").Return(nil, c.state.errEvents).Once()
+				ctrl.events = subscriber
+			}
+
+			bus.Publish(context.Background(), eventbus.Event{ID: "s", UserID: "4"})
+			bus.Publish(context.Background(), eventbus.Event{ID: "a", UserID: "1"})
+			bus.Publish(context.Background(), eventbus.Event{ID: "b", UserID: "2"})
+			bus.Publish(context.Background(), eventbus.Event{ID: "x", UserID: "3"})
+
+			familyUsers := []models.FamilyUser{
+				{FamilyID: "456", UserID: "1"},
+				{FamilyID: "456", UserID: "2"},
+				{FamilyID: "456", UserID: "4"},
+			}
+
+			if c.state.invalidUser {
+				familyUsers = familyUsers[1:]
+			}
+
+			if !c.state.familyIdMissing {
+				familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, c.state.errFamilyUsers).Once()
+
+				if !c.state.invalidUser && c.state.errFamilyUsers == nil {
+					family := models.Family{
+						FamilyID: "456",
+						Parents:  map[string]models.FamilyMember{"1": {UserID: "1"}},
+						Children: map[string]models.FamilyMember{"2": {UserID: "2"}, "4": {UserID: "4"}},
+					}
+					familyDB.EXPECT().GetFamilyMembersByUserIDs(mock.Anything, "456", []string{"1", "2", "4"}).Return(family, c.state.errFamily).Once()
+
+					if c.state.errFamily == nil {
+						settings := models.NewFamilySettings("456")
+						settings.HideRankingsFromChildren = c.state.hideRankings
+						familyDB.EXPECT().GetFamilySettings(mock.Anything, "456").Return(settings, c.state.errSettings).Once()
+					}
+				}
+			}
+
+			if c.state.publishLater {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					bus.Publish(context.Background(), eventbus.Event{ID: "y", UserID: "3"})
+					bus.Publish(context.Background(), eventbus.Event{ID: "c", UserID: "1"})
+				}()
+				pollTimeout = time.Second
+				defer func() { pollTimeout = 50 * time.Millisecond }()
+			}
+
+			userID := "1"
+			if c.state.userID != "" {
+				userID = c.state.userID
+			}
+
+			evt := events.APIGatewayProxyRequest{
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Authorizer: map[string]interface{}{
+						"claims": map[string]interface{}{
+							"sub": userID,
+						},
+					},
+				},
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), evt)
+
+			endpoint := "/v1/events?family_id=456"
+			if c.state.familyIdMissing {
+				endpoint = "/v1/events"
+			}
+			if c.state.lastEventID != "" {
+				endpoint += "&last_event_id=" + c.state.lastEventID
+			}
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("GET", endpoint, nil).WithContext(ctx)
+
+			ctrl.GetEventsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == 200 {
+				data := result.Data.(map[string]any)
+
+				ids := []string{}
+				for _, e := range data["events"].([]any) {
+					ids = append(ids, e.(map[string]any)["id"].(string))
+				}
+				assert.Equal(t, c.want.events, ids)
+
+				lastEventID := c.state.lastEventID
+				if len(ids) > 0 {
+					lastEventID = ids[len(ids)-1]
+				}
+				assert.Equal(t, lastEventID, data["last_event_id"])
+			}
+
+			familyDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_GetEventsHandler_Stream(t *testing.T) {
+	familyDB := mocks.NewMockIFamilyStorage(t)
+	familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{
+		{FamilyID: "456", UserID: "1"},
+		{FamilyID: "456", UserID: "2"},
+	}, nil).Once()
+	familyDB.EXPECT().GetFamilyMembersByUserIDs(mock.Anything, "456", []string{"1", "2"}).Return(models.Family{
+		FamilyID: "456",
+		Parents:  map[string]models.FamilyMember{"1": {UserID: "1"}},
+		Children: map[string]models.FamilyMember{"2": {UserID: "2"}},
+	}, nil).Once()
+	familyDB.EXPECT().GetFamilySettings(mock.Anything, "456").Return(models.NewFamilySettings("456"), nil).Once()
+
+	bus := eventbus.New(10)
+	bus.Publish(context.Background(), eventbus.Event{ID: "a", Type: eventbus.EventTypePointsRequested, UserID: "2"})
+
+	ctrl := FamilyController{
+		events:   bus,
+		familyDB: familyDB,
+	}
+	ctrl.EnableStreaming()
+
+	pollInterval = 5 * time.Millisecond
+
+	r := gin.New()
+	r.GET("/v1/events", func(cgin *gin.Context) {
+		cgin.Request = cgin.Request.WithContext(handlers.PrepareAuthorizedContextWithClaims(cgin.Request.Context(), map[string]any{"sub": "1"}))
+		cgin.Next()
+	}, ctrl.GetEventsHandler)
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/v1/events?family_id=456", nil)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	// wait for the backlog event, then publish a new one
+	reader := bufio.NewReader(resp.Body)
+	readEventID := func() string {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return ""
+			}
+			if strings.HasPrefix(line, "id:") {
+				return strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			}
+		}
+	}
+
+	assert.Equal(t, "a", readEventID())
+
+	bus.Publish(context.Background(), eventbus.Event{ID: "x", UserID: "3"})
+	bus.Publish(context.Background(), eventbus.Event{ID: "b", Type: eventbus.EventTypeBalanceChanged, UserID: "1"})
+
+	assert.Equal(t, "b", readEventID())
+}
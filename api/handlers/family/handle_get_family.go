@@ -57,24 +57,9 @@ func (c *FamilyController) handleGetFamily(ctx context.Context, req *getFamilyHa
 		"family_id": req.FamilyID,
 	})
 
-	familyUsers, err := c.familyDB.GetFamilyUsers(ctx, req.FamilyID)
+	userIds, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID)
 	if err != nil {
-		logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to get family users")
-		return resp, fmt.Errorf("failed to get family users: %w", err)
-	}
-
-	userIsPartOfFamily := false
-	userIds := make([]string, len(familyUsers))
-	for idx, fu := range familyUsers {
-		userIds[idx] = fu.UserID
-		if req.UserID == fu.UserID {
-			userIsPartOfFamily = true
-		}
-	}
-
-	if !userIsPartOfFamily {
-		logger.Errorf("user is not part of family")
-		return resp, apierr.New(apierr.AccessDenied).WithError("user is not part of family")
+		return resp, err
 	}
 
 	family, err := c.familyDB.GetFamilyMembersByUserIDs(ctx, req.FamilyID, userIds)
@@ -0,0 +1,225 @@
+package family
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+type getFamilyPointsHandlerRequest struct {
+	FamilyID string
+	UserID   string
+}
+
+type familyChildPoints struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	*familyChildStats
+	Rank int `json:"rank,omitempty"` // by points of the last 7 days. Omitted if rankings are hidden.
+}
+
+// familyChildStats are omitted for siblings if rankings are hidden from children
+type familyChildStats struct {
+	Balance                  int `json:"balance"`
+	PointsLast7Days          int `json:"points_last_7_days"`
+	PointsLostLast7Days      int `json:"points_lost_last_7_days"`
+	PointsCashedOutLast7Days int `json:"points_cashed_out_last_7_days"`
+	PendingRequests          int `json:"pending_requests"`
+}
+
+type getFamilyPointsHandlerResponse struct {
+	FamilyID       string              `json:"family_id"`
+	Children       []familyChildPoints `json:"children"`
+	RankingsHidden bool                `json:"rankings_hidden"`
+}
+
+// GetFamilyPointsHandler returns the balance and weekly progress of every child in the family,
+// ranked by the points they got in the last 7 days
+func (c *FamilyController) GetFamilyPointsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getFamilyPointsHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetFamilyPoints(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleGetFamilyPoints(ctx context.Context, req *getFamilyPointsHandlerRequest) (getFamilyPointsHandlerResponse, error) {
+	resp := getFamilyPointsHandlerResponse{
+		FamilyID: req.FamilyID,
+		Children: []familyChildPoints{},
+	}
+
+	if req.UserID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	if req.FamilyID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id")
+	}
+
+	userIds, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID)
+	if err != nil {
+		return resp, err
+	}
+
+	family, err := c.familyDB.GetFamilyMembersByUserIDs(ctx, req.FamilyID, userIds)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get family: %w", err)
+	}
+
+	settings, err := c.familyDB.GetFamilySettings(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get family settings: %w", err)
+	}
+
+	children, err := c.getChildrenPoints(ctx, family.Children, time.Now().UTC())
+	if err != nil {
+		return resp, err
+	}
+
+	_, isParent := family.Parents[req.UserID]
+	resp.RankingsHidden = !isParent && settings.HideRankingsFromChildren
+
+	if resp.RankingsHidden {
+		// children only see their own points, siblings' points would give away the rankings
+		for idx := range children {
+			if children[idx].UserID != req.UserID {
+				children[idx].familyChildStats = nil
+			}
+		}
+
+		slices.SortFunc(children, func(a, b familyChildPoints) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	} else {
+		rankChildren(children)
+	}
+
+	resp.Children = children
+	return resp, nil
+}
+
+// getChildrenPoints summarizes the points of all children concurrently
+func (c *FamilyController) getChildrenPoints(ctx context.Context, members map[string]models.FamilyMember, now time.Time) ([]familyChildPoints, error) {
+	from := now.AddDate(0, 0, -14)   // minus two weeks
+	weekAgo := now.AddDate(0, 0, -7) // minus one week
+
+	filter := models.QueryPointsFilter{
+		UpdatedOn: *models.NewDateFilter().WithRange(from, now),
+		Statuses: []models.PointStatus{
+			models.PointStatusSettled,
+			models.PointStatusWaiting,
+		},
+		Types: []models.PointRequestType{
+			models.PointRequestTypeCashout,
+			models.PointRequestTypeAdd,
+			models.PointRequestTypeSubtract,
+		},
+		Attributes: []string{
+			"id",
+			"updated_on",
+			"points",
+			"point_type_id",
+			"status",
+			"request.type",
+		},
+	}
+
+	children := make([]familyChildPoints, 0, len(members))
+	for _, m := range members {
+		children = append(children, familyChildPoints{UserID: m.UserID, Name: m.Name, familyChildStats: &familyChildStats{}})
+	}
+
+	errs := make([]error, len(children))
+	wg := sync.WaitGroup{}
+
+	for idx := range children {
+		wg.Add(1)
+		go func(child *familyChildPoints, idx int) {
+			defer wg.Done()
+
+			points, err := c.pointsDB.GetPointsByUserID(ctx, child.UserID, filter)
+			if err != nil {
+				errs[idx] = fmt.Errorf("failed to get points of user %s: %w", child.UserID, err)
+				return
+			}
+
+			// points of other types can't be compared, so children are ranked by the default type only
+			up := models.UserPoints{}
+			up.Summarize(weekAgo, models.PointsOfType(points, models.DefaultPointTypeID))
+
+			child.PointsLast7Days = up.PointsLast7Days
+			child.PointsLostLast7Days = up.PointsLostLast7Days
+			child.PointsCashedOutLast7Days = up.PointsCashedOutLast7Days
+
+			for _, p := range points {
+				if p.Status == models.PointStatusWaiting {
+					child.PendingRequests++
+				}
+			}
+
+			// the balance may have been settled before the last two weeks
+			balance, err := c.pointsDB.GetPointBalance(ctx, child.UserID, models.DefaultPointTypeID)
+			if err != nil {
+				errs[idx] = fmt.Errorf("failed to get balance of user %s: %w", child.UserID, err)
+				return
+			}
+
+			child.Balance = balance.Balance
+		}(&children[idx], idx)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			log.Get().WithContext(ctx).WithField("error", err.Error()).Errorf("failed to get family points")
+			return nil, err
+		}
+	}
+
+	return children, nil
+}
+
+// rankChildren sorts children by their points of the last 7 days and ranks them. Children
+// with the same points share a rank.
+func rankChildren(children []familyChildPoints) {
+	slices.SortFunc(children, func(a, b familyChildPoints) int {
+		if n := cmp.Compare(b.PointsLast7Days, a.PointsLast7Days); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	for idx := range children {
+		if idx > 0 && children[idx].PointsLast7Days == children[idx-1].PointsLast7Days {
+			children[idx].Rank = children[idx-1].Rank
+		} else {
+			children[idx].Rank = idx + 1
+		}
+	}
+}
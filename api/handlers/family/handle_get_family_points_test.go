@@ -0,0 +1,235 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetFamilyPointsHandler(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get family: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+
+			ctrl := FamilyController{
+				familyDB: familyDB,
+				pointsDB: pointsDB,
+			}
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}, {FamilyID: "456", UserID: "2"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "1"
+			}
+
+			family := models.Family{
+				FamilyID: "456",
+				Parents:  map[string]models.FamilyMember{"123": {UserID: "123", Name: "John"}},
+				Children: map[string]models.FamilyMember{"2": {UserID: "2", Name: "Jane"}},
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				familyDB.EXPECT().GetFamilyMembersByUserIDs(mock.Anything, "456", []string{"123", "2"}).Return(family, c.state.err).Once()
+			}
+			if c.want.code == http.StatusOK {
+				familyDB.EXPECT().GetFamilySettings(mock.Anything, "456").Return(models.NewFamilySettings("456"), nil).Once()
+				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "2", mock.Anything).Return([]models.Point{}, nil).Once()
+				pointsDB.EXPECT().GetPointBalance(mock.Anything, "2", models.DefaultPointTypeID).Return(models.PointBalance{}, nil).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetFamilyPointsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				assert.NotNil(t, result.Data)
+			}
+
+			familyDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleGetFamilyPoints(t *testing.T) {
+	type state struct {
+		userID       string
+		hideRankings bool
+		errSettings  error
+		errPoints    error
+		errBalance   error
+	}
+	type want struct {
+		err    string
+		hidden bool
+		names  []string
+		ranks  []int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - parent", state{userID: "1"}, want{"", false, []string{"Bo", "Al", "Cy"}, []int{1, 2, 2}}},
+		{"happy path - child", state{userID: "2"}, want{"", false, []string{"Bo", "Al", "Cy"}, []int{1, 2, 2}}},
+		{"happy path - parent sees rankings when hidden", state{userID: "1", hideRankings: true}, want{"", false, []string{"Bo", "Al", "Cy"}, []int{1, 2, 2}}},
+		{"happy path - rankings hidden from child", state{userID: "2", hideRankings: true}, want{"", true, []string{"Al", "Bo", "Cy"}, []int{0, 0, 0}}},
+		{"fail - missing user ID", state{}, want{err: "unauthorized: missing user ID"}},
+		{"fail - get settings", state{userID: "1", errSettings: errFail}, want{err: "failed to get family settings: fail"}},
+		{"fail - get points", state{userID: "1", errPoints: errFail}, want{err: "failed to get points of user 3: fail"}},
+		{"fail - get balance", state{userID: "1", errBalance: errFail}, want{err: "failed to get balance of user 3: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+
+			ctrl := FamilyController{
+				familyDB: familyDB,
+				pointsDB: pointsDB,
+			}
+
+			now := time.Now().UTC()
+			bal := func(v int) *int {
+				return &v
+			}
+
+			family := models.Family{
+				FamilyID: "456",
+				Parents:  map[string]models.FamilyMember{"1": {UserID: "1", Name: "Mom"}},
+				Children: map[string]models.FamilyMember{
+					"2": {UserID: "2", Name: "Al"},
+					"3": {UserID: "3", Name: "Bo"},
+					"4": {UserID: "4", Name: "Cy"},
+				},
+			}
+
+			points := map[string][]models.Point{
+				"2": {
+					{Status: models.PointStatusWaiting, Points: 4, UpdatedOn: now.Add(-time.Hour), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+					{Status: models.PointStatusSettled, Points: 2, Balance: bal(12), UpdatedOn: now.AddDate(0, 0, -1), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+				},
+				"3": {
+					// points of other types don't count towards rankings
+					{Status: models.PointStatusSettled, PointTypeID: "st", Points: 60, Balance: bal(60), UpdatedOn: now.Add(-time.Hour), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+					{Status: models.PointStatusSettled, Points: 5, Balance: bal(5), UpdatedOn: now.AddDate(0, 0, -2), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+				},
+				"4": {
+					{Status: models.PointStatusSettled, Points: 2, Balance: bal(30), UpdatedOn: now.AddDate(0, 0, -3), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+				},
+			}
+
+			balances := map[string]int{"2": 12, "3": 5, "4": 30}
+
+			if c.state.userID != "" {
+				familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{
+					{FamilyID: "456", UserID: "1"},
+					{FamilyID: "456", UserID: "2"},
+					{FamilyID: "456", UserID: "3"},
+					{FamilyID: "456", UserID: "4"},
+				}, nil).Once()
+				familyDB.EXPECT().GetFamilyMembersByUserIDs(mock.Anything, "456", mock.Anything).Return(family, nil).Once()
+
+				settings := models.NewFamilySettings("456")
+				settings.HideRankingsFromChildren = c.state.hideRankings
+				familyDB.EXPECT().GetFamilySettings(mock.Anything, "456").Return(settings, c.state.errSettings).Once()
+
+				if c.state.errSettings == nil {
+					for userID, p := range points {
+						var errPoints, errBalance error
+						if userID == "3" {
+							errPoints = c.state.errPoints
+							errBalance = c.state.errBalance
+						}
+
+						pointsDB.EXPECT().GetPointsByUserID(mock.Anything, userID, mock.Anything).Return(p, errPoints).Once()
+
+						if errPoints == nil {
+							pointsDB.EXPECT().GetPointBalance(mock.Anything, userID, models.DefaultPointTypeID).
+								Return(models.PointBalance{UserID: userID, Balance: balances[userID]}, errBalance).Once()
+						}
+					}
+				}
+			}
+
+			res, err := ctrl.handleGetFamilyPoints(context.Background(), &getFamilyPointsHandlerRequest{
+				FamilyID: "456",
+				UserID:   c.state.userID,
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, c.want.hidden, res.RankingsHidden)
+				assert.Len(t, res.Children, 3)
+
+				names := []string{}
+				ranks := []int{}
+				for _, child := range res.Children {
+					names = append(names, child.Name)
+					ranks = append(ranks, child.Rank)
+
+					switch {
+					case child.UserID == "2":
+						assert.Equal(t, 12, child.Balance)
+						assert.Equal(t, 2, child.PointsLast7Days)
+						assert.Equal(t, 1, child.PendingRequests)
+					case c.want.hidden:
+						assert.Nil(t, child.familyChildStats, "siblings' points are hidden")
+					case child.UserID == "3":
+						assert.Equal(t, 5, child.Balance)
+					}
+				}
+				assert.Equal(t, c.want.names, names)
+				assert.Equal(t, c.want.ranks, ranks)
+			}
+
+			familyDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+		})
+	}
+}
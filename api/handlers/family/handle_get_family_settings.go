@@ -0,0 +1,67 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getFamilySettingsHandlerRequest struct {
+	FamilyID string
+	UserID   string
+}
+
+type getFamilySettingsHandlerResponse struct {
+	Settings models.FamilySettings `json:"settings"`
+}
+
+func (c *FamilyController) GetFamilySettingsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getFamilySettingsHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetFamilySettings(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleGetFamilySettings(ctx context.Context, req *getFamilySettingsHandlerRequest) (getFamilySettingsHandlerResponse, error) {
+	resp := getFamilySettingsHandlerResponse{}
+
+	if req.UserID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	if req.FamilyID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id")
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	settings, err := c.familyDB.GetFamilySettings(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get family settings: %w", err)
+	}
+
+	resp.Settings = settings
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getPointTypesHandlerRequest struct {
+	FamilyID string
+	UserID   string
+}
+
+type getPointTypesHandlerResponse struct {
+	PointTypes []models.PointType `json:"point_types"`
+}
+
+// GetPointTypesHandler returns the types of points the family tracks, starting with the default type
+func (c *FamilyController) GetPointTypesHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getPointTypesHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetPointTypes(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleGetPointTypes(ctx context.Context, req *getPointTypesHandlerRequest) (getPointTypesHandlerResponse, error) {
+	resp := getPointTypesHandlerResponse{}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	pointTypes, err := c.getPointTypes(ctx, req.FamilyID)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.PointTypes = pointTypes
+	return resp, nil
+}
+
+// getPointTypes returns the default type and the types the family configured
+func (c *FamilyController) getPointTypes(ctx context.Context, familyID string) ([]models.PointType, error) {
+	familyTypes, err := c.pointTypeDB.GetPointTypesByFamilyID(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point types: %w", err)
+	}
+
+	return models.MergePointTypes(familyTypes), nil
+}
@@ -0,0 +1,85 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetPointTypesHandler(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get point types: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
+
+			ctrl := FamilyController{
+				familyDB:    familyDB,
+				pointTypeDB: pointTypeDB,
+			}
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "1"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				pointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{
+					{FamilyID: "456", ID: "st", Name: "Screen time"},
+				}, c.state.err).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetPointTypesHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				pointTypes := result.Data.(map[string]any)["point_types"].([]any)
+				assert.Len(t, pointTypes, 2)
+				assert.Equal(t, models.DefaultPointTypeID, pointTypes[0].(map[string]any)["id"])
+			}
+
+			familyDB.AssertExpectations(t)
+			pointTypeDB.AssertExpectations(t)
+		})
+	}
+}
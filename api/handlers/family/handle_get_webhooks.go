@@ -0,0 +1,110 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getWebhooksHandlerRequest struct {
+	FamilyID  string
+	UserID    string
+	WebhookID string
+}
+
+type getWebhooksHandlerResponse struct {
+	Webhooks []models.Webhook `json:"webhooks"`
+}
+
+type getWebhookDeliveriesHandlerResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// GetWebhooksHandler returns the family's webhooks
+func (c *FamilyController) GetWebhooksHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getWebhooksHandlerRequest{
+		FamilyID: cgin.Param("family_id"),
+		UserID:   authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetWebhooks(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+// GetWebhookDeliveriesHandler returns the deliveries of a family's webhook, latest first
+func (c *FamilyController) GetWebhookDeliveriesHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getWebhooksHandlerRequest{
+		FamilyID:  cgin.Param("family_id"),
+		UserID:    authInfo.GetUserID(),
+		WebhookID: cgin.Param("webhook_id"),
+	}
+
+	resp, err := c.handleGetWebhookDeliveries(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleGetWebhooks(ctx context.Context, req *getWebhooksHandlerRequest) (getWebhooksHandlerResponse, error) {
+	resp := getWebhooksHandlerResponse{}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	webhooks, err := c.webhookDB.GetWebhooksByFamilyID(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	resp.Webhooks = webhooks
+	return resp, nil
+}
+
+func (c *FamilyController) handleGetWebhookDeliveries(ctx context.Context, req *getWebhooksHandlerRequest) (getWebhookDeliveriesHandlerResponse, error) {
+	resp := getWebhookDeliveriesHandlerResponse{}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	// makes sure the webhook belongs to the family
+	if _, err := c.webhookDB.GetWebhookByID(ctx, req.FamilyID, req.WebhookID); err != nil {
+		return resp, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	deliveries, err := c.webhookDB.GetWebhookDeliveries(ctx, req.WebhookID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	resp.Deliveries = deliveries
+	return resp, nil
+}
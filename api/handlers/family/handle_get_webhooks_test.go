@@ -0,0 +1,164 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetWebhooksHandler(t *testing.T) {
+	type state struct {
+		invalidUser bool
+		err         error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get webhooks: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				webhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{
+					{FamilyID: "456", ID: "1", URL: "https://example.com/hook", Secret: "shh"},
+				}, c.state.err).Once()
+			}
+
+			ctrl := FamilyController{
+				familyDB:  familyDB,
+				webhookDB: webhookDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetWebhooksHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				webhooks := result.Data.(map[string]any)["webhooks"].([]any)
+				assert.Len(t, webhooks, 1)
+
+				// secrets are never returned
+				assert.NotContains(t, webhooks[0].(map[string]any), "secret")
+			}
+
+			familyDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_GetWebhookDeliveriesHandler(t *testing.T) {
+	type state struct {
+		invalidUser    bool
+		errGetHook     error
+		errDeliveries  error
+		skipDeliveries bool
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - invalid user", state{invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden}},
+		{"fail - webhook not found", state{errGetHook: apierr.New(apierr.NotFound), skipDeliveries: true}, want{"resource not found", http.StatusNotFound}},
+		{"fail - internal server error", state{errDeliveries: errFail}, want{"failed to get webhook deliveries: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			webhookDB := mocks.NewMockIWebhookStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				webhookDB.EXPECT().GetWebhookByID(mock.Anything, "456", "1").Return(models.Webhook{FamilyID: "456", ID: "1"}, c.state.errGetHook).Once()
+			}
+			if !c.state.invalidUser && !c.state.skipDeliveries {
+				webhookDB.EXPECT().GetWebhookDeliveries(mock.Anything, "1").Return([]models.WebhookDelivery{
+					{WebhookID: "1", ID: "2", Status: models.WebhookDeliveryStatusSucceeded, Attempts: 1},
+				}, c.state.errDeliveries).Once()
+			}
+
+			ctrl := FamilyController{
+				familyDB:  familyDB,
+				webhookDB: webhookDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.AddParam("webhook_id", "1")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetWebhookDeliveriesHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				deliveries := result.Data.(map[string]any)["deliveries"].([]any)
+				assert.Len(t, deliveries, 1)
+				assert.Equal(t, "SUCCEEDED", deliveries[0].(map[string]any)["status"])
+			}
+
+			familyDB.AssertExpectations(t)
+			webhookDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/notify"
+)
+
+// updateFamilySettingsHandlerRequest only changes the settings that are set
+type updateFamilySettingsHandlerRequest struct {
+	FamilyID                 string  `json:"-"`
+	HideRankingsFromChildren *bool   `json:"hide_rankings_from_children"`
+	Language                 *string `json:"language"` // An empty language lets members get notifications in their own language
+	UserID                   string  `json:"-"`
+}
+
+type updateFamilySettingsHandlerResponse struct {
+	Settings models.FamilySettings `json:"settings"`
+}
+
+func (c *FamilyController) UpdateFamilySettingsHandler(cgin *gin.Context) {
+
+	var req updateFamilySettingsHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.FamilyID = cgin.Param("family_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleUpdateFamilySettings(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleUpdateFamilySettings(ctx context.Context, req *updateFamilySettingsHandlerRequest) (updateFamilySettingsHandlerResponse, error) {
+	resp := updateFamilySettingsHandlerResponse{}
+
+	if req.UserID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	if req.FamilyID == "" {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id")
+	}
+
+	if req.Language != nil && *req.Language != "" && !notify.IsSupportedLanguage(*req.Language) {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("language must be one of: %s", strings.Join(notify.Languages, ", ")))
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	settings, err := c.familyDB.GetFamilySettings(ctx, req.FamilyID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get family settings: %w", err)
+	}
+
+	if req.HideRankingsFromChildren != nil {
+		settings.HideRankingsFromChildren = *req.HideRankingsFromChildren
+	}
+
+	if req.Language != nil {
+		settings.Language = *req.Language
+	}
+
+	settings.UpdatedByUserID = req.UserID
+	settings.UpdatedOn = time.Now().UTC()
+	settings.UpdatedOnStr = util.ToFormattedUTC(settings.UpdatedOn)
+
+	if err := c.familyDB.SaveFamilySettings(ctx, settings); err != nil {
+		return resp, fmt.Errorf("failed to save family settings: %w", err)
+	}
+
+	resp.Settings = settings
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+package family
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_UpdateFamilySettingsHandler(t *testing.T) {
+	type state struct {
+		body        string
+		invalidUser bool
+		errSave     error
+	}
+	type want struct {
+		err      string
+		code     int
+		hide     bool
+		language string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{body: `{"hide_rankings_from_children":true}`}, want{"", http.StatusOK, true, ""}},
+		{"happy path - language", state{body: `{"language":"es"}`}, want{"", http.StatusOK, false, "es"}},
+		{"happy path - unchanged if not set", state{body: `{}`}, want{"", http.StatusOK, false, ""}},
+		{"fail - invalid json", state{body: `{`}, want{"failed to unmarshal json body: unexpected EOF", http.StatusBadRequest, false, ""}},
+		{"fail - unsupported language", state{body: `{"language":"xx"}`}, want{"language must be one of: en, es", http.StatusBadRequest, false, ""}},
+		{"fail - invalid user", state{body: `{}`, invalidUser: true}, want{"access denied: user is not part of family", http.StatusForbidden, false, ""}},
+		{"fail - save", state{body: `{}`, errSave: errFail}, want{"failed to save family settings: fail", http.StatusInternalServerError, false, ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+
+			ctrl := FamilyController{
+				familyDB: familyDB,
+			}
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "123"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "1"
+			}
+
+			if c.want.code != http.StatusBadRequest {
+				familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			}
+			if c.want.code == http.StatusOK || c.state.errSave != nil {
+				familyDB.EXPECT().GetFamilySettings(mock.Anything, "456").Return(models.NewFamilySettings("456"), nil).Once()
+				familyDB.EXPECT().SaveFamilySettings(mock.Anything, mock.MatchedBy(func(s models.FamilySettings) bool {
+					return s.FamilyID == "456" && s.HideRankingsFromChildren == c.want.hide &&
+						s.Language == c.want.language &&
+						s.UpdatedByUserID == "123" && s.UpdatedOnStr != ""
+				})).Return(c.state.errSave).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("family_id", "456")
+			cgin.Request = httptest.NewRequest("PUT", "/", strings.NewReader(c.state.body)).WithContext(ctx)
+
+			ctrl.UpdateFamilySettingsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			familyDB.AssertExpectations(t)
+		})
+	}
+}
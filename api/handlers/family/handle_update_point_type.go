@@ -0,0 +1,134 @@
+package family
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type updatePointTypeHandlerRequest struct {
+	Cashout     *models.CashoutRule `json:"cashout"`
+	FamilyID    string              `json:"-"`
+	Name        string              `json:"name"`
+	PointTypeID string              `json:"-"`
+	Unit        string              `json:"unit"`
+	UserID      string              `json:"-"`
+}
+
+type updatePointTypeHandlerResponse struct {
+	PointType models.PointType `json:"point_type"`
+}
+
+// UpdatePointTypeHandler replaces the name, unit and cashout rule of a point type. The default type
+// can be updated too, i.e. to give it a cashout rule.
+func (c *FamilyController) UpdatePointTypeHandler(cgin *gin.Context) {
+
+	var req updatePointTypeHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.FamilyID = cgin.Param("family_id")
+	req.PointTypeID = cgin.Param("point_type_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleUpdatePointType(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *FamilyController) handleUpdatePointType(ctx context.Context, req *updatePointTypeHandlerRequest) (updatePointTypeHandlerResponse, error) {
+	resp := updatePointTypeHandlerResponse{}
+
+	if err := validateUpdatePointType(req); err != nil {
+		return resp, err
+	}
+
+	if _, err := c.getFamilyUserIDs(ctx, req.FamilyID, req.UserID); err != nil {
+		return resp, err
+	}
+
+	pointTypes, err := c.getPointTypes(ctx, req.FamilyID)
+	if err != nil {
+		return resp, err
+	}
+
+	pointType, ok := models.FindPointType(pointTypes, req.PointTypeID)
+	if !ok {
+		return resp, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("point type (id=%s)", req.PointTypeID))
+	}
+
+	if err := checkPointTypeName(pointTypes, pointType.ID, req.Name); err != nil {
+		return resp, err
+	}
+
+	now := util.ToFormattedUTC(time.Now())
+
+	// the built-in default type becomes the family's own
+	if pointType.FamilyID == "" {
+		pointType.FamilyID = req.FamilyID
+		pointType.CreatedByUserID = req.UserID
+		pointType.CreatedOnStr = now
+	}
+
+	pointType.Name = strings.TrimSpace(req.Name)
+	pointType.Unit = strings.TrimSpace(req.Unit)
+	pointType.Cashout = req.Cashout
+	pointType.UpdatedOnStr = now
+
+	if err := c.pointTypeDB.SavePointType(ctx, pointType); err != nil {
+		return resp, fmt.Errorf("failed to save point type: %w", err)
+	}
+
+	pointType.ParseTimes()
+	resp.PointType = pointType
+
+	return resp, nil
+}
+
+func validateUpdatePointType(req *updatePointTypeHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.FamilyID == "" {
+		apierr.AppendError("missing family_id")
+	}
+
+	if req.PointTypeID == "" {
+		apierr.AppendError("missing point_type_id")
+	}
+
+	validatePointTypeFields(apierr, req.Name, req.Unit, req.Cashout)
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
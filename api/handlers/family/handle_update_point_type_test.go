@@ -0,0 +1,118 @@
+package family
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_handleUpdatePointType(t *testing.T) {
+	type state struct {
+		pointTypeID string
+		name        string
+		invalidUser bool
+		errGetTypes error
+		errSave     error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{pointTypeID: "st", name: "Screen minutes"}, want{}},
+		{"happy path - same name", state{pointTypeID: "st", name: "screen time"}, want{}},
+		{"happy path - default type", state{pointTypeID: models.DefaultPointTypeID, name: "Stars"}, want{}},
+		{"fail - invalid user", state{pointTypeID: "st", name: "Stars", invalidUser: true}, want{"access denied: user is not part of family"}},
+		{"fail - not found", state{pointTypeID: "nope", name: "Stars"}, want{"not found: point type (id=nope)"}},
+		{"fail - name taken", state{pointTypeID: "st", name: "points"}, want{"a point type named 'points' already exists"}},
+		{"fail - get types", state{pointTypeID: "st", name: "Stars", errGetTypes: errFail}, want{"failed to get point types: fail"}},
+		{"fail - save type", state{pointTypeID: "st", name: "Stars", errSave: errFail}, want{"failed to save point type: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			familyDB := mocks.NewMockIFamilyStorage(t)
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
+
+			familyUsers := []models.FamilyUser{{FamilyID: "456", UserID: "1"}}
+			if c.state.invalidUser {
+				familyUsers[0].UserID = "2"
+			}
+
+			familyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return(familyUsers, nil).Once()
+			if !c.state.invalidUser {
+				pointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{
+					{FamilyID: "456", ID: "st", Name: "Screen time", CreatedByUserID: "9", CreatedOnStr: "2024-03-01T00:00:00Z"},
+				}, c.state.errGetTypes).Once()
+			}
+			if c.want.err == "" || c.state.errSave != nil {
+				pointTypeDB.EXPECT().SavePointType(mock.Anything, mock.MatchedBy(func(pt models.PointType) bool {
+					// the built-in default type becomes the family's own
+					createdBy := "9"
+					if c.state.pointTypeID == models.DefaultPointTypeID {
+						createdBy = "1"
+					}
+					return pt.FamilyID == "456" && pt.ID == c.state.pointTypeID && pt.Name == c.state.name &&
+						pt.Cashout.Points == 10 && pt.CreatedByUserID == createdBy && pt.UpdatedOnStr != ""
+				})).Return(c.state.errSave).Once()
+			}
+
+			ctrl := FamilyController{
+				familyDB:    familyDB,
+				pointTypeDB: pointTypeDB,
+			}
+
+			req := &updatePointTypeHandlerRequest{
+				Cashout:     &models.CashoutRule{Points: 10, Value: 30},
+				FamilyID:    "456",
+				Name:        c.state.name,
+				PointTypeID: c.state.pointTypeID,
+				UserID:      "1",
+			}
+
+			res, err := ctrl.handleUpdatePointType(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, c.state.pointTypeID, res.PointType.ID)
+				assert.False(t, res.PointType.UpdatedOn.IsZero())
+			}
+
+			familyDB.AssertExpectations(t)
+			pointTypeDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateUpdatePointType(t *testing.T) {
+	type test struct {
+		name string
+		req  updatePointTypeHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", updatePointTypeHandlerRequest{UserID: "1", FamilyID: "456", PointTypeID: "st", Name: "Money"}, ""},
+		{"fail - missing user", updatePointTypeHandlerRequest{FamilyID: "456", PointTypeID: "st", Name: "Money"}, "unauthorized: missing user ID"},
+		{"fail - missing point type", updatePointTypeHandlerRequest{UserID: "1", FamilyID: "456", Name: "Money"}, "missing point_type_id"},
+		{"fail - invalid cashout rule", updatePointTypeHandlerRequest{UserID: "1", FamilyID: "456", PointTypeID: "st", Name: "Money", Cashout: &models.CashoutRule{Points: 1, Value: 1, Minimum: -1}}, "cashout points and value must be positive integers"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateUpdatePointType(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
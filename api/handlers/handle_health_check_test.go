@@ -7,12 +7,13 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/storage"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_Controller_HealthCheckHandler(t *testing.T) {
-	c, err := NewLambdaController(context.Background(), env.GetEnv("ENV"))
+	// the health check doesn't use storage
+	c, err := NewLambdaController(context.Background(), storage.Storages{})
 	if err != nil {
 		panic("failed to initialize lambda controller: " + err.Error())
 	}
@@ -13,14 +13,7 @@ type LambdaController struct {
 	pointsDB storage.IPointsStorage
 }
 
-func NewLambdaController(ctx context.Context, env string) (*LambdaController, error) {
-	storageCfg := storage.Config{Env: env}
-
-	pointsDB, err := storage.NewDynamoDbStorage(storageCfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize points db: %w", err)
-	}
-
+func NewLambdaController(ctx context.Context, stores storage.Storages) (*LambdaController, error) {
 	authController, err := auth.New(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize auth controller: %w", err)
@@ -28,6 +21,6 @@ func NewLambdaController(ctx context.Context, env string) (*LambdaController, er
 
 	return &LambdaController{
 		auth:     authController,
-		pointsDB: pointsDB,
+		pointsDB: stores.Core,
 	}, nil
 }
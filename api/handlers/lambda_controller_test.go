@@ -4,11 +4,15 @@ import (
 	"context"
 	"testing"
 
+	"github.com/sebboness/yektaspoints/storage"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_PointsController_New(t *testing.T) {
-	c, err := NewLambdaController(context.Background(), "local")
+	stores, err := storage.NewStorages(storage.Config{Env: "local"})
+	assert.Nil(t, err)
+
+	c, err := NewLambdaController(context.Background(), stores)
 	assert.Nil(t, err)
 	assert.NotNil(t, c)
 }
@@ -0,0 +1,158 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/segmentio/ksuid"
+)
+
+type addPointAttachmentHandlerRequest struct {
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"` // In bytes
+	PointID     string `json:"-"`
+	UserID      string `json:"-"`
+}
+
+type addPointAttachmentHandlerResponse struct {
+	Attachment models.Attachment `json:"attachment"`
+	UploadURL  string            `json:"upload_url"`
+	ExpiresOn  time.Time         `json:"expires_on"`
+}
+
+// AddPointAttachmentHandler attaches a file to the child's own point request. The response has
+// a pre-signed URL the file is then PUT to, with the requested content type and size.
+func (c *PointsController) AddPointAttachmentHandler(cgin *gin.Context) {
+
+	var req addPointAttachmentHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.PointID = cgin.Param("point_id")
+	req.UserID = authInfo.GetUserID()
+
+	// children can only attach files to their own requests
+	if targetUserID := cgin.Param("user_id"); targetUserID != req.UserID {
+		apiErr := apierr.New(apierr.AccessDenied).WithError("only the child can attach files to their request")
+		cgin.JSON(apiErr.StatusCode(), handlers.ErrorResult(apiErr))
+		return
+	}
+
+	resp, err := c.handleAddPointAttachment(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusCreated, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleAddPointAttachment(ctx context.Context, req *addPointAttachmentHandlerRequest) (addPointAttachmentHandlerResponse, error) {
+	resp := addPointAttachmentHandlerResponse{}
+
+	if err := validateAddPointAttachment(req); err != nil {
+		return resp, err
+	}
+
+	now := time.Now()
+
+	a := models.Attachment{
+		ID:               ksuid.New().String(),
+		ContentType:      req.ContentType,
+		Size:             req.Size,
+		UploadedByUserID: req.UserID,
+		CreatedOnStr:     util.ToFormatted(now),
+	}
+
+	var uploadURL string
+
+	// children tend to attach several photos at once, so an attachment added to the point in the
+	// meantime is re-read and kept
+	err := storage.RetryOnConflict(ctx, func(ctx context.Context) error {
+		point, err := c.pointsDB.GetPointByID(ctx, req.UserID, req.PointID)
+		if err != nil {
+			return fmt.Errorf("failed to get point: %w", err)
+		}
+
+		apiErr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+		if point.Status != models.PointStatusWaiting && point.Status != models.PointStatusChangesRequested {
+			return apiErr.WithError("files can only be attached to requests waiting for a decision")
+		}
+
+		if len(point.Attachments) >= models.AttachmentsPerPoint {
+			return apiErr.WithError(fmt.Sprintf("a request can have at most %d attachments", models.AttachmentsPerPoint))
+		}
+
+		uploadURL, err = c.attachments.PresignUpload(ctx, point.AttachmentKey(a.ID), a.ContentType, a.Size, attachment.URLExpiry)
+		if err != nil {
+			return fmt.Errorf("failed to presign upload: %w", err)
+		}
+
+		point.Attachments = append(point.Attachments, a)
+		if err := c.pointsDB.SavePoint(ctx, point); err != nil {
+			return fmt.Errorf("failed to save points: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	a.ParseTimes()
+	resp.Attachment = a
+	resp.UploadURL = uploadURL
+	resp.ExpiresOn = now.Add(attachment.URLExpiry).UTC()
+
+	return resp, nil
+}
+
+func validateAddPointAttachment(req *addPointAttachmentHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.PointID == "" {
+		apierr.AppendError("missing point_id")
+	}
+
+	if !slices.Contains(models.AttachmentContentTypes, req.ContentType) {
+		apierr.AppendErrorf("content_type must be one of: %s", strings.Join(models.AttachmentContentTypes, ", "))
+	}
+
+	if req.Size <= 0 || req.Size > models.AttachmentMaxSize {
+		apierr.AppendErrorf("size must be between 1 and %d bytes", models.AttachmentMaxSize)
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
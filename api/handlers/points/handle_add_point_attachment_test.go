@@ -0,0 +1,191 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	attmocks "github.com/sebboness/yektaspoints/mocks/attachment"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_AddPointAttachmentHandler(t *testing.T) {
+	type state struct {
+		targetUserID string
+		body         string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{"123", `{"content_type":"image/jpeg","size":2048}`}, want{"", 201}},
+		{"fail - someone else's request", state{"2", `{"content_type":"image/jpeg","size":2048}`}, want{"only the child can attach files to their request", 403}},
+		{"fail - invalid body", state{"123", `{"size":`}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{"123", `{"content_type":"image/jpeg"}`}, want{"size must be between 1 and 10485760 bytes", 400}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockAttachments := attmocks.NewMockStore(t)
+
+			if c.want.code == 201 {
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "123", "p1").Return(models.Point{ID: "p1", UserID: "123", Status: models.PointStatusWaiting}, nil).Once()
+				mockAttachments.EXPECT().PresignUpload(mock.Anything, mock.Anything, "image/jpeg", int64(2048), attachment.URLExpiry).Return("https://upload", nil).Once()
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			ctrl := PointsController{
+				attachments: mockAttachments,
+				pointsDB:    mockPointsDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("user_id", c.state.targetUserID)
+			cgin.AddParam("point_id", "p1")
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.AddPointAttachmentHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == 201 {
+				assert.Equal(t, "https://upload", result.Data.(map[string]any)["upload_url"])
+			}
+
+			mockPointsDB.AssertExpectations(t)
+			mockAttachments.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleAddPointAttachment(t *testing.T) {
+	type state struct {
+		point        models.Point
+		conflict     bool
+		errGetPoint  error
+		errPresign   error
+		errSavePoint error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	waiting := models.Point{ID: "p1", UserID: "1", Status: models.PointStatusWaiting}
+	changesRequested := models.Point{ID: "p1", UserID: "1", Status: models.PointStatusChangesRequested}
+	settled := models.Point{ID: "p1", UserID: "1", Status: models.PointStatusSettled}
+	full := waiting
+	full.Attachments = make([]models.Attachment, models.AttachmentsPerPoint)
+
+	cases := []test{
+		{"happy path - waiting", state{point: waiting}, want{}},
+		{"happy path - changes requested", state{point: changesRequested}, want{}},
+		{"happy path - retried after conflict", state{point: waiting, conflict: true}, want{}},
+		{"fail - settled", state{point: settled}, want{"files can only be attached to requests waiting for a decision"}},
+		{"fail - too many attachments", state{point: full}, want{"a request can have at most 5 attachments"}},
+		{"fail - get point", state{errGetPoint: errFail}, want{"failed to get point: fail"}},
+		{"fail - presign", state{point: waiting, errPresign: errFail}, want{"failed to presign upload: fail"}},
+		{"fail - save point", state{point: waiting, errSavePoint: errFail}, want{"failed to save points: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockAttachments := attmocks.NewMockStore(t)
+
+			mockPointsDB.EXPECT().GetPointByID(mock.Anything, "1", "p1").Return(c.state.point, c.state.errGetPoint).Once()
+			if c.state.conflict {
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "1", "p1").Return(c.state.point, nil).Once()
+				mockAttachments.EXPECT().PresignUpload(mock.Anything, mock.Anything, "image/png", int64(1024), attachment.URLExpiry).Return("https://upload", nil).Once()
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.Anything).Return(apierr.New(apierr.Conflict)).Once()
+			}
+
+			accepted := c.state.errGetPoint == nil && c.state.point.Status != models.PointStatusSettled &&
+				len(c.state.point.Attachments) < models.AttachmentsPerPoint
+			if accepted {
+				mockAttachments.EXPECT().PresignUpload(mock.Anything, mock.MatchedBy(func(key string) bool {
+					return len(key) > len("1/p1/")
+				}), "image/png", int64(1024), attachment.URLExpiry).Return("https://upload", c.state.errPresign).Once()
+			}
+			if accepted && c.state.errPresign == nil {
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.MatchedBy(func(p models.Point) bool {
+					return len(p.Attachments) == 1 && p.Attachments[0].UploadedByUserID == "1"
+				})).Return(c.state.errSavePoint).Once()
+			}
+
+			ctrl := PointsController{
+				attachments: mockAttachments,
+				pointsDB:    mockPointsDB,
+			}
+
+			res, err := ctrl.handleAddPointAttachment(context.Background(), &addPointAttachmentHandlerRequest{
+				ContentType: "image/png",
+				Size:        1024,
+				PointID:     "p1",
+				UserID:      "1",
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, "https://upload", res.UploadURL)
+				assert.NotEmpty(t, res.Attachment.ID)
+				assert.False(t, res.Attachment.CreatedOn.IsZero())
+				assert.False(t, res.ExpiresOn.IsZero())
+			}
+
+			mockPointsDB.AssertExpectations(t)
+			mockAttachments.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateAddPointAttachment(t *testing.T) {
+	type test struct {
+		name string
+		req  addPointAttachmentHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", addPointAttachmentHandlerRequest{UserID: "1", PointID: "p1", ContentType: "image/heic", Size: 1}, ""},
+		{"fail - missing user", addPointAttachmentHandlerRequest{PointID: "p1", ContentType: "image/heic", Size: 1}, "unauthorized: missing user ID"},
+		{"fail - missing point", addPointAttachmentHandlerRequest{UserID: "1", ContentType: "image/heic", Size: 1}, "missing point_id"},
+		{"fail - unsupported content type", addPointAttachmentHandlerRequest{UserID: "1", PointID: "p1", ContentType: "application/pdf", Size: 1}, "content_type must be one of: image/heic, image/jpeg, image/png, image/webp"},
+		{"fail - too large", addPointAttachmentHandlerRequest{UserID: "1", PointID: "p1", ContentType: "image/heic", Size: models.AttachmentMaxSize + 1}, "size must be between 1 and 10485760 bytes"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateAddPointAttachment(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
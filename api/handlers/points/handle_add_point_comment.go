@@ -0,0 +1,160 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/segmentio/ksuid"
+)
+
+const maxPointCommentLength = 500
+
+type addPointCommentHandlerRequest struct {
+	RequestChanges bool   `json:"request_changes"` // Parents can ask the child to amend a waiting request
+	Text           string `json:"text"`
+	PointID        string `json:"-"`
+	TargetUserID   string `json:"-"`
+	UserID         string `json:"-"`
+}
+
+type addPointCommentHandlerResponse struct {
+	Comment models.PointComment `json:"comment"`
+	Point   models.Point        `json:"point"`
+}
+
+// AddPointCommentHandler adds a comment to the thread of a child's point request. The child and
+// their parents can comment, and parents can request changes to a request that is waiting for a decision.
+func (c *PointsController) AddPointCommentHandler(cgin *gin.Context) {
+
+	var req addPointCommentHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.PointID = cgin.Param("point_id")
+	req.TargetUserID = cgin.Param("user_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleAddPointComment(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusCreated, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleAddPointComment(ctx context.Context, req *addPointCommentHandlerRequest) (addPointCommentHandlerResponse, error) {
+	resp := addPointCommentHandlerResponse{}
+
+	if err := validateAddPointComment(req); err != nil {
+		return resp, err
+	}
+
+	if err := c.checkPointsAccess(ctx, req.UserID, req.TargetUserID); err != nil {
+		return resp, err
+	}
+
+	// access was checked, so anyone but the child is a parent
+	if req.RequestChanges && req.UserID == req.TargetUserID {
+		return resp, apierr.New(apierr.AccessDenied).WithError("only parents can request changes")
+	}
+
+	now := util.ToFormatted(time.Now())
+
+	var point models.Point
+
+	// changes are requested before the comment is saved, so there's no comment requesting changes
+	// of a point that was decided in the meantime. The point is re-read if someone else changed it.
+	err := storage.RetryOnConflict(ctx, func(ctx context.Context) error {
+		var err error
+
+		point, err = c.pointsDB.GetPointByID(ctx, req.TargetUserID, req.PointID)
+		if err != nil {
+			return fmt.Errorf("failed to get point: %w", err)
+		}
+
+		if !req.RequestChanges {
+			return nil
+		}
+
+		if point.Status != models.PointStatusWaiting {
+			return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+				WithError("changes can only be requested on points waiting for a decision")
+		}
+
+		point.Status = models.PointStatusChangesRequested
+		point.UpdatedOnStr = now
+
+		if err := c.pointsDB.SavePoint(ctx, point); err != nil {
+			return fmt.Errorf("failed to save points: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return resp, err
+	}
+
+	comment := models.PointComment{
+		UserID:          req.TargetUserID,
+		ID:              ksuid.New().String(),
+		PointID:         req.PointID,
+		AuthorUserID:    req.UserID,
+		Text:            strings.TrimSpace(req.Text),
+		RequestsChanges: req.RequestChanges,
+		CreatedOnStr:    now,
+	}
+
+	if err := c.pointCommentDB.SavePointComment(ctx, comment); err != nil {
+		return resp, fmt.Errorf("failed to save point comment: %w", err)
+	}
+
+	comment.ParseTimes()
+	point.ParseTimes()
+	resp.Comment = comment
+	resp.Point = point
+
+	c.events.Publish(ctx, eventbus.Event{
+		Type:   eventbus.EventTypePointCommented,
+		UserID: req.TargetUserID,
+		Data:   comment,
+	})
+
+	return resp, nil
+}
+
+func validateAddPointComment(req *addPointCommentHandlerRequest) error {
+	if err := validatePointRef(req.UserID, req.TargetUserID, req.PointID); err != nil {
+		return err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" || len(text) > maxPointCommentLength {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("text must be between 1 and %d characters", maxPointCommentLength))
+	}
+
+	return nil
+}
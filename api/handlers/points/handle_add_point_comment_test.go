@@ -0,0 +1,206 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_AddPointCommentHandler(t *testing.T) {
+	type state struct {
+		body string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{`{"text":"Here's the photo!"}`}, want{"", 201}},
+		{"fail - invalid body", state{`{"text":`}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{`{"text":" "}`}, want{"text must be between 1 and 500 characters", 400}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointCommentDB := mocks.NewMockIPointCommentStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			if c.want.code == 201 {
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "123", "p1").Return(models.Point{ID: "p1", UserID: "123", Status: models.PointStatusChangesRequested}, nil).Once()
+				mockPointCommentDB.EXPECT().SavePointComment(mock.Anything, mock.Anything).Return(nil).Once()
+				mockEvents.EXPECT().Publish(mock.Anything, mock.Anything).Once()
+			}
+
+			ctrl := PointsController{
+				events:         mockEvents,
+				pointCommentDB: mockPointCommentDB,
+				pointsDB:       mockPointsDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("user_id", "123")
+			cgin.AddParam("point_id", "p1")
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.AddPointCommentHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockPointCommentDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleAddPointComment(t *testing.T) {
+	type state struct {
+		userID         string
+		requestChanges bool
+		pointStatus    models.PointStatus
+		decidedStatus  models.PointStatus // status of the point after a conflicting save
+		errSaveComment error
+		errSavePoint   error
+	}
+	type want struct {
+		err    string
+		status models.PointStatus
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - child comments", state{userID: "2", pointStatus: models.PointStatusWaiting}, want{"", models.PointStatusWaiting}},
+		{"happy path - parent comments", state{userID: "1", pointStatus: models.PointStatusSettled}, want{"", models.PointStatusSettled}},
+		{"happy path - parent requests changes", state{userID: "1", requestChanges: true, pointStatus: models.PointStatusWaiting}, want{"", models.PointStatusChangesRequested}},
+		{"fail - child requests changes", state{userID: "2", requestChanges: true, pointStatus: models.PointStatusWaiting}, want{"only parents can request changes", ""}},
+		{"fail - request changes on settled point", state{userID: "1", requestChanges: true, pointStatus: models.PointStatusSettled}, want{"changes can only be requested on points waiting for a decision", ""}},
+		{"happy path - retried after conflict", state{userID: "1", requestChanges: true, pointStatus: models.PointStatusWaiting, decidedStatus: models.PointStatusWaiting}, want{"", models.PointStatusChangesRequested}},
+		{"fail - decided in the meantime", state{userID: "1", requestChanges: true, pointStatus: models.PointStatusWaiting, decidedStatus: models.PointStatusSettled}, want{"changes can only be requested on points waiting for a decision", ""}},
+		{"fail - save comment", state{userID: "1", pointStatus: models.PointStatusWaiting, errSaveComment: errFail}, want{"failed to save point comment: fail", ""}},
+		{"fail - save comment after requesting changes", state{userID: "1", requestChanges: true, pointStatus: models.PointStatusWaiting, errSaveComment: errFail}, want{"failed to save point comment: fail", ""}},
+		{"fail - save point", state{userID: "1", requestChanges: true, pointStatus: models.PointStatusWaiting, errSavePoint: errFail}, want{"failed to save points: fail", ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointCommentDB := mocks.NewMockIPointCommentStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			if c.state.userID == "1" {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "2").Return(models.User{UserID: "2", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+			}
+
+			isChangesRequested := mock.MatchedBy(func(p models.Point) bool {
+				return p.Status == models.PointStatusChangesRequested && p.UpdatedOnStr != ""
+			})
+
+			if !strings.HasPrefix(c.want.err, "only") {
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "2", "p1").Return(models.Point{ID: "p1", UserID: "2", Status: c.state.pointStatus}, nil).Once()
+			}
+			if c.state.decidedStatus != "" {
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, isChangesRequested).Return(apierr.New(apierr.Conflict)).Once()
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "2", "p1").Return(models.Point{ID: "p1", UserID: "2", Status: c.state.decidedStatus}, nil).Once()
+			}
+
+			validRequest := !strings.HasPrefix(c.want.err, "only") && !strings.HasPrefix(c.want.err, "changes")
+
+			// the point is saved before the comment
+			if validRequest && c.state.requestChanges {
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, isChangesRequested).Return(c.state.errSavePoint).Once()
+			}
+			if validRequest && c.state.errSavePoint == nil {
+				mockPointCommentDB.EXPECT().SavePointComment(mock.Anything, mock.MatchedBy(func(comment models.PointComment) bool {
+					return comment.UserID == "2" && comment.PointID == "p1" && comment.AuthorUserID == c.state.userID &&
+						comment.Text == "Send a photo first" && comment.RequestsChanges == c.state.requestChanges && comment.ID != ""
+				})).Return(c.state.errSaveComment).Once()
+			}
+			if c.want.err == "" {
+				mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+					return evt.Type == eventbus.EventTypePointCommented && evt.UserID == "2"
+				})).Once()
+			}
+
+			ctrl := PointsController{
+				events:         mockEvents,
+				pointCommentDB: mockPointCommentDB,
+				pointsDB:       mockPointsDB,
+				userDB:         mockUserDB,
+			}
+
+			res, err := ctrl.handleAddPointComment(context.Background(), &addPointCommentHandlerRequest{
+				RequestChanges: c.state.requestChanges,
+				Text:           " Send a photo first ",
+				PointID:        "p1",
+				TargetUserID:   "2",
+				UserID:         c.state.userID,
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, c.want.status, res.Point.Status)
+				assert.False(t, res.Comment.CreatedOn.IsZero())
+			}
+
+			mockPointCommentDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateAddPointComment(t *testing.T) {
+	type test struct {
+		name string
+		req  addPointCommentHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", addPointCommentHandlerRequest{UserID: "1", TargetUserID: "2", PointID: "p1", Text: "Nice"}, ""},
+		{"fail - missing point", addPointCommentHandlerRequest{UserID: "1", TargetUserID: "2", Text: "Nice"}, "missing point_id"},
+		{"fail - empty text", addPointCommentHandlerRequest{UserID: "1", TargetUserID: "2", PointID: "p1", Text: "  "}, "text must be between 1 and 500 characters"},
+		{"fail - text too long", addPointCommentHandlerRequest{UserID: "1", TargetUserID: "2", PointID: "p1", Text: strings.Repeat("a", 501)}, "text must be between 1 and 500 characters"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateAddPointComment(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
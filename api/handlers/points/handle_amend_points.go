@@ -0,0 +1,142 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+)
+
+type amendPointsHandlerRequest struct {
+	Points  int    `json:"points"` // Optional, the points are kept if not set
+	Reason  string `json:"reason"` // Optional, the reason is kept if not set
+	PointID string `json:"-"`
+	UserID  string `json:"-"`
+}
+
+// AmendPointsHandler lets a child amend the points or reason of a request their parents asked
+// changes for. The request is then waiting for a decision again.
+func (c *PointsController) AmendPointsHandler(cgin *gin.Context) {
+
+	var req amendPointsHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.PointID = cgin.Param("point_id")
+	req.UserID = authInfo.GetUserID()
+
+	// children can only amend their own requests
+	if targetUserID := cgin.Param("user_id"); targetUserID != req.UserID {
+		apiErr := apierr.New(apierr.AccessDenied).WithError("only the child can amend their request")
+		cgin.JSON(apiErr.StatusCode(), handlers.ErrorResult(apiErr))
+		return
+	}
+
+	resp, err := c.handleAmendPoints(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleAmendPoints(ctx context.Context, req *amendPointsHandlerRequest) (pointsHandlerResponse, error) {
+	resp := pointsHandlerResponse{}
+
+	if err := validateAmendPoints(req); err != nil {
+		return resp, err
+	}
+
+	point, err := c.pointsDB.GetPointByID(ctx, req.UserID, req.PointID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get point: %w", err)
+	}
+
+	apiErr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if point.Status != models.PointStatusChangesRequested {
+		return resp, apiErr.WithError("only requests with changes requested can be amended")
+	}
+
+	if req.Points != 0 {
+		// what cashed out points are worth was settled by the cashout rule when requested
+		if point.Request.Type == models.PointRequestTypeCashout {
+			return resp, apiErr.WithError("points of a cashout can't be amended")
+		}
+		point.Points = req.Points
+	}
+
+	if req.Reason != "" {
+		point.Request.Reason = req.Reason
+	}
+
+	point.Status = models.PointStatusWaiting
+	point.UpdatedOnStr = util.ToFormatted(time.Now())
+
+	if err := c.pointsDB.SavePoint(ctx, point); err != nil {
+		return resp, fmt.Errorf("failed to save points: %w", err)
+	}
+
+	point.ParseTimes()
+	resp.Point = point
+	resp.Summary = point.ToPointSummary()
+
+	c.events.Publish(ctx, eventbus.Event{
+		Type:   eventbus.EventTypePointsRequested,
+		UserID: req.UserID,
+		Data:   resp.Summary,
+	})
+
+	return resp, nil
+}
+
+func validateAmendPoints(req *amendPointsHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.PointID == "" {
+		apierr.AppendError("missing point_id")
+	}
+
+	if req.Points < 0 {
+		apierr.AppendError("points must be a positive integer")
+	}
+
+	// same check as when requesting points
+	if req.Reason != "" && len(req.Reason) <= 5 {
+		apierr.AppendError("reason for requesting points must not be empty")
+	}
+
+	if req.Points == 0 && req.Reason == "" {
+		apierr.AppendError("points or reason must be amended")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
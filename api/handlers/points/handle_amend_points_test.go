@@ -0,0 +1,180 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_AmendPointsHandler(t *testing.T) {
+	type state struct {
+		targetUserID string
+		body         string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{"123", `{"points":3}`}, want{"", 200}},
+		{"fail - someone else's request", state{"2", `{"points":3}`}, want{"only the child can amend their request", 403}},
+		{"fail - invalid body", state{"123", `{"points":`}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{"123", `{}`}, want{"points or reason must be amended", 400}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			if c.want.code == 200 {
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "123", "p1").Return(models.Point{ID: "p1", UserID: "123", Status: models.PointStatusChangesRequested}, nil).Once()
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.Anything).Return(nil).Once()
+				mockEvents.EXPECT().Publish(mock.Anything, mock.Anything).Once()
+			}
+
+			ctrl := PointsController{
+				events:   mockEvents,
+				pointsDB: mockPointsDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("user_id", c.state.targetUserID)
+			cgin.AddParam("point_id", "p1")
+			cgin.Request = httptest.NewRequest("PUT", "/", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.AmendPointsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockPointsDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleAmendPoints(t *testing.T) {
+	type state struct {
+		points       int
+		reason       string
+		point        models.Point
+		errGetPoint  error
+		errSavePoint error
+	}
+	type want struct {
+		err    string
+		points int
+		reason string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	requested := models.Point{ID: "p1", UserID: "1", Points: 5, Status: models.PointStatusChangesRequested,
+		Request: models.PointRequest{Type: models.PointRequestTypeAdd, Reason: "Cleaned room"}}
+	cashout := models.Point{ID: "p1", UserID: "1", Points: -5, Status: models.PointStatusChangesRequested,
+		Request: models.PointRequest{Type: models.PointRequestTypeCashout, Reason: "Ice cream"}}
+	waiting := requested
+	waiting.Status = models.PointStatusWaiting
+
+	cases := []test{
+		{"happy path - points", state{points: 3, point: requested}, want{"", 3, "Cleaned room"}},
+		{"happy path - reason", state{reason: "Cleaned room, see photo", point: requested}, want{"", 5, "Cleaned room, see photo"}},
+		{"happy path - reason of cashout", state{reason: "Ice cream cone", point: cashout}, want{"", -5, "Ice cream cone"}},
+		{"fail - points of cashout", state{points: 3, point: cashout}, want{"points of a cashout can't be amended", 0, ""}},
+		{"fail - no changes requested", state{points: 3, point: waiting}, want{"only requests with changes requested can be amended", 0, ""}},
+		{"fail - get point", state{points: 3, errGetPoint: errFail}, want{"failed to get point: fail", 0, ""}},
+		{"fail - save point", state{points: 3, point: requested, errSavePoint: errFail}, want{"failed to save points: fail", 0, ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			mockPointsDB.EXPECT().GetPointByID(mock.Anything, "1", "p1").Return(c.state.point, c.state.errGetPoint).Once()
+			if c.want.err == "" || c.state.errSavePoint != nil {
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.MatchedBy(func(p models.Point) bool {
+					return p.Status == models.PointStatusWaiting && p.UpdatedOnStr != ""
+				})).Return(c.state.errSavePoint).Once()
+			}
+			if c.want.err == "" {
+				mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+					return evt.Type == eventbus.EventTypePointsRequested
+				})).Once()
+			}
+
+			ctrl := PointsController{
+				events:   mockEvents,
+				pointsDB: mockPointsDB,
+			}
+
+			res, err := ctrl.handleAmendPoints(context.Background(), &amendPointsHandlerRequest{
+				Points:  c.state.points,
+				Reason:  c.state.reason,
+				PointID: "p1",
+				UserID:  "1",
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, c.want.points, res.Point.Points)
+				assert.Equal(t, c.want.reason, res.Point.Request.Reason)
+				assert.Equal(t, models.PointStatus(models.PointStatusWaiting), res.Point.Status)
+			}
+
+			mockPointsDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateAmendPoints(t *testing.T) {
+	type test struct {
+		name string
+		req  amendPointsHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", amendPointsHandlerRequest{UserID: "1", PointID: "p1", Points: 2}, ""},
+		{"fail - missing user", amendPointsHandlerRequest{PointID: "p1", Points: 2}, "unauthorized: missing user ID"},
+		{"fail - missing point", amendPointsHandlerRequest{UserID: "1", Points: 2}, "missing point_id"},
+		{"fail - negative points", amendPointsHandlerRequest{UserID: "1", PointID: "p1", Points: -2}, "points must be a positive integer"},
+		{"fail - short reason", amendPointsHandlerRequest{UserID: "1", PointID: "p1", Reason: "abc"}, "reason for requesting points must not be empty"},
+		{"fail - nothing amended", amendPointsHandlerRequest{UserID: "1", PointID: "p1"}, "points or reason must be amended"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateAmendPoints(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
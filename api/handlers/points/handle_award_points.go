@@ -0,0 +1,216 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/segmentio/ksuid"
+)
+
+// how many children can be awarded points at once
+const maxAwardUsers = 25
+
+type awardPointsHandlerRequest struct {
+	Points      int      `json:"points"`        // Positive to award, negative to deduct points
+	PointTypeID string   `json:"point_type_id"` // Optional, points are of the default type if not set
+	Reason      string   `json:"reason"`
+	UserIDs     []string `json:"user_ids"`
+	UserID      string   `json:"-"`
+}
+
+type awardPointsHandlerResponse struct {
+	Summaries []models.PointSummary `json:"point_summaries"`
+}
+
+// AwardPointsHandler awards (or deducts) points to several children at once. The points are
+// settled right away and saved all-or-nothing, each with the child's new balance.
+func (c *PointsController) AwardPointsHandler(cgin *gin.Context) {
+
+	var req awardPointsHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleAwardPoints(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusCreated, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleAwardPoints(ctx context.Context, req *awardPointsHandlerRequest) (awardPointsHandlerResponse, error) {
+	resp := awardPointsHandlerResponse{
+		Summaries: []models.PointSummary{},
+	}
+
+	if err := validateAwardPoints(req); err != nil {
+		return resp, err
+	}
+
+	parent, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	for _, childID := range req.UserIDs {
+		child, err := c.userDB.GetUserByID(ctx, childID)
+		if err != nil {
+			return resp, fmt.Errorf("failed to get user %s: %w", childID, err)
+		}
+
+		sharesFamily := slices.ContainsFunc(parent.FamilyIDs, func(fid string) bool {
+			return slices.Contains(child.FamilyIDs, fid)
+		})
+
+		if !parent.IsParent() || !child.IsChild() || !sharesFamily {
+			return resp, apierr.New(apierr.AccessDenied).WithError(fmt.Sprintf("user is not a parent of child (id=%s)", childID))
+		}
+	}
+
+	typeID := models.Point{PointTypeID: req.PointTypeID}.TypeID()
+	if typeID != models.DefaultPointTypeID {
+		pointTypes, err := c.getPointTypes(ctx, parent)
+		if err != nil {
+			return resp, err
+		}
+
+		if _, ok := models.FindPointType(pointTypes, typeID); !ok {
+			return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+				WithError(fmt.Sprintf("unsupported point type '%s'", typeID))
+		}
+	}
+
+	requestType := models.PointRequestTypeAdd
+	if req.Points < 0 {
+		requestType = models.PointRequestTypeSubtract
+	}
+
+	now := util.ToFormatted(time.Now())
+	points := make([]models.Point, len(req.UserIDs))
+
+	// balances are re-read if someone else changed them in the meantime
+	err = storage.RetryOnConflict(ctx, func(ctx context.Context) error {
+		balances := make([]models.PointBalance, len(req.UserIDs))
+
+		for idx, childID := range req.UserIDs {
+			balance, err := c.pointBalance(ctx, childID, typeID)
+			if err != nil {
+				return err
+			}
+			balance.Balance += req.Points
+			balances[idx] = balance
+
+			points[idx] = models.Point{
+				ID:          ksuid.New().String(),
+				UserID:      childID,
+				Points:      req.Points,
+				PointTypeID: req.PointTypeID,
+				Balance:     &balance.Balance,
+				Status:      models.PointStatusSettled,
+				Request: models.PointRequest{
+					DecidedByUserID: req.UserID,
+					DecidedOnStr:    now,
+					Decision:        models.PointRequestDecisionApprove,
+					Reason:          req.Reason,
+					Type:            requestType,
+				},
+				CreatedOnStr: now,
+				UpdatedOnStr: now,
+			}
+		}
+
+		if err := c.pointsDB.SavePoints(ctx, points, balances); err != nil {
+			return fmt.Errorf("failed to save points: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return resp, err
+	}
+
+	for _, point := range points {
+		point.ParseTimes()
+		summary := point.ToPointSummary()
+		resp.Summaries = append(resp.Summaries, summary)
+
+		c.events.Publish(ctx, eventbus.Event{
+			Type:   eventbus.EventTypeBalanceChanged,
+			UserID: point.UserID,
+			Data:   summary,
+		})
+	}
+
+	return resp, nil
+}
+
+// pointBalance returns the user's balance of the type. Save it along with the points that change
+// it, so the save fails if someone else changed the balance in the meantime.
+func (c *PointsController) pointBalance(ctx context.Context, userID, typeID string) (models.PointBalance, error) {
+	balance, err := c.pointsDB.GetPointBalance(ctx, userID, typeID)
+	if err != nil {
+		return balance, fmt.Errorf("failed to get balance of user %s: %w", userID, err)
+	}
+
+	return balance, nil
+}
+
+func validateAwardPoints(req *awardPointsHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.Points == 0 {
+		apierr.AppendError("points must not be zero")
+	}
+
+	if req.Reason == "" || len(req.Reason) > 200 {
+		apierr.AppendError("reason must be between 1 and 200 characters")
+	}
+
+	if len(req.UserIDs) == 0 || len(req.UserIDs) > maxAwardUsers {
+		apierr.AppendErrorf("user_ids must have between 1 and %d users", maxAwardUsers)
+	} else {
+		seen := map[string]bool{}
+		for _, id := range req.UserIDs {
+			if id == "" || seen[id] {
+				apierr.AppendError("user_ids must not be empty or repeated")
+				break
+			}
+			seen[id] = true
+		}
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
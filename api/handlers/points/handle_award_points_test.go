@@ -0,0 +1,268 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_AwardPointsHandler(t *testing.T) {
+	type state struct {
+		body string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{`{"points":3,"reason":"Cleaned the garage","user_ids":["a"]}`}, want{"", 201}},
+		{"fail - invalid body", state{`{"points":`}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{`{"points":0,"reason":"Cleaned the garage","user_ids":["a"]}`}, want{"points must not be zero", 400}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointTypeDB := mocks.NewMockIPointTypeStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			if c.want.code == 201 {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "a").Return(models.User{UserID: "a", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "a", models.DefaultPointTypeID).Return(models.PointBalance{UserID: "a", PointTypeID: models.DefaultPointTypeID}, nil).Once()
+				mockPointsDB.EXPECT().SavePoints(mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockEvents.EXPECT().Publish(mock.Anything, mock.Anything).Once()
+			}
+
+			ctrl := PointsController{
+				events:      mockEvents,
+				pointTypeDB: mockPointTypeDB,
+				pointsDB:    mockPointsDB,
+				userDB:      mockUserDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("POST", "/points/award", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.AwardPointsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == 201 {
+				assert.Len(t, result.Data.(map[string]any)["point_summaries"], 1)
+			}
+
+			mockPointTypeDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleAwardPoints(t *testing.T) {
+	type state struct {
+		points        int
+		pointTypeID   string
+		stranger      bool
+		errUser       error
+		errPointTypes error
+		errBalance    error
+		errSavePoints error
+		conflicts     int
+	}
+	type want struct {
+		err      string
+		balances []int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - award", state{points: 5}, want{"", []int{9, 5}}},
+		{"happy path - deduct", state{points: -2}, want{"", []int{2, -2}}},
+		{"happy path - other type", state{points: 10, pointTypeID: "st"}, want{"", []int{40, 10}}},
+		{"fail - not a parent of child", state{points: 5, stranger: true}, want{"user is not a parent of child (id=c2)", nil}},
+		{"fail - unsupported point type", state{points: 5, pointTypeID: "nope"}, want{"unsupported point type 'nope'", nil}},
+		{"fail - get user", state{points: 5, errUser: errFail}, want{"failed to get user: fail", nil}},
+		{"fail - get point types", state{points: 5, pointTypeID: "st", errPointTypes: errFail}, want{"failed to get point types: fail", nil}},
+		{"happy path - balance changed in the meantime", state{points: 5, conflicts: 1}, want{"", []int{9, 5}}},
+		{"fail - get balance", state{points: 5, errBalance: errFail}, want{"failed to get balance of user c1: fail", nil}},
+		{"fail - save points", state{points: 5, errSavePoints: errFail}, want{"failed to save points: fail", nil}},
+		{"fail - balance keeps changing", state{points: 5, conflicts: storage.ConflictRetryAttempts}, want{"failed to save points: conflict", nil}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointTypeDB := mocks.NewMockIPointTypeStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			parent := models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+			c2 := models.User{UserID: "c2", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+			if c.state.stranger {
+				c2.FamilyIDs = []string{"f2"}
+			}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(parent, c.state.errUser).Once()
+			if c.state.errUser == nil {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c1").Return(models.User{UserID: "c1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c2").Return(c2, nil).Once()
+			}
+
+			accessGranted := c.state.errUser == nil && !c.state.stranger
+			if accessGranted && c.state.pointTypeID != "" {
+				mockPointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "f1").Return([]models.PointType{
+					{FamilyID: "f1", ID: "st", Name: "Screen time"},
+				}, c.state.errPointTypes).Once()
+			}
+
+			typeID := c.state.pointTypeID
+			if typeID == "" {
+				typeID = models.DefaultPointTypeID
+			}
+
+			c1Balance := models.PointBalance{UserID: "c1", PointTypeID: typeID, Balance: 4, Version: 7}
+			if typeID == "st" {
+				c1Balance.Balance = 30
+			}
+			c2Balance := models.PointBalance{UserID: "c2", PointTypeID: typeID}
+
+			// every conflicting attempt re-reads the balances
+			attempts := min(c.state.conflicts+1, storage.ConflictRetryAttempts)
+
+			typeChecked := accessGranted && c.state.errPointTypes == nil && c.state.pointTypeID != "nope"
+			if typeChecked && c.state.errBalance != nil {
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "c1", typeID).Return(c1Balance, c.state.errBalance).Once()
+			}
+			if typeChecked && c.state.errBalance == nil {
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "c1", typeID).Return(c1Balance, nil).Times(attempts)
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "c2", typeID).Return(c2Balance, nil).Times(attempts)
+
+				wantBalances := []models.PointBalance{
+					{UserID: "c1", PointTypeID: typeID, Balance: c1Balance.Balance + c.state.points, Version: 7},
+					{UserID: "c2", PointTypeID: typeID, Balance: c.state.points},
+				}
+				isPoints := mock.MatchedBy(func(points []models.Point) bool {
+					if len(points) != 2 {
+						return false
+					}
+					for idx, p := range points {
+						if c.want.balances != nil && *p.Balance != c.want.balances[idx] {
+							return false
+						}
+						if p.Status != models.PointStatusSettled || p.Request.DecidedByUserID != "1" || p.PointTypeID != c.state.pointTypeID {
+							return false
+						}
+					}
+					return true
+				})
+
+				if c.state.conflicts > 0 {
+					mockPointsDB.EXPECT().SavePoints(mock.Anything, isPoints, wantBalances).Return(apierr.Conflict).Times(c.state.conflicts)
+				}
+				if c.state.conflicts < storage.ConflictRetryAttempts {
+					mockPointsDB.EXPECT().SavePoints(mock.Anything, isPoints, wantBalances).Return(c.state.errSavePoints).Once()
+				}
+			}
+			if c.want.err == "" {
+				mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+					return evt.Type == eventbus.EventTypeBalanceChanged
+				})).Twice()
+			}
+
+			ctrl := PointsController{
+				events:      mockEvents,
+				pointTypeDB: mockPointTypeDB,
+				pointsDB:    mockPointsDB,
+				userDB:      mockUserDB,
+			}
+
+			req := &awardPointsHandlerRequest{
+				Points:      c.state.points,
+				PointTypeID: c.state.pointTypeID,
+				Reason:      "Cleaned the garage",
+				UserIDs:     []string{"c1", "c2"},
+				UserID:      "1",
+			}
+
+			res, err := ctrl.handleAwardPoints(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Len(t, res.Summaries, 2)
+				assert.Equal(t, "c1", res.Summaries[0].UserID)
+				assert.Equal(t, c.state.points, res.Summaries[1].Points)
+
+				wantType := models.PointRequestTypeAdd
+				if c.state.points < 0 {
+					wantType = models.PointRequestTypeSubtract
+				}
+				assert.Equal(t, wantType, res.Summaries[0].Type)
+			}
+
+			mockPointTypeDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateAwardPoints(t *testing.T) {
+	type test struct {
+		name string
+		req  awardPointsHandlerRequest
+		err  string
+	}
+
+	tooMany := make([]string, maxAwardUsers+1)
+
+	cases := []test{
+		{"happy path", awardPointsHandlerRequest{UserID: "1", Points: -3, Reason: "Fought", UserIDs: []string{"a", "b"}}, ""},
+		{"fail - missing user", awardPointsHandlerRequest{Points: 3, Reason: "Chores", UserIDs: []string{"a"}}, "unauthorized: missing user ID"},
+		{"fail - zero points", awardPointsHandlerRequest{UserID: "1", Reason: "Chores", UserIDs: []string{"a"}}, "points must not be zero"},
+		{"fail - missing reason", awardPointsHandlerRequest{UserID: "1", Points: 3, UserIDs: []string{"a"}}, "reason must be between 1 and 200 characters"},
+		{"fail - no users", awardPointsHandlerRequest{UserID: "1", Points: 3, Reason: "Chores"}, "user_ids must have between 1 and 25 users"},
+		{"fail - too many users", awardPointsHandlerRequest{UserID: "1", Points: 3, Reason: "Chores", UserIDs: tooMany}, "user_ids must have between 1 and 25 users"},
+		{"fail - repeated user", awardPointsHandlerRequest{UserID: "1", Points: 3, Reason: "Chores", UserIDs: []string{"a", "a"}}, "user_ids must not be empty or repeated"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateAwardPoints(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
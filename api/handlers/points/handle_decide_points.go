@@ -0,0 +1,178 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+)
+
+type decidePointsHandlerRequest struct {
+	Decision    models.PointRequestDecision `json:"decision"`     // APPROVE or DENY
+	ParentNotes string                      `json:"parent_notes"` // Optional
+	ChildID     string                      `json:"-"`
+	PointID     string                      `json:"-"`
+	UserID      string                      `json:"-"`
+}
+
+// DecidePointsHandler lets a parent approve or deny a child's request. The request is settled
+// either way, approved points with the child's new balance, denied ones with the balance unchanged.
+func (c *PointsController) DecidePointsHandler(cgin *gin.Context) {
+
+	var req decidePointsHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.ChildID = cgin.Param("user_id")
+	req.PointID = cgin.Param("point_id")
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleDecidePoints(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleDecidePoints(ctx context.Context, req *decidePointsHandlerRequest) (pointsHandlerResponse, error) {
+	resp := pointsHandlerResponse{}
+
+	if err := validateDecidePoints(req); err != nil {
+		return resp, err
+	}
+
+	parent, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	child, err := c.userDB.GetUserByID(ctx, req.ChildID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user %s: %w", req.ChildID, err)
+	}
+
+	sharesFamily := slices.ContainsFunc(parent.FamilyIDs, func(fid string) bool {
+		return slices.Contains(child.FamilyIDs, fid)
+	})
+
+	if !parent.IsParent() || !child.IsChild() || !sharesFamily {
+		return resp, apierr.New(apierr.AccessDenied).WithError(fmt.Sprintf("user is not a parent of child (id=%s)", req.ChildID))
+	}
+
+	var point models.Point
+
+	// the point and balance are re-read if someone else changed them in the meantime
+	err = storage.RetryOnConflict(ctx, func(ctx context.Context) error {
+		var err error
+
+		point, err = c.pointsDB.GetPointByID(ctx, req.ChildID, req.PointID)
+		if err != nil {
+			return fmt.Errorf("failed to get point: %w", err)
+		}
+
+		apiErr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+		if point.Status != models.PointStatusWaiting && point.Status != models.PointStatusChangesRequested {
+			return apiErr.WithError("only requests waiting for a decision can be decided")
+		}
+
+		// denied requests keep the balance, but still record it like every settled point
+		balance, err := c.pointBalance(ctx, req.ChildID, point.TypeID())
+		if err != nil {
+			return err
+		}
+
+		if req.Decision == models.PointRequestDecisionApprove {
+			balance.Balance += point.Points
+
+			// the balance may have dropped since the cashout was requested
+			if point.Request.Type == models.PointRequestTypeCashout && balance.Balance < 0 {
+				return apiErr.WithError("child doesn't have enough points to cash out")
+			}
+		}
+
+		now := util.ToFormatted(time.Now())
+
+		point.Status = models.PointStatusSettled
+		point.Balance = &balance.Balance
+		point.Request.DecidedByUserID = req.UserID
+		point.Request.DecidedOnStr = now
+		point.Request.Decision = req.Decision
+		point.Request.ParentNotes = req.ParentNotes
+		point.UpdatedOnStr = now
+
+		if err := c.pointsDB.SavePoints(ctx, []models.Point{point}, []models.PointBalance{balance}); err != nil {
+			return fmt.Errorf("failed to save points: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return resp, err
+	}
+
+	point.ParseTimes()
+	resp.Point = point
+	resp.Summary = point.ToPointSummary()
+
+	c.events.Publish(ctx, eventbus.Event{
+		Type:   eventbus.EventTypePointsDecided,
+		UserID: req.ChildID,
+		Data:   resp.Summary,
+	})
+
+	return resp, nil
+}
+
+func validateDecidePoints(req *decidePointsHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.ChildID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if req.PointID == "" {
+		apierr.AppendError("missing point_id")
+	}
+
+	if req.Decision != models.PointRequestDecisionApprove && req.Decision != models.PointRequestDecisionDeny {
+		apierr.AppendErrorf("decision must be %s or %s", models.PointRequestDecisionApprove, models.PointRequestDecisionDeny)
+	}
+
+	if len(req.ParentNotes) > 200 {
+		apierr.AppendError("parent_notes must be at most 200 characters")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_DecidePointsHandler(t *testing.T) {
+	type state struct {
+		body string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{`{"decision":"APPROVE"}`}, want{"", 200}},
+		{"fail - invalid body", state{`{"decision":`}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{`{"decision":"MAYBE"}`}, want{"decision must be APPROVE or DENY", 400}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			if c.want.code == 200 {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c1").Return(models.User{UserID: "c1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "c1", "p1").Return(models.Point{ID: "p1", UserID: "c1", Points: 5, Status: models.PointStatusWaiting}, nil).Once()
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "c1", models.DefaultPointTypeID).Return(models.PointBalance{UserID: "c1", PointTypeID: models.DefaultPointTypeID}, nil).Once()
+				mockPointsDB.EXPECT().SavePoints(mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockEvents.EXPECT().Publish(mock.Anything, mock.Anything).Once()
+			}
+
+			ctrl := PointsController{
+				events:   mockEvents,
+				pointsDB: mockPointsDB,
+				userDB:   mockUserDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("user_id", "c1")
+			cgin.AddParam("point_id", "p1")
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.DecidePointsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleDecidePoints(t *testing.T) {
+	type state struct {
+		decision     models.PointRequestDecision
+		point        models.Point
+		stranger     bool
+		errUser      error
+		errGetPoint  error
+		errBalance   error
+		errSavePoint error
+	}
+	type want struct {
+		err     string
+		balance int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	requested := models.Point{ID: "p1", UserID: "c1", Points: 5, Status: models.PointStatusWaiting,
+		Request: models.PointRequest{Type: models.PointRequestTypeAdd, Reason: "Cleaned room"}}
+	changesRequested := requested
+	changesRequested.Status = models.PointStatusChangesRequested
+	cashout := models.Point{ID: "p1", UserID: "c1", Points: -20, Status: models.PointStatusWaiting,
+		Request: models.PointRequest{Type: models.PointRequestTypeCashout, Reason: "Ice cream"}}
+	settled := requested
+	settled.Status = models.PointStatusSettled
+
+	approve := models.PointRequestDecisionApprove
+	deny := models.PointRequestDecisionDeny
+
+	cases := []test{
+		{"happy path - approve", state{decision: approve, point: requested}, want{"", 15}},
+		{"happy path - approve after changes requested", state{decision: approve, point: changesRequested}, want{"", 15}},
+		{"happy path - deny keeps balance", state{decision: deny, point: requested}, want{"", 10}},
+		{"happy path - deny cashout without enough points", state{decision: deny, point: cashout}, want{"", 10}},
+		{"fail - approve cashout without enough points", state{decision: approve, point: cashout}, want{"child doesn't have enough points to cash out", 0}},
+		{"fail - already settled", state{decision: approve, point: settled}, want{"only requests waiting for a decision can be decided", 0}},
+		{"fail - not a parent of child", state{decision: approve, point: requested, stranger: true}, want{"user is not a parent of child (id=c1)", 0}},
+		{"fail - get user", state{decision: approve, errUser: errFail}, want{"failed to get user: fail", 0}},
+		{"fail - get point", state{decision: approve, errGetPoint: errFail}, want{"failed to get point: fail", 0}},
+		{"fail - get balance", state{decision: approve, point: requested, errBalance: errFail}, want{"failed to get balance of user c1: fail", 0}},
+		{"fail - save point", state{decision: approve, point: requested, errSavePoint: errFail}, want{"failed to save points: fail", 15}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			child := models.User{UserID: "c1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+			if c.state.stranger {
+				child.FamilyIDs = []string{"f2"}
+			}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}, c.state.errUser).Once()
+			if c.state.errUser == nil {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "c1").Return(child, nil).Once()
+			}
+
+			accessGranted := c.state.errUser == nil && !c.state.stranger
+			if accessGranted {
+				mockPointsDB.EXPECT().GetPointByID(mock.Anything, "c1", "p1").Return(c.state.point, c.state.errGetPoint).Once()
+			}
+
+			decidable := accessGranted && c.state.errGetPoint == nil && c.state.point.Status != models.PointStatusSettled
+			if decidable {
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "c1", models.DefaultPointTypeID).
+					Return(models.PointBalance{UserID: "c1", PointTypeID: models.DefaultPointTypeID, Balance: 10, Version: 3}, c.state.errBalance).Once()
+			}
+			if decidable && c.state.errBalance == nil && (c.want.err == "" || c.state.errSavePoint != nil) {
+				mockPointsDB.EXPECT().SavePoints(mock.Anything, mock.MatchedBy(func(points []models.Point) bool {
+					p := points[0]
+					return len(points) == 1 &&
+						p.Status == models.PointStatusSettled &&
+						*p.Balance == c.want.balance &&
+						p.Request.Decision == c.state.decision &&
+						p.Request.DecidedByUserID == "1" &&
+						p.Request.DecidedOnStr != "" &&
+						p.Request.ParentNotes == "Good job"
+				}), []models.PointBalance{{UserID: "c1", PointTypeID: models.DefaultPointTypeID, Balance: c.want.balance, Version: 3}}).
+					Return(c.state.errSavePoint).Once()
+			}
+			if c.want.err == "" {
+				mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+					summary, ok := evt.Data.(models.PointSummary)
+					return evt.Type == eventbus.EventTypePointsDecided &&
+						evt.UserID == "c1" &&
+						ok && summary.Decision == c.state.decision && summary.DecidedByUserID == "1"
+				})).Once()
+			}
+
+			ctrl := PointsController{
+				events:   mockEvents,
+				pointsDB: mockPointsDB,
+				userDB:   mockUserDB,
+			}
+
+			resp, err := ctrl.handleDecidePoints(context.Background(), &decidePointsHandlerRequest{
+				Decision:    c.state.decision,
+				ParentNotes: "Good job",
+				ChildID:     "c1",
+				PointID:     "p1",
+				UserID:      "1",
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, c.want.balance, *resp.Point.Balance)
+				assert.EqualValues(t, models.PointStatusSettled, resp.Point.Status)
+			}
+
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package points
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+const exportFormatCsv = "csv"
+const exportFormatJson = "json"
+
+// exported rows are flushed to the client in batches of this size
+const exportFlushSize = 100
+
+var exportCsvHeader = []string{"date", "type", "point_type", "status", "reason", "points", "balance", "decided_by", "parent_notes"}
+
+type exportUserPointsHandlerRequest struct {
+	Format       string
+	From         *time.Time
+	To           *time.Time
+	TargetUserID string
+	UserID       string
+}
+
+// ledgerEntry is a single row of the exported points ledger
+type ledgerEntry struct {
+	Date        time.Time               `json:"date"`
+	Type        models.PointRequestType `json:"type"`
+	PointType   string                  `json:"point_type"`
+	Status      models.PointStatus      `json:"status"`
+	Reason      string                  `json:"reason"`
+	Points      int                     `json:"points"`
+	Balance     *int                    `json:"balance"`
+	DecidedBy   string                  `json:"decided_by"`
+	ParentNotes string                  `json:"parent_notes"`
+}
+
+// ledgerWriter writes ledger entries in an export format
+type ledgerWriter interface {
+	Write(e ledgerEntry) error
+	Flush() error // Writes buffered entries to the underlying writer
+	Close() error
+}
+
+// ExportUserPointsHandler streams the full points ledger of a child (oldest first) as csv or json.
+// Optional from and to query parameters (dates or RFC3339 timestamps) limit the exported period.
+func (c *PointsController) ExportUserPointsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &exportUserPointsHandlerRequest{
+		Format:       cgin.DefaultQuery("format", exportFormatCsv),
+		TargetUserID: cgin.Param("user_id"),
+		UserID:       authInfo.GetUserID(),
+	}
+
+	ctx := cgin.Request.Context()
+
+	err := parseExportDates(req, cgin.Query("from"), cgin.Query("to"))
+	if err == nil {
+		err = validateExportUserPoints(req)
+	}
+	if err == nil {
+		err = c.checkPointsAccess(ctx, req.UserID, req.TargetUserID)
+	}
+
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	contentType := "text/csv"
+	if req.Format == exportFormatJson {
+		contentType = "application/json"
+	}
+
+	filename := fmt.Sprintf("points-%s-%s.%s", req.TargetUserID, time.Now().UTC().Format("20060102"), req.Format)
+	cgin.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	cgin.Header("Content-Type", contentType)
+	cgin.Status(http.StatusOK)
+
+	// the response has started, so errors can only be logged from here on
+	if err := c.handleExportUserPoints(ctx, req, cgin.Writer); err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":          err.Error(),
+			"target_user_id": req.TargetUserID,
+			"user_id":        req.UserID,
+		}).Errorf("failed to export points")
+	}
+}
+
+func (c *PointsController) handleExportUserPoints(ctx context.Context, req *exportUserPointsHandlerRequest, w io.Writer) error {
+	var lw ledgerWriter
+	if req.Format == exportFormatJson {
+		lw = newJsonLedgerWriter(w)
+	} else {
+		lw = newCsvLedgerWriter(w)
+	}
+
+	filter := models.QueryPointsFilter{Ascending: true}
+	if req.From != nil {
+		filter.UpdatedOn.WithFrom(*req.From)
+	}
+	if req.To != nil {
+		filter.UpdatedOn.WithTo(*req.To)
+	}
+
+	// parents that decided requests, so each parent is only looked up once
+	deciders := map[string]string{}
+	rows := 0
+
+	err := c.pointsDB.StreamPointsByUserID(ctx, req.TargetUserID, filter, func(p models.Point) error {
+		entry := ledgerEntry{
+			Date:        p.UpdatedOn,
+			Type:        p.Request.Type,
+			PointType:   p.TypeID(),
+			Status:      p.Status,
+			Reason:      p.Request.Reason,
+			Points:      p.Points,
+			DecidedBy:   c.deciderName(ctx, deciders, p.Request.DecidedByUserID),
+			ParentNotes: p.Request.ParentNotes,
+		}
+
+		// only settled points have a running balance, which is the balance of their type
+		if p.Status == models.PointStatusSettled {
+			entry.Balance = p.Balance
+		}
+
+		if err := lw.Write(entry); err != nil {
+			return fmt.Errorf("failed to write ledger entry: %w", err)
+		}
+
+		rows++
+		if rows%exportFlushSize == 0 {
+			if err := lw.Flush(); err != nil {
+				return fmt.Errorf("failed to flush ledger entries: %w", err)
+			}
+			flush(w)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to stream points: %w", err)
+	}
+
+	return lw.Close()
+}
+
+// deciderName returns the name of the parent that decided a request, or their user ID if
+// the parent can't be found
+func (c *PointsController) deciderName(ctx context.Context, cache map[string]string, userID string) string {
+	if userID == "" {
+		return ""
+	}
+
+	if name, ok := cache[userID]; ok {
+		return name
+	}
+
+	name := userID
+	if user, err := c.userDB.GetUserByID(ctx, userID); err == nil {
+		name = user.Name
+	}
+
+	cache[userID] = name
+	return name
+}
+
+func flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// parseExportDates parses the from and to query parameters. Plain dates are the start of the
+// day for from, and the end of the day for to.
+func parseExportDates(req *exportUserPointsHandlerRequest, from, to string) error {
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if from != "" {
+		t, err := parseQueryDate(from, false)
+		if err != nil {
+			apierr.AppendError("from must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		} else {
+			req.From = &t
+		}
+	}
+
+	if to != "" {
+		t, err := parseQueryDate(to, true)
+		if err != nil {
+			apierr.AppendError("to must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		} else {
+			req.To = &t
+		}
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
+
+// parseQueryDate parses a date (YYYY-MM-DD) or RFC3339 timestamp in UTC. Plain dates are the
+// end of the day if endOfDay is set.
+func parseQueryDate(val string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err := time.Parse(time.DateOnly, val)
+	if err != nil {
+		return t, err
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+func validateExportUserPoints(req *exportUserPointsHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.TargetUserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if req.Format != exportFormatCsv && req.Format != exportFormatJson {
+		apierr.AppendErrorf("format must be one of: %s, %s", exportFormatCsv, exportFormatJson)
+	}
+
+	if req.From != nil && req.To != nil && req.From.After(*req.To) {
+		apierr.AppendError("from must not be after to")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
+
+type csvLedgerWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCsvLedgerWriter(w io.Writer) *csvLedgerWriter {
+	return &csvLedgerWriter{w: csv.NewWriter(w)}
+}
+
+func (lw *csvLedgerWriter) Write(e ledgerEntry) error {
+	if !lw.headerWritten {
+		if err := lw.w.Write(exportCsvHeader); err != nil {
+			return err
+		}
+		lw.headerWritten = true
+	}
+
+	balance := ""
+	if e.Balance != nil {
+		balance = strconv.Itoa(*e.Balance)
+	}
+
+	return lw.w.Write([]string{
+		util.ToFormatted(e.Date),
+		string(e.Type),
+		e.PointType,
+		string(e.Status),
+		escapeCsvFormula(e.Reason),
+		strconv.Itoa(e.Points),
+		balance,
+		escapeCsvFormula(e.DecidedBy),
+		escapeCsvFormula(e.ParentNotes),
+	})
+}
+
+func (lw *csvLedgerWriter) Flush() error {
+	lw.w.Flush()
+	return lw.w.Error()
+}
+
+func (lw *csvLedgerWriter) Close() error {
+	if !lw.headerWritten {
+		if err := lw.w.Write(exportCsvHeader); err != nil {
+			return err
+		}
+	}
+
+	lw.w.Flush()
+	return lw.w.Error()
+}
+
+// escapeCsvFormula prefixes text that spreadsheets would run as a formula with a quote, so
+// children and parents can't inject formulas into an exported ledger
+func escapeCsvFormula(val string) string {
+	if val != "" && strings.ContainsRune("=+-@\t\r", rune(val[0])) {
+		return "'" + val
+	}
+	return val
+}
+
+// jsonLedgerWriter writes entries as a json array, one entry at a time
+type jsonLedgerWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	entries int
+}
+
+func newJsonLedgerWriter(w io.Writer) *jsonLedgerWriter {
+	return &jsonLedgerWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (lw *jsonLedgerWriter) Write(e ledgerEntry) error {
+	sep := ","
+	if lw.entries == 0 {
+		sep = "["
+	}
+
+	if _, err := io.WriteString(lw.w, sep); err != nil {
+		return err
+	}
+
+	lw.entries++
+	return lw.enc.Encode(e)
+}
+
+// Flush does nothing, entries are written to the underlying writer right away
+func (lw *jsonLedgerWriter) Flush() error {
+	return nil
+}
+
+func (lw *jsonLedgerWriter) Close() error {
+	if lw.entries == 0 {
+		_, err := io.WriteString(lw.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(lw.w, "]\n")
+	return err
+}
@@ -0,0 +1,214 @@
+package points
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_ExportUserPointsHandler(t *testing.T) {
+	type state struct {
+		query     string
+		parent    models.User
+		errStream error
+	}
+	type want struct {
+		err  string
+		code int
+		body string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	parent := models.User{UserID: "123", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+	stranger := models.User{UserID: "123", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleParent}}
+
+	csvBody := "date,type,point_type,status,reason,points,balance,decided_by,parent_notes\n" +
+		"2024-03-01T10:00:00Z,ADD,points,SETTLED,\"cleaned room, twice\",5,5,Jane,good job\n" +
+		"2024-03-02T10:00:00Z,CASHOUT,points,SETTLED,ice cream,-3,2,Jane,\n" +
+		"2024-03-03T10:00:00Z,ADD,st,WAITING,homework,2,,,\n"
+
+	cases := []test{
+		{"happy path - csv", state{query: "?from=2024-03-01&to=2024-03-03", parent: parent}, want{"", http.StatusOK, csvBody}},
+		{"happy path - json", state{query: "?format=json", parent: parent}, want{"", http.StatusOK, ""}},
+		{"happy path - stream fails after response started", state{parent: parent, errStream: errFail}, want{"", http.StatusOK, ""}},
+		{"fail - invalid format", state{query: "?format=xml", parent: parent}, want{"format must be one of: csv, json", http.StatusBadRequest, ""}},
+		{"fail - invalid date", state{query: "?from=yesterday", parent: parent}, want{"from must be a date (YYYY-MM-DD) or RFC3339 timestamp", http.StatusBadRequest, ""}},
+		{"fail - not a parent of child", state{parent: stranger}, want{"user is not a parent of child", http.StatusForbidden, ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			ctrl := PointsController{
+				pointsDB: pointsDB,
+				userDB:   userDB,
+			}
+
+			child := models.User{UserID: "a", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+			points := []models.Point{
+				{Status: "SETTLED", Points: 5, Balance: bal(5), UpdatedOn: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+					Request: models.PointRequest{Type: "ADD", Reason: "cleaned room, twice", DecidedByUserID: "123", ParentNotes: "good job"}},
+				{Status: "SETTLED", Points: -3, Balance: bal(2), UpdatedOn: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+					Request: models.PointRequest{Type: "CASHOUT", Reason: "ice cream", DecidedByUserID: "123"}},
+				{Status: "WAITING", PointTypeID: "st", Points: 2, Balance: bal(99), UpdatedOn: time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC),
+					Request: models.PointRequest{Type: "ADD", Reason: "homework"}},
+			}
+
+			validRequest := c.want.code != http.StatusBadRequest
+			if validRequest {
+				userDB.EXPECT().GetUserByID(mock.Anything, "123").Return(c.state.parent, nil).Once()
+				userDB.EXPECT().GetUserByID(mock.Anything, "a").Return(child, nil).Once()
+			}
+
+			if c.want.code == http.StatusOK {
+				pointsDB.EXPECT().StreamPointsByUserID(mock.Anything, "a", mock.MatchedBy(func(f models.QueryPointsFilter) bool {
+					return f.Ascending
+				}), mock.Anything).RunAndReturn(func(ctx context.Context, userID string, f models.QueryPointsFilter, fn func(models.Point) error) error {
+					if c.state.errStream != nil {
+						return c.state.errStream
+					}
+					for _, p := range points {
+						if err := fn(p); err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Once()
+
+				if c.state.errStream == nil {
+					// the decider is only looked up once
+					userDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{Name: "Jane"}, nil).Once()
+				}
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.AddParam("user_id", "a")
+			cgin.Request = httptest.NewRequest("GET", "/"+c.state.query, nil).WithContext(ctx)
+
+			ctrl.ExportUserPointsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+
+			if c.want.code != http.StatusOK {
+				result := tests.AssertResult(t, w.Body)
+				tests.AssertResultError(t, result, c.want.err)
+			} else if c.want.body != "" {
+				assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+				assert.Equal(t, c.want.body, w.Body.String())
+			} else if c.state.errStream == nil {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+				entries := []ledgerEntry{}
+				assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &entries))
+				assert.Len(t, entries, 3)
+				assert.Equal(t, "Jane", entries[0].DecidedBy)
+				assert.Nil(t, entries[2].Balance)
+			}
+
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_jsonLedgerWriter_empty(t *testing.T) {
+	w := httptest.NewRecorder()
+	lw := newJsonLedgerWriter(w)
+	assert.Nil(t, lw.Close())
+	assert.Equal(t, "[]\n", w.Body.String())
+}
+
+func Test_parseExportDates(t *testing.T) {
+	req := &exportUserPointsHandlerRequest{}
+
+	err := parseExportDates(req, "2024-03-01", "2024-03-03")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *req.From)
+	assert.Equal(t, time.Date(2024, 3, 3, 23, 59, 59, 999999999, time.UTC), *req.To)
+
+	err = parseExportDates(req, "2024-03-01T10:00:00+02:00", "")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), *req.From)
+
+	err = parseExportDates(req, "", "tomorrow")
+	tests.AssertError(t, err, "to must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+}
+
+func Test_validateExportUserPoints(t *testing.T) {
+	from := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	type test struct {
+		name string
+		req  exportUserPointsHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", exportUserPointsHandlerRequest{Format: "csv", TargetUserID: "a", UserID: "1"}, ""},
+		{"fail - missing user", exportUserPointsHandlerRequest{Format: "csv", TargetUserID: "a"}, "unauthorized: missing user ID"},
+		{"fail - missing target user", exportUserPointsHandlerRequest{Format: "csv", UserID: "1"}, "missing user_id"},
+		{"fail - from after to", exportUserPointsHandlerRequest{Format: "csv", TargetUserID: "a", UserID: "1", From: &from, To: &to}, "from must not be after to"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateExportUserPoints(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
+
+func Test_csvLedgerWriter(t *testing.T) {
+	type test struct {
+		name  string
+		entry ledgerEntry
+		want  string
+	}
+
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []test{
+		{"plain text", ledgerEntry{Date: date, Reason: "cleaned room", DecidedBy: "Jane", ParentNotes: "good job"},
+			"2024-03-01T10:00:00Z,,,,cleaned room,0,,Jane,good job\n"},
+		{"formulas are escaped", ledgerEntry{Date: date, Reason: "=HYPERLINK(\"http://x\")", DecidedBy: "@Jane", ParentNotes: "+1"},
+			"2024-03-01T10:00:00Z,,,,\"'=HYPERLINK(\"\"http://x\"\")\",0,,'@Jane,'+1\n"},
+		{"negative text is escaped", ledgerEntry{Date: date, Reason: "-5 for yelling"},
+			"2024-03-01T10:00:00Z,,,,'-5 for yelling,0,,,\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf strings.Builder
+			lw := newCsvLedgerWriter(&buf)
+
+			assert.Nil(t, lw.Write(c.entry))
+			assert.Empty(t, buf.String(), "entries are buffered until flushed")
+
+			assert.Nil(t, lw.Flush())
+			assert.Equal(t, "date,type,point_type,status,reason,points,balance,decided_by,parent_notes\n"+c.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getPointHandlerRequest struct {
+	PointID      string
+	TargetUserID string
+	UserID       string
+}
+
+type getPointHandlerResponse struct {
+	Point models.Point `json:"point"`
+}
+
+// GetPointHandler returns a child's point, with download URLs of its attachments
+func (c *PointsController) GetPointHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getPointHandlerRequest{
+		PointID:      cgin.Param("point_id"),
+		TargetUserID: cgin.Param("user_id"),
+		UserID:       authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetPoint(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleGetPoint(ctx context.Context, req *getPointHandlerRequest) (getPointHandlerResponse, error) {
+	resp := getPointHandlerResponse{}
+
+	if err := validatePointRef(req.UserID, req.TargetUserID, req.PointID); err != nil {
+		return resp, err
+	}
+
+	if err := c.checkPointsAccess(ctx, req.UserID, req.TargetUserID); err != nil {
+		return resp, err
+	}
+
+	point, err := c.pointsDB.GetPointByID(ctx, req.TargetUserID, req.PointID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get point: %w", err)
+	}
+
+	for idx, a := range point.Attachments {
+		url, err := c.attachments.PresignDownload(ctx, point.AttachmentKey(a.ID), attachment.URLExpiry)
+		if err != nil {
+			return resp, fmt.Errorf("failed to presign download: %w", err)
+		}
+		point.Attachments[idx].URL = url
+	}
+
+	point.ParseTimes()
+	resp.Point = point
+	return resp, nil
+}
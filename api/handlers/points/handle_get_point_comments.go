@@ -0,0 +1,95 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getPointCommentsHandlerRequest struct {
+	PointID      string
+	TargetUserID string
+	UserID       string
+}
+
+type getPointCommentsHandlerResponse struct {
+	Comments []models.PointComment `json:"comments"`
+}
+
+// GetPointCommentsHandler returns the comment thread of a child's point request, oldest first
+func (c *PointsController) GetPointCommentsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getPointCommentsHandlerRequest{
+		PointID:      cgin.Param("point_id"),
+		TargetUserID: cgin.Param("user_id"),
+		UserID:       authInfo.GetUserID(),
+	}
+
+	resp, err := c.handleGetPointComments(cgin.Request.Context(), req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleGetPointComments(ctx context.Context, req *getPointCommentsHandlerRequest) (getPointCommentsHandlerResponse, error) {
+	resp := getPointCommentsHandlerResponse{
+		Comments: []models.PointComment{},
+	}
+
+	if err := validatePointRef(req.UserID, req.TargetUserID, req.PointID); err != nil {
+		return resp, err
+	}
+
+	if err := c.checkPointsAccess(ctx, req.UserID, req.TargetUserID); err != nil {
+		return resp, err
+	}
+
+	if _, err := c.pointsDB.GetPointByID(ctx, req.TargetUserID, req.PointID); err != nil {
+		return resp, fmt.Errorf("failed to get point: %w", err)
+	}
+
+	comments, err := c.pointCommentDB.GetPointComments(ctx, req.TargetUserID, req.PointID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get point comments: %w", err)
+	}
+
+	resp.Comments = comments
+	return resp, nil
+}
+
+// validatePointRef validates the requesting user and the user and ID of the point they refer to
+func validatePointRef(userID, targetUserID, pointID string) error {
+	if userID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if targetUserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if pointID == "" {
+		apierr.AppendError("missing point_id")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
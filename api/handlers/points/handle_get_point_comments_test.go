@@ -0,0 +1,151 @@
+package points
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetPointCommentsHandler(t *testing.T) {
+	type state struct {
+		errPoint error
+		err      error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - point not found", state{errPoint: apierr.New(apierr.NotFound).WithError("point (id=p1)")}, want{"point (id=p1)", http.StatusNotFound}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get point comments: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			pointCommentDB := mocks.NewMockIPointCommentStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+
+			ctrl := PointsController{
+				pointCommentDB: pointCommentDB,
+				pointsDB:       pointsDB,
+			}
+
+			pointsDB.EXPECT().GetPointByID(mock.Anything, "123", "p1").Return(models.Point{ID: "p1", UserID: "123"}, c.state.errPoint).Once()
+			if c.state.errPoint == nil {
+				pointCommentDB.EXPECT().GetPointComments(mock.Anything, "123", "p1").Return([]models.PointComment{
+					{UserID: "123", ID: "1", PointID: "p1", AuthorUserID: "9", Text: "Send a photo first"},
+				}, c.state.err).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			// the user's own points don't need an access check
+			cgin.AddParam("user_id", "123")
+			cgin.AddParam("point_id", "p1")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetPointCommentsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				assert.Len(t, result.Data.(map[string]any)["comments"], 1)
+			}
+
+			pointCommentDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleGetPointComments(t *testing.T) {
+	type state struct {
+		userID string
+		parent models.User
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	parent := models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+	stranger := models.User{UserID: "1", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleParent}}
+
+	cases := []test{
+		{"happy path - parent", state{userID: "1", parent: parent}, want{}},
+		{"fail - missing user ID", state{}, want{"unauthorized: missing user ID"}},
+		{"fail - not a parent of child", state{userID: "1", parent: stranger}, want{"user is not a parent of child"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			pointCommentDB := mocks.NewMockIPointCommentStorage(t)
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			ctrl := PointsController{
+				pointCommentDB: pointCommentDB,
+				pointsDB:       pointsDB,
+				userDB:         userDB,
+			}
+
+			if c.state.userID != "" {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(c.state.parent, nil).Once()
+				userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(models.User{UserID: "2", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+			}
+			if c.want.err == "" {
+				pointsDB.EXPECT().GetPointByID(mock.Anything, "2", "p1").Return(models.Point{ID: "p1", UserID: "2"}, nil).Once()
+				pointCommentDB.EXPECT().GetPointComments(mock.Anything, "2", "p1").Return([]models.PointComment{}, nil).Once()
+			}
+
+			res, err := ctrl.handleGetPointComments(context.Background(), &getPointCommentsHandlerRequest{
+				PointID:      "p1",
+				TargetUserID: "2",
+				UserID:       c.state.userID,
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.NotNil(t, res.Comments)
+			}
+
+			pointCommentDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validatePointRef(t *testing.T) {
+	tests.AssertError(t, validatePointRef("1", "2", "p1"), "")
+	tests.AssertError(t, validatePointRef("", "2", "p1"), "unauthorized: missing user ID")
+	tests.AssertError(t, validatePointRef("1", "", "p1"), "missing user_id")
+	tests.AssertError(t, validatePointRef("1", "2", ""), "missing point_id")
+}
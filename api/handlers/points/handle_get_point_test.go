@@ -0,0 +1,158 @@
+package points
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	attmocks "github.com/sebboness/yektaspoints/mocks/attachment"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetPointHandler(t *testing.T) {
+	type state struct {
+		errPoint error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", http.StatusOK}},
+		{"fail - point not found", state{errPoint: apierr.New(apierr.NotFound).WithError("point (id=p1)")}, want{"point (id=p1)", http.StatusNotFound}},
+		{"fail - internal server error", state{errPoint: errFail}, want{"failed to get point: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			attachments := attmocks.NewMockStore(t)
+
+			ctrl := PointsController{
+				attachments: attachments,
+				pointsDB:    pointsDB,
+			}
+
+			pointsDB.EXPECT().GetPointByID(mock.Anything, "123", "p1").Return(models.Point{
+				ID:          "p1",
+				UserID:      "123",
+				Attachments: []models.Attachment{{ID: "a1", ContentType: "image/png"}},
+			}, c.state.errPoint).Once()
+			if c.state.errPoint == nil {
+				attachments.EXPECT().PresignDownload(mock.Anything, "123/p1/a1", attachment.URLExpiry).Return("https://download", nil).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			// the user's own points don't need an access check
+			cgin.AddParam("user_id", "123")
+			cgin.AddParam("point_id", "p1")
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetPointHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				point := result.Data.(map[string]any)["point"].(map[string]any)
+				assert.Equal(t, "https://download", point["attachments"].([]any)[0].(map[string]any)["url"])
+			}
+
+			pointsDB.AssertExpectations(t)
+			attachments.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleGetPoint(t *testing.T) {
+	type state struct {
+		userID     string
+		parent     models.User
+		errPresign error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	parent := models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+	stranger := models.User{UserID: "1", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleParent}}
+
+	cases := []test{
+		{"happy path - parent", state{userID: "1", parent: parent}, want{}},
+		{"fail - missing user ID", state{}, want{"unauthorized: missing user ID"}},
+		{"fail - not a parent of child", state{userID: "1", parent: stranger}, want{"user is not a parent of child"}},
+		{"fail - presign download", state{userID: "1", parent: parent, errPresign: errFail}, want{"failed to presign download: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+			attachments := attmocks.NewMockStore(t)
+
+			ctrl := PointsController{
+				attachments: attachments,
+				pointsDB:    pointsDB,
+				userDB:      userDB,
+			}
+
+			if c.state.userID != "" {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(c.state.parent, nil).Once()
+				userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(models.User{UserID: "2", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+			}
+			if c.state.parent.FamilyIDs != nil && c.state.parent.FamilyIDs[0] == "f1" {
+				pointsDB.EXPECT().GetPointByID(mock.Anything, "2", "p1").Return(models.Point{
+					ID:          "p1",
+					UserID:      "2",
+					Attachments: []models.Attachment{{ID: "a1"}, {ID: "a2"}},
+				}, nil).Once()
+				attachments.EXPECT().PresignDownload(mock.Anything, "2/p1/a1", attachment.URLExpiry).Return("https://a1", c.state.errPresign).Once()
+				if c.state.errPresign == nil {
+					attachments.EXPECT().PresignDownload(mock.Anything, "2/p1/a2", attachment.URLExpiry).Return("https://a2", nil).Once()
+				}
+			}
+
+			res, err := ctrl.handleGetPoint(context.Background(), &getPointHandlerRequest{
+				PointID:      "p1",
+				TargetUserID: "2",
+				UserID:       c.state.userID,
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, "https://a1", res.Point.Attachments[0].URL)
+				assert.Equal(t, "https://a2", res.Point.Attachments[1].URL)
+			}
+
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+			attachments.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+// analytics cover the last 30 days if no period is requested
+const analyticsDefaultDays = 30
+
+// upper bound of buckets in a response, e.g. a bit over a year of daily buckets
+const analyticsMaxBuckets = 400
+
+type getPointsAnalyticsHandlerRequest struct {
+	Bucket       models.AnalyticsBucket
+	From         time.Time
+	PointTypeID  string
+	To           time.Time
+	TargetUserID string
+	UserID       string
+}
+
+type getPointsAnalyticsHandlerResponse struct {
+	models.PointsAnalytics
+}
+
+// GetPointsAnalyticsHandler returns a child's points aggregated by day, week or month and by
+// reason, plus the approval rate and average decision latency of their requests. Only points of
+// a single type (the default type, unless point_type_id is given) are analyzed.
+func (c *PointsController) GetPointsAnalyticsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req := &getPointsAnalyticsHandlerRequest{
+		Bucket:       models.AnalyticsBucket(cgin.DefaultQuery("bucket", string(models.AnalyticsBucketDay))),
+		PointTypeID:  cgin.DefaultQuery("point_type_id", models.DefaultPointTypeID),
+		TargetUserID: cgin.Param("user_id"),
+		UserID:       authInfo.GetUserID(),
+	}
+
+	resp := getPointsAnalyticsHandlerResponse{}
+	err := parseAnalyticsDates(req, cgin.Query("from"), cgin.Query("to"), time.Now().UTC())
+	if err == nil {
+		resp, err = c.handleGetPointsAnalytics(cgin.Request.Context(), req)
+	}
+
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleGetPointsAnalytics(ctx context.Context, req *getPointsAnalyticsHandlerRequest) (getPointsAnalyticsHandlerResponse, error) {
+	resp := getPointsAnalyticsHandlerResponse{}
+
+	if err := validateGetPointsAnalytics(req); err != nil {
+		return resp, err
+	}
+
+	if err := c.checkPointsAccess(ctx, req.UserID, req.TargetUserID); err != nil {
+		return resp, err
+	}
+
+	filter := models.QueryPointsFilter{
+		UpdatedOn: *models.NewDateFilter().WithRange(req.From, req.To),
+		Attributes: []string{
+			"id",
+			"created_on",
+			"updated_on",
+			"points",
+			"point_type_id",
+			"status",
+			"request.decided_on",
+			"request.decision",
+			"request.reason",
+			"request.type",
+		},
+	}
+
+	points, err := c.pointsDB.GetPointsByUserID(ctx, req.TargetUserID, filter)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	resp.PointsAnalytics.Analyze(req.From, req.To, req.Bucket, models.PointsOfType(points, req.PointTypeID))
+	return resp, nil
+}
+
+// parseAnalyticsDates parses the from and to query parameters. To defaults to now, and from to
+// 30 days before to.
+func parseAnalyticsDates(req *getPointsAnalyticsHandlerRequest, from, to string, now time.Time) error {
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	req.To = now
+	if to != "" {
+		t, err := parseQueryDate(to, true)
+		if err != nil {
+			apierr.AppendError("to must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		} else {
+			req.To = t
+		}
+	}
+
+	req.From = req.To.AddDate(0, 0, -analyticsDefaultDays)
+	if from != "" {
+		t, err := parseQueryDate(from, false)
+		if err != nil {
+			apierr.AppendError("from must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		} else {
+			req.From = t
+		}
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
+
+func validateGetPointsAnalytics(req *getPointsAnalyticsHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.TargetUserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if !req.Bucket.IsValid() {
+		apierr.AppendErrorf("bucket must be one of: %s, %s, %s", models.AnalyticsBucketDay, models.AnalyticsBucketWeek, models.AnalyticsBucketMonth)
+	}
+
+	if req.From.After(req.To) {
+		apierr.AppendError("from must not be after to")
+	} else if req.Bucket.IsValid() && req.Bucket.Exceeds(req.From, req.To, analyticsMaxBuckets) {
+		apierr.AppendErrorf("period must not have more than %d buckets of a %s", analyticsMaxBuckets, req.Bucket)
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package points
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetPointsAnalyticsHandler(t *testing.T) {
+	type state struct {
+		query string
+		err   error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{query: "?from=2024-03-01&to=2024-03-31&bucket=week"}, want{"", http.StatusOK}},
+		{"happy path - defaults", state{}, want{"", http.StatusOK}},
+		{"fail - invalid date", state{query: "?to=now"}, want{"to must be a date (YYYY-MM-DD) or RFC3339 timestamp", http.StatusBadRequest}},
+		{"fail - invalid bucket", state{query: "?bucket=year"}, want{"bucket must be one of: day, week, month", http.StatusBadRequest}},
+		{"fail - internal server error", state{err: errFail}, want{"failed to get points: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			pointsDB := mocks.NewMockIPointsStorage(t)
+
+			ctrl := PointsController{
+				pointsDB: pointsDB,
+			}
+
+			points := []models.Point{
+				{ID: "1", UserID: "123", Status: models.PointStatusSettled, Points: 1, UpdatedOn: time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)},
+				{ID: "2", UserID: "123", Status: models.PointStatusSettled, Points: 1, UpdatedOn: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+			}
+
+			if c.want.code != http.StatusBadRequest {
+				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "123", mock.Anything).Return(points, c.state.err).Once()
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			// the user's own analytics don't need an access check
+			cgin.AddParam("user_id", "123")
+			cgin.Request = httptest.NewRequest("GET", "/"+c.state.query, nil).WithContext(ctx)
+
+			ctrl.GetPointsAnalyticsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == http.StatusOK {
+				assert.NotNil(t, result.Data)
+			}
+
+			pointsDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleGetPointsAnalytics(t *testing.T) {
+	type state struct {
+		userID  string
+		parent  models.User
+		errUser error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	parent := models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+	stranger := models.User{UserID: "1", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleParent}}
+
+	cases := []test{
+		{"happy path", state{userID: "1", parent: parent}, want{}},
+		{"fail - missing user ID", state{}, want{"unauthorized: missing user ID"}},
+		{"fail - not a parent of child", state{userID: "1", parent: stranger}, want{"user is not a parent of child"}},
+		{"fail - get user", state{userID: "1", errUser: errFail}, want{"failed to get user: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			ctrl := PointsController{
+				pointsDB: pointsDB,
+				userDB:   userDB,
+			}
+
+			from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			to := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+
+			if c.state.userID != "" {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(c.state.parent, c.state.errUser).Once()
+				if c.state.errUser == nil {
+					userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(models.User{UserID: "2", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, nil).Once()
+				}
+			}
+
+			if c.want.err == "" {
+				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, "2", mock.MatchedBy(func(f models.QueryPointsFilter) bool {
+					return f.UpdatedOn.From.Equal(from) && f.UpdatedOn.To.Equal(to)
+				})).Return([]models.Point{
+					{Status: models.PointStatusSettled, Points: 3, UpdatedOn: from.AddDate(0, 0, 10), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+					{Status: models.PointStatusSettled, PointTypeID: "st", Points: 30, UpdatedOn: from.AddDate(0, 0, 10), Request: models.PointRequest{Type: models.PointRequestTypeAdd}},
+				}, nil).Once()
+			}
+
+			req := &getPointsAnalyticsHandlerRequest{
+				Bucket:       models.AnalyticsBucketMonth,
+				From:         from,
+				PointTypeID:  models.DefaultPointTypeID,
+				To:           to,
+				TargetUserID: "2",
+				UserID:       c.state.userID,
+			}
+
+			res, err := ctrl.handleGetPointsAnalytics(ctx, req)
+
+			tests.AssertError(t, err, c.want.err)
+			if c.want.err == "" {
+				assert.Len(t, res.Buckets, 1)
+				assert.Equal(t, 3, res.Totals.Earned)
+			}
+
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_parseAnalyticsDates(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	req := &getPointsAnalyticsHandlerRequest{}
+	err := parseAnalyticsDates(req, "", "", now)
+	assert.Nil(t, err)
+	assert.Equal(t, now, req.To)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), req.From)
+
+	req = &getPointsAnalyticsHandlerRequest{}
+	err = parseAnalyticsDates(req, "2024-01-01", "2024-01-31", now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), req.From)
+	assert.Equal(t, time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC), req.To)
+
+	err = parseAnalyticsDates(req, "last week", "", now)
+	tests.AssertError(t, err, "from must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+}
+
+func Test_validateGetPointsAnalytics(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	type test struct {
+		name string
+		req  getPointsAnalyticsHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", getPointsAnalyticsHandlerRequest{Bucket: "day", From: from, To: from.AddDate(1, 0, 0), TargetUserID: "a", UserID: "1"}, ""},
+		{"fail - missing target user", getPointsAnalyticsHandlerRequest{Bucket: "day", From: from, To: from, UserID: "1"}, "missing user_id"},
+		{"fail - from after to", getPointsAnalyticsHandlerRequest{Bucket: "day", From: from, To: from.AddDate(0, 0, -1), TargetUserID: "a", UserID: "1"}, "from must not be after to"},
+		{"fail - too many buckets", getPointsAnalyticsHandlerRequest{Bucket: "day", From: from, To: from.AddDate(2, 0, 0), TargetUserID: "a", UserID: "1"}, "period must not have more than 400 buckets of a day"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateGetPointsAnalytics(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
@@ -19,7 +19,9 @@ type getPointsSummaryHandlerRequest struct {
 }
 
 type getPointsSummaryHandlerResponse struct {
-	models.UserPoints
+	models.UserPoints // Points of the default type, for clients that only know a single type
+
+	Types []models.PointTypeSummary `json:"types"`
 }
 
 func (c *PointsController) GetPointsSummaryHandler(cgin *gin.Context) {
@@ -70,6 +72,7 @@ func (c *PointsController) handleGetPointsSummary(ctx context.Context, req *getP
 		"updated_on",
 		"points",
 		"balance",
+		"point_type_id",
 		"status",
 		"request.decided_by_user_id",
 		"request.decision",
@@ -98,9 +101,20 @@ func (c *PointsController) handleGetPointsSummary(ctx context.Context, req *getP
 		return resp, fmt.Errorf("failed to get points: %w", err)
 	}
 
-	// map all points to user point summaries
+	user, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	pointTypes, err := c.getPointTypes(ctx, user)
+	if err != nil {
+		return resp, err
+	}
+
+	// map all points to user point summaries of each type
 	// the weekAgo date will summarize point amounts from last 7 days.
-	c.mapPointsToSummaries(&resp.UserPoints, weekAgo, points)
+	resp.Types = models.SummarizeByType(weekAgo, pointTypes, points)
+	resp.UserPoints = resp.Types[0].UserPoints
 
 	logger := log.Get()
 	logger.WithContext(ctx).WithFields(map[string]any{
@@ -114,47 +128,3 @@ func (c *PointsController) handleGetPointsSummary(ctx context.Context, req *getP
 
 	return resp, nil
 }
-
-func (c *PointsController) mapPointsToSummaries(up *models.UserPoints, recentFromDate time.Time, points []models.Point) {
-
-	unsettled := []models.PointSummary{}
-	settled := []models.PointSummary{}
-	cashouts := []models.PointSummary{}
-
-	// user's points is the balance value in the most recent settled point object
-	for _, p := range points {
-		// first settled point is latest
-		if up.Balance == 0 && p.Status == models.PointStatusSettled && p.Balance != nil {
-			up.Balance = *p.Balance
-		}
-
-		// sum up points after given recentFromDate
-		if p.UpdatedOn.Compare(recentFromDate) >= 0 &&
-			p.Status == models.PointStatusSettled &&
-			p.Request.Type != models.PointRequestTypeCashout {
-
-			up.PointsLast7Days += p.Points
-
-			if p.Points < 0 {
-				up.PointsLostLast7Days += p.Points
-			}
-		}
-
-		// unsettled points
-		if len(unsettled) < 3 && p.Status == models.PointStatusWaiting {
-			unsettled = append(unsettled, p.ToPointSummary())
-		}
-		// settled points
-		if len(settled) < 3 && p.Status == models.PointStatusSettled && p.Request.Type != models.PointRequestTypeCashout {
-			settled = append(settled, p.ToPointSummary())
-		}
-		// cashouts
-		if len(cashouts) < 3 && p.Status == models.PointStatusSettled && p.Request.Type == models.PointRequestTypeCashout {
-			cashouts = append(cashouts, p.ToPointSummary())
-		}
-	}
-
-	up.RecentRequests = unsettled
-	up.RecentPoints = settled
-	up.RecentCashouts = cashouts
-}
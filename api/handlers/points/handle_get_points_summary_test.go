@@ -40,10 +40,14 @@ func Test_Controller_GetPointsSummaryHandler(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
 			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
 
 			ctrl := PointsController{
-				pointsDB: pointsDB,
+				pointTypeDB: pointTypeDB,
+				pointsDB:    pointsDB,
+				userDB:      userDB,
 			}
 
 			points := []models.Point{
@@ -55,6 +59,9 @@ func Test_Controller_GetPointsSummaryHandler(t *testing.T) {
 			if !c.state.missingUser {
 				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, mock.Anything, mock.Anything).Return(points, c.state.err).Once()
 			}
+			if !c.state.missingUser && c.state.err == nil {
+				userDB.EXPECT().GetUserByID(mock.Anything, "a").Return(models.User{UserID: "a"}, nil).Once()
+			}
 
 			ctx := context.Background()
 
@@ -83,17 +90,22 @@ func Test_Controller_GetPointsSummaryHandler(t *testing.T) {
 
 			if c.want.code == 200 {
 				assert.NotNil(t, result.Data)
+				assert.Len(t, result.Data.(map[string]any)["types"], 1)
 			}
 
+			pointTypeDB.AssertExpectations(t)
 			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
 		})
 	}
 }
 
 func Test_Controller_handleGetPointsSummary(t *testing.T) {
 	type state struct {
-		missingUser  bool
-		getPointsErr error
+		missingUser      bool
+		getPointsErr     error
+		getUserErr       error
+		getPointTypesErr error
 	}
 	type want struct {
 		err string
@@ -108,16 +120,22 @@ func Test_Controller_handleGetPointsSummary(t *testing.T) {
 		{"happy path", state{}, want{}},
 		{"fail - missing user ID", state{missingUser: true}, want{"missing user id"}},
 		{"fail - get points error", state{getPointsErr: errFail}, want{"failed to get points"}},
+		{"fail - get user error", state{getUserErr: errFail}, want{"failed to get user: fail"}},
+		{"fail - get point types error", state{getPointTypesErr: errFail}, want{"failed to get point types: fail"}},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 
 			ctx := context.Background()
+			pointTypeDB := mocks.NewMockIPointTypeStorage(t)
 			pointsDB := mocks.NewMockIPointsStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
 
 			ctrl := PointsController{
-				pointsDB: pointsDB,
+				pointTypeDB: pointTypeDB,
+				pointsDB:    pointsDB,
+				userDB:      userDB,
 			}
 
 			if !c.state.missingUser {
@@ -144,8 +162,26 @@ func Test_Controller_handleGetPointsSummary(t *testing.T) {
 					}
 				}
 
+				// points of another type have their own balance
+				points = append([]models.Point{{
+					Status:      "SETTLED",
+					PointTypeID: "st",
+					Points:      30,
+					Balance:     bal(90),
+					UpdatedOn:   now,
+					Request:     models.PointRequest{Type: "ADD"},
+				}}, points...)
+
 				pointsDB.EXPECT().GetPointsByUserID(mock.Anything, mock.Anything, mock.Anything).Return(points, c.state.getPointsErr).Once()
 			}
+			if !c.state.missingUser && c.state.getPointsErr == nil {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", FamilyIDs: []string{"456"}}, c.state.getUserErr).Once()
+			}
+			if !c.state.missingUser && c.state.getPointsErr == nil && c.state.getUserErr == nil {
+				pointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{
+					{FamilyID: "456", ID: "st", Name: "Screen time"},
+				}, c.state.getPointTypesErr).Once()
+			}
 
 			req := &getPointsSummaryHandlerRequest{
 				UserID: "1",
@@ -162,70 +198,17 @@ func Test_Controller_handleGetPointsSummary(t *testing.T) {
 				assert.Equal(t, 20, res.Balance)
 				assert.GreaterOrEqual(t, 8, res.PointsLast7Days)
 				assert.Equal(t, 0, res.PointsLostLast7Days)
-			}
-
-			pointsDB.AssertExpectations(t)
-		})
-	}
-}
-
-func Test_Controller_mapPointsToSummaries(t *testing.T) {
-	type test struct {
-		name string
-	}
-
-	cases := []test{
-		{"happy path"},
-	}
 
-	for _, c := range cases {
-		t.Run(c.name, func(t *testing.T) {
-
-			ctrl := PointsController{}
-
-			bal := func(v int) *int {
-				return &v
+				assert.Len(t, res.Types, 2)
+				assert.Equal(t, models.DefaultPointTypeID, res.Types[0].PointType.ID)
+				assert.Equal(t, 20, res.Types[0].Balance)
+				assert.Equal(t, "st", res.Types[1].PointType.ID)
+				assert.Equal(t, 90, res.Types[1].Balance)
 			}
 
-			// setup mock points
-			now := time.Now()
-			from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -7)
-
-			p0 := models.Point{ID: "0", Status: "SETTLED", Points: 1, Balance: bal(18), UpdatedOn: now.AddDate(0, 0, 0), Request: models.PointRequest{Type: "ADD"}}
-			p1 := models.Point{ID: "1", Status: "SETTLED", Points: 1, Balance: bal(17), UpdatedOn: now.AddDate(0, 0, -1), Request: models.PointRequest{Type: "ADD"}}
-			p2 := models.Point{ID: "2", Status: "SETTLED", Points: -1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -2), Request: models.PointRequest{Type: "SUBTRACT"}}
-			p3 := models.Point{ID: "3", Status: "SETTLED", Points: 1, Balance: bal(17), UpdatedOn: now.AddDate(0, 0, -3), Request: models.PointRequest{Type: "ADD"}}
-			p4 := models.Point{ID: "4", Status: "SETTLED", Points: 1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -4), Request: models.PointRequest{Type: "ADD"}}
-			p5 := models.Point{ID: "5", Status: "SETTLED", Points: -1, Balance: bal(15), UpdatedOn: now.AddDate(0, 0, -5), Request: models.PointRequest{Type: "CASHOUT"}}
-			p6 := models.Point{ID: "6", Status: "SETTLED", Points: 1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -6), Request: models.PointRequest{Type: "ADD"}}
-			p7 := models.Point{ID: "7", Status: "WAITING", Points: 1, UpdatedOn: now.AddDate(0, 0, -7), Request: models.PointRequest{Type: "ADD"}}
-			p8 := models.Point{ID: "8", Status: "SETTLED", Points: -1, Balance: bal(15), UpdatedOn: now.AddDate(0, 0, -8), Request: models.PointRequest{Type: "SUBTRACT"}}
-			p9 := models.Point{ID: "9", Status: "SETTLED", Points: -1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -9), Request: models.PointRequest{Type: "CASHOUT"}}
-
-			points := []models.Point{p0, p1, p2, p3, p4, p5, p6, p7, p8, p9}
-
-			up := &models.UserPoints{}
-			ctrl.mapPointsToSummaries(up, from, points)
-
-			assert.Equal(t, 18, up.Balance)
-			assert.Equal(t, 4, up.PointsLast7Days)
-			assert.Equal(t, -1, up.PointsLostLast7Days)
-
-			assert.Len(t, up.RecentPoints, 3)
-			assert.Len(t, up.RecentRequests, 1)
-			assert.Len(t, up.RecentCashouts, 2)
-
-			// assert recent points
-			assert.Equal(t, "0", up.RecentPoints[0].ID)
-			assert.Equal(t, "1", up.RecentPoints[1].ID)
-			assert.Equal(t, "2", up.RecentPoints[2].ID)
-
-			// assert open requests
-			assert.Equal(t, "7", up.RecentRequests[0].ID)
-
-			// assert cashouts
-			assert.Equal(t, "5", up.RecentCashouts[0].ID)
-			assert.Equal(t, "9", up.RecentCashouts[1].ID)
+			pointTypeDB.AssertExpectations(t)
+			pointsDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
 		})
 	}
 }
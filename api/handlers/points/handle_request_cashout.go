@@ -0,0 +1,185 @@
+package points
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/segmentio/ksuid"
+)
+
+type cashoutHandlerRequest struct {
+	Points      int    `json:"points"`
+	PointTypeID string `json:"point_type_id"` // Optional, points are of the default type if not set
+	Reason      string `json:"reason"`
+	UserID      string `json:"-"`
+}
+
+// RequestCashoutHandler requests to cash out points of a type by the type's cashout rule.
+// Parents decide on cashouts like on any other request.
+func (c *PointsController) RequestCashoutHandler(cgin *gin.Context) {
+
+	var req cashoutHandlerRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleRequestCashout(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *PointsController) handleRequestCashout(ctx context.Context, req *cashoutHandlerRequest) (pointsHandlerResponse, error) {
+	resp := pointsHandlerResponse{}
+
+	if err := validateRequestCashout(req); err != nil {
+		return resp, err
+	}
+
+	user, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	pointTypes, err := c.getPointTypes(ctx, user)
+	if err != nil {
+		return resp, err
+	}
+
+	typeID := models.Point{PointTypeID: req.PointTypeID}.TypeID()
+	pointType, ok := models.FindPointType(pointTypes, typeID)
+	if !ok {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("unsupported point type '%s'", typeID))
+	}
+
+	value, err := pointType.CashoutValue(req.Points)
+	if err != nil {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError(err.Error())
+	}
+
+	available, err := c.availablePoints(ctx, req.UserID, typeID)
+	if err != nil {
+		return resp, err
+	}
+
+	if req.Points > available {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("not enough %s points to cash out (available=%d)", pointType.Name, available))
+	}
+
+	now := util.ToFormatted(time.Now())
+
+	point := models.Point{
+		ID:          ksuid.New().String(),
+		UserID:      req.UserID,
+		Points:      -req.Points,
+		PointTypeID: req.PointTypeID,
+		Status:      models.PointStatusWaiting,
+		Request: models.PointRequest{
+			CashoutValue: value,
+			Reason:       req.Reason,
+			Type:         models.PointRequestTypeCashout,
+		},
+		CreatedOnStr: now,
+		UpdatedOnStr: now,
+	}
+
+	err = c.pointsDB.SavePoint(ctx, point)
+	if err != nil {
+		return resp, fmt.Errorf("failed to save points: %w", err)
+	}
+
+	point.ParseTimes()
+	resp.Point = point
+	resp.Summary = point.ToPointSummary()
+
+	c.events.Publish(ctx, eventbus.Event{
+		Type:   eventbus.EventTypePointsRequested,
+		UserID: req.UserID,
+		Data:   resp.Summary,
+	})
+
+	return resp, nil
+}
+
+// availablePoints returns the balance of the user's points of the type, less the points of cashouts
+// that are still waiting for a decision or being amended
+func (c *PointsController) availablePoints(ctx context.Context, userID, typeID string) (int, error) {
+	balance, err := c.pointBalance(ctx, userID, typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := c.pointsDB.GetPointsByUserID(ctx, userID, models.QueryPointsFilter{
+		Statuses: []models.PointStatus{
+			models.PointStatusChangesRequested,
+			models.PointStatusWaiting,
+		},
+		Types: []models.PointRequestType{
+			models.PointRequestTypeCashout,
+		},
+		Attributes: []string{
+			"id",
+			"updated_on",
+			"points",
+			"point_type_id",
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	available := balance.Balance
+	for _, p := range models.PointsOfType(pending, typeID) {
+		available += p.Points
+	}
+
+	return available, nil
+}
+
+func validateRequestCashout(req *cashoutHandlerRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.Points <= 0 {
+		apierr.AppendError("points must be a positive integer")
+	}
+
+	if len(req.Reason) > 200 {
+		apierr.AppendError("reason must not be longer than 200 characters")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
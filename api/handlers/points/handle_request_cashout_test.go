@@ -0,0 +1,206 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_RequestCashoutHandler(t *testing.T) {
+	type state struct {
+		body string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{`{"points":3,"reason":"Ice cream"}`}, want{"", 200}},
+		{"fail - invalid body", state{`{"points":`}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{`{"points":0}`}, want{"points must be a positive integer", 400}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointTypeDB := mocks.NewMockIPointTypeStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			if c.want.code == 200 {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123"}, nil).Once()
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "123", models.DefaultPointTypeID).Return(models.PointBalance{Balance: 5}, nil).Once()
+				mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "123", mock.Anything).Return([]models.Point{}, nil).Once()
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.Anything).Return(nil).Once()
+				mockEvents.EXPECT().Publish(mock.Anything, mock.Anything).Once()
+			}
+
+			ctrl := PointsController{
+				events:      mockEvents,
+				pointTypeDB: mockPointTypeDB,
+				pointsDB:    mockPointsDB,
+				userDB:      mockUserDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("POST", "/points/cashout", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.RequestCashoutHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockPointTypeDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleRequestCashout(t *testing.T) {
+	type state struct {
+		points        int
+		pointTypeID   string
+		errPointTypes error
+		errBalance    error
+		errPoints     error
+		errSavePoint  error
+	}
+	type want struct {
+		err   string
+		value int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - default type", state{points: 4}, want{"", 4}},
+		{"happy path - by cashout rule", state{points: 20, pointTypeID: "st"}, want{"", 60}},
+		{"fail - unsupported point type", state{points: 20, pointTypeID: "nope"}, want{"unsupported point type 'nope'", 0}},
+		{"fail - not a multiple", state{points: 15, pointTypeID: "st"}, want{"Screen time points must be cashed out in multiples of 10", 0}},
+		{"fail - not enough points", state{points: 5}, want{"not enough Points points to cash out (available=4)", 0}},
+		{"fail - waiting cashouts aren't available", state{points: 40, pointTypeID: "st"}, want{"not enough Screen time points to cash out (available=30)", 0}},
+		{"fail - get point types", state{points: 4, errPointTypes: errFail}, want{"failed to get point types: fail", 0}},
+		{"fail - get balance", state{points: 4, errBalance: errFail}, want{"failed to get balance of user 123: fail", 0}},
+		{"fail - get points", state{points: 4, errPoints: errFail}, want{"failed to get points: fail", 0}},
+		{"fail - save points", state{points: 4, errSavePoint: errFail}, want{"failed to save points: fail", 4}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockPointTypeDB := mocks.NewMockIPointTypeStorage(t)
+			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123", FamilyIDs: []string{"456"}}, nil).Once()
+			mockPointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{
+				{FamilyID: "456", ID: "st", Name: "Screen time", Cashout: &models.CashoutRule{Points: 10, Value: 30}},
+			}, c.state.errPointTypes).Once()
+
+			balances := map[string]int{models.DefaultPointTypeID: 4, "st": 50}
+
+			// cashouts waiting for a decision or being amended, of all types
+			pending := []models.Point{
+				{Status: models.PointStatusWaiting, PointTypeID: "st", Points: -10, Request: models.PointRequest{Type: models.PointRequestTypeCashout}},
+				{Status: models.PointStatusChangesRequested, PointTypeID: "st", Points: -10, Request: models.PointRequest{Type: models.PointRequestTypeCashout}},
+			}
+
+			typeID := models.Point{PointTypeID: c.state.pointTypeID}.TypeID()
+
+			validRequest := c.state.errPointTypes == nil && c.state.pointTypeID != "nope" && c.state.points != 15
+			if validRequest {
+				mockPointsDB.EXPECT().GetPointBalance(mock.Anything, "123", typeID).
+					Return(models.PointBalance{UserID: "123", PointTypeID: typeID, Balance: balances[typeID]}, c.state.errBalance).Once()
+			}
+			if validRequest && c.state.errBalance == nil {
+				mockPointsDB.EXPECT().GetPointsByUserID(mock.Anything, "123", mock.MatchedBy(func(f models.QueryPointsFilter) bool {
+					return len(f.Statuses) == 2 && len(f.Types) == 1 && f.Types[0] == models.PointRequestTypeCashout
+				})).Return(pending, c.state.errPoints).Once()
+			}
+			if c.want.value > 0 {
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.MatchedBy(func(p models.Point) bool {
+					return p.Points == -c.state.points && p.PointTypeID == c.state.pointTypeID &&
+						p.Status == models.PointStatusWaiting && p.Request.Type == models.PointRequestTypeCashout &&
+						p.Request.CashoutValue == c.want.value
+				})).Return(c.state.errSavePoint).Once()
+			}
+			if c.want.err == "" {
+				mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+					return evt.Type == eventbus.EventTypePointsRequested && evt.UserID == "123"
+				})).Once()
+			}
+
+			ctrl := PointsController{
+				events:      mockEvents,
+				pointTypeDB: mockPointTypeDB,
+				pointsDB:    mockPointsDB,
+				userDB:      mockUserDB,
+			}
+
+			res, err := ctrl.handleRequestCashout(context.Background(), &cashoutHandlerRequest{
+				Points:      c.state.points,
+				PointTypeID: c.state.pointTypeID,
+				UserID:      "123",
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, c.want.value, res.Point.Request.CashoutValue)
+			}
+
+			mockPointTypeDB.AssertExpectations(t)
+			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateRequestCashout(t *testing.T) {
+	type test struct {
+		name string
+		req  cashoutHandlerRequest
+		err  string
+	}
+
+	cases := []test{
+		{"happy path", cashoutHandlerRequest{UserID: "123", Points: 1}, ""},
+		{"fail - missing user", cashoutHandlerRequest{Points: 1}, "unauthorized: missing user ID"},
+		{"fail - invalid points", cashoutHandlerRequest{UserID: "123", Points: -1}, "points must be a positive integer"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateRequestCashout(&c.req)
+			tests.AssertError(t, err, c.err)
+		})
+	}
+}
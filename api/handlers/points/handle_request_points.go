@@ -11,13 +11,15 @@ import (
 	"github.com/sebboness/yektaspoints/models"
 	"github.com/sebboness/yektaspoints/util"
 	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
 	"github.com/segmentio/ksuid"
 )
 
 type pointsHandlerRequest struct {
-	Points int    `json:"points"`
-	Reason string `json:"reason"`
-	UserID string `json:"-"`
+	Points      int    `json:"points"`
+	PointTypeID string `json:"point_type_id"` // Optional, points are of the default type if not set
+	Reason      string `json:"reason"`
+	UserID      string `json:"-"`
 }
 
 type pointsHandlerResponse struct {
@@ -61,13 +63,31 @@ func (c *PointsController) handleRequestPoints(ctx context.Context, req *pointsH
 		return resp, err
 	}
 
+	if req.PointTypeID != "" && req.PointTypeID != models.DefaultPointTypeID {
+		user, err := c.userDB.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			return resp, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		pointTypes, err := c.getPointTypes(ctx, user)
+		if err != nil {
+			return resp, err
+		}
+
+		if _, ok := models.FindPointType(pointTypes, req.PointTypeID); !ok {
+			return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+				WithError(fmt.Sprintf("unsupported point type '%s'", req.PointTypeID))
+		}
+	}
+
 	now := util.ToFormatted(time.Now())
 
 	point := models.Point{
-		ID:     ksuid.New().String(),
-		UserID: req.UserID,
-		Points: req.Points,
-		Status: models.PointStatusWaiting,
+		ID:          ksuid.New().String(),
+		UserID:      req.UserID,
+		Points:      req.Points,
+		PointTypeID: req.PointTypeID,
+		Status:      models.PointStatusWaiting,
 		Request: models.PointRequest{
 			Type:   models.PointRequestTypeAdd,
 			Reason: req.Reason,
@@ -85,6 +105,12 @@ func (c *PointsController) handleRequestPoints(ctx context.Context, req *pointsH
 	resp.Point = point
 	resp.Summary = point.ToPointSummary()
 
+	c.events.Publish(ctx, eventbus.Event{
+		Type:   eventbus.EventTypePointsRequested,
+		UserID: req.UserID,
+		Data:   resp.Summary,
+	})
+
 	return resp, nil
 }
 
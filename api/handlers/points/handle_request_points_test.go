@@ -10,8 +10,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sebboness/yektaspoints/handlers"
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
 	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
 	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
 	"github.com/sebboness/yektaspoints/util/tests"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -52,14 +55,19 @@ func Test_Controller_RequestPointsHandler(t *testing.T) {
 			evtBodyStr := string(evtBody)
 
 			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
 
 			if !c.state.invalidBody {
 				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.Anything).Return(c.state.errSavePoint).Once()
+				if c.state.errSavePoint == nil {
+					mockEvents.EXPECT().Publish(mock.Anything, mock.Anything).Once()
+				}
 			} else {
 				evtBodyStr = `{"user_id":`
 			}
 
 			ctrl := PointsController{
+				events:   mockEvents,
 				pointsDB: mockPointsDB,
 			}
 
@@ -81,6 +89,7 @@ func Test_Controller_RequestPointsHandler(t *testing.T) {
 			}
 
 			mockPointsDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
 		})
 	}
 }
@@ -88,6 +97,8 @@ func Test_Controller_RequestPointsHandler(t *testing.T) {
 func Test_Controller_handleRequestPoints(t *testing.T) {
 	type state struct {
 		validationError bool
+		pointTypeID     string
+		errPointTypes   error
 		errSavePoint    error
 	}
 	type want struct {
@@ -101,40 +112,67 @@ func Test_Controller_handleRequestPoints(t *testing.T) {
 
 	cases := []test{
 		{"happy path", state{}, want{}},
+		{"happy path - default point type", state{pointTypeID: models.DefaultPointTypeID}, want{}},
+		{"happy path - family point type", state{pointTypeID: "st"}, want{}},
 		{"fail - validation error", state{validationError: true}, want{"invalid input: failed to validate request"}},
+		{"fail - unsupported point type", state{pointTypeID: "nope"}, want{"unsupported point type 'nope'"}},
+		{"fail - get point types", state{pointTypeID: "st", errPointTypes: errFail}, want{"failed to get point types: fail"}},
 		{"fail - save points", state{errSavePoint: errFail}, want{"failed to save points: fail"}},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			req := &pointsHandlerRequest{
-				UserID: "123",
-				Points: 1,
-				Reason: "I worked hard",
+				UserID:      "123",
+				Points:      1,
+				PointTypeID: c.state.pointTypeID,
+				Reason:      "I worked hard",
 			}
 
 			if c.state.validationError {
 				req.Points = -1
 			}
 
+			mockPointTypeDB := mocks.NewMockIPointTypeStorage(t)
 			mockPointsDB := mocks.NewMockIPointsStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockEvents := evtmocks.NewMockPublisher(t)
 
-			saveCalled := map[bool]int{true: 0, false: 1}[c.state.validationError]
+			if c.state.pointTypeID != "" && c.state.pointTypeID != models.DefaultPointTypeID {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123", FamilyIDs: []string{"456"}}, nil).Once()
+				mockPointTypeDB.EXPECT().GetPointTypesByFamilyID(mock.Anything, "456").Return([]models.PointType{{FamilyID: "456", ID: "st"}}, c.state.errPointTypes).Once()
+			}
+
+			saveCalled := 1
+			if c.state.validationError || c.state.pointTypeID == "nope" || c.state.errPointTypes != nil {
+				saveCalled = 0
+			}
 			if saveCalled > 0 {
-				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.Anything).Return(c.state.errSavePoint).Times(saveCalled)
+				mockPointsDB.EXPECT().SavePoint(mock.Anything, mock.MatchedBy(func(p models.Point) bool {
+					return p.PointTypeID == c.state.pointTypeID
+				})).Return(c.state.errSavePoint).Times(saveCalled)
+			}
+			if saveCalled > 0 && c.state.errSavePoint == nil {
+				mockEvents.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(evt eventbus.Event) bool {
+					return evt.Type == eventbus.EventTypePointsRequested && evt.UserID == "123"
+				})).Once()
 			}
 
 			ctrl := PointsController{
-				pointsDB: mockPointsDB,
+				events:      mockEvents,
+				pointTypeDB: mockPointTypeDB,
+				pointsDB:    mockPointsDB,
+				userDB:      mockUserDB,
 			}
 
 			ctx := context.Background()
 			_, err := ctrl.handleRequestPoints(ctx, req)
-			if err != nil {
-				assert.Contains(t, err.Error(), c.want.err)
-			}
+			tests.AssertError(t, err, c.want.err)
 
+			mockPointTypeDB.AssertExpectations(t)
 			mockPointsDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockEvents.AssertExpectations(t)
 		})
 	}
 }
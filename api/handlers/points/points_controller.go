@@ -3,23 +3,83 @@ package points
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	"github.com/sebboness/yektaspoints/models"
 	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/eventbus"
 )
 
 type PointsController struct {
-	pointsDB storage.IPointsStorage
+	attachments    attachment.Store
+	events         eventbus.Publisher
+	pointCommentDB storage.IPointCommentStorage
+	pointTypeDB    storage.IPointTypeStorage
+	pointsDB       storage.IPointsStorage
+	userDB         storage.IUserStorage
 }
 
-func NewPointsController(ctx context.Context, env string) (*PointsController, error) {
-	storageCfg := storage.Config{Env: env}
+func NewPointsController(ctx context.Context, stores storage.Storages) (*PointsController, error) {
+	return &PointsController{
+		events:         eventbus.Get(),
+		pointCommentDB: stores.DynamoDb,
+		pointTypeDB:    stores.DynamoDb,
+		pointsDB:       stores.Core,
+		userDB:         stores.Core,
+	}, nil
+}
 
-	userDB, err := storage.NewDynamoDbStorage(storageCfg)
+// UseEventPublisher replaces the publisher points events are sent to
+func (c *PointsController) UseEventPublisher(p eventbus.Publisher) {
+	c.events = p
+}
+
+// UseAttachmentStore sets the store files attached to point requests are kept in
+func (c *PointsController) UseAttachmentStore(s attachment.Store) {
+	c.attachments = s
+}
+
+// checkPointsAccess returns an access denied error unless the user is the target user, or a
+// parent of the target child
+func (c *PointsController) checkPointsAccess(ctx context.Context, userID, targetUserID string) error {
+	if userID == targetUserID {
+		return nil
+	}
+
+	parent, err := c.userDB.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize points db: %w", err)
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &PointsController{
-		pointsDB: userDB,
-	}, nil
+	child, err := c.userDB.GetUserByID(ctx, targetUserID)
+	if err != nil {
+		return fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	sharesFamily := slices.ContainsFunc(parent.FamilyIDs, func(fid string) bool {
+		return slices.Contains(child.FamilyIDs, fid)
+	})
+
+	if !parent.IsParent() || !child.IsChild() || !sharesFamily {
+		return apierr.New(apierr.AccessDenied).WithError("user is not a parent of child")
+	}
+
+	return nil
+}
+
+// getPointTypes returns the default type and the types of the user's families
+func (c *PointsController) getPointTypes(ctx context.Context, user models.User) ([]models.PointType, error) {
+	familyTypes := []models.PointType{}
+
+	for _, familyID := range user.FamilyIDs {
+		types, err := c.pointTypeDB.GetPointTypesByFamilyID(ctx, familyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get point types: %w", err)
+		}
+		familyTypes = append(familyTypes, types...)
+	}
+
+	return models.MergePointTypes(familyTypes), nil
 }
@@ -5,14 +5,73 @@ import (
 	"errors"
 	"testing"
 
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
 	"github.com/sebboness/yektaspoints/util/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+func bal(v int) *int {
+	return &v
+}
+
 var errFail = errors.New("fail")
 
 func Test_Controller_New(t *testing.T) {
-	c, err := NewPointsController(context.Background(), "local")
+	stores, err := storage.NewStorages(storage.Config{Env: "local"})
+	assert.Nil(t, err)
+
+	c, err := NewPointsController(context.Background(), stores)
 	tests.AssertError(t, err, "")
 	assert.NotNil(t, c)
 }
+
+func Test_Controller_checkPointsAccess(t *testing.T) {
+	type state struct {
+		userID    string
+		parent    models.User
+		child     models.User
+		errParent error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	parent := models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+	child := models.User{UserID: "2", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+	otherChild := models.User{UserID: "2", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleChild}}
+
+	cases := []test{
+		{"happy path - own points", state{userID: "2"}, want{}},
+		{"happy path - parent of child", state{userID: "1", parent: parent, child: child}, want{}},
+		{"fail - child of other family", state{userID: "1", parent: parent, child: otherChild}, want{"user is not a parent of child"}},
+		{"fail - sibling", state{userID: "1", parent: models.User{FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}, child: child}, want{"user is not a parent of child"}},
+		{"fail - get user", state{userID: "1", errParent: errFail}, want{"failed to get user: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			userDB := mocks.NewMockIUserStorage(t)
+
+			if c.state.userID != "2" {
+				userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(c.state.parent, c.state.errParent).Once()
+				if c.state.errParent == nil {
+					userDB.EXPECT().GetUserByID(mock.Anything, "2").Return(c.state.child, nil).Once()
+				}
+			}
+
+			ctrl := PointsController{userDB: userDB}
+
+			err := ctrl.checkPointsAccess(context.Background(), c.state.userID, "2")
+			tests.AssertError(t, err, c.want.err)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
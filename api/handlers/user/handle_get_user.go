@@ -7,17 +7,37 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
 	apierr "github.com/sebboness/yektaspoints/util/error"
 	"github.com/sebboness/yektaspoints/util/log"
 )
 
 type getUserResponse struct {
-	Email     string   `json:"email"`
-	FamilyIDs []string `json:"family_ids"`
-	Name      string   `json:"name"`
-	UserID    string   `json:"user_id"`
-	Username  string   `json:"username"`
-	Roles     []string `json:"roles"`
+	ChildCallName string   `json:"child_call_name"`
+	Email         string   `json:"email"`
+	PendingEmail  string   `json:"pending_email,omitempty"`
+	FamilyIDs     []string `json:"family_ids"`
+	Name          string   `json:"name"`
+	UserID        string   `json:"user_id"`
+	Username      string   `json:"username"`
+	Roles         []string `json:"roles"`
+
+	Notifications models.NotificationPreferences `json:"notifications"`
+}
+
+func newGetUserResponse(user models.User) getUserResponse {
+	return getUserResponse{
+		ChildCallName: user.ChildCallName,
+		Email:         user.Email,
+		PendingEmail:  user.PendingEmail,
+		FamilyIDs:     user.FamilyIDs,
+		Name:          user.Name,
+		Roles:         user.Roles,
+		UserID:        user.UserID,
+		Username:      user.Username,
+
+		Notifications: user.Notifications,
+	}
 }
 
 // GetUserHandler returns user data from the currently logged-in user.
@@ -55,12 +75,5 @@ func (c *UserController) handleGetUser(ctx context.Context, userId string) (getU
 		return resp, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	resp.Email = user.Email
-	resp.FamilyIDs = user.FamilyIDs
-	resp.Name = user.Name
-	resp.Roles = user.Roles
-	resp.UserID = user.UserID
-	resp.Username = user.Username
-
-	return resp, nil
+	return newGetUserResponse(user), nil
 }
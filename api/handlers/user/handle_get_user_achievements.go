@@ -0,0 +1,64 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/achievements"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type getUserAchievementsResponse struct {
+	Achievements []models.Achievement `json:"achievements"`
+
+	// All rules the user can earn achievements by, including the ones already earned
+	Rules []models.AchievementRule `json:"rules"`
+}
+
+// GetUserAchievementsHandler returns the achievements the currently logged-in user earned
+func (c *UserController) GetUserAchievementsHandler(cgin *gin.Context) {
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	if !authInfo.HasInfo() {
+		cgin.JSON(http.StatusUnauthorized, handlers.ErrorResult(apierr.Unauthorized))
+		return
+	}
+
+	resp, err := c.handleGetUserAchievements(cgin.Request.Context(), authInfo.GetUserID())
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *UserController) handleGetUserAchievements(ctx context.Context, userId string) (getUserAchievementsResponse, error) {
+	resp := getUserAchievementsResponse{}
+
+	user, err := c.userDB.GetUserByID(ctx, userId)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	resp.Achievements, err = c.achievementDB.GetAchievementsByUserID(ctx, userId)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get achievements: %w", err)
+	}
+
+	resp.Rules, err = achievements.Rules(ctx, c.achievementDB, user.FamilyIDs)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_GetUserAchievementsHandler(t *testing.T) {
+	type state struct {
+		hasNoAuth          bool
+		getUserErr         error
+		getAchievementsErr error
+		getRulesErr        error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 200}},
+		{"fail - unauthorized", state{hasNoAuth: true}, want{"unauthorized", 401}},
+		{"fail - get user", state{getUserErr: errFail}, want{"failed to get user: fail", 500}},
+		{"fail - get achievements", state{getAchievementsErr: errFail}, want{"failed to get achievements: fail", 500}},
+		{"fail - get rules", state{getRulesErr: errFail}, want{"failed to get achievement rules: fail", 500}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAchievementDB := mocks.NewMockIAchievementStorage(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			ctrl := UserController{
+				achievementDB: mockAchievementDB,
+				userDB:        mockUserDB,
+			}
+
+			ctx := context.Background()
+
+			evt := events.APIGatewayProxyRequest{
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Authorizer: map[string]interface{}{
+						"claims": map[string]interface{}{
+							"cognito:username": "john",
+							"email":            "john@info.co",
+							"email_verified":   "true",
+							"name":             "John",
+							"sub":              "1",
+						},
+					},
+				},
+			}
+
+			if c.state.hasNoAuth {
+				evt.RequestContext.Authorizer = nil
+			} else {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", FamilyIDs: []string{"456"}}, c.state.getUserErr).Once()
+			}
+			if !c.state.hasNoAuth && c.state.getUserErr == nil {
+				mockAchievementDB.EXPECT().GetAchievementsByUserID(mock.Anything, "1").Return([]models.Achievement{
+					{UserID: "1", RuleID: "streak-3"},
+				}, c.state.getAchievementsErr).Once()
+			}
+			if !c.state.hasNoAuth && c.state.getUserErr == nil && c.state.getAchievementsErr == nil {
+				mockAchievementDB.EXPECT().GetAchievementRulesByFamilyID(mock.Anything, "456").Return([]models.AchievementRule{
+					{FamilyID: "456", ID: "r1"},
+				}, c.state.getRulesErr).Once()
+			}
+
+			ctx = handlers.PrepareAuthorizedContext(ctx, evt)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			ctrl.GetUserAchievementsHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			if c.want.code == 200 {
+				data := result.Data.(map[string]any)
+				assert.Len(t, data["achievements"], 1)
+				assert.Len(t, data["rules"], len(models.DefaultAchievementRules)+1)
+			}
+
+			mockAchievementDB.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
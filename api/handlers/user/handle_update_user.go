@@ -0,0 +1,295 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/sebboness/yektaspoints/util/notify"
+)
+
+type updateUserRequest struct {
+	models.UserProfileUpdate
+
+	// Set in code
+	UserID       string `json:"-"`
+	TargetUserID string `json:"-"`
+}
+
+type updateUserResponse struct {
+	User                      getUserResponse `json:"user"`
+	EmailVerificationRequired bool            `json:"email_verification_required"`
+}
+
+type userEmailVerifyRequest struct {
+	AccessToken string `json:"access_token"`
+	Code        string `json:"code"`
+
+	// Set in code
+	UserID string `json:"-"`
+}
+
+// UpdateUserHandler updates the profile of the currently logged-in user
+func (c *UserController) UpdateUserHandler(cgin *gin.Context) {
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	c.updateUser(cgin, authInfo.GetUserID())
+}
+
+// UpdateChildUserHandler updates the profile of a child of the currently logged-in parent
+func (c *UserController) UpdateChildUserHandler(cgin *gin.Context) {
+	c.updateUser(cgin, cgin.Param("user_id"))
+}
+
+// UserEmailVerifyHandler verifies the currently logged-in user's email with the code
+// that was sent to them after changing it. Users can't do anything else until they verify
+// their new email, so this handler must not require a verified email.
+func (c *UserController) UserEmailVerifyHandler(cgin *gin.Context) {
+
+	var req userEmailVerifyRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.UserID = authInfo.GetUserID()
+
+	err = c.handleUserEmailVerify(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(nil))
+}
+
+func (c *UserController) updateUser(cgin *gin.Context, targetUserID string) {
+
+	var req updateUserRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.UserID = authInfo.GetUserID()
+	req.TargetUserID = targetUserID
+
+	resp, err := c.handleUpdateUser(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *UserController) handleUpdateUser(ctx context.Context, req *updateUserRequest) (updateUserResponse, error) {
+	resp := updateUserResponse{}
+
+	if err := validateUpdateUser(req); err != nil {
+		return resp, err
+	}
+
+	logger := log.Get().WithContext(ctx).AddFields(map[string]any{
+		"target_user_id": req.TargetUserID,
+		"user_id":        req.UserID,
+	})
+
+	user, err := c.userDB.GetUserByID(ctx, req.TargetUserID)
+	if err != nil {
+		logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to get user")
+		return resp, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// parents may only update the profiles of children in their own families
+	if req.TargetUserID != req.UserID {
+		parent, err := c.userDB.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to get parent")
+			return resp, fmt.Errorf("failed to get parent: %w", err)
+		}
+
+		sharesFamily := slices.ContainsFunc(parent.FamilyIDs, func(fid string) bool {
+			return slices.Contains(user.FamilyIDs, fid)
+		})
+
+		if !parent.IsParent() || !user.IsChild() || !sharesFamily {
+			logger.Errorf("user is not a parent of child")
+			return resp, apierr.New(apierr.AccessDenied).WithError("user is not a parent of child")
+		}
+	}
+
+	// name and email also live in cognito. Changing the email marks it as unverified,
+	// which makes cognito send a new verification code to the new address. The user keeps
+	// their current email until they verify the new one.
+	profile := req.UserProfileUpdate
+	profile.Email = nil
+
+	attributes := map[string]string{}
+	if req.Name != nil && *req.Name != user.Name {
+		attributes["name"] = *req.Name
+	}
+	if req.Email != nil && *req.Email != user.Email {
+		taken, err := c.isUserTaken(ctx, c.userDB.GetUserByEmail, *req.Email)
+		if err != nil {
+			logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to check email")
+			return resp, fmt.Errorf("failed to check email: %w", err)
+		}
+		if taken {
+			return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("email is already registered")
+		}
+
+		attributes["email"] = *req.Email
+		attributes["email_verified"] = "false"
+		profile.PendingEmail = req.Email
+		resp.EmailVerificationRequired = true
+	}
+
+	if len(attributes) > 0 {
+		if err := c.auth.UpdateUserAttributes(ctx, user.Username, attributes); err != nil {
+			logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to update user attributes")
+			return resp, fmt.Errorf("failed to update user attributes: %w", err)
+		}
+	}
+
+	if err := c.userDB.UpdateUserProfile(ctx, req.TargetUserID, profile); err != nil {
+		logger.WithFields(map[string]any{"error": err.Error()}).Errorf("failed to update user profile")
+		return resp, fmt.Errorf("failed to update user profile: %w", err)
+	}
+
+	if req.ChildCallName != nil {
+		user.ChildCallName = *req.ChildCallName
+	}
+	if profile.PendingEmail != nil {
+		user.PendingEmail = *profile.PendingEmail
+	}
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Notifications != nil {
+		user.Notifications = *req.Notifications
+	}
+
+	resp.User = newGetUserResponse(user)
+	return resp, nil
+}
+
+func (c *UserController) handleUserEmailVerify(ctx context.Context, req *userEmailVerifyRequest) error {
+
+	if err := validateUserEmailVerify(req); err != nil {
+		return err
+	}
+
+	err := c.auth.VerifyUserAttribute(ctx, req.AccessToken, "email", req.Code)
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":   err.Error(),
+			"user_id": req.UserID,
+		}).Errorf("failed to verify email")
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	user, err := c.userDB.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// the verified email replaces the user's email
+	if user.PendingEmail != "" {
+		cleared := ""
+		err := c.userDB.UpdateUserProfile(ctx, req.UserID, models.UserProfileUpdate{
+			Email:        &user.PendingEmail,
+			PendingEmail: &cleared,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update user profile: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateUpdateUser(req *updateUserRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.TargetUserID == "" {
+		apierr.AppendError("missing user_id")
+	}
+
+	if req.ChildCallName == nil && req.Email == nil && req.Name == nil && req.Notifications == nil {
+		apierr.AppendError("nothing to update")
+	}
+
+	if req.Email != nil {
+		if _, err := mail.ParseAddress(*req.Email); err != nil {
+			apierr.AppendError("email must be a valid email address")
+		}
+	}
+
+	if req.Name != nil && len(*req.Name) < 2 {
+		apierr.AppendError("name must be at least 2 characters long")
+	}
+
+	if req.Notifications != nil && req.Notifications.Language != "" && !notify.IsSupportedLanguage(req.Notifications.Language) {
+		apierr.AppendErrorf("language must be one of: %s", strings.Join(notify.Languages, ", "))
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
+
+func validateUserEmailVerify(req *userEmailVerifyRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.AccessToken == "" {
+		apierr.AppendError("missing access_token")
+	}
+
+	if req.Code == "" {
+		apierr.AppendError("missing code")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
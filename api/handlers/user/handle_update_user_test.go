@@ -0,0 +1,309 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Controller_UpdateUserHandler(t *testing.T) {
+	type state struct {
+		body       string
+		getUserErr error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{body: `{"child_call_name":"Mom"}`}, want{"", http.StatusOK}},
+		{"fail - invalid body", state{body: `{"name":`}, want{"failed to unmarshal json body", http.StatusBadRequest}},
+		{"fail - nothing to update", state{body: `{}`}, want{"nothing to update", http.StatusBadRequest}},
+		{"fail - internal server error", state{body: `{"child_call_name":"Mom"}`, getUserErr: errFail}, want{"failed to get user: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			if c.want.code == http.StatusOK || c.state.getUserErr != nil {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123"}, c.state.getUserErr).Once()
+
+				if c.state.getUserErr == nil {
+					mockUserDB.EXPECT().UpdateUserProfile(mock.Anything, "123", mock.Anything).Return(nil).Once()
+				}
+			}
+
+			ctrl := UserController{
+				userDB: mockUserDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("PATCH", "/", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.UpdateUserHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_handleUpdateUser(t *testing.T) {
+	type state struct {
+		targetUserID string
+		email        string
+		name         string
+		parent       models.User
+		emailTaken   bool
+		errEmail     error
+		errAuth      error
+		errUpdate    error
+	}
+	type want struct {
+		err                 string
+		attributes          map[string]string
+		verificationPending bool
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	parent := models.User{UserID: "1", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleParent}}
+	otherParent := models.User{UserID: "1", FamilyIDs: []string{"f2"}, Roles: []string{models.RoleParent}}
+
+	cases := []test{
+		{"happy path - name", state{targetUserID: "1", name: "Johnny"}, want{"", map[string]string{"name": "Johnny"}, false}},
+		{"happy path - email", state{targetUserID: "1", email: "new@info.co"}, want{"", map[string]string{"email": "new@info.co", "email_verified": "false"}, true}},
+		{"happy path - same email", state{targetUserID: "1", email: "john@info.co"}, want{"", nil, false}},
+		{"fail - email taken", state{targetUserID: "1", email: "new@info.co", emailTaken: true}, want{"email is already registered", nil, false}},
+		{"fail - check email error", state{targetUserID: "1", email: "new@info.co", errEmail: errFail}, want{"failed to check email: fail", nil, false}},
+		{"happy path - parent updates child", state{targetUserID: "2", name: "Jane", parent: parent}, want{"", map[string]string{"name": "Jane"}, false}},
+		{"fail - parent of other family", state{targetUserID: "2", name: "Jane", parent: otherParent}, want{"user is not a parent of child", nil, false}},
+		{"fail - auth error", state{targetUserID: "1", name: "Johnny", errAuth: errFail}, want{"failed to update user attributes: fail", map[string]string{"name": "Johnny"}, false}},
+		{"fail - update error", state{targetUserID: "1", name: "Johnny", errUpdate: errFail}, want{"failed to update user profile: fail", map[string]string{"name": "Johnny"}, false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			user := models.User{UserID: "1", Username: "john", Name: "John", Email: "john@info.co"}
+			if c.state.targetUserID == "2" {
+				user = models.User{UserID: "2", Username: "jane", Name: "Janey", FamilyIDs: []string{"f1"}, Roles: []string{models.RoleChild}}
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(c.state.parent, nil).Once()
+			}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, c.state.targetUserID).Return(user, nil).Once()
+
+			denied := c.state.targetUserID == "2" && c.state.parent.FamilyIDs[0] != "f1"
+
+			emailChanged := c.state.email != "" && c.state.email != user.Email
+			if emailChanged {
+				found := models.User{UserID: "3"}
+				errFound := c.state.errEmail
+				if !c.state.emailTaken && errFound == nil {
+					errFound = apierr.New(apierr.NotFound)
+				}
+
+				mockUserDB.EXPECT().GetUserByEmail(mock.Anything, c.state.email).Return(found, errFound).Once()
+			}
+
+			if !denied && !c.state.emailTaken && c.state.errEmail == nil {
+				if c.want.attributes != nil {
+					mockAuther.EXPECT().UpdateUserAttributes(mock.Anything, user.Username, c.want.attributes).Return(c.state.errAuth).Once()
+				}
+
+				if c.state.errAuth == nil {
+					// a changed email is kept pending until it's verified
+					mockUserDB.EXPECT().UpdateUserProfile(mock.Anything, c.state.targetUserID, mock.MatchedBy(func(p models.UserProfileUpdate) bool {
+						if emailChanged {
+							return p.Email == nil && p.PendingEmail != nil && *p.PendingEmail == c.state.email
+						}
+						return p.PendingEmail == nil
+					})).Return(c.state.errUpdate).Once()
+				}
+			}
+
+			ctrl := UserController{
+				auth:   mockAuther,
+				userDB: mockUserDB,
+			}
+
+			req := &updateUserRequest{
+				UserID:       "1",
+				TargetUserID: c.state.targetUserID,
+			}
+			if c.state.email != "" {
+				req.Email = &c.state.email
+			}
+			if c.state.name != "" {
+				req.Name = &c.state.name
+			}
+
+			res, err := ctrl.handleUpdateUser(context.Background(), req)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, c.want.verificationPending, res.EmailVerificationRequired)
+				if c.state.name != "" {
+					assert.Equal(t, c.state.name, res.User.Name)
+				}
+				if c.want.verificationPending {
+					assert.Equal(t, user.Email, res.User.Email)
+					assert.Equal(t, c.state.email, res.User.PendingEmail)
+				}
+			}
+
+			mockAuther.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Controller_UserEmailVerifyHandler(t *testing.T) {
+	type state struct {
+		body         string
+		pendingEmail string
+		errAuth      error
+		errUpdate    error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{body: `{"access_token":"abc","code":"123456"}`}, want{"", http.StatusOK}},
+		{"happy path - pending email replaces email", state{body: `{"access_token":"abc","code":"123456"}`, pendingEmail: "new@info.co"}, want{"", http.StatusOK}},
+		{"fail - invalid body", state{body: `{"code":`}, want{"failed to unmarshal json body", http.StatusBadRequest}},
+		{"fail - missing code", state{body: `{"access_token":"abc"}`}, want{"missing code", http.StatusBadRequest}},
+		{"fail - internal server error", state{body: `{"access_token":"abc","code":"123456"}`, errAuth: errFail}, want{"failed to verify email: fail", http.StatusInternalServerError}},
+		{"fail - update error", state{body: `{"access_token":"abc","code":"123456"}`, pendingEmail: "new@info.co", errUpdate: errFail}, want{"failed to update user profile: fail", http.StatusInternalServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			verified := c.want.code == http.StatusOK || c.state.errUpdate != nil
+			if verified || c.state.errAuth != nil {
+				mockAuther.EXPECT().VerifyUserAttribute(mock.Anything, "abc", "email", "123456").Return(c.state.errAuth).Once()
+			}
+
+			if verified {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{UserID: "123", Email: "old@info.co", PendingEmail: c.state.pendingEmail}, nil).Once()
+
+				if c.state.pendingEmail != "" {
+					mockUserDB.EXPECT().UpdateUserProfile(mock.Anything, "123", mock.MatchedBy(func(p models.UserProfileUpdate) bool {
+						return *p.Email == c.state.pendingEmail && *p.PendingEmail == ""
+					})).Return(c.state.errUpdate).Once()
+				}
+			}
+
+			ctrl := UserController{
+				auth:   mockAuther,
+				userDB: mockUserDB,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(c.state.body))).WithContext(ctx)
+
+			ctrl.UserEmailVerifyHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockAuther.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateUpdateUser(t *testing.T) {
+	type state struct {
+		userId   string
+		email    string
+		name     string
+		language string
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{userId: "1", name: "John", email: "john@info.co"}, want{}},
+		{"fail - missing user", state{name: "John"}, want{"unauthorized: missing user ID"}},
+		{"fail - nothing to update", state{userId: "1"}, want{"nothing to update"}},
+		{"fail - invalid email", state{userId: "1", email: "john"}, want{"email must be a valid email address"}},
+		{"fail - short name", state{userId: "1", name: "J"}, want{"name must be at least 2 characters long"}},
+		{"happy path - notification language", state{userId: "1", language: "es"}, want{}},
+		{"fail - unsupported notification language", state{userId: "1", language: "xx"}, want{"language must be one of: en, es"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := &updateUserRequest{
+				UserID:       c.state.userId,
+				TargetUserID: c.state.userId,
+			}
+			if c.state.email != "" {
+				req.Email = &c.state.email
+			}
+			if c.state.name != "" {
+				req.Name = &c.state.name
+			}
+			if c.state.language != "" {
+				req.Notifications = &models.NotificationPreferences{Language: c.state.language}
+			}
+
+			err := validateUpdateUser(req)
+			tests.AssertError(t, err, c.want.err)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/util/auth"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+type userMfaAssociateRequest struct {
+	AccessToken string `json:"access_token"`
+
+	// Set in code
+	UserID string `json:"-"`
+}
+
+type userMfaAssociateResponse struct {
+	auth.SoftwareTokenResult
+}
+
+type userMfaVerifyRequest struct {
+	AccessToken string `json:"access_token"`
+	Code        string `json:"code"`
+	DeviceName  string `json:"device_name"`
+
+	// Set in code
+	UserID string `json:"-"`
+}
+
+// UserMfaAssociateHandler starts the setup of a TOTP authenticator app for the currently logged-in user
+func (c *UserController) UserMfaAssociateHandler(cgin *gin.Context) {
+
+	var req userMfaAssociateRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.UserID = authInfo.GetUserID()
+
+	resp, err := c.handleUserMfaAssociate(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+// UserMfaVerifyHandler verifies a code from the user's TOTP authenticator app and enables it as their second factor
+func (c *UserController) UserMfaVerifyHandler(cgin *gin.Context) {
+
+	var req userMfaVerifyRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	authInfo := handlers.GetAuthorizerInfo(cgin)
+	req.UserID = authInfo.GetUserID()
+
+	err = c.handleUserMfaVerify(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(nil))
+}
+
+func (c *UserController) handleUserMfaAssociate(ctx context.Context, req *userMfaAssociateRequest) (userMfaAssociateResponse, error) {
+	resp := userMfaAssociateResponse{}
+
+	if err := validateUserMfaAssociate(req); err != nil {
+		return resp, err
+	}
+
+	result, err := c.auth.AssociateSoftwareToken(ctx, req.AccessToken)
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":   err.Error(),
+			"user_id": req.UserID,
+		}).Errorf("failed to associate software token")
+		return resp, fmt.Errorf("failed to associate software token: %w", err)
+	}
+
+	resp.SoftwareTokenResult = result
+	return resp, nil
+}
+
+func (c *UserController) handleUserMfaVerify(ctx context.Context, req *userMfaVerifyRequest) error {
+
+	if err := validateUserMfaVerify(req); err != nil {
+		return err
+	}
+
+	err := c.auth.VerifySoftwareToken(ctx, req.AccessToken, req.Code, req.DeviceName)
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":   err.Error(),
+			"user_id": req.UserID,
+		}).Errorf("failed to verify software token")
+		return fmt.Errorf("failed to verify software token: %w", err)
+	}
+
+	return nil
+}
+
+func validateUserMfaAssociate(req *userMfaAssociateRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.AccessToken == "" {
+		apierr.AppendError("missing access_token")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
+
+func validateUserMfaVerify(req *userMfaVerifyRequest) error {
+	if req.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: missing user ID", apierr.Unauthorized))
+	}
+
+	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
+
+	if req.AccessToken == "" {
+		apierr.AppendError("missing access_token")
+	}
+
+	if req.Code == "" {
+		apierr.AppendError("missing code")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
+	return nil
+}
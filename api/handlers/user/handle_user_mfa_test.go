@@ -0,0 +1,179 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	"github.com/sebboness/yektaspoints/util/auth"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_UserMfaAssociateHandler(t *testing.T) {
+	type state struct {
+		invalidBody bool
+		errAuth     error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 200}},
+		{"fail - invalid body", state{invalidBody: true}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{errAuth: apierr.New(apierr.InvalidInput)}, want{"invalid input", 400}},
+		{"fail - internal server error", state{errAuth: errors.New("fail")}, want{"fail", 500}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			req := &userMfaAssociateRequest{
+				AccessToken: "abc",
+			}
+
+			evtBody, _ := json.Marshal(req)
+			evtBodyStr := string(evtBody)
+
+			mockAuther := authmocks.NewMockAuthController(t)
+
+			if !c.state.invalidBody {
+				mockAuther.EXPECT().AssociateSoftwareToken(mock.Anything, mock.Anything).Return(auth.SoftwareTokenResult{SecretCode: "secret"}, c.state.errAuth).Once()
+			} else {
+				evtBodyStr = `{"access_token":`
+			}
+
+			ctrl := UserController{
+				auth: mockAuther,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(evtBodyStr))).WithContext(ctx)
+
+			ctrl.UserMfaAssociateHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockAuther.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UserMfaVerifyHandler(t *testing.T) {
+	type state struct {
+		invalidBody bool
+		errAuth     error
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 200}},
+		{"fail - invalid body", state{invalidBody: true}, want{"failed to unmarshal json body", 400}},
+		{"fail - validation error", state{errAuth: apierr.New(apierr.InvalidInput)}, want{"invalid input", 400}},
+		{"fail - internal server error", state{errAuth: errors.New("fail")}, want{"fail", 500}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			req := &userMfaVerifyRequest{
+				AccessToken: "abc",
+				Code:        "123456",
+				DeviceName:  "phone",
+			}
+
+			evtBody, _ := json.Marshal(req)
+			evtBodyStr := string(evtBody)
+
+			mockAuther := authmocks.NewMockAuthController(t)
+
+			if !c.state.invalidBody {
+				mockAuther.EXPECT().VerifySoftwareToken(mock.Anything, "abc", "123456", "phone").Return(c.state.errAuth).Once()
+			} else {
+				evtBodyStr = `{"access_token":`
+			}
+
+			ctrl := UserController{
+				auth: mockAuther,
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), handlers.MockApiGWEvent)
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(evtBodyStr))).WithContext(ctx)
+
+			ctrl.UserMfaVerifyHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockAuther.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_validateUserMfaVerify(t *testing.T) {
+	type state struct {
+		userId      string
+		accessToken string
+		code        string
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{userId: "1", accessToken: "abc", code: "123456"}, want{}},
+		{"fail - missing user", state{accessToken: "abc", code: "123456"}, want{"unauthorized: missing user ID"}},
+		{"fail - missing access token", state{userId: "1", code: "123456"}, want{"missing access_token"}},
+		{"fail - missing code", state{userId: "1", accessToken: "abc"}, want{"missing code"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := &userMfaVerifyRequest{
+				UserID:      c.state.userId,
+				AccessToken: c.state.accessToken,
+				Code:        c.state.code,
+			}
+
+			err := validateUserMfaVerify(req)
+			tests.AssertError(t, err, c.want.err)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/mail"
@@ -58,7 +59,7 @@ func (c *UserController) UserRegisterHandler(cgin *gin.Context) {
 func (c *UserController) handleUserRegister(ctx context.Context, req *userRegisterRequest) (userRegisterResponse, error) {
 	resp := userRegisterResponse{}
 
-	if err := validateUserRegister(req); err != nil {
+	if err := c.validateUserRegister(ctx, req); err != nil {
 		return resp, err
 	}
 
@@ -88,6 +89,8 @@ func (c *UserController) handleUserRegister(ctx context.Context, req *userRegist
 		UpdatedOnStr: util.ToFormattedUTC(time.Now()),
 		FamilyIDs:    []string{},
 		Roles:        []string{},
+
+		ConfirmationSentOnStr: util.ToFormattedUTC(time.Now()),
 	}
 
 	if err := c.userDB.SaveUser(ctx, user); err != nil {
@@ -95,6 +98,16 @@ func (c *UserController) handleUserRegister(ctx context.Context, req *userRegist
 			"user_id": result.UserID,
 			"error":   err.Error(),
 		}).Errorf("failed to store new user '%s'", req.Username)
+
+		// roll back the cognito registration so the username isn't left taken by a user that
+		// has no record. If this fails as well, the reconcile job will clean up the orphan.
+		if delErr := c.auth.DeleteUser(ctx, req.Username); delErr != nil {
+			logger.WithContext(ctx).WithFields(map[string]any{
+				"user_id": result.UserID,
+				"error":   delErr.Error(),
+			}).Errorf("failed to roll back cognito registration of user '%s'", req.Username)
+		}
+
 		return resp, fmt.Errorf("failed to save new user '%s': %w", req.Username, err)
 	}
 
@@ -102,7 +115,9 @@ func (c *UserController) handleUserRegister(ctx context.Context, req *userRegist
 	return resp, nil
 }
 
-func validateUserRegister(req *userRegisterRequest) error {
+// validateUserRegister validates the request fields and, if they are valid,
+// checks that the username and email aren't already taken by another user
+func (c *UserController) validateUserRegister(ctx context.Context, req *userRegisterRequest) error {
 	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
 
 	if len(req.Username) < 4 {
@@ -142,5 +157,39 @@ func validateUserRegister(req *userRegisterRequest) error {
 		return apierr
 	}
 
+	taken, err := c.isUserTaken(ctx, c.userDB.GetUserByUsername, req.Username)
+	if err != nil {
+		return fmt.Errorf("failed to check username: %w", err)
+	}
+	if taken {
+		apierr.AppendError("username is already taken")
+	}
+
+	taken, err = c.isUserTaken(ctx, c.userDB.GetUserByEmail, req.Email)
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if taken {
+		apierr.AppendError("email is already registered")
+	}
+
+	if len(apierr.Errors()) > 0 {
+		return apierr
+	}
+
 	return nil
 }
+
+// isUserTaken returns true if getUser finds a user by the given value
+func (c *UserController) isUserTaken(ctx context.Context, getUser func(context.Context, string) (models.User, error), value string) (bool, error) {
+	_, err := getUser(ctx, value)
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, apierr.NotFound) {
+		return false, nil
+	}
+
+	return false, err
+}
@@ -15,9 +15,6 @@ import (
 type userRegisterConfirmRequest struct {
 	Username string `json:"username"`
 	Code     string `json:"code"`
-
-	// Set in code
-	UserID string `json:"-"`
 }
 
 // UserRegisterConfirmHandler confirms a user registration by providing a code that was emailed/SMSed to them
@@ -33,9 +30,6 @@ func (c *UserController) UserRegisterConfirmHandler(cgin *gin.Context) {
 		return
 	}
 
-	authInfo := handlers.GetAuthorizerInfo(cgin)
-	req.UserID = authInfo.GetUserID()
-
 	err = c.handleUserRegisterConfirm(cgin.Request.Context(), &req)
 	if err != nil {
 		if apierr := apierr.IsApiError(err); apierr != nil {
@@ -58,7 +52,17 @@ func (c *UserController) handleUserRegisterConfirm(ctx context.Context, req *use
 
 	logger := log.Get()
 
-	err := c.auth.ConfirmRegistration(ctx, req.Username, req.Code)
+	// the user has no token yet, so they are identified by their username
+	user, err := c.userDB.GetUserByUsername(ctx, req.Username)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"error":    err.Error(),
+			"username": req.Username,
+		}).Errorf("failed to get user")
+		return fmt.Errorf("failed to get user '%s': %w", req.Username, err)
+	}
+
+	err = c.auth.ConfirmRegistration(ctx, req.Username, req.Code)
 	if err != nil {
 		logger.WithContext(ctx).WithFields(map[string]any{
 			"error":    err.Error(),
@@ -67,7 +71,7 @@ func (c *UserController) handleUserRegisterConfirm(ctx context.Context, req *use
 		return fmt.Errorf("failed to confirm user registration for '%s': %w", req.Username, err)
 	}
 
-	err = c.userDB.UpdateUserStatus(ctx, req.UserID, models.UserStatusActive)
+	err = c.userDB.UpdateUserStatus(ctx, user.UserID, models.UserStatusActive)
 	if err != nil {
 		logger.WithContext(ctx).WithFields(map[string]any{
 			"error":    err.Error(),
@@ -86,10 +90,6 @@ func validateUserRegisterConfirm(req *userRegisterConfirmRequest) error {
 		apierr.AppendError("missing username")
 	}
 
-	if req.UserID == "" {
-		apierr.AppendError("missing user_id")
-	}
-
 	if req.Code == "" {
 		apierr.AppendError("missing code")
 	}
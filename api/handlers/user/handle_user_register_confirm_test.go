@@ -13,7 +13,7 @@ import (
 	"github.com/sebboness/yektaspoints/handlers"
 	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
 	mocks "github.com/sebboness/yektaspoints/mocks/storage"
-
+	"github.com/sebboness/yektaspoints/models"
 	apierr "github.com/sebboness/yektaspoints/util/error"
 	"github.com/sebboness/yektaspoints/util/tests"
 	"github.com/stretchr/testify/assert"
@@ -61,7 +61,6 @@ func Test_UserRegisterConfirmHandler(t *testing.T) {
 
 			req := &userRegisterConfirmRequest{
 				Code:     "123456",
-				UserID:   "1",
 				Username: "john",
 			}
 
@@ -72,8 +71,9 @@ func Test_UserRegisterConfirmHandler(t *testing.T) {
 			mockUserDB := mocks.NewMockIUserStorage(t)
 
 			if !c.state.invalidBody {
+				mockUserDB.EXPECT().GetUserByUsername(mock.Anything, "john").Return(models.User{UserID: "1"}, nil).Once()
 				mockAuther.EXPECT().ConfirmRegistration(mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
-				mockUserDB.EXPECT().UpdateUserStatus(mock.Anything, mock.Anything, mock.Anything).Return(c.state.updateErr).Once()
+				mockUserDB.EXPECT().UpdateUserStatus(mock.Anything, "1", mock.Anything).Return(c.state.updateErr).Once()
 			} else {
 				evtBodyStr = `{"":`
 			}
@@ -102,6 +102,7 @@ func Test_UserRegisterConfirmHandler(t *testing.T) {
 func Test_handleUserRegisterConfirm(t *testing.T) {
 	type state struct {
 		hasValidationErr bool
+		getUserErr       error
 		regErr           error
 		updateErr        error
 	}
@@ -117,6 +118,7 @@ func Test_handleUserRegisterConfirm(t *testing.T) {
 	cases := []test{
 		{"happy path - password flow", state{}, want{}},
 		{"fail - invalid input", state{hasValidationErr: true}, want{"failed to validate request"}},
+		{"fail - get user error", state{getUserErr: errFail}, want{"failed to get user 'john'"}},
 		{"fail - register error", state{regErr: errFail}, want{"failed to confirm user registration for 'john'"}},
 		{"fail - update error", state{updateErr: errFail}, want{"failed to update user status to active for 'john'"}},
 	}
@@ -134,15 +136,17 @@ func Test_handleUserRegisterConfirm(t *testing.T) {
 			}
 
 			if !c.state.hasValidationErr {
+				mockUserDB.EXPECT().GetUserByUsername(mock.Anything, "john").Return(models.User{UserID: "1"}, c.state.getUserErr).Once()
+			}
+			if !c.state.hasValidationErr && c.state.getUserErr == nil {
 				mockAuther.EXPECT().ConfirmRegistration(mock.Anything, mock.Anything, mock.Anything).Return(c.state.regErr).Once()
 			}
-			if !c.state.hasValidationErr && c.state.regErr == nil {
-				mockUserDB.EXPECT().UpdateUserStatus(mock.Anything, mock.Anything, mock.Anything).Return(c.state.updateErr).Once()
+			if !c.state.hasValidationErr && c.state.getUserErr == nil && c.state.regErr == nil {
+				mockUserDB.EXPECT().UpdateUserStatus(mock.Anything, "1", mock.Anything).Return(c.state.updateErr).Once()
 			}
 
 			req := &userRegisterConfirmRequest{
 				Code:     "123456",
-				UserID:   "1",
 				Username: "john",
 			}
 
@@ -162,9 +166,8 @@ func Test_handleUserRegisterConfirm(t *testing.T) {
 
 func Test_validateUserRegisterConfirm(t *testing.T) {
 	type state struct {
-		code   string
-		uname  string
-		userId string
+		code  string
+		uname string
 	}
 	type want struct {
 		err string
@@ -176,17 +179,15 @@ func Test_validateUserRegisterConfirm(t *testing.T) {
 	}
 
 	cases := []test{
-		{"happy path", state{uname: "john", userId: "1", code: "John"}, want{}},
-		{"missing user_id", state{uname: "1", userId: "", code: "John"}, want{"missing user_id"}},
-		{"missing username", state{uname: "", userId: "1", code: "John"}, want{"missing username"}},
-		{"missing code", state{uname: "john", userId: "1", code: ""}, want{"missing code"}},
+		{"happy path", state{uname: "john", code: "John"}, want{}},
+		{"missing username", state{uname: "", code: "John"}, want{"missing username"}},
+		{"missing code", state{uname: "john", code: ""}, want{"missing code"}},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			req := &userRegisterConfirmRequest{
 				Code:     c.state.code,
-				UserID:   c.state.userId,
 				Username: c.state.uname,
 			}
 
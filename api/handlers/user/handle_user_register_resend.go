@@ -0,0 +1,89 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/auth"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// resendConfirmationInterval is the minimum time between two confirmation codes sent to the same username
+const resendConfirmationInterval = time.Minute
+
+type userRegisterResendRequest struct {
+	Username string `json:"username"`
+}
+
+type userRegisterResendResponse struct {
+	auth.CodeDeliveryResult
+}
+
+// UserRegisterResendHandler sends a new registration confirmation code to a user that has not confirmed their account yet
+func (c *UserController) UserRegisterResendHandler(cgin *gin.Context) {
+
+	var req userRegisterResendRequest
+
+	// try to unmarshal from request body
+	err := cgin.BindJSON(&req)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal json body: %w", err)
+		cgin.JSON(http.StatusBadRequest, handlers.ErrorResult(err))
+		return
+	}
+
+	resp, err := c.handleUserRegisterResend(cgin.Request.Context(), &req)
+	if err != nil {
+		if apierr := apierr.IsApiError(err); apierr != nil {
+			cgin.JSON(apierr.StatusCode(), handlers.ErrorResult(apierr))
+			return
+		}
+
+		cgin.JSON(http.StatusInternalServerError, handlers.ErrorResult(err))
+		return
+	}
+
+	cgin.JSON(http.StatusOK, handlers.SuccessResult(resp))
+}
+
+func (c *UserController) handleUserRegisterResend(ctx context.Context, req *userRegisterResendRequest) (userRegisterResendResponse, error) {
+	resp := userRegisterResendResponse{}
+
+	if req.Username == "" {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing username")
+	}
+
+	logger := log.Get().WithContext(ctx).AddField("username", req.Username)
+
+	user, err := c.userDB.GetUserByUsername(ctx, req.Username)
+	if err != nil {
+		logger.WithField("error", err.Error()).Errorf("failed to get user")
+		return resp, fmt.Errorf("failed to get user '%s': %w", req.Username, err)
+	}
+
+	if user.Status != models.UserStatusUnverified {
+		return resp, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("user is already confirmed")
+	}
+
+	// reserve the send before calling cognito, so concurrent requests can't both get through
+	err = c.userDB.UpdateUserConfirmationSentOn(ctx, user.UserID, time.Now(), resendConfirmationInterval)
+	if err != nil {
+		logger.WithField("error", err.Error()).Warnf("failed to update confirmation sent on")
+		return resp, err
+	}
+
+	result, err := c.auth.ResendConfirmationCode(ctx, req.Username)
+	if err != nil {
+		logger.WithField("error", err.Error()).Errorf("failed to resend confirmation code")
+		return resp, fmt.Errorf("failed to resend confirmation code for '%s': %w", req.Username, err)
+	}
+
+	resp.CodeDeliveryResult = result
+	return resp, nil
+}
@@ -0,0 +1,132 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/auth"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_UserRegisterResendHandler(t *testing.T) {
+	type state struct {
+		body string
+	}
+	type want struct {
+		err  string
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{body: `{"username":"john"}`}, want{"", http.StatusOK}},
+		{"fail - invalid body", state{body: `{"username":`}, want{"failed to unmarshal json body", http.StatusBadRequest}},
+		{"fail - missing username", state{body: `{}`}, want{"missing username", http.StatusBadRequest}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			if c.want.code == http.StatusOK {
+				mockUserDB.EXPECT().GetUserByUsername(mock.Anything, "john").Return(models.User{UserID: "1", Status: models.UserStatusUnverified}, nil).Once()
+				mockUserDB.EXPECT().UpdateUserConfirmationSentOn(mock.Anything, "1", mock.Anything, resendConfirmationInterval).Return(nil).Once()
+				mockAuther.EXPECT().ResendConfirmationCode(mock.Anything, "john").Return(auth.CodeDeliveryResult{ConfirmationType: "EMAIL"}, nil).Once()
+			}
+
+			ctrl := UserController{
+				auth:   mockAuther,
+				userDB: mockUserDB,
+			}
+
+			w := httptest.NewRecorder()
+			cgin, _ := gin.CreateTestContext(w)
+			cgin.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(c.state.body)))
+
+			ctrl.UserRegisterResendHandler(cgin)
+
+			assert.Equal(t, c.want.code, w.Code)
+			result := tests.AssertResult(t, w.Body)
+			tests.AssertResultError(t, result, c.want.err)
+
+			mockAuther.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_handleUserRegisterResend(t *testing.T) {
+	type state struct {
+		status     models.UserStatus
+		getUserErr error
+		updateErr  error
+		resendErr  error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	tooManyErr := apierr.New(apierr.TooManyRequests)
+
+	cases := []test{
+		{"happy path", state{status: models.UserStatusUnverified}, want{}},
+		{"fail - get user error", state{getUserErr: errFail}, want{"failed to get user 'john': fail"}},
+		{"fail - already confirmed", state{status: models.UserStatusActive}, want{"user is already confirmed"}},
+		{"fail - rate limited", state{status: models.UserStatusUnverified, updateErr: tooManyErr}, want{"too many requests"}},
+		{"fail - resend error", state{status: models.UserStatusUnverified, resendErr: errFail}, want{"failed to resend confirmation code for 'john': fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockAuther := authmocks.NewMockAuthController(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			mockUserDB.EXPECT().GetUserByUsername(mock.Anything, "john").Return(models.User{UserID: "1", Status: c.state.status}, c.state.getUserErr).Once()
+
+			if c.state.getUserErr == nil && c.state.status == models.UserStatusUnverified {
+				mockUserDB.EXPECT().UpdateUserConfirmationSentOn(mock.Anything, "1", mock.Anything, resendConfirmationInterval).Return(c.state.updateErr).Once()
+
+				if c.state.updateErr == nil {
+					mockAuther.EXPECT().ResendConfirmationCode(mock.Anything, "john").Return(auth.CodeDeliveryResult{}, c.state.resendErr).Once()
+				}
+			}
+
+			ctrl := UserController{
+				auth:   mockAuther,
+				userDB: mockUserDB,
+			}
+
+			_, err := ctrl.handleUserRegisterResend(context.Background(), &userRegisterResendRequest{Username: "john"})
+			tests.AssertError(t, err, c.want.err)
+
+			if c.state.updateErr != nil {
+				assert.Equal(t, http.StatusTooManyRequests, apierr.IsApiError(err).StatusCode())
+			}
+
+			mockAuther.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
 	mocks "github.com/sebboness/yektaspoints/mocks/storage"
-
+	"github.com/sebboness/yektaspoints/models"
 	"github.com/sebboness/yektaspoints/util/auth"
 	apierr "github.com/sebboness/yektaspoints/util/error"
 	"github.com/sebboness/yektaspoints/util/tests"
@@ -20,6 +20,7 @@ import (
 )
 
 var errFail = errors.New("fail")
+var errNotFound = apierr.New(apierr.NotFound)
 
 func Test_UserRegisterHandler(t *testing.T) {
 	type state struct {
@@ -63,8 +64,13 @@ func Test_UserRegisterHandler(t *testing.T) {
 			authRes := auth.UserRegisterResult{UserID: "john"}
 
 			if !c.state.invalidBody {
+				mockUserDB.EXPECT().GetUserByUsername(mock.Anything, "john").Return(models.User{}, errNotFound).Once()
+				mockUserDB.EXPECT().GetUserByEmail(mock.Anything, "john@info.co").Return(models.User{}, errNotFound).Once()
 				mockAuther.EXPECT().Register(mock.Anything, mock.Anything).Return(authRes, nil).Once()
 				mockUserDB.EXPECT().SaveUser(mock.Anything, mock.Anything).Return(c.state.errSave).Once()
+				if c.state.errSave != nil {
+					mockAuther.EXPECT().DeleteUser(mock.Anything, "john").Return(nil).Once()
+				}
 			} else {
 				evtBodyStr = `{"user_id":`
 			}
@@ -95,6 +101,7 @@ func Test_handleUserRegister(t *testing.T) {
 		hasValidationErr bool
 		regErr           error
 		saveErr          error
+		deleteErr        error
 	}
 	type want struct {
 		err string
@@ -110,6 +117,7 @@ func Test_handleUserRegister(t *testing.T) {
 		{"fail - invalid input", state{hasValidationErr: true}, want{"failed to validate request"}},
 		{"fail - register error", state{regErr: errFail}, want{"failed to register user 'john'"}},
 		{"fail - save error", state{saveErr: errFail}, want{"failed to save new user 'john'"}},
+		{"fail - save error and rollback error", state{saveErr: errFail, deleteErr: errFail}, want{"failed to save new user 'john'"}},
 	}
 
 	for _, c := range cases {
@@ -130,11 +138,17 @@ func Test_handleUserRegister(t *testing.T) {
 			}
 
 			if !c.state.hasValidationErr {
+				mockUserDB.EXPECT().GetUserByUsername(mock.Anything, "john").Return(models.User{}, errNotFound).Once()
+				mockUserDB.EXPECT().GetUserByEmail(mock.Anything, "john@info.co").Return(models.User{}, errNotFound).Once()
 				mockAuther.EXPECT().Register(mock.Anything, mock.Anything).Return(regResult, c.state.regErr).Once()
 			}
 			if !c.state.hasValidationErr && c.state.regErr == nil {
 				mockUserDB.EXPECT().SaveUser(mock.Anything, mock.Anything).Return(c.state.saveErr).Once()
 			}
+			if c.state.saveErr != nil {
+				// the cognito user must be deleted again if the user record couldn't be saved
+				mockAuther.EXPECT().DeleteUser(mock.Anything, "john").Return(c.state.deleteErr).Once()
+			}
 
 			req := &userRegisterRequest{
 				Username:        "john",
@@ -163,11 +177,14 @@ func Test_handleUserRegister(t *testing.T) {
 
 func Test_validateUserRegister(t *testing.T) {
 	type state struct {
-		name  string
-		email string
-		uname string
-		pass  string
-		cpass string
+		name        string
+		email       string
+		uname       string
+		pass        string
+		cpass       string
+		lookup      bool
+		usernameErr error
+		emailErr    error
 	}
 	type want struct {
 		err string
@@ -179,7 +196,11 @@ func Test_validateUserRegister(t *testing.T) {
 	}
 
 	cases := []test{
-		{"happy path", state{email: "john@info.co", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John"}, want{}},
+		{"happy path", state{email: "john@info.co", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John", lookup: true, usernameErr: errNotFound, emailErr: errNotFound}, want{}},
+		{"username taken", state{email: "john@info.co", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John", lookup: true, emailErr: errNotFound}, want{"username is already taken"}},
+		{"email taken", state{email: "john@info.co", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John", lookup: true, usernameErr: errNotFound}, want{"email is already registered"}},
+		{"username lookup error", state{email: "john@info.co", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John", lookup: true, usernameErr: errFail}, want{"failed to check username: fail"}},
+		{"email lookup error", state{email: "john@info.co", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John", lookup: true, usernameErr: errNotFound, emailErr: errFail}, want{"failed to check email: fail"}},
 		{"missing email", state{email: "", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John"}, want{"email must be a valid email address"}},
 		{"invalid email", state{email: "blah", uname: "john", pass: "Test123!", cpass: "Test123!", name: "John"}, want{"email must be a valid email address"}},
 		{"missing username", state{email: "john@info.co", uname: "", pass: "Test123!", cpass: "Test123!", name: "John"}, want{"username must be at least 4 characters long"}},
@@ -202,8 +223,23 @@ func Test_validateUserRegister(t *testing.T) {
 				Name:            c.state.name,
 			}
 
-			err := validateUserRegister(req)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			if c.state.lookup {
+				mockUserDB.EXPECT().GetUserByUsername(mock.Anything, c.state.uname).Return(models.User{}, c.state.usernameErr).Once()
+				if c.state.usernameErr == nil || errors.Is(c.state.usernameErr, apierr.NotFound) {
+					mockUserDB.EXPECT().GetUserByEmail(mock.Anything, c.state.email).Return(models.User{}, c.state.emailErr).Once()
+				}
+			}
+
+			ctrl := UserController{
+				userDB: mockUserDB,
+			}
+
+			err := ctrl.validateUserRegister(context.Background(), req)
 			tests.AssertError(t, err, c.want.err)
+
+			mockUserDB.AssertExpectations(t)
 		})
 	}
 }
@@ -9,25 +9,20 @@ import (
 )
 
 type UserController struct {
-	auth   auth.AuthController
-	userDB storage.IUserStorage
+	achievementDB storage.IAchievementStorage
+	auth          auth.AuthController
+	userDB        storage.IUserStorage
 }
 
-func NewUserController(ctx context.Context, env string) (*UserController, error) {
-	storageCfg := storage.Config{Env: env}
-
-	userDB, err := storage.NewDynamoDbStorage(storageCfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize user db: %w", err)
-	}
-
+func NewUserController(ctx context.Context, stores storage.Storages) (*UserController, error) {
 	authController, err := auth.New(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize auth controller: %w", err)
 	}
 
 	return &UserController{
-		auth:   authController,
-		userDB: userDB,
+		achievementDB: stores.DynamoDb,
+		auth:          authController,
+		userDB:        stores.Core,
 	}, nil
 }
@@ -18,6 +18,11 @@ type userAuthRequest struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 	ClientID     string `json:"client_id,omitempty"`
 	ClientSecret string `json:"client_secret,omitempty"`
+
+	// Used with grant_type "mfa_code" to respond to an MFA challenge
+	ChallengeName string `json:"challenge_name,omitempty"`
+	MfaCode       string `json:"mfa_code,omitempty"`
+	Session       string `json:"session,omitempty"`
 }
 
 type userAuthResponse struct {
@@ -72,6 +77,17 @@ func (c *UserAuthController) handleUserAuth(ctx context.Context, req *userAuthRe
 			return resp, fmt.Errorf("failed to refresh token: %w", err)
 		}
 		result = authResult
+	} else if req.GrantType == auth.GrantTypeMfaCode {
+		authResult, err := c.auth.RespondToMfaChallenge(ctx, auth.MfaChallengeRequest{
+			ChallengeName: req.ChallengeName,
+			Code:          req.MfaCode,
+			Session:       req.Session,
+			Username:      req.Username,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to respond to mfa challenge: %w", err)
+		}
+		result = authResult
 	}
 
 	resp.AuthResult = result
@@ -99,6 +115,19 @@ func validateUserAuth(req *userAuthRequest) error {
 		if req.RefreshToken == "" {
 			apierr.AppendError("missing refresh_token")
 		}
+	} else if req.GrantType == auth.GrantTypeMfaCode {
+		if req.Username == "" {
+			apierr.AppendError("missing username")
+		}
+		if req.Session == "" {
+			apierr.AppendError("missing session")
+		}
+		if req.MfaCode == "" {
+			apierr.AppendError("missing mfa_code")
+		}
+		if _, ok := auth.SupportedMfaChallenges[req.ChallengeName]; !ok {
+			apierr.AppendErrorf("unsupported challenge_name \"%s\"", req.ChallengeName)
+		}
 	}
 
 	// pwResult := auth.ValidatePassword(req.Password)
@@ -94,6 +94,7 @@ func Test_handleUserAuth(t *testing.T) {
 	type state struct {
 		isPwFlow         bool
 		isRtFlow         bool
+		isMfaFlow        bool
 		hasValidationErr bool
 		authErr          error
 	}
@@ -109,9 +110,11 @@ func Test_handleUserAuth(t *testing.T) {
 	cases := []test{
 		{"happy path - password flow", state{isPwFlow: true}, want{}},
 		{"happy path - refresh token flow", state{isRtFlow: true}, want{}},
+		{"happy path - mfa code flow", state{isMfaFlow: true}, want{}},
 		{"fail - invalid input", state{isPwFlow: true, hasValidationErr: true}, want{"failed to validate request"}},
 		{"fail - password flow", state{isPwFlow: true, authErr: errFail}, want{"failed to authenticate"}},
 		{"fail - refresh token flow", state{isRtFlow: true, authErr: errFail}, want{"failed to refresh token"}},
+		{"fail - mfa code flow", state{isMfaFlow: true, authErr: errFail}, want{"failed to respond to mfa challenge"}},
 	}
 
 	for _, c := range cases {
@@ -149,6 +152,18 @@ func Test_handleUserAuth(t *testing.T) {
 						authRes, c.state.authErr)
 				}
 			}
+			if c.state.isMfaFlow {
+				req.GrantType = auth.GrantTypeMfaCode
+
+				if !c.state.hasValidationErr {
+					req.Session = "session"
+					req.MfaCode = "123456"
+					req.ChallengeName = auth.MfaChallengeSoftwareToken
+					mockAuther.EXPECT().RespondToMfaChallenge(
+						mock.Anything, mock.Anything).Return(
+						authRes, c.state.authErr)
+				}
+			}
 
 			ctx := context.Background()
 			res, err := ctrl.handleUserAuth(ctx, req)
@@ -169,6 +184,9 @@ func Test_validateUserAuth(t *testing.T) {
 		username     string
 		password     string
 		refreshToken string
+		session      string
+		mfaCode      string
+		challenge    string
 	}
 	type want struct {
 		err string
@@ -187,6 +205,10 @@ func Test_validateUserAuth(t *testing.T) {
 		{"fail granttype password - missing password", state{grantType: auth.GrantTypePassword, username: "123"}, want{"missing password"}},
 		{"fail granttype refreshtoken - missing username", state{grantType: auth.GrantTypeRefreshToken, refreshToken: "456"}, want{"missing username"}},
 		{"fail granttype refreshtoken - missing refreshtoken", state{grantType: auth.GrantTypeRefreshToken, username: "123"}, want{"missing refresh_token"}},
+		{"happy path granttype mfa code", state{grantType: auth.GrantTypeMfaCode, username: "123", session: "abc", mfaCode: "456", challenge: auth.MfaChallengeSms}, want{}},
+		{"fail granttype mfa code - missing session", state{grantType: auth.GrantTypeMfaCode, username: "123", mfaCode: "456", challenge: auth.MfaChallengeSms}, want{"missing session"}},
+		{"fail granttype mfa code - missing code", state{grantType: auth.GrantTypeMfaCode, username: "123", session: "abc", challenge: auth.MfaChallengeSms}, want{"missing mfa_code"}},
+		{"fail granttype mfa code - unsupported challenge", state{grantType: auth.GrantTypeMfaCode, username: "123", session: "abc", mfaCode: "456", challenge: "CUSTOM"}, want{"unsupported challenge_name \"CUSTOM\""}},
 	}
 
 	for _, c := range cases {
@@ -196,6 +218,10 @@ func Test_validateUserAuth(t *testing.T) {
 				Username:     c.state.username,
 				Password:     c.state.password,
 				RefreshToken: c.state.refreshToken,
+				Session:      c.state.session,
+				MfaCode:      c.state.mfaCode,
+
+				ChallengeName: c.state.challenge,
 			}
 
 			err := validateUserAuth(req)
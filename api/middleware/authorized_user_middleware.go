@@ -9,7 +9,18 @@ import (
 	"github.com/sebboness/yektaspoints/util/result"
 )
 
+// WithAuthorizedUser rejects requests of users who aren't logged in or haven't verified their email
 func WithAuthorizedUser() gin.HandlerFunc {
+	return withUser(true)
+}
+
+// WithAuthenticatedUser rejects requests of users who aren't logged in. Unlike WithAuthorizedUser,
+// it lets users with an unverified email through, i.e. so they can verify a changed email.
+func WithAuthenticatedUser() gin.HandlerFunc {
+	return withUser(false)
+}
+
+func withUser(requireVerifiedEmail bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		authInfo := handlers.GetAuthorizerInfo(c)
@@ -26,7 +37,7 @@ func WithAuthorizedUser() gin.HandlerFunc {
 			return
 		}
 
-		if !authInfo.IsEmailVerified() {
+		if requireVerifiedEmail && !authInfo.IsEmailVerified() {
 			// reject request
 			c.AbortWithStatusJSON(http.StatusUnauthorized, result.ErrorResult(fmt.Errorf("unverified user")))
 			return
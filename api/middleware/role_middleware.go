@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/sebboness/yektaspoints/util/result"
+)
+
+var roleUserDB storage.IUserStorage
+
+// UseUserStorage sets the user storage RequireRole checks the stored roles and status of users with
+func UseUserStorage(userDB storage.IUserStorage) {
+	roleUserDB = userDB
+}
+
+// RequireRole rejects requests from users that don't belong to at least one of the given roles.
+// If the token has cognito:groups, they must include one of the roles. Tokens are valid for up to
+// an hour though, so the roles stored on the user record must include one as well, and the user
+// must not be deactivated or deleted. That way, revoked roles and disabled users are rejected
+// right away.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		authInfo := handlers.GetAuthorizerInfo(c)
+
+		if !authInfo.HasInfo() || authInfo.GetUserID() == "" {
+			// reject request
+			c.AbortWithStatusJSON(http.StatusUnauthorized, result.ErrorResult(fmt.Errorf("unauthorized")))
+			return
+		}
+
+		if len(authInfo.GetGroups()) > 0 && !authInfo.HasGroup(roles...) {
+			// reject request
+			c.AbortWithStatusJSON(http.StatusForbidden, result.ErrorResult(fmt.Errorf("access denied")))
+			return
+		}
+
+		if roleUserDB == nil {
+			// reject request
+			c.AbortWithStatusJSON(http.StatusForbidden, result.ErrorResult(fmt.Errorf("access denied")))
+			return
+		}
+
+		ctx := c.Request.Context()
+		user, err := roleUserDB.GetUserByID(ctx, authInfo.GetUserID())
+		if err != nil {
+			log.Get().WithContext(ctx).WithFields(map[string]any{
+				"error":   err.Error(),
+				"user_id": authInfo.GetUserID(),
+			}).Errorf("failed to get user roles")
+
+			// reject request
+			c.AbortWithStatusJSON(http.StatusForbidden, result.ErrorResult(fmt.Errorf("access denied")))
+			return
+		}
+
+		if user.Status == models.UserStatusInactive || user.Status == models.UserStatusDeleted || !user.HasAnyRole(roles...) {
+			// reject request
+			c.AbortWithStatusJSON(http.StatusForbidden, result.ErrorResult(fmt.Errorf("access denied")))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+func Test_RequireRole(t *testing.T) {
+	type state struct {
+		hasNoAuth  bool
+		groups     any
+		userRoles  []string
+		userStatus models.UserStatus
+		getUserErr error
+	}
+	type want struct {
+		code int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - comma separated groups", state{groups: "child,parent", userRoles: []string{"parent"}}, want{http.StatusOK}},
+		{"happy path - bracketed groups", state{groups: "[admin parent]", userRoles: []string{"parent"}}, want{http.StatusOK}},
+		{"happy path - list of groups", state{groups: []any{"parent"}, userRoles: []string{"parent"}}, want{http.StatusOK}},
+		{"happy path - no groups", state{userRoles: []string{"parent"}}, want{http.StatusOK}},
+		{"fail - unauthorized", state{hasNoAuth: true}, want{http.StatusUnauthorized}},
+		{"fail - not in group", state{groups: "[child]"}, want{http.StatusForbidden}},
+		{"fail - role revoked since the token was issued", state{groups: "[parent]", userRoles: []string{"child"}}, want{http.StatusForbidden}},
+		{"fail - deactivated since the token was issued", state{groups: "[parent]", userRoles: []string{"parent"}, userStatus: models.UserStatusInactive}, want{http.StatusForbidden}},
+		{"fail - deleted", state{userRoles: []string{"parent"}, userStatus: models.UserStatusDeleted}, want{http.StatusForbidden}},
+		{"fail - stored roles don't match", state{userRoles: []string{"child"}}, want{http.StatusForbidden}},
+		{"fail - get user error", state{getUserErr: errFail}, want{http.StatusForbidden}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			UseUserStorage(mockUserDB)
+
+			claims := map[string]any{
+				"sub": "1",
+			}
+			if c.state.groups != nil {
+				claims["cognito:groups"] = c.state.groups
+			}
+
+			// stored roles are only checked if the token's groups match
+			if !c.state.hasNoAuth && c.state.groups != "[child]" {
+				status := models.UserStatusActive
+				if c.state.userStatus != "" {
+					status = c.state.userStatus
+				}
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{Roles: c.state.userRoles, Status: status}, c.state.getUserErr).Once()
+			}
+
+			evt := events.APIGatewayProxyRequest{
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Authorizer: map[string]any{"claims": claims},
+				},
+			}
+
+			if c.state.hasNoAuth {
+				evt.RequestContext.Authorizer = nil
+			}
+
+			ctx := handlers.PrepareAuthorizedContext(context.Background(), evt)
+
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.GET("/", RequireRole(models.RoleParent), func(cgin *gin.Context) {
+				cgin.Status(http.StatusOK)
+			})
+
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+			assert.Equal(t, c.want.code, w.Code)
+
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
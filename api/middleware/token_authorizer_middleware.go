@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	"github.com/sebboness/yektaspoints/util/auth"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// WithTokenAuthorizer does what the API gateway cognito authorizer does in lambda: it verifies the
+// ID token in the Authorization header and puts its claims into the request context.
+// Requests without a valid token are passed on without claims, so WithAuthorizedUser rejects them
+// on protected routes while public routes keep working.
+func WithTokenAuthorizer(verifier auth.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		token := strings.TrimSpace(c.GetHeader("Authorization"))
+		token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		claims, err := verifier.Verify(ctx, token)
+		if err != nil {
+			log.Get().WithContext(ctx).WithField("error", err.Error()).Warnf("failed to verify token")
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(handlers.PrepareAuthorizedContextWithClaims(ctx, claims))
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebboness/yektaspoints/handlers"
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_WithTokenAuthorizer(t *testing.T) {
+	type state struct {
+		header string
+		errVer error
+	}
+	type want struct {
+		userID string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - bearer token", state{header: "Bearer abc"}, want{"123"}},
+		{"happy path - raw token", state{header: "abc"}, want{"123"}},
+		{"no token", state{}, want{""}},
+		{"invalid token", state{header: "Bearer abc", errVer: errFail}, want{""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockVerifier := authmocks.NewMockTokenVerifier(t)
+			if c.state.header != "" {
+				mockVerifier.EXPECT().Verify(mock.Anything, "abc").Return(map[string]any{"sub": "123"}, c.state.errVer).Once()
+			}
+
+			userID := ""
+
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.GET("/", WithTokenAuthorizer(mockVerifier), func(cgin *gin.Context) {
+				userID = handlers.GetAuthorizerInfo(cgin).GetUserID()
+				cgin.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if c.state.header != "" {
+				req.Header.Set("Authorization", c.state.header)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, c.want.userID, userID)
+
+			mockVerifier.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// How long to wait for a new table to become active
+const tableActiveTimeout = 2 * time.Minute
+
+// DynamoDbClient is the storage client plus the table management operations migrations need
+type DynamoDbClient interface {
+	storage.DynamoDbClient
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+// Migration is a versioned change of the tables or their data. Migrations run in order of their
+// version, once per environment, and must never be changed or renumbered once released.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, m *Migrator) error
+}
+
+// AppliedMigration is the record of a migration in the metadata table
+type AppliedMigration struct {
+	Version      int       `json:"version" dynamodbav:"version"`
+	Name         string    `json:"name" dynamodbav:"name"`
+	AppliedOnStr string    `json:"-" dynamodbav:"applied_on"`
+	AppliedOn    time.Time `json:"applied_on" dynamodbav:"-"`
+}
+
+type Report struct {
+	Applied []AppliedMigration `json:"applied"`
+	Pending []AppliedMigration `json:"pending"`
+}
+
+// Migrator applies the migrations that haven't been applied to an environment yet, and records
+// the applied migrations in the environment's metadata table
+type Migrator struct {
+	client     DynamoDbClient
+	cfg        storage.Config
+	migrations []Migration
+	logger     *log.Logger
+}
+
+// NewMigrator returns a migrator of the tables of the storage config, on the config's endpoint
+func NewMigrator(ctx context.Context, cfg storage.Config) (*Migrator, error) {
+	client, err := storage.NewDynamoDbClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMigratorWithClient(client, cfg, Migrations), nil
+}
+
+func NewMigratorWithClient(client DynamoDbClient, cfg storage.Config, migrations []Migration) *Migrator {
+	migrations = slices.Clone(migrations)
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+
+	return &Migrator{
+		client:     client,
+		cfg:        cfg,
+		migrations: migrations,
+		logger:     log.Get(),
+	}
+}
+
+// TableName returns the name of the table in the migrator's storage config
+func (m *Migrator) TableName(table string) string {
+	return m.cfg.TableName(table)
+}
+
+// Client returns the client migrations use to change tables and data
+func (m *Migrator) Client() DynamoDbClient {
+	return m.client
+}
+
+// Run applies the pending migrations in order, and stops at the first one that fails. If dryRun
+// is true, the pending migrations are only reported.
+func (m *Migrator) Run(ctx context.Context, dryRun bool) (Report, error) {
+	report := Report{
+		Applied: []AppliedMigration{},
+		Pending: []AppliedMigration{},
+	}
+
+	if err := m.validate(); err != nil {
+		return report, err
+	}
+
+	applied := map[int]bool{}
+
+	exists, err := m.tableExists(ctx, m.TableName(metadataTable))
+	if err != nil {
+		return report, err
+	}
+
+	if exists {
+		records, err := m.appliedMigrations(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, r := range records {
+			applied[r.Version] = true
+		}
+	} else if !dryRun {
+		if err := m.CreateTable(ctx, metadataTableInput(m.TableName(metadataTable))); err != nil {
+			return report, err
+		}
+	}
+
+	for _, mig := range m.migrations {
+		if applied[mig.Version] {
+			continue
+		}
+
+		if dryRun {
+			report.Pending = append(report.Pending, AppliedMigration{Version: mig.Version, Name: mig.Name})
+			continue
+		}
+
+		logger := m.logger.WithContext(ctx).WithFields(map[string]any{"name": mig.Name, "version": mig.Version})
+		logger.Infof("applying migration")
+
+		if err := mig.Up(ctx, m); err != nil {
+			return report, fmt.Errorf("failed to apply migration %d (%s): %w", mig.Version, mig.Name, err)
+		}
+
+		record, err := m.recordMigration(ctx, mig)
+		if err != nil {
+			return report, err
+		}
+
+		report.Applied = append(report.Applied, record)
+	}
+
+	return report, nil
+}
+
+// CreateTable creates the table and waits for it to become active. Tables that already exist are
+// left as they are, so migrations can run against environments whose tables were created by
+// terraform.
+func (m *Migrator) CreateTable(ctx context.Context, input *dynamodb.CreateTableInput) error {
+	name := aws.ToString(input.TableName)
+
+	exists, err := m.tableExists(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		m.logger.WithContext(ctx).WithField("table", name).Infof("table already exists")
+		return nil
+	}
+
+	_, err = m.client.CreateTable(ctx, input)
+	if err != nil {
+		var inUseErr *types.ResourceInUseException
+		if errors.As(err, &inUseErr) {
+			return nil
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return fmt.Errorf("failed to create table %s: %w", name, apiErr)
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(m.client, func(o *dynamodb.TableExistsWaiterOptions) {
+		o.MinDelay = time.Second
+	})
+
+	err = waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)}, tableActiveTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to wait for table %s: %w", name, err)
+	}
+
+	m.logger.WithContext(ctx).WithField("table", name).Infof("table created")
+	return nil
+}
+
+func (m *Migrator) tableExists(ctx context.Context, name string) (bool, error) {
+	_, err := m.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(name),
+	})
+
+	if err != nil {
+		var notFoundErr *types.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return false, nil
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return false, fmt.Errorf("failed to describe table %s: %w", name, apiErr)
+	}
+
+	return true, nil
+}
+
+func (m *Migrator) appliedMigrations(ctx context.Context) ([]AppliedMigration, error) {
+	records := []AppliedMigration{}
+
+	scanPaginator := dynamodb.NewScanPaginator(m.client, &dynamodb.ScanInput{
+		TableName: aws.String(m.TableName(metadataTable)),
+	})
+
+	for scanPaginator.HasMorePages() {
+		resp, err := scanPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return records, fmt.Errorf("failed to scan applied migrations: %w", apiErr)
+		}
+
+		var page []AppliedMigration
+		if err := attributevalue.UnmarshalListOfMaps(resp.Items, &page); err != nil {
+			return records, fmt.Errorf("failed to unmarshal applied migrations: %w", err)
+		}
+
+		records = append(records, page...)
+	}
+
+	return records, nil
+}
+
+// recordMigration saves the migration as applied. Fails if another run has recorded it first.
+func (m *Migrator) recordMigration(ctx context.Context, mig Migration) (AppliedMigration, error) {
+	now := time.Now().UTC()
+
+	record := AppliedMigration{
+		Version:      mig.Version,
+		Name:         mig.Name,
+		AppliedOnStr: util.ToFormattedUTC(now),
+		AppliedOn:    now,
+	}
+
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return record, fmt.Errorf("failed to marshal map from migration: %w", err)
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("version"))).Build()
+	if err != nil {
+		return record, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	_, err = m.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(m.TableName(metadataTable)),
+		Item:                     item,
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+	})
+
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return record, apierr.New(apierr.Conflict).WithError(fmt.Sprintf("migration %d was applied by another run", mig.Version))
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return record, fmt.Errorf("failed to record migration %d: %w", mig.Version, apiErr)
+	}
+
+	return record, nil
+}
+
+func (m *Migrator) validate() error {
+	versions := []int{}
+	for _, mig := range m.migrations {
+		versions = append(versions, mig.Version)
+	}
+
+	return validateVersions(versions)
+}
+
+func validateVersions(versions []int) error {
+	seen := map[int]bool{}
+
+	for _, v := range versions {
+		if v <= 0 || seen[v] {
+			return fmt.Errorf("migration versions must be positive and unique (version=%d)", v)
+		}
+		seen[v] = true
+	}
+
+	return nil
+}
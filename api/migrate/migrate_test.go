@@ -0,0 +1,201 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/migrate"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+func describeTable(name string) any {
+	return mock.MatchedBy(func(in *dynamodb.DescribeTableInput) bool {
+		return aws.ToString(in.TableName) == name
+	})
+}
+
+func activeTable() *dynamodb.DescribeTableOutput {
+	return &dynamodb.DescribeTableOutput{
+		Table: &types.TableDescription{TableStatus: types.TableStatusActive},
+	}
+}
+
+func Test_Migrator_Run(t *testing.T) {
+	type state struct {
+		metadataExists bool
+		applied        []int
+		dryRun         bool
+		duplicate      bool
+		errUp          error
+		errRecord      error
+	}
+	type want struct {
+		err     string
+		applied []int
+		pending []int
+		ran     []int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - new environment", state{}, want{"", []int{1, 2}, []int{}, []int{1, 2}}},
+		{"happy path - some applied", state{metadataExists: true, applied: []int{1}}, want{"", []int{2}, []int{}, []int{2}}},
+		{"happy path - all applied", state{metadataExists: true, applied: []int{1, 2}}, want{"", []int{}, []int{}, nil}},
+		{"happy path - dry run", state{metadataExists: true, applied: []int{1}, dryRun: true}, want{"", []int{}, []int{2}, nil}},
+		{"happy path - dry run of new environment", state{dryRun: true}, want{"", []int{}, []int{1, 2}, nil}},
+		{"fail - migration", state{metadataExists: true, errUp: errFail}, want{"failed to apply migration 2 (two): fail", []int{1}, []int{}, []int{1, 2}}},
+		{"fail - applied by another run", state{metadataExists: true, errRecord: &types.ConditionalCheckFailedException{}}, want{"conflict: migration 1 was applied by another run", []int{}, []int{}, []int{1}}},
+		{"fail - duplicate versions", state{duplicate: true}, want{"migration versions must be positive and unique (version=2)", []int{}, []int{}, nil}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockClient := mocks.NewMockDynamoDbClient(t)
+
+			var ran []int
+			up := func(version int, err error) func(context.Context, *Migrator) error {
+				return func(ctx context.Context, m *Migrator) error {
+					ran = append(ran, version)
+					return err
+				}
+			}
+
+			// sorted by version when the migrator is created
+			migrations := []Migration{
+				{Version: 2, Name: "two", Up: up(2, c.state.errUp)},
+				{Version: 1, Name: "one", Up: up(1, nil)},
+			}
+			if c.state.duplicate {
+				migrations[1].Version = 2
+			}
+
+			metadata := "mypoints-test-migrations"
+
+			if !c.state.duplicate {
+				if c.state.metadataExists {
+					mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(metadata)).Return(activeTable(), nil).Once()
+
+					items := []map[string]types.AttributeValue{}
+					for _, v := range c.state.applied {
+						items = append(items, map[string]types.AttributeValue{
+							"version": &types.AttributeValueMemberN{Value: strconv.Itoa(v)},
+						})
+					}
+					mockClient.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: items}, nil).Once()
+				} else {
+					mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(metadata)).Return(nil, &types.ResourceNotFoundException{}).Once()
+				}
+			}
+
+			if !c.state.metadataExists && !c.state.dryRun && !c.state.duplicate {
+				// checked again before creating the table, then waited for
+				mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(metadata)).Return(nil, &types.ResourceNotFoundException{}).Once()
+				mockClient.EXPECT().CreateTable(mock.Anything, mock.MatchedBy(func(in *dynamodb.CreateTableInput) bool {
+					return aws.ToString(in.TableName) == metadata
+				})).Return(&dynamodb.CreateTableOutput{}, nil).Once()
+				mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(metadata), mock.Anything).Return(activeTable(), nil).Once()
+			}
+
+			records := len(c.want.applied)
+			if c.state.errRecord != nil {
+				records = 1
+			}
+			if records > 0 {
+				mockClient.EXPECT().PutItem(mock.Anything, mock.MatchedBy(func(in *dynamodb.PutItemInput) bool {
+					return aws.ToString(in.TableName) == metadata && aws.ToString(in.ConditionExpression) == "attribute_not_exists (#0)"
+				})).Return(&dynamodb.PutItemOutput{}, c.state.errRecord).Times(records)
+			}
+
+			m := NewMigratorWithClient(mockClient, storage.Config{Env: "test"}, migrations)
+
+			report, err := m.Run(context.Background(), c.state.dryRun)
+			tests.AssertError(t, err, c.want.err)
+
+			versions := func(records []AppliedMigration) []int {
+				v := []int{}
+				for _, r := range records {
+					v = append(v, r.Version)
+				}
+				return v
+			}
+
+			if c.state.errUp != nil {
+				// the report isn't complete when a migration fails
+				assert.Equal(t, c.want.ran, ran)
+				mockClient.AssertExpectations(t)
+				return
+			}
+
+			assert.Equal(t, c.want.applied, versions(report.Applied))
+			assert.Equal(t, c.want.pending, versions(report.Pending))
+			assert.Equal(t, c.want.ran, ran)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Migrator_CreateTable(t *testing.T) {
+	type state struct {
+		exists      bool
+		errDescribe error
+		errCreate   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"happy path - already exists", state{exists: true}, want{}},
+		{"happy path - created in the meantime", state{errCreate: &types.ResourceInUseException{}}, want{}},
+		{"fail - describe table", state{errDescribe: errFail}, want{"failed to describe table mypoints-test-audit: fail"}},
+		{"fail - create table", state{errCreate: errFail}, want{"failed to create table mypoints-test-audit: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockClient := mocks.NewMockDynamoDbClient(t)
+			name := "mypoints-test-audit"
+
+			if c.state.exists {
+				mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(name)).Return(activeTable(), nil).Once()
+			} else if c.state.errDescribe != nil {
+				mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(name)).Return(nil, c.state.errDescribe).Once()
+			} else {
+				mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(name)).Return(nil, &types.ResourceNotFoundException{}).Once()
+				mockClient.EXPECT().CreateTable(mock.Anything, mock.Anything).Return(&dynamodb.CreateTableOutput{}, c.state.errCreate).Once()
+
+				if c.state.errCreate == nil {
+					mockClient.EXPECT().DescribeTable(mock.Anything, describeTable(name), mock.Anything).Return(activeTable(), nil).Once()
+				}
+			}
+
+			m := NewMigratorWithClient(mockClient, storage.Config{Env: "test"}, nil)
+
+			err := m.CreateTable(context.Background(), tableInput(m.TableName("audit"), "user_id", "id"))
+			tests.AssertError(t, err, c.want.err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+// Table with the applied migrations of an environment
+const metadataTable = "migrations"
+
+// Migrations are all migrations, in order. Append new migrations with the next version.
+var Migrations = []Migration{
+	{Version: 1, Name: "create tables", Up: createTables},
+	{Version: 2, Name: "backfill point balances", Up: backfillPointBalances},
+	{Version: 3, Name: "create family event table", Up: createFamilyEventTable},
+	{Version: 4, Name: "create point balance table", Up: createPointBalanceTable},
+}
+
+// createTables creates the tables and indexes of the storage package. Matches the tables in
+// deploy/terraform/dynamodb.tf, except that all tables are billed on demand.
+func createTables(ctx context.Context, m *Migrator) error {
+	tables := []*dynamodb.CreateTableInput{
+		tableInput(m.TableName(storage.TablePoints), "user_id", "id",
+			localIndex("user_id", "updated_on")),
+		tableInput(m.TableName(storage.TableUser), "user_id", "",
+			globalIndex("email"),
+			globalIndex("username")),
+		tableInput(m.TableName(storage.TableFamilyUser), "family_id", "user_id"),
+		tableInput(m.TableName(storage.TableFamilySettings), "family_id", ""),
+		tableInput(m.TableName(storage.TableAchievement), "user_id", "rule_id"),
+		tableInput(m.TableName(storage.TableAchievementRule), "family_id", "id"),
+		tableInput(m.TableName(storage.TablePointComment), "user_id", "id",
+			localIndex("user_id", "point_id")),
+		tableInput(m.TableName(storage.TablePointType), "family_id", "id"),
+		tableInput(m.TableName(storage.TableAudit), "user_id", "id"),
+		tableInput(m.TableName(storage.TableWebhook), "family_id", "id"),
+		tableInput(m.TableName(storage.TableWebhookDelivery), "webhook_id", "id"),
+	}
+
+	for _, t := range tables {
+		if err := m.CreateTable(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillPointBalances sets the running balance of settled points that were saved before points
+// had balances. Balances are kept per user and point type, in the order points were updated, and
+// continue from the last point that already has a balance. Denied requests are settled without
+// changing the balance, so they get the balance of the point before them.
+func backfillPointBalances(ctx context.Context, m *Migrator) error {
+	table := m.TableName(storage.TablePoints)
+
+	proj := expression.NamesList(
+		expression.Name("user_id"),
+		expression.Name("id"),
+		expression.Name("status"),
+		expression.Name("points"),
+		expression.Name("point_type_id"),
+		expression.Name("balance"),
+		expression.Name("updated_on"),
+		expression.Name("request.decision"),
+	)
+	projExpr, err := expression.NewBuilder().WithProjection(proj).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build projection expression: %w", err)
+	}
+
+	ledgers := map[string][]models.Point{}
+
+	scanPaginator := dynamodb.NewScanPaginator(m.client, &dynamodb.ScanInput{
+		TableName:                aws.String(table),
+		ExpressionAttributeNames: projExpr.Names(),
+		ProjectionExpression:     projExpr.Projection(),
+	})
+
+	for scanPaginator.HasMorePages() {
+		resp, err := scanPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return fmt.Errorf("failed to scan points: %w", apiErr)
+		}
+
+		var points []models.Point
+		if err := attributevalue.UnmarshalListOfMaps(resp.Items, &points); err != nil {
+			return fmt.Errorf("failed to unmarshal points: %w", err)
+		}
+
+		for _, p := range points {
+			if p.Status != models.PointStatusSettled {
+				continue
+			}
+
+			key := p.UserID + "/" + p.TypeID()
+			ledgers[key] = append(ledgers[key], p)
+		}
+	}
+
+	updated := 0
+	for _, ledger := range ledgers {
+		slices.SortStableFunc(ledger, func(a, b models.Point) int {
+			if c := cmp.Compare(a.UpdatedOnStr, b.UpdatedOnStr); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ID, b.ID)
+		})
+
+		balance := 0
+		for _, p := range ledger {
+			if p.Balance != nil {
+				balance = *p.Balance
+				continue
+			}
+
+			if p.Request.Decision != models.PointRequestDecisionDeny {
+				balance += p.Points
+			}
+			if err := setPointBalance(ctx, m.client, table, p, balance); err != nil {
+				return err
+			}
+			updated++
+		}
+	}
+
+	m.logger.WithContext(ctx).WithField("points", updated).Infof("backfilled point balances")
+	return nil
+}
+
+// setPointBalance sets the balance of the point, unless a balance was set in the meantime
+func setPointBalance(ctx context.Context, client DynamoDbClient, table string, p models.Point, balance int) error {
+	update := expression.Set(expression.Name("balance"), expression.Value(balance)).
+		Add(expression.Name("version"), expression.Value(1))
+	cond := expression.AttributeExists(expression.Name("id")).
+		And(expression.AttributeNotExists(expression.Name("balance")))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	key, err := attributevalue.MarshalMap(map[string]string{
+		"user_id": p.UserID,
+		"id":      p.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+
+	if err != nil {
+		// the balance was set since the points were scanned
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return nil
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return fmt.Errorf("failed to set balance of point (id=%s): %w", p.ID, apiErr)
+	}
+
+	return nil
+}
+
+// createFamilyEventTable creates the table of family events, whose range key is the number of the
+// event within its family. Expired events are only removed where the table's TTL is turned on.
+func createFamilyEventTable(ctx context.Context, m *Migrator) error {
+	input := tableInput(m.TableName(storage.TableFamilyEvent), "family_id", "seq")
+	input.AttributeDefinitions[1].AttributeType = types.ScalarAttributeTypeN
+
+	return m.CreateTable(ctx, input)
+}
+
+// createPointBalanceTable creates the table of the users' balances of each point type. Balances
+// that weren't saved yet are read from the users' latest settled points, so they aren't backfilled.
+func createPointBalanceTable(ctx context.Context, m *Migrator) error {
+	return m.CreateTable(ctx, tableInput(m.TableName(storage.TablePointBalance), "user_id", "point_type_id"))
+}
+
+type index struct {
+	global   bool
+	hashKey  string
+	rangeKey string
+}
+
+// globalIndex is an index named "<hashKey>-index" with all attributes
+func globalIndex(hashKey string) index {
+	return index{global: true, hashKey: hashKey}
+}
+
+// localIndex is an index named "<rangeKey>-index" with all attributes
+func localIndex(hashKey, rangeKey string) index {
+	return index{hashKey: hashKey, rangeKey: rangeKey}
+}
+
+// tableInput returns the input for creating an on demand table with string keys. The range key
+// is optional.
+func tableInput(name, hashKey, rangeKey string, indexes ...index) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(name),
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema:   keySchema(hashKey, rangeKey),
+	}
+
+	attribs := []string{hashKey, rangeKey}
+
+	for _, idx := range indexes {
+		attribs = append(attribs, idx.hashKey, idx.rangeKey)
+
+		if idx.global {
+			input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+				IndexName:  aws.String(idx.hashKey + "-index"),
+				KeySchema:  keySchema(idx.hashKey, idx.rangeKey),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			})
+		} else {
+			input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+				IndexName:  aws.String(idx.rangeKey + "-index"),
+				KeySchema:  keySchema(idx.hashKey, idx.rangeKey),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			})
+		}
+	}
+
+	for _, a := range attribs {
+		if a == "" || slices.ContainsFunc(input.AttributeDefinitions, func(d types.AttributeDefinition) bool {
+			return aws.ToString(d.AttributeName) == a
+		}) {
+			continue
+		}
+
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(a),
+			AttributeType: types.ScalarAttributeTypeS,
+		})
+	}
+
+	return input
+}
+
+func keySchema(hashKey, rangeKey string) []types.KeySchemaElement {
+	schema := []types.KeySchemaElement{
+		{AttributeName: aws.String(hashKey), KeyType: types.KeyTypeHash},
+	}
+
+	if rangeKey != "" {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(rangeKey), KeyType: types.KeyTypeRange})
+	}
+
+	return schema
+}
+
+func metadataTableInput(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		TableName:   aws.String(name),
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("version"), KeyType: types.KeyTypeHash},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("version"), AttributeType: types.ScalarAttributeTypeN},
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package migrate
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/migrate"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Migrations(t *testing.T) {
+	for idx, mig := range Migrations {
+		assert.Equal(t, idx+1, mig.Version, "migrations must be numbered in order")
+		assert.NotEmpty(t, mig.Name)
+		assert.NotNil(t, mig.Up)
+	}
+}
+
+func Test_createTables(t *testing.T) {
+	mockClient := mocks.NewMockDynamoDbClient(t)
+
+	// all tables already exist, except for the points table
+	mockClient.EXPECT().DescribeTable(mock.Anything, describeTable("mypoints-test-points")).Return(nil, &types.ResourceNotFoundException{}).Once()
+	mockClient.EXPECT().DescribeTable(mock.Anything, mock.Anything).Return(activeTable(), nil).Times(10)
+	mockClient.EXPECT().CreateTable(mock.Anything, mock.MatchedBy(func(in *dynamodb.CreateTableInput) bool {
+		return aws.ToString(in.TableName) == "mypoints-test-points" &&
+			len(in.KeySchema) == 2 &&
+			len(in.AttributeDefinitions) == 3 &&
+			aws.ToString(in.LocalSecondaryIndexes[0].IndexName) == "updated_on-index"
+	})).Return(&dynamodb.CreateTableOutput{}, nil).Once()
+	mockClient.EXPECT().DescribeTable(mock.Anything, describeTable("mypoints-test-points"), mock.Anything).Return(activeTable(), nil).Once()
+
+	m := NewMigratorWithClient(mockClient, storage.Config{Env: "test"}, nil)
+
+	err := createTables(context.Background(), m)
+	assert.Nil(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func Test_createFamilyEventTable(t *testing.T) {
+	mockClient := mocks.NewMockDynamoDbClient(t)
+
+	mockClient.EXPECT().DescribeTable(mock.Anything, describeTable("mypoints-test-family-event")).Return(nil, &types.ResourceNotFoundException{}).Once()
+	mockClient.EXPECT().CreateTable(mock.Anything, mock.MatchedBy(func(in *dynamodb.CreateTableInput) bool {
+		return aws.ToString(in.TableName) == "mypoints-test-family-event" &&
+			len(in.KeySchema) == 2 &&
+			aws.ToString(in.AttributeDefinitions[1].AttributeName) == "seq" &&
+			in.AttributeDefinitions[1].AttributeType == types.ScalarAttributeTypeN
+	})).Return(&dynamodb.CreateTableOutput{}, nil).Once()
+	mockClient.EXPECT().DescribeTable(mock.Anything, describeTable("mypoints-test-family-event"), mock.Anything).Return(activeTable(), nil).Once()
+
+	m := NewMigratorWithClient(mockClient, storage.Config{Env: "test"}, nil)
+
+	err := createFamilyEventTable(context.Background(), m)
+	assert.Nil(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func Test_createPointBalanceTable(t *testing.T) {
+	mockClient := mocks.NewMockDynamoDbClient(t)
+
+	mockClient.EXPECT().DescribeTable(mock.Anything, describeTable("mypoints-test-point-balance")).Return(nil, &types.ResourceNotFoundException{}).Once()
+	mockClient.EXPECT().CreateTable(mock.Anything, mock.MatchedBy(func(in *dynamodb.CreateTableInput) bool {
+		return aws.ToString(in.TableName) == "mypoints-test-point-balance" &&
+			len(in.KeySchema) == 2 &&
+			aws.ToString(in.KeySchema[0].AttributeName) == "user_id" &&
+			aws.ToString(in.KeySchema[1].AttributeName) == "point_type_id"
+	})).Return(&dynamodb.CreateTableOutput{}, nil).Once()
+	mockClient.EXPECT().DescribeTable(mock.Anything, describeTable("mypoints-test-point-balance"), mock.Anything).Return(activeTable(), nil).Once()
+
+	m := NewMigratorWithClient(mockClient, storage.Config{Env: "test"}, nil)
+
+	err := createPointBalanceTable(context.Background(), m)
+	assert.Nil(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func Test_tableInput(t *testing.T) {
+	input := tableInput("user", "user_id", "", globalIndex("email"), globalIndex("username"))
+
+	assert.Len(t, input.KeySchema, 1)
+	assert.Len(t, input.AttributeDefinitions, 3)
+	assert.Len(t, input.GlobalSecondaryIndexes, 2)
+	assert.Equal(t, "email-index", aws.ToString(input.GlobalSecondaryIndexes[0].IndexName))
+	assert.Len(t, input.GlobalSecondaryIndexes[1].KeySchema, 1)
+	assert.Equal(t, types.BillingModePayPerRequest, input.BillingMode)
+}
+
+func Test_backfillPointBalances(t *testing.T) {
+	type state struct {
+		errScan   error
+		errUpdate error
+	}
+	type want struct {
+		err      string
+		balances map[string]int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	// the denied request keeps the balance of the point before it
+	all := map[string]int{"p1": 5, "p2": 8, "p6": 8, "p5": 6}
+
+	cases := []test{
+		{"happy path", state{}, want{"", all}},
+		{"happy path - balance set in the meantime", state{errUpdate: &types.ConditionalCheckFailedException{}}, want{"", all}},
+		{"fail - scan", state{errScan: errFail}, want{"failed to scan points: fail", nil}},
+		{"fail - update", state{errUpdate: errFail}, want{"failed to set balance of point", nil}},
+	}
+
+	point := func(id, typeID string, status models.PointStatus, points int, balance *int, updatedOn string) map[string]types.AttributeValue {
+		return pointItem(models.Point{
+			UserID:       "u1",
+			ID:           id,
+			PointTypeID:  typeID,
+			Status:       status,
+			Points:       points,
+			Balance:      balance,
+			UpdatedOnStr: updatedOn,
+		})
+	}
+	denied := pointItem(models.Point{UserID: "u1", ID: "p6", Status: models.PointStatusSettled, Points: 20, UpdatedOnStr: "2024-01-02",
+		Request: models.PointRequest{Decision: models.PointRequestDecisionDeny}})
+
+	ten := 10
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockClient := mocks.NewMockDynamoDbClient(t)
+
+			// scanned in no particular order
+			mockClient.EXPECT().Scan(mock.Anything, mock.MatchedBy(func(in *dynamodb.ScanInput) bool {
+				return aws.ToString(in.TableName) == "mypoints-test-points" &&
+					strings.Contains(aws.ToString(in.ProjectionExpression), ".") // request.decision
+			}), mock.Anything).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{
+				denied,
+				point("p2", "", models.PointStatusSettled, 3, nil, "2024-01-02"),
+				point("p5", "st", models.PointStatusSettled, -4, nil, "2024-01-03"),
+				point("p3", "", models.PointStatusWaiting, 7, nil, "2024-01-03"),
+				point("p1", "", models.PointStatusSettled, 5, nil, "2024-01-01"),
+				point("p4", "st", models.PointStatusSettled, 10, &ten, "2024-01-01"),
+			}}, c.state.errScan).Once()
+
+			balances := map[string]int{}
+
+			if c.state.errScan == nil {
+				updates := mockClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).
+					RunAndReturn(func(ctx context.Context, in *dynamodb.UpdateItemInput, f ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						var key struct {
+							ID string `dynamodbav:"id"`
+						}
+						_ = attributevalue.UnmarshalMap(in.Key, &key)
+
+						// the values are the balance and the version increment (1)
+						for _, v := range in.ExpressionAttributeValues {
+							if n, ok := v.(*types.AttributeValueMemberN); ok && n.Value != "1" {
+								balances[key.ID], _ = strconv.Atoi(n.Value)
+							}
+						}
+
+						return &dynamodb.UpdateItemOutput{}, c.state.errUpdate
+					})
+
+				if c.state.errUpdate == errFail {
+					updates.Once()
+				} else {
+					updates.Times(4)
+				}
+			}
+
+			m := NewMigratorWithClient(mockClient, storage.Config{Env: "test"}, nil)
+
+			err := backfillPointBalances(context.Background(), m)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, c.want.balances, balances)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func pointItem(p models.Point) map[string]types.AttributeValue {
+	item, _ := attributevalue.MarshalMap(p)
+	return item
+}
@@ -0,0 +1,260 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// Table with the applied migrations of a SQL database
+const sqlMetadataTable = "schema_migrations"
+
+// SQLMigration is a versioned change of the tables of the SQL storage backends. Like migrations,
+// they must never be changed or renumbered once released.
+type SQLMigration struct {
+	Version int
+	Name    string
+
+	// Statements returns the statements of the migration in the dialect (postgres or sqlite)
+	Statements func(dialect string) []string
+}
+
+// SQLMigrations are all migrations of the SQL storage backends, in order. Append new migrations
+// with the next version.
+var SQLMigrations = []SQLMigration{
+	{Version: 1, Name: "create tables", Statements: createTablesSQL},
+	{Version: 2, Name: "create point balances table", Statements: createPointBalancesTableSQL},
+}
+
+// createTablesSQL creates the tables of the SQL storage. Keys and dates are compared byte by
+// byte like in DynamoDB, which is the default in SQLite, but needs the "C" collation in
+// PostgreSQL.
+func createTablesSQL(dialect string) []string {
+	text := "TEXT"
+	if dialect == storage.BackendPostgres {
+		text = `TEXT COLLATE "C"`
+	}
+
+	return []string{
+		`CREATE TABLE points (
+			user_id      ` + text + ` NOT NULL,
+			id           ` + text + ` NOT NULL,
+			status       TEXT NOT NULL,
+			request_type TEXT NOT NULL,
+			created_on   ` + text + ` NOT NULL,
+			updated_on   ` + text + ` NOT NULL,
+			version      INTEGER NOT NULL,
+			data         TEXT NOT NULL,
+			PRIMARY KEY (user_id, id)
+		)`,
+		`CREATE INDEX points_updated_on_idx ON points (user_id, updated_on)`,
+		`CREATE TABLE users (
+			user_id  ` + text + ` NOT NULL PRIMARY KEY,
+			email    TEXT,
+			username TEXT,
+			version  INTEGER NOT NULL,
+			data     TEXT NOT NULL
+		)`,
+		`CREATE INDEX users_email_idx ON users (email)`,
+		`CREATE INDEX users_username_idx ON users (username)`,
+		`CREATE TABLE family_users (
+			family_id ` + text + ` NOT NULL,
+			user_id   ` + text + ` NOT NULL,
+			PRIMARY KEY (family_id, user_id)
+		)`,
+		`CREATE TABLE family_settings (
+			family_id ` + text + ` NOT NULL PRIMARY KEY,
+			data      TEXT NOT NULL
+		)`,
+	}
+}
+
+// createPointBalancesTableSQL creates the table of the users' balances of each point type
+func createPointBalancesTableSQL(dialect string) []string {
+	text := "TEXT"
+	if dialect == storage.BackendPostgres {
+		text = `TEXT COLLATE "C"`
+	}
+
+	return []string{
+		`CREATE TABLE point_balances (
+			user_id       ` + text + ` NOT NULL,
+			point_type_id ` + text + ` NOT NULL,
+			balance       INTEGER NOT NULL,
+			version       INTEGER NOT NULL,
+			PRIMARY KEY (user_id, point_type_id)
+		)`,
+	}
+}
+
+// SQLMigrator applies the migrations that haven't been applied to a SQL database yet, and records
+// the applied migrations in the database's metadata table
+type SQLMigrator struct {
+	storage    *storage.SQLStorage
+	migrations []SQLMigration
+	logger     *log.Logger
+}
+
+func NewSQLMigrator(s *storage.SQLStorage, migrations []SQLMigration) *SQLMigrator {
+	migrations = slices.Clone(migrations)
+	slices.SortFunc(migrations, func(a, b SQLMigration) int {
+		return a.Version - b.Version
+	})
+
+	return &SQLMigrator{
+		storage:    s,
+		migrations: migrations,
+		logger:     log.Get(),
+	}
+}
+
+// Run applies the pending migrations in order, each in its own transaction, and stops at the
+// first one that fails. If dryRun is true, the pending migrations are only reported.
+func (m *SQLMigrator) Run(ctx context.Context, dryRun bool) (Report, error) {
+	report := Report{
+		Applied: []AppliedMigration{},
+		Pending: []AppliedMigration{},
+	}
+
+	versions := []int{}
+	for _, mig := range m.migrations {
+		versions = append(versions, mig.Version)
+	}
+	if err := validateVersions(versions); err != nil {
+		return report, err
+	}
+
+	applied := map[int]bool{}
+
+	exists, err := m.metadataTableExists(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	if exists {
+		applied, err = m.appliedVersions(ctx)
+		if err != nil {
+			return report, err
+		}
+	} else if !dryRun {
+		_, err := m.storage.DB().ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			version    INTEGER NOT NULL PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_on TEXT NOT NULL
+		)`, sqlMetadataTable))
+		if err != nil {
+			return report, fmt.Errorf("failed to create table %s: %w", sqlMetadataTable, err)
+		}
+	}
+
+	for _, mig := range m.migrations {
+		if applied[mig.Version] {
+			continue
+		}
+
+		if dryRun {
+			report.Pending = append(report.Pending, AppliedMigration{Version: mig.Version, Name: mig.Name})
+			continue
+		}
+
+		logger := m.logger.WithContext(ctx).WithFields(map[string]any{"name": mig.Name, "version": mig.Version})
+		logger.Infof("applying migration")
+
+		record, err := m.apply(ctx, mig)
+		if err != nil {
+			return report, err
+		}
+
+		report.Applied = append(report.Applied, record)
+	}
+
+	return report, nil
+}
+
+// apply runs the statements of the migration and records it in a single transaction, so a
+// migration is either applied and recorded or not at all
+func (m *SQLMigrator) apply(ctx context.Context, mig SQLMigration) (AppliedMigration, error) {
+	now := time.Now().UTC()
+
+	record := AppliedMigration{
+		Version:      mig.Version,
+		Name:         mig.Name,
+		AppliedOnStr: util.ToFormattedUTC(now),
+		AppliedOn:    now,
+	}
+
+	tx, err := m.storage.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return record, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, stmt := range mig.Statements(m.storage.Dialect()) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return record, fmt.Errorf("failed to apply migration %d (%s): %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, m.storage.Rebind(fmt.Sprintf(
+		"INSERT INTO %s (version, name, applied_on) VALUES (?, ?, ?) ON CONFLICT (version) DO NOTHING", sqlMetadataTable)),
+		record.Version, record.Name, record.AppliedOnStr)
+	if err != nil {
+		return record, fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return record, apierr.New(apierr.Conflict).WithError(fmt.Sprintf("migration %d was applied by another run", mig.Version))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return record, fmt.Errorf("failed to commit migration %d: %w", mig.Version, err)
+	}
+
+	return record, nil
+}
+
+func (m *SQLMigrator) metadataTableExists(ctx context.Context) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if m.storage.Dialect() == storage.BackendPostgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	}
+
+	var n int
+	if err := m.storage.DB().QueryRowContext(ctx, m.storage.Rebind(query), sqlMetadataTable).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", sqlMetadataTable, err)
+	}
+
+	return n > 0, nil
+}
+
+func (m *SQLMigrator) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	applied := map[int]bool{}
+
+	rows, err := m.storage.DB().QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", sqlMetadataTable))
+	if err != nil {
+		return applied, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return applied, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return applied, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
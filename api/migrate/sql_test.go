@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SQLMigrator_Run(t *testing.T) {
+	type state struct {
+		applied   []int
+		dryRun    bool
+		duplicate bool
+		failing   bool
+	}
+	type want struct {
+		err     string
+		applied []int
+		pending []int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - new database", state{}, want{"", []int{1, 2}, []int{}}},
+		{"happy path - some applied", state{applied: []int{1}}, want{"", []int{2}, []int{}}},
+		{"happy path - all applied", state{applied: []int{1, 2}}, want{"", []int{}, []int{}}},
+		{"happy path - dry run", state{applied: []int{1}, dryRun: true}, want{"", []int{}, []int{2}}},
+		{"happy path - dry run of new database", state{dryRun: true}, want{"", []int{}, []int{1, 2}}},
+		{"fail - statement", state{failing: true}, want{"failed to apply migration 2 (two)", []int{1}, []int{}}},
+		{"fail - duplicate versions", state{duplicate: true}, want{"migration versions must be positive and unique (version=2)", []int{}, []int{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			s, err := storage.NewSQLStorage(storage.Config{
+				Backend: storage.BackendSQLite,
+				DSN:     fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")),
+			})
+			assert.Nil(t, err)
+			defer s.Close()
+
+			stmts := func(sql ...string) func(string) []string {
+				return func(string) []string { return sql }
+			}
+
+			// sorted by version when the migrator is created
+			migrations := []SQLMigration{
+				{Version: 2, Name: "two", Statements: stmts("CREATE TABLE two (id TEXT)")},
+				{Version: 1, Name: "one", Statements: stmts("CREATE TABLE one (id TEXT)")},
+			}
+			if c.state.failing {
+				migrations[0].Statements = stmts("CREATE TABLE two (id TEXT)", "CREATE TABLE one (id TEXT)")
+			}
+			if c.state.duplicate {
+				migrations[1].Version = 2
+			}
+
+			if len(c.state.applied) > 0 {
+				_, err := NewSQLMigrator(s, migrations[len(migrations)-len(c.state.applied):]).Run(ctx, false)
+				assert.Nil(t, err)
+			}
+
+			report, err := NewSQLMigrator(s, migrations).Run(ctx, c.state.dryRun)
+			tests.AssertError(t, err, c.want.err)
+
+			versions := func(records []AppliedMigration) []int {
+				v := []int{}
+				for _, r := range records {
+					v = append(v, r.Version)
+				}
+				return v
+			}
+
+			assert.Equal(t, c.want.applied, versions(report.Applied))
+			assert.Equal(t, c.want.pending, versions(report.Pending))
+
+			if c.state.failing {
+				// the failed migration is rolled back
+				var n int
+				err := s.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'two'").Scan(&n)
+				assert.Nil(t, err)
+				assert.Equal(t, 0, n)
+			}
+		})
+	}
+}
+
+func Test_SQLMigrations(t *testing.T) {
+	for idx, mig := range SQLMigrations {
+		assert.Equal(t, idx+1, mig.Version, "migrations must be numbered in order")
+		assert.NotEmpty(t, mig.Name)
+		assert.NotEmpty(t, mig.Statements(storage.BackendSQLite))
+		assert.Len(t, mig.Statements(storage.BackendPostgres), len(mig.Statements(storage.BackendSQLite)))
+	}
+
+	assert.Contains(t, createTablesSQL(storage.BackendPostgres)[0], `user_id      TEXT COLLATE "C" NOT NULL`)
+}
@@ -6,7 +6,6 @@ import (
 	context "context"
 
 	auth "github.com/sebboness/yektaspoints/util/auth"
-
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -71,6 +70,63 @@ func (_c *MockAuthController_AssignUserToRole_Call) RunAndReturn(run func(contex
 	return _c
 }
 
+// AssociateSoftwareToken provides a mock function with given fields: ctx, accessToken
+func (_m *MockAuthController) AssociateSoftwareToken(ctx context.Context, accessToken string) (auth.SoftwareTokenResult, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssociateSoftwareToken")
+	}
+
+	var r0 auth.SoftwareTokenResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (auth.SoftwareTokenResult, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) auth.SoftwareTokenResult); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		r0 = ret.Get(0).(auth.SoftwareTokenResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthController_AssociateSoftwareToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssociateSoftwareToken'
+type MockAuthController_AssociateSoftwareToken_Call struct {
+	*mock.Call
+}
+
+// AssociateSoftwareToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accessToken string
+func (_e *MockAuthController_Expecter) AssociateSoftwareToken(ctx interface{}, accessToken interface{}) *MockAuthController_AssociateSoftwareToken_Call {
+	return &MockAuthController_AssociateSoftwareToken_Call{Call: _e.mock.On("AssociateSoftwareToken", ctx, accessToken)}
+}
+
+func (_c *MockAuthController_AssociateSoftwareToken_Call) Run(run func(ctx context.Context, accessToken string)) *MockAuthController_AssociateSoftwareToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_AssociateSoftwareToken_Call) Return(_a0 auth.SoftwareTokenResult, _a1 error) *MockAuthController_AssociateSoftwareToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthController_AssociateSoftwareToken_Call) RunAndReturn(run func(context.Context, string) (auth.SoftwareTokenResult, error)) *MockAuthController_AssociateSoftwareToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Authenticate provides a mock function with given fields: ctx, username, password
 func (_m *MockAuthController) Authenticate(ctx context.Context, username string, password string) (auth.AuthResult, error) {
 	ret := _m.Called(ctx, username, password)
@@ -177,6 +233,265 @@ func (_c *MockAuthController_ConfirmRegistration_Call) RunAndReturn(run func(con
 	return _c
 }
 
+// DeleteUser provides a mock function with given fields: ctx, username
+func (_m *MockAuthController) DeleteUser(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type MockAuthController_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockAuthController_Expecter) DeleteUser(ctx interface{}, username interface{}) *MockAuthController_DeleteUser_Call {
+	return &MockAuthController_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, username)}
+}
+
+func (_c *MockAuthController_DeleteUser_Call) Run(run func(ctx context.Context, username string)) *MockAuthController_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_DeleteUser_Call) Return(_a0 error) *MockAuthController_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_DeleteUser_Call) RunAndReturn(run func(context.Context, string) error) *MockAuthController_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DisableUser provides a mock function with given fields: ctx, username
+func (_m *MockAuthController) DisableUser(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_DisableUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableUser'
+type MockAuthController_DisableUser_Call struct {
+	*mock.Call
+}
+
+// DisableUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockAuthController_Expecter) DisableUser(ctx interface{}, username interface{}) *MockAuthController_DisableUser_Call {
+	return &MockAuthController_DisableUser_Call{Call: _e.mock.On("DisableUser", ctx, username)}
+}
+
+func (_c *MockAuthController_DisableUser_Call) Run(run func(ctx context.Context, username string)) *MockAuthController_DisableUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_DisableUser_Call) Return(_a0 error) *MockAuthController_DisableUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_DisableUser_Call) RunAndReturn(run func(context.Context, string) error) *MockAuthController_DisableUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnableUser provides a mock function with given fields: ctx, username
+func (_m *MockAuthController) EnableUser(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_EnableUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnableUser'
+type MockAuthController_EnableUser_Call struct {
+	*mock.Call
+}
+
+// EnableUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockAuthController_Expecter) EnableUser(ctx interface{}, username interface{}) *MockAuthController_EnableUser_Call {
+	return &MockAuthController_EnableUser_Call{Call: _e.mock.On("EnableUser", ctx, username)}
+}
+
+func (_c *MockAuthController_EnableUser_Call) Run(run func(ctx context.Context, username string)) *MockAuthController_EnableUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_EnableUser_Call) Return(_a0 error) *MockAuthController_EnableUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_EnableUser_Call) RunAndReturn(run func(context.Context, string) error) *MockAuthController_EnableUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUserIDsByAttribute provides a mock function with given fields: ctx, attribute, value
+func (_m *MockAuthController) ListUserIDsByAttribute(ctx context.Context, attribute string, value string) ([]string, error) {
+	ret := _m.Called(ctx, attribute, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUserIDsByAttribute")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, attribute, value)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, attribute, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, attribute, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthController_ListUserIDsByAttribute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUserIDsByAttribute'
+type MockAuthController_ListUserIDsByAttribute_Call struct {
+	*mock.Call
+}
+
+// ListUserIDsByAttribute is a helper method to define mock.On call
+//   - ctx context.Context
+//   - attribute string
+//   - value string
+func (_e *MockAuthController_Expecter) ListUserIDsByAttribute(ctx interface{}, attribute interface{}, value interface{}) *MockAuthController_ListUserIDsByAttribute_Call {
+	return &MockAuthController_ListUserIDsByAttribute_Call{Call: _e.mock.On("ListUserIDsByAttribute", ctx, attribute, value)}
+}
+
+func (_c *MockAuthController_ListUserIDsByAttribute_Call) Run(run func(ctx context.Context, attribute string, value string)) *MockAuthController_ListUserIDsByAttribute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_ListUserIDsByAttribute_Call) Return(_a0 []string, _a1 error) *MockAuthController_ListUserIDsByAttribute_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthController_ListUserIDsByAttribute_Call) RunAndReturn(run func(context.Context, string, string) ([]string, error)) *MockAuthController_ListUserIDsByAttribute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function with given fields: ctx
+func (_m *MockAuthController) ListUsers(ctx context.Context) ([]auth.PoolUser, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []auth.PoolUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]auth.PoolUser, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []auth.PoolUser); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]auth.PoolUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthController_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type MockAuthController_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAuthController_Expecter) ListUsers(ctx interface{}) *MockAuthController_ListUsers_Call {
+	return &MockAuthController_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx)}
+}
+
+func (_c *MockAuthController_ListUsers_Call) Run(run func(ctx context.Context)) *MockAuthController_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_ListUsers_Call) Return(_a0 []auth.PoolUser, _a1 error) *MockAuthController_ListUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthController_ListUsers_Call) RunAndReturn(run func(context.Context) ([]auth.PoolUser, error)) *MockAuthController_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RefreshToken provides a mock function with given fields: ctx, username, token
 func (_m *MockAuthController) RefreshToken(ctx context.Context, username string, token string) (auth.AuthResult, error) {
 	ret := _m.Called(ctx, username, token)
@@ -292,6 +607,168 @@ func (_c *MockAuthController_Register_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// RemoveUserFromRole provides a mock function with given fields: ctx, username, role
+func (_m *MockAuthController) RemoveUserFromRole(ctx context.Context, username string, role string) error {
+	ret := _m.Called(ctx, username, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveUserFromRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_RemoveUserFromRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveUserFromRole'
+type MockAuthController_RemoveUserFromRole_Call struct {
+	*mock.Call
+}
+
+// RemoveUserFromRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - role string
+func (_e *MockAuthController_Expecter) RemoveUserFromRole(ctx interface{}, username interface{}, role interface{}) *MockAuthController_RemoveUserFromRole_Call {
+	return &MockAuthController_RemoveUserFromRole_Call{Call: _e.mock.On("RemoveUserFromRole", ctx, username, role)}
+}
+
+func (_c *MockAuthController_RemoveUserFromRole_Call) Run(run func(ctx context.Context, username string, role string)) *MockAuthController_RemoveUserFromRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_RemoveUserFromRole_Call) Return(_a0 error) *MockAuthController_RemoveUserFromRole_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_RemoveUserFromRole_Call) RunAndReturn(run func(context.Context, string, string) error) *MockAuthController_RemoveUserFromRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResendConfirmationCode provides a mock function with given fields: ctx, username
+func (_m *MockAuthController) ResendConfirmationCode(ctx context.Context, username string) (auth.CodeDeliveryResult, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResendConfirmationCode")
+	}
+
+	var r0 auth.CodeDeliveryResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (auth.CodeDeliveryResult, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) auth.CodeDeliveryResult); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(auth.CodeDeliveryResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthController_ResendConfirmationCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResendConfirmationCode'
+type MockAuthController_ResendConfirmationCode_Call struct {
+	*mock.Call
+}
+
+// ResendConfirmationCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockAuthController_Expecter) ResendConfirmationCode(ctx interface{}, username interface{}) *MockAuthController_ResendConfirmationCode_Call {
+	return &MockAuthController_ResendConfirmationCode_Call{Call: _e.mock.On("ResendConfirmationCode", ctx, username)}
+}
+
+func (_c *MockAuthController_ResendConfirmationCode_Call) Run(run func(ctx context.Context, username string)) *MockAuthController_ResendConfirmationCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_ResendConfirmationCode_Call) Return(_a0 auth.CodeDeliveryResult, _a1 error) *MockAuthController_ResendConfirmationCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthController_ResendConfirmationCode_Call) RunAndReturn(run func(context.Context, string) (auth.CodeDeliveryResult, error)) *MockAuthController_ResendConfirmationCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RespondToMfaChallenge provides a mock function with given fields: ctx, req
+func (_m *MockAuthController) RespondToMfaChallenge(ctx context.Context, req auth.MfaChallengeRequest) (auth.AuthResult, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RespondToMfaChallenge")
+	}
+
+	var r0 auth.AuthResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, auth.MfaChallengeRequest) (auth.AuthResult, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, auth.MfaChallengeRequest) auth.AuthResult); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(auth.AuthResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, auth.MfaChallengeRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthController_RespondToMfaChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RespondToMfaChallenge'
+type MockAuthController_RespondToMfaChallenge_Call struct {
+	*mock.Call
+}
+
+// RespondToMfaChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req auth.MfaChallengeRequest
+func (_e *MockAuthController_Expecter) RespondToMfaChallenge(ctx interface{}, req interface{}) *MockAuthController_RespondToMfaChallenge_Call {
+	return &MockAuthController_RespondToMfaChallenge_Call{Call: _e.mock.On("RespondToMfaChallenge", ctx, req)}
+}
+
+func (_c *MockAuthController_RespondToMfaChallenge_Call) Run(run func(ctx context.Context, req auth.MfaChallengeRequest)) *MockAuthController_RespondToMfaChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(auth.MfaChallengeRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_RespondToMfaChallenge_Call) Return(_a0 auth.AuthResult, _a1 error) *MockAuthController_RespondToMfaChallenge_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthController_RespondToMfaChallenge_Call) RunAndReturn(run func(context.Context, auth.MfaChallengeRequest) (auth.AuthResult, error)) *MockAuthController_RespondToMfaChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdatePassword provides a mock function with given fields: ctx, session, username, password
 func (_m *MockAuthController) UpdatePassword(ctx context.Context, session string, username string, password string) error {
 	ret := _m.Called(ctx, session, username, password)
@@ -341,6 +818,152 @@ func (_c *MockAuthController_UpdatePassword_Call) RunAndReturn(run func(context.
 	return _c
 }
 
+// UpdateUserAttributes provides a mock function with given fields: ctx, username, attributes
+func (_m *MockAuthController) UpdateUserAttributes(ctx context.Context, username string, attributes map[string]string) error {
+	ret := _m.Called(ctx, username, attributes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserAttributes")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) error); ok {
+		r0 = rf(ctx, username, attributes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_UpdateUserAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserAttributes'
+type MockAuthController_UpdateUserAttributes_Call struct {
+	*mock.Call
+}
+
+// UpdateUserAttributes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - attributes map[string]string
+func (_e *MockAuthController_Expecter) UpdateUserAttributes(ctx interface{}, username interface{}, attributes interface{}) *MockAuthController_UpdateUserAttributes_Call {
+	return &MockAuthController_UpdateUserAttributes_Call{Call: _e.mock.On("UpdateUserAttributes", ctx, username, attributes)}
+}
+
+func (_c *MockAuthController_UpdateUserAttributes_Call) Run(run func(ctx context.Context, username string, attributes map[string]string)) *MockAuthController_UpdateUserAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_UpdateUserAttributes_Call) Return(_a0 error) *MockAuthController_UpdateUserAttributes_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_UpdateUserAttributes_Call) RunAndReturn(run func(context.Context, string, map[string]string) error) *MockAuthController_UpdateUserAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifySoftwareToken provides a mock function with given fields: ctx, accessToken, code, deviceName
+func (_m *MockAuthController) VerifySoftwareToken(ctx context.Context, accessToken string, code string, deviceName string) error {
+	ret := _m.Called(ctx, accessToken, code, deviceName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifySoftwareToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, accessToken, code, deviceName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_VerifySoftwareToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifySoftwareToken'
+type MockAuthController_VerifySoftwareToken_Call struct {
+	*mock.Call
+}
+
+// VerifySoftwareToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accessToken string
+//   - code string
+//   - deviceName string
+func (_e *MockAuthController_Expecter) VerifySoftwareToken(ctx interface{}, accessToken interface{}, code interface{}, deviceName interface{}) *MockAuthController_VerifySoftwareToken_Call {
+	return &MockAuthController_VerifySoftwareToken_Call{Call: _e.mock.On("VerifySoftwareToken", ctx, accessToken, code, deviceName)}
+}
+
+func (_c *MockAuthController_VerifySoftwareToken_Call) Run(run func(ctx context.Context, accessToken string, code string, deviceName string)) *MockAuthController_VerifySoftwareToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_VerifySoftwareToken_Call) Return(_a0 error) *MockAuthController_VerifySoftwareToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_VerifySoftwareToken_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockAuthController_VerifySoftwareToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyUserAttribute provides a mock function with given fields: ctx, accessToken, attribute, code
+func (_m *MockAuthController) VerifyUserAttribute(ctx context.Context, accessToken string, attribute string, code string) error {
+	ret := _m.Called(ctx, accessToken, attribute, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyUserAttribute")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, accessToken, attribute, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthController_VerifyUserAttribute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyUserAttribute'
+type MockAuthController_VerifyUserAttribute_Call struct {
+	*mock.Call
+}
+
+// VerifyUserAttribute is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accessToken string
+//   - attribute string
+//   - code string
+func (_e *MockAuthController_Expecter) VerifyUserAttribute(ctx interface{}, accessToken interface{}, attribute interface{}, code interface{}) *MockAuthController_VerifyUserAttribute_Call {
+	return &MockAuthController_VerifyUserAttribute_Call{Call: _e.mock.On("VerifyUserAttribute", ctx, accessToken, attribute, code)}
+}
+
+func (_c *MockAuthController_VerifyUserAttribute_Call) Run(run func(ctx context.Context, accessToken string, attribute string, code string)) *MockAuthController_VerifyUserAttribute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthController_VerifyUserAttribute_Call) Return(_a0 error) *MockAuthController_VerifyUserAttribute_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthController_VerifyUserAttribute_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockAuthController_VerifyUserAttribute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockAuthController creates a new instance of MockAuthController. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockAuthController(t interface {
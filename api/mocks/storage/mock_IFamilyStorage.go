@@ -22,6 +22,100 @@ func (_m *MockIFamilyStorage) EXPECT() *MockIFamilyStorage_Expecter {
 	return &MockIFamilyStorage_Expecter{mock: &_m.Mock}
 }
 
+// AddFamilyUser provides a mock function with given fields: ctx, familyUser
+func (_m *MockIFamilyStorage) AddFamilyUser(ctx context.Context, familyUser models.FamilyUser) error {
+	ret := _m.Called(ctx, familyUser)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddFamilyUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FamilyUser) error); ok {
+		r0 = rf(ctx, familyUser)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIFamilyStorage_AddFamilyUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddFamilyUser'
+type MockIFamilyStorage_AddFamilyUser_Call struct {
+	*mock.Call
+}
+
+// AddFamilyUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - familyUser models.FamilyUser
+func (_e *MockIFamilyStorage_Expecter) AddFamilyUser(ctx interface{}, familyUser interface{}) *MockIFamilyStorage_AddFamilyUser_Call {
+	return &MockIFamilyStorage_AddFamilyUser_Call{Call: _e.mock.On("AddFamilyUser", ctx, familyUser)}
+}
+
+func (_c *MockIFamilyStorage_AddFamilyUser_Call) Run(run func(ctx context.Context, familyUser models.FamilyUser)) *MockIFamilyStorage_AddFamilyUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.FamilyUser))
+	})
+	return _c
+}
+
+func (_c *MockIFamilyStorage_AddFamilyUser_Call) Return(_a0 error) *MockIFamilyStorage_AddFamilyUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIFamilyStorage_AddFamilyUser_Call) RunAndReturn(run func(context.Context, models.FamilyUser) error) *MockIFamilyStorage_AddFamilyUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteFamilySettings provides a mock function with given fields: ctx, family_id
+func (_m *MockIFamilyStorage) DeleteFamilySettings(ctx context.Context, family_id string) error {
+	ret := _m.Called(ctx, family_id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFamilySettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, family_id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIFamilyStorage_DeleteFamilySettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteFamilySettings'
+type MockIFamilyStorage_DeleteFamilySettings_Call struct {
+	*mock.Call
+}
+
+// DeleteFamilySettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - family_id string
+func (_e *MockIFamilyStorage_Expecter) DeleteFamilySettings(ctx interface{}, family_id interface{}) *MockIFamilyStorage_DeleteFamilySettings_Call {
+	return &MockIFamilyStorage_DeleteFamilySettings_Call{Call: _e.mock.On("DeleteFamilySettings", ctx, family_id)}
+}
+
+func (_c *MockIFamilyStorage_DeleteFamilySettings_Call) Run(run func(ctx context.Context, family_id string)) *MockIFamilyStorage_DeleteFamilySettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockIFamilyStorage_DeleteFamilySettings_Call) Return(_a0 error) *MockIFamilyStorage_DeleteFamilySettings_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIFamilyStorage_DeleteFamilySettings_Call) RunAndReturn(run func(context.Context, string) error) *MockIFamilyStorage_DeleteFamilySettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetFamilyMembersByUserIDs provides a mock function with given fields: ctx, family_id, user_ids
 func (_m *MockIFamilyStorage) GetFamilyMembersByUserIDs(ctx context.Context, family_id string, user_ids []string) (models.Family, error) {
 	ret := _m.Called(ctx, family_id, user_ids)
@@ -80,6 +174,63 @@ func (_c *MockIFamilyStorage_GetFamilyMembersByUserIDs_Call) RunAndReturn(run fu
 	return _c
 }
 
+// GetFamilySettings provides a mock function with given fields: ctx, family_id
+func (_m *MockIFamilyStorage) GetFamilySettings(ctx context.Context, family_id string) (models.FamilySettings, error) {
+	ret := _m.Called(ctx, family_id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFamilySettings")
+	}
+
+	var r0 models.FamilySettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.FamilySettings, error)); ok {
+		return rf(ctx, family_id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.FamilySettings); ok {
+		r0 = rf(ctx, family_id)
+	} else {
+		r0 = ret.Get(0).(models.FamilySettings)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, family_id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIFamilyStorage_GetFamilySettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFamilySettings'
+type MockIFamilyStorage_GetFamilySettings_Call struct {
+	*mock.Call
+}
+
+// GetFamilySettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - family_id string
+func (_e *MockIFamilyStorage_Expecter) GetFamilySettings(ctx interface{}, family_id interface{}) *MockIFamilyStorage_GetFamilySettings_Call {
+	return &MockIFamilyStorage_GetFamilySettings_Call{Call: _e.mock.On("GetFamilySettings", ctx, family_id)}
+}
+
+func (_c *MockIFamilyStorage_GetFamilySettings_Call) Run(run func(ctx context.Context, family_id string)) *MockIFamilyStorage_GetFamilySettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockIFamilyStorage_GetFamilySettings_Call) Return(_a0 models.FamilySettings, _a1 error) *MockIFamilyStorage_GetFamilySettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIFamilyStorage_GetFamilySettings_Call) RunAndReturn(run func(context.Context, string) (models.FamilySettings, error)) *MockIFamilyStorage_GetFamilySettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetFamilyUsers provides a mock function with given fields: ctx, family_id
 func (_m *MockIFamilyStorage) GetFamilyUsers(ctx context.Context, family_id string) ([]models.FamilyUser, error) {
 	ret := _m.Called(ctx, family_id)
@@ -139,6 +290,149 @@ func (_c *MockIFamilyStorage_GetFamilyUsers_Call) RunAndReturn(run func(context.
 	return _c
 }
 
+// MoveFamilyUser provides a mock function with given fields: ctx, userId, fromFamilyId, toFamilyId
+func (_m *MockIFamilyStorage) MoveFamilyUser(ctx context.Context, userId string, fromFamilyId string, toFamilyId string) error {
+	ret := _m.Called(ctx, userId, fromFamilyId, toFamilyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MoveFamilyUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userId, fromFamilyId, toFamilyId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIFamilyStorage_MoveFamilyUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MoveFamilyUser'
+type MockIFamilyStorage_MoveFamilyUser_Call struct {
+	*mock.Call
+}
+
+// MoveFamilyUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - fromFamilyId string
+//   - toFamilyId string
+func (_e *MockIFamilyStorage_Expecter) MoveFamilyUser(ctx interface{}, userId interface{}, fromFamilyId interface{}, toFamilyId interface{}) *MockIFamilyStorage_MoveFamilyUser_Call {
+	return &MockIFamilyStorage_MoveFamilyUser_Call{Call: _e.mock.On("MoveFamilyUser", ctx, userId, fromFamilyId, toFamilyId)}
+}
+
+func (_c *MockIFamilyStorage_MoveFamilyUser_Call) Run(run func(ctx context.Context, userId string, fromFamilyId string, toFamilyId string)) *MockIFamilyStorage_MoveFamilyUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockIFamilyStorage_MoveFamilyUser_Call) Return(_a0 error) *MockIFamilyStorage_MoveFamilyUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIFamilyStorage_MoveFamilyUser_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockIFamilyStorage_MoveFamilyUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveFamilyUser provides a mock function with given fields: ctx, familyUser
+func (_m *MockIFamilyStorage) RemoveFamilyUser(ctx context.Context, familyUser models.FamilyUser) error {
+	ret := _m.Called(ctx, familyUser)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveFamilyUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FamilyUser) error); ok {
+		r0 = rf(ctx, familyUser)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIFamilyStorage_RemoveFamilyUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveFamilyUser'
+type MockIFamilyStorage_RemoveFamilyUser_Call struct {
+	*mock.Call
+}
+
+// RemoveFamilyUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - familyUser models.FamilyUser
+func (_e *MockIFamilyStorage_Expecter) RemoveFamilyUser(ctx interface{}, familyUser interface{}) *MockIFamilyStorage_RemoveFamilyUser_Call {
+	return &MockIFamilyStorage_RemoveFamilyUser_Call{Call: _e.mock.On("RemoveFamilyUser", ctx, familyUser)}
+}
+
+func (_c *MockIFamilyStorage_RemoveFamilyUser_Call) Run(run func(ctx context.Context, familyUser models.FamilyUser)) *MockIFamilyStorage_RemoveFamilyUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.FamilyUser))
+	})
+	return _c
+}
+
+func (_c *MockIFamilyStorage_RemoveFamilyUser_Call) Return(_a0 error) *MockIFamilyStorage_RemoveFamilyUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIFamilyStorage_RemoveFamilyUser_Call) RunAndReturn(run func(context.Context, models.FamilyUser) error) *MockIFamilyStorage_RemoveFamilyUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveFamilySettings provides a mock function with given fields: ctx, settings
+func (_m *MockIFamilyStorage) SaveFamilySettings(ctx context.Context, settings models.FamilySettings) error {
+	ret := _m.Called(ctx, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveFamilySettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FamilySettings) error); ok {
+		r0 = rf(ctx, settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIFamilyStorage_SaveFamilySettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveFamilySettings'
+type MockIFamilyStorage_SaveFamilySettings_Call struct {
+	*mock.Call
+}
+
+// SaveFamilySettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - settings models.FamilySettings
+func (_e *MockIFamilyStorage_Expecter) SaveFamilySettings(ctx interface{}, settings interface{}) *MockIFamilyStorage_SaveFamilySettings_Call {
+	return &MockIFamilyStorage_SaveFamilySettings_Call{Call: _e.mock.On("SaveFamilySettings", ctx, settings)}
+}
+
+func (_c *MockIFamilyStorage_SaveFamilySettings_Call) Run(run func(ctx context.Context, settings models.FamilySettings)) *MockIFamilyStorage_SaveFamilySettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.FamilySettings))
+	})
+	return _c
+}
+
+func (_c *MockIFamilyStorage_SaveFamilySettings_Call) Return(_a0 error) *MockIFamilyStorage_SaveFamilySettings_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIFamilyStorage_SaveFamilySettings_Call) RunAndReturn(run func(context.Context, models.FamilySettings) error) *MockIFamilyStorage_SaveFamilySettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockIFamilyStorage creates a new instance of MockIFamilyStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockIFamilyStorage(t interface {
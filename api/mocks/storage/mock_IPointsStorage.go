@@ -22,6 +22,64 @@ func (_m *MockIPointsStorage) EXPECT() *MockIPointsStorage_Expecter {
 	return &MockIPointsStorage_Expecter{mock: &_m.Mock}
 }
 
+// GetPointBalance provides a mock function with given fields: ctx, userId, typeId
+func (_m *MockIPointsStorage) GetPointBalance(ctx context.Context, userId string, typeId string) (models.PointBalance, error) {
+	ret := _m.Called(ctx, userId, typeId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPointBalance")
+	}
+
+	var r0 models.PointBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (models.PointBalance, error)); ok {
+		return rf(ctx, userId, typeId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) models.PointBalance); ok {
+		r0 = rf(ctx, userId, typeId)
+	} else {
+		r0 = ret.Get(0).(models.PointBalance)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userId, typeId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIPointsStorage_GetPointBalance_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPointBalance'
+type MockIPointsStorage_GetPointBalance_Call struct {
+	*mock.Call
+}
+
+// GetPointBalance is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - typeId string
+func (_e *MockIPointsStorage_Expecter) GetPointBalance(ctx interface{}, userId interface{}, typeId interface{}) *MockIPointsStorage_GetPointBalance_Call {
+	return &MockIPointsStorage_GetPointBalance_Call{Call: _e.mock.On("GetPointBalance", ctx, userId, typeId)}
+}
+
+func (_c *MockIPointsStorage_GetPointBalance_Call) Run(run func(ctx context.Context, userId string, typeId string)) *MockIPointsStorage_GetPointBalance_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIPointsStorage_GetPointBalance_Call) Return(_a0 models.PointBalance, _a1 error) *MockIPointsStorage_GetPointBalance_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIPointsStorage_GetPointBalance_Call) RunAndReturn(run func(context.Context, string, string) (models.PointBalance, error)) *MockIPointsStorage_GetPointBalance_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPointByID provides a mock function with given fields: ctx, userId, id
 func (_m *MockIPointsStorage) GetPointByID(ctx context.Context, userId string, id string) (models.Point, error) {
 	ret := _m.Called(ctx, userId, id)
@@ -187,6 +245,150 @@ func (_c *MockIPointsStorage_SavePoint_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// SavePoints provides a mock function with given fields: ctx, points, balances
+func (_m *MockIPointsStorage) SavePoints(ctx context.Context, points []models.Point, balances []models.PointBalance) error {
+	ret := _m.Called(ctx, points, balances)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePoints")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Point, []models.PointBalance) error); ok {
+		r0 = rf(ctx, points, balances)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIPointsStorage_SavePoints_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePoints'
+type MockIPointsStorage_SavePoints_Call struct {
+	*mock.Call
+}
+
+// SavePoints is a helper method to define mock.On call
+//   - ctx context.Context
+//   - points []models.Point
+//   - balances []models.PointBalance
+func (_e *MockIPointsStorage_Expecter) SavePoints(ctx interface{}, points interface{}, balances interface{}) *MockIPointsStorage_SavePoints_Call {
+	return &MockIPointsStorage_SavePoints_Call{Call: _e.mock.On("SavePoints", ctx, points, balances)}
+}
+
+func (_c *MockIPointsStorage_SavePoints_Call) Run(run func(ctx context.Context, points []models.Point, balances []models.PointBalance)) *MockIPointsStorage_SavePoints_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]models.Point), args[2].([]models.PointBalance))
+	})
+	return _c
+}
+
+func (_c *MockIPointsStorage_SavePoints_Call) Return(_a0 error) *MockIPointsStorage_SavePoints_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIPointsStorage_SavePoints_Call) RunAndReturn(run func(context.Context, []models.Point, []models.PointBalance) error) *MockIPointsStorage_SavePoints_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScrubPoints provides a mock function with given fields: ctx, userId
+func (_m *MockIPointsStorage) ScrubPoints(ctx context.Context, userId string) error {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScrubPoints")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIPointsStorage_ScrubPoints_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScrubPoints'
+type MockIPointsStorage_ScrubPoints_Call struct {
+	*mock.Call
+}
+
+// ScrubPoints is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+func (_e *MockIPointsStorage_Expecter) ScrubPoints(ctx interface{}, userId interface{}) *MockIPointsStorage_ScrubPoints_Call {
+	return &MockIPointsStorage_ScrubPoints_Call{Call: _e.mock.On("ScrubPoints", ctx, userId)}
+}
+
+func (_c *MockIPointsStorage_ScrubPoints_Call) Run(run func(ctx context.Context, userId string)) *MockIPointsStorage_ScrubPoints_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockIPointsStorage_ScrubPoints_Call) Return(_a0 error) *MockIPointsStorage_ScrubPoints_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIPointsStorage_ScrubPoints_Call) RunAndReturn(run func(context.Context, string) error) *MockIPointsStorage_ScrubPoints_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StreamPointsByUserID provides a mock function with given fields: ctx, userId, filters, fn
+func (_m *MockIPointsStorage) StreamPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter, fn func(models.Point) error) error {
+	ret := _m.Called(ctx, userId, filters, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamPointsByUserID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.QueryPointsFilter, func(models.Point) error) error); ok {
+		r0 = rf(ctx, userId, filters, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIPointsStorage_StreamPointsByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StreamPointsByUserID'
+type MockIPointsStorage_StreamPointsByUserID_Call struct {
+	*mock.Call
+}
+
+// StreamPointsByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - filters models.QueryPointsFilter
+//   - fn func(models.Point) error
+func (_e *MockIPointsStorage_Expecter) StreamPointsByUserID(ctx interface{}, userId interface{}, filters interface{}, fn interface{}) *MockIPointsStorage_StreamPointsByUserID_Call {
+	return &MockIPointsStorage_StreamPointsByUserID_Call{Call: _e.mock.On("StreamPointsByUserID", ctx, userId, filters, fn)}
+}
+
+func (_c *MockIPointsStorage_StreamPointsByUserID_Call) Run(run func(ctx context.Context, userId string, filters models.QueryPointsFilter, fn func(models.Point) error)) *MockIPointsStorage_StreamPointsByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.QueryPointsFilter), args[3].(func(models.Point) error))
+	})
+	return _c
+}
+
+func (_c *MockIPointsStorage_StreamPointsByUserID_Call) Return(_a0 error) *MockIPointsStorage_StreamPointsByUserID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIPointsStorage_StreamPointsByUserID_Call) RunAndReturn(run func(context.Context, string, models.QueryPointsFilter, func(models.Point) error) error) *MockIPointsStorage_StreamPointsByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockIPointsStorage creates a new instance of MockIPointsStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockIPointsStorage(t interface {
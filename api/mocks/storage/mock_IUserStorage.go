@@ -7,6 +7,8 @@ import (
 
 	models "github.com/sebboness/yektaspoints/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockIUserStorage is an autogenerated mock type for the IUserStorage type
@@ -22,6 +24,169 @@ func (_m *MockIUserStorage) EXPECT() *MockIUserStorage_Expecter {
 	return &MockIUserStorage_Expecter{mock: &_m.Mock}
 }
 
+// AddUserFamily provides a mock function with given fields: ctx, userId, familyId
+func (_m *MockIUserStorage) AddUserFamily(ctx context.Context, userId string, familyId string) error {
+	ret := _m.Called(ctx, userId, familyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddUserFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userId, familyId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIUserStorage_AddUserFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddUserFamily'
+type MockIUserStorage_AddUserFamily_Call struct {
+	*mock.Call
+}
+
+// AddUserFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - familyId string
+func (_e *MockIUserStorage_Expecter) AddUserFamily(ctx interface{}, userId interface{}, familyId interface{}) *MockIUserStorage_AddUserFamily_Call {
+	return &MockIUserStorage_AddUserFamily_Call{Call: _e.mock.On("AddUserFamily", ctx, userId, familyId)}
+}
+
+func (_c *MockIUserStorage_AddUserFamily_Call) Run(run func(ctx context.Context, userId string, familyId string)) *MockIUserStorage_AddUserFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_AddUserFamily_Call) Return(_a0 error) *MockIUserStorage_AddUserFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIUserStorage_AddUserFamily_Call) RunAndReturn(run func(context.Context, string, string) error) *MockIUserStorage_AddUserFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllUsers provides a mock function with given fields: ctx
+func (_m *MockIUserStorage) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllUsers")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.User, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.User); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIUserStorage_GetAllUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllUsers'
+type MockIUserStorage_GetAllUsers_Call struct {
+	*mock.Call
+}
+
+// GetAllUsers is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockIUserStorage_Expecter) GetAllUsers(ctx interface{}) *MockIUserStorage_GetAllUsers_Call {
+	return &MockIUserStorage_GetAllUsers_Call{Call: _e.mock.On("GetAllUsers", ctx)}
+}
+
+func (_c *MockIUserStorage_GetAllUsers_Call) Run(run func(ctx context.Context)) *MockIUserStorage_GetAllUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_GetAllUsers_Call) Return(_a0 []models.User, _a1 error) *MockIUserStorage_GetAllUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIUserStorage_GetAllUsers_Call) RunAndReturn(run func(context.Context) ([]models.User, error)) *MockIUserStorage_GetAllUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByEmail provides a mock function with given fields: ctx, email
+func (_m *MockIUserStorage) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByEmail")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIUserStorage_GetUserByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByEmail'
+type MockIUserStorage_GetUserByEmail_Call struct {
+	*mock.Call
+}
+
+// GetUserByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *MockIUserStorage_Expecter) GetUserByEmail(ctx interface{}, email interface{}) *MockIUserStorage_GetUserByEmail_Call {
+	return &MockIUserStorage_GetUserByEmail_Call{Call: _e.mock.On("GetUserByEmail", ctx, email)}
+}
+
+func (_c *MockIUserStorage_GetUserByEmail_Call) Run(run func(ctx context.Context, email string)) *MockIUserStorage_GetUserByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_GetUserByEmail_Call) Return(_a0 models.User, _a1 error) *MockIUserStorage_GetUserByEmail_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIUserStorage_GetUserByEmail_Call) RunAndReturn(run func(context.Context, string) (models.User, error)) *MockIUserStorage_GetUserByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetUserByID provides a mock function with given fields: ctx, userId
 func (_m *MockIUserStorage) GetUserByID(ctx context.Context, userId string) (models.User, error) {
 	ret := _m.Called(ctx, userId)
@@ -79,6 +244,111 @@ func (_c *MockIUserStorage_GetUserByID_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// GetUserByUsername provides a mock function with given fields: ctx, username
+func (_m *MockIUserStorage) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByUsername")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIUserStorage_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
+type MockIUserStorage_GetUserByUsername_Call struct {
+	*mock.Call
+}
+
+// GetUserByUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockIUserStorage_Expecter) GetUserByUsername(ctx interface{}, username interface{}) *MockIUserStorage_GetUserByUsername_Call {
+	return &MockIUserStorage_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", ctx, username)}
+}
+
+func (_c *MockIUserStorage_GetUserByUsername_Call) Run(run func(ctx context.Context, username string)) *MockIUserStorage_GetUserByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_GetUserByUsername_Call) Return(_a0 models.User, _a1 error) *MockIUserStorage_GetUserByUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIUserStorage_GetUserByUsername_Call) RunAndReturn(run func(context.Context, string) (models.User, error)) *MockIUserStorage_GetUserByUsername_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveUserFamily provides a mock function with given fields: ctx, userId, familyId
+func (_m *MockIUserStorage) RemoveUserFamily(ctx context.Context, userId string, familyId string) error {
+	ret := _m.Called(ctx, userId, familyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveUserFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userId, familyId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIUserStorage_RemoveUserFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveUserFamily'
+type MockIUserStorage_RemoveUserFamily_Call struct {
+	*mock.Call
+}
+
+// RemoveUserFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - familyId string
+func (_e *MockIUserStorage_Expecter) RemoveUserFamily(ctx interface{}, userId interface{}, familyId interface{}) *MockIUserStorage_RemoveUserFamily_Call {
+	return &MockIUserStorage_RemoveUserFamily_Call{Call: _e.mock.On("RemoveUserFamily", ctx, userId, familyId)}
+}
+
+func (_c *MockIUserStorage_RemoveUserFamily_Call) Run(run func(ctx context.Context, userId string, familyId string)) *MockIUserStorage_RemoveUserFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_RemoveUserFamily_Call) Return(_a0 error) *MockIUserStorage_RemoveUserFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIUserStorage_RemoveUserFamily_Call) RunAndReturn(run func(context.Context, string, string) error) *MockIUserStorage_RemoveUserFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SaveUser provides a mock function with given fields: ctx, user
 func (_m *MockIUserStorage) SaveUser(ctx context.Context, user models.User) error {
 	ret := _m.Called(ctx, user)
@@ -126,6 +396,198 @@ func (_c *MockIUserStorage_SaveUser_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// ScrubUser provides a mock function with given fields: ctx, userId
+func (_m *MockIUserStorage) ScrubUser(ctx context.Context, userId string) error {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScrubUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIUserStorage_ScrubUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScrubUser'
+type MockIUserStorage_ScrubUser_Call struct {
+	*mock.Call
+}
+
+// ScrubUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+func (_e *MockIUserStorage_Expecter) ScrubUser(ctx interface{}, userId interface{}) *MockIUserStorage_ScrubUser_Call {
+	return &MockIUserStorage_ScrubUser_Call{Call: _e.mock.On("ScrubUser", ctx, userId)}
+}
+
+func (_c *MockIUserStorage_ScrubUser_Call) Run(run func(ctx context.Context, userId string)) *MockIUserStorage_ScrubUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_ScrubUser_Call) Return(_a0 error) *MockIUserStorage_ScrubUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIUserStorage_ScrubUser_Call) RunAndReturn(run func(context.Context, string) error) *MockIUserStorage_ScrubUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUserConfirmationSentOn provides a mock function with given fields: ctx, userId, sentOn, minInterval
+func (_m *MockIUserStorage) UpdateUserConfirmationSentOn(ctx context.Context, userId string, sentOn time.Time, minInterval time.Duration) error {
+	ret := _m.Called(ctx, userId, sentOn, minInterval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserConfirmationSentOn")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Duration) error); ok {
+		r0 = rf(ctx, userId, sentOn, minInterval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIUserStorage_UpdateUserConfirmationSentOn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserConfirmationSentOn'
+type MockIUserStorage_UpdateUserConfirmationSentOn_Call struct {
+	*mock.Call
+}
+
+// UpdateUserConfirmationSentOn is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - sentOn time.Time
+//   - minInterval time.Duration
+func (_e *MockIUserStorage_Expecter) UpdateUserConfirmationSentOn(ctx interface{}, userId interface{}, sentOn interface{}, minInterval interface{}) *MockIUserStorage_UpdateUserConfirmationSentOn_Call {
+	return &MockIUserStorage_UpdateUserConfirmationSentOn_Call{Call: _e.mock.On("UpdateUserConfirmationSentOn", ctx, userId, sentOn, minInterval)}
+}
+
+func (_c *MockIUserStorage_UpdateUserConfirmationSentOn_Call) Run(run func(ctx context.Context, userId string, sentOn time.Time, minInterval time.Duration)) *MockIUserStorage_UpdateUserConfirmationSentOn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_UpdateUserConfirmationSentOn_Call) Return(_a0 error) *MockIUserStorage_UpdateUserConfirmationSentOn_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIUserStorage_UpdateUserConfirmationSentOn_Call) RunAndReturn(run func(context.Context, string, time.Time, time.Duration) error) *MockIUserStorage_UpdateUserConfirmationSentOn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUserProfile provides a mock function with given fields: ctx, userId, profile
+func (_m *MockIUserStorage) UpdateUserProfile(ctx context.Context, userId string, profile models.UserProfileUpdate) error {
+	ret := _m.Called(ctx, userId, profile)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserProfile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UserProfileUpdate) error); ok {
+		r0 = rf(ctx, userId, profile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIUserStorage_UpdateUserProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserProfile'
+type MockIUserStorage_UpdateUserProfile_Call struct {
+	*mock.Call
+}
+
+// UpdateUserProfile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - profile models.UserProfileUpdate
+func (_e *MockIUserStorage_Expecter) UpdateUserProfile(ctx interface{}, userId interface{}, profile interface{}) *MockIUserStorage_UpdateUserProfile_Call {
+	return &MockIUserStorage_UpdateUserProfile_Call{Call: _e.mock.On("UpdateUserProfile", ctx, userId, profile)}
+}
+
+func (_c *MockIUserStorage_UpdateUserProfile_Call) Run(run func(ctx context.Context, userId string, profile models.UserProfileUpdate)) *MockIUserStorage_UpdateUserProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.UserProfileUpdate))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_UpdateUserProfile_Call) Return(_a0 error) *MockIUserStorage_UpdateUserProfile_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIUserStorage_UpdateUserProfile_Call) RunAndReturn(run func(context.Context, string, models.UserProfileUpdate) error) *MockIUserStorage_UpdateUserProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUserRoles provides a mock function with given fields: ctx, userId, roles
+func (_m *MockIUserStorage) UpdateUserRoles(ctx context.Context, userId string, roles []string) error {
+	ret := _m.Called(ctx, userId, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserRoles")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, userId, roles)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIUserStorage_UpdateUserRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserRoles'
+type MockIUserStorage_UpdateUserRoles_Call struct {
+	*mock.Call
+}
+
+// UpdateUserRoles is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId string
+//   - roles []string
+func (_e *MockIUserStorage_Expecter) UpdateUserRoles(ctx interface{}, userId interface{}, roles interface{}) *MockIUserStorage_UpdateUserRoles_Call {
+	return &MockIUserStorage_UpdateUserRoles_Call{Call: _e.mock.On("UpdateUserRoles", ctx, userId, roles)}
+}
+
+func (_c *MockIUserStorage_UpdateUserRoles_Call) Run(run func(ctx context.Context, userId string, roles []string)) *MockIUserStorage_UpdateUserRoles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockIUserStorage_UpdateUserRoles_Call) Return(_a0 error) *MockIUserStorage_UpdateUserRoles_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIUserStorage_UpdateUserRoles_Call) RunAndReturn(run func(context.Context, string, []string) error) *MockIUserStorage_UpdateUserRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateUserStatus provides a mock function with given fields: ctx, userId, status
 func (_m *MockIUserStorage) UpdateUserStatus(ctx context.Context, userId string, status models.UserStatus) error {
 	ret := _m.Called(ctx, userId, status)
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+type AchievementRuleType string
+
+// Points earned on N days in a row
+const AchievementRuleTypeStreak AchievementRuleType = "STREAK"
+
+// N points earned in total
+const AchievementRuleTypeTotal AchievementRuleType = "TOTAL"
+
+// The first cashout
+const AchievementRuleTypeFirstCashout AchievementRuleType = "FIRST_CASHOUT"
+
+// A balance of N points
+const AchievementRuleTypeGoal AchievementRuleType = "GOAL"
+
+// AchievementRuleTypes are all types of rules achievements can be earned by
+var AchievementRuleTypes = []AchievementRuleType{
+	AchievementRuleTypeStreak,
+	AchievementRuleTypeTotal,
+	AchievementRuleTypeFirstCashout,
+	AchievementRuleTypeGoal,
+}
+
+// AchievementRule describes how a badge is earned. Rules without a family ID apply to every family.
+type AchievementRule struct {
+	FamilyID        string              `json:"family_id" dynamodbav:"family_id"`
+	ID              string              `json:"id" dynamodbav:"id"`
+	Name            string              `json:"name" dynamodbav:"name"`
+	Description     string              `json:"description" dynamodbav:"description,omitempty"`
+	Type            AchievementRuleType `json:"type" dynamodbav:"type"`
+	Threshold       int                 `json:"threshold" dynamodbav:"threshold"`
+	BonusPoints     int                 `json:"bonus_points" dynamodbav:"bonus_points"`
+	CreatedByUserID string              `json:"created_by_user_id" dynamodbav:"created_by_user_id,omitempty"`
+	CreatedOnStr    string              `json:"-" dynamodbav:"created_on,omitempty"`
+	CreatedOn       time.Time           `json:"created_on" dynamodbav:"-"`
+
+	// Only count points of this category (see PointCategory). Applies to streak and total rules.
+	Category string `json:"category" dynamodbav:"category,omitempty"`
+}
+
+// Achievement is a badge a user earned
+type Achievement struct {
+	UserID      string    `json:"user_id" dynamodbav:"user_id"`
+	RuleID      string    `json:"rule_id" dynamodbav:"rule_id"`
+	FamilyID    string    `json:"family_id" dynamodbav:"family_id,omitempty"`
+	Name        string    `json:"name" dynamodbav:"name"`
+	Description string    `json:"description" dynamodbav:"description,omitempty"`
+	BonusPoints int       `json:"bonus_points" dynamodbav:"bonus_points"`
+	EarnedOnStr string    `json:"-" dynamodbav:"earned_on"`
+	EarnedOn    time.Time `json:"earned_on" dynamodbav:"-"`
+}
+
+// DefaultAchievementRules can be earned in every family
+var DefaultAchievementRules = []AchievementRule{
+	{ID: "streak-3", Name: "On a roll", Description: "Earned points 3 days in a row", Type: AchievementRuleTypeStreak, Threshold: 3},
+	{ID: "streak-7", Name: "Full week", Description: "Earned points 7 days in a row", Type: AchievementRuleTypeStreak, Threshold: 7},
+	{ID: "total-100", Name: "Century", Description: "Earned 100 points", Type: AchievementRuleTypeTotal, Threshold: 100},
+	{ID: "first-cashout", Name: "First treat", Description: "Cashed out points for the first time", Type: AchievementRuleTypeFirstCashout},
+	{ID: "goal-50", Name: "Saver", Description: "Saved up a balance of 50 points", Type: AchievementRuleTypeGoal, Threshold: 50},
+}
+
+// IsValid returns true if the rule type is one of the known types
+func (t AchievementRuleType) IsValid() bool {
+	return slices.Contains(AchievementRuleTypes, t)
+}
+
+func (r *AchievementRule) ParseTimes() {
+	if r.CreatedOnStr != "" {
+		r.CreatedOn = util.ParseTime_RFC3339Nano(r.CreatedOnStr)
+	}
+}
+
+func (a *Achievement) ParseTimes() {
+	if a.EarnedOnStr != "" {
+		a.EarnedOn = util.ParseTime_RFC3339Nano(a.EarnedOnStr)
+	}
+}
+
+// IsMet returns true if the user's settled points (latest first) meet the rule. Bonus points
+// of other achievements don't count towards streaks and totals.
+func (r AchievementRule) IsMet(points []Point) bool {
+	switch r.Type {
+	case AchievementRuleTypeStreak:
+		return r.longestStreak(points) >= r.Threshold
+	case AchievementRuleTypeTotal:
+		return r.totalEarned(points) >= r.Threshold
+	case AchievementRuleTypeFirstCashout:
+		return slices.ContainsFunc(points, func(p Point) bool {
+			return p.Status == PointStatusSettled && p.Request.Type == PointRequestTypeCashout
+		})
+	case AchievementRuleTypeGoal:
+		return LatestBalance(points) >= r.Threshold
+	}
+
+	return false
+}
+
+// NewAchievement returns the achievement of the user for earning the rule
+func (r AchievementRule) NewAchievement(userID string, earnedOn time.Time) Achievement {
+	return Achievement{
+		UserID:      userID,
+		RuleID:      r.ID,
+		FamilyID:    r.FamilyID,
+		Name:        r.Name,
+		Description: r.Description,
+		BonusPoints: r.BonusPoints,
+		EarnedOnStr: util.ToFormattedUTC(earnedOn),
+		EarnedOn:    earnedOn,
+	}
+}
+
+// counts returns true if the point counts as earned points towards the rule
+func (r AchievementRule) counts(p Point) bool {
+	return p.Status == PointStatusSettled &&
+		p.Request.Type == PointRequestTypeAdd &&
+		p.Request.Decision != PointRequestDecisionDeny &&
+		p.Request.AchievementRuleID == "" &&
+		p.Points > 0 &&
+		(r.Category == "" || PointCategory(p) == r.Category)
+}
+
+func (r AchievementRule) totalEarned(points []Point) int {
+	total := 0
+	for _, p := range points {
+		if r.counts(p) {
+			total += p.Points
+		}
+	}
+	return total
+}
+
+// longestStreak returns the most days in a row (in UTC) with earned points
+func (r AchievementRule) longestStreak(points []Point) int {
+	days := []time.Time{}
+	for _, p := range points {
+		if r.counts(p) {
+			t := p.UpdatedOn.UTC()
+			days = append(days, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+		}
+	}
+
+	slices.SortFunc(days, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	days = slices.Compact(days)
+
+	longest := 0
+	streak := 0
+	for idx, day := range days {
+		if idx > 0 && days[idx-1].AddDate(0, 0, 1).Equal(day) {
+			streak++
+		} else {
+			streak = 1
+		}
+		longest = max(longest, streak)
+	}
+
+	return longest
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AchievementRule_IsMet(t *testing.T) {
+	type test struct {
+		name string
+		rule AchievementRule
+		want bool
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC)
+	}
+	bal := func(v int) *int {
+		return &v
+	}
+
+	// latest first
+	points := []Point{
+		{Status: PointStatusSettled, Points: 10, Balance: bal(42), UpdatedOn: day(9), Request: PointRequest{Type: PointRequestTypeAdd, AchievementRuleID: "streak-3"}},
+		{Status: PointStatusSettled, Points: 4, Balance: bal(32), UpdatedOn: day(8), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "Dishes"}},
+		{Status: PointStatusSettled, Points: 4, Balance: bal(28), UpdatedOn: day(7), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "dishes"}},
+		{Status: PointStatusSettled, Points: -2, Balance: bal(24), UpdatedOn: day(6), Request: PointRequest{Type: PointRequestTypeCashout}},
+		{Status: PointStatusSettled, Points: 8, Balance: bal(26), UpdatedOn: day(6), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "homework", Decision: PointRequestDecisionDeny}},
+		{Status: PointStatusSettled, Points: 6, Balance: bal(26), UpdatedOn: day(5), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "homework"}},
+		{Status: PointStatusSettled, Points: 20, Balance: bal(20), UpdatedOn: day(3), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "dishes"}},
+	}
+
+	cases := []test{
+		{"streak - met", AchievementRule{Type: AchievementRuleTypeStreak, Threshold: 2}, true},
+		{"streak - not met, bonus and denied points don't count", AchievementRule{Type: AchievementRuleTypeStreak, Threshold: 3}, false},
+		{"streak - category", AchievementRule{Type: AchievementRuleTypeStreak, Threshold: 2, Category: "dishes"}, true},
+		{"streak - category not met", AchievementRule{Type: AchievementRuleTypeStreak, Threshold: 2, Category: "homework"}, false},
+		{"total - met", AchievementRule{Type: AchievementRuleTypeTotal, Threshold: 34}, true},
+		{"total - not met", AchievementRule{Type: AchievementRuleTypeTotal, Threshold: 35}, false},
+		{"total - category", AchievementRule{Type: AchievementRuleTypeTotal, Threshold: 28, Category: "dishes"}, true},
+		{"first cashout", AchievementRule{Type: AchievementRuleTypeFirstCashout}, true},
+		{"goal - met", AchievementRule{Type: AchievementRuleTypeGoal, Threshold: 42}, true},
+		{"goal - not met", AchievementRule{Type: AchievementRuleTypeGoal, Threshold: 43}, false},
+		{"unknown type", AchievementRule{Type: "NOPE"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, c.rule.IsMet(points))
+		})
+	}
+
+	assert.False(t, AchievementRule{Type: AchievementRuleTypeFirstCashout}.IsMet(points[:3]))
+	assert.False(t, AchievementRule{Type: AchievementRuleTypeGoal, Threshold: 1}.IsMet([]Point{}))
+}
+
+func Test_AchievementRule_NewAchievement(t *testing.T) {
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	r := AchievementRule{FamilyID: "f1", ID: "r1", Name: "Dishwasher", BonusPoints: 5}
+
+	a := r.NewAchievement("1", now)
+	assert.Equal(t, Achievement{
+		UserID:      "1",
+		RuleID:      "r1",
+		FamilyID:    "f1",
+		Name:        "Dishwasher",
+		BonusPoints: 5,
+		EarnedOnStr: "2024-03-09T12:00:00Z",
+		EarnedOn:    now,
+	}, a)
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+type AnalyticsBucket string
+
+const AnalyticsBucketDay AnalyticsBucket = "day"
+const AnalyticsBucketWeek AnalyticsBucket = "week"
+const AnalyticsBucketMonth AnalyticsBucket = "month"
+
+// UncategorizedCategory is the category of points without a reason
+const UncategorizedCategory = "uncategorized"
+
+// PointStats sums up points. Lost and cashed out points are positive.
+type PointStats struct {
+	Earned    int `json:"earned"`
+	Lost      int `json:"lost"`
+	CashedOut int `json:"cashed_out"`
+	Requests  int `json:"requests"`
+	Pending   int `json:"pending"`
+	Approved  int `json:"approved"`
+	Denied    int `json:"denied"`
+}
+
+type PointsBucketStats struct {
+	Start time.Time `json:"start"`
+	PointStats
+}
+
+type PointsCategoryStats struct {
+	Category string `json:"category"`
+	PointStats
+}
+
+type PointsAnalytics struct {
+	From                      time.Time             `json:"from"`
+	To                        time.Time             `json:"to"`
+	Bucket                    AnalyticsBucket       `json:"bucket"`
+	Totals                    PointStats            `json:"totals"`
+	Buckets                   []PointsBucketStats   `json:"buckets"`
+	Categories                []PointsCategoryStats `json:"categories"`
+	ApprovalRate              *float64              `json:"approval_rate"`                // nil if no request was decided
+	AvgDecisionLatencySeconds *float64              `json:"avg_decision_latency_seconds"` // nil if no request was decided
+}
+
+// IsValid returns true if the bucket is one of day, week or month
+func (b AnalyticsBucket) IsValid() bool {
+	return b == AnalyticsBucketDay || b == AnalyticsBucketWeek || b == AnalyticsBucketMonth
+}
+
+// Start returns the start of the bucket the given time falls in. Weeks start on Monday.
+func (b AnalyticsBucket) Start(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch b {
+	case AnalyticsBucketWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -daysSinceMonday)
+	case AnalyticsBucketMonth:
+		return t.AddDate(0, 0, 1-t.Day())
+	}
+
+	return t
+}
+
+// Next returns the start of the bucket after the one starting at the given time
+func (b AnalyticsBucket) Next(start time.Time) time.Time {
+	switch b {
+	case AnalyticsBucketWeek:
+		return start.AddDate(0, 0, 7)
+	case AnalyticsBucketMonth:
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// Exceeds returns true if there are more than max buckets between from and to
+func (b AnalyticsBucket) Exceeds(from, to time.Time, max int) bool {
+	count := 0
+	for start := b.Start(from); !start.After(to); start = b.Next(start) {
+		if count++; count > max {
+			return true
+		}
+	}
+	return false
+}
+
+// PointCategory returns the category of a point, which is its lower-cased reason
+func PointCategory(p Point) string {
+	category := strings.ToLower(strings.Join(strings.Fields(p.Request.Reason), " "))
+	if category == "" {
+		return UncategorizedCategory
+	}
+	return category
+}
+
+// add adds the point to the stats
+func (s *PointStats) add(p Point) {
+	s.Requests++
+
+	switch p.Request.Decision {
+	case PointRequestDecisionApprove:
+		s.Approved++
+	case PointRequestDecisionDeny:
+		s.Denied++
+	}
+
+	switch {
+	case p.Status == PointStatusWaiting:
+		s.Pending++
+	case p.Request.Decision == PointRequestDecisionDeny:
+		// denied requests don't change the balance
+	case p.Request.Type == PointRequestTypeCashout:
+		s.CashedOut -= p.Points
+	case p.Points < 0:
+		s.Lost -= p.Points
+	default:
+		s.Earned += p.Points
+	}
+}
+
+// Analyze aggregates the points updated between from and to into buckets and reason categories.
+// Buckets are contiguous (empty buckets are included) and in the location of from.
+func (a *PointsAnalytics) Analyze(from, to time.Time, bucket AnalyticsBucket, points []Point) {
+	a.From = from
+	a.To = to
+	a.Bucket = bucket
+	a.Totals = PointStats{}
+	a.Buckets = []PointsBucketStats{}
+	a.Categories = []PointsCategoryStats{}
+	a.ApprovalRate = nil
+	a.AvgDecisionLatencySeconds = nil
+
+	loc := from.Location()
+	bucketIdx := map[time.Time]int{}
+	for start := bucket.Start(from); !start.After(to); start = bucket.Next(start) {
+		bucketIdx[start] = len(a.Buckets)
+		a.Buckets = append(a.Buckets, PointsBucketStats{Start: start})
+	}
+
+	categories := map[string]*PointStats{}
+	var latency time.Duration
+	latencies := 0
+
+	for _, p := range points {
+		if p.UpdatedOn.Before(from) || p.UpdatedOn.After(to) {
+			continue
+		}
+
+		a.Totals.add(p)
+
+		if idx, ok := bucketIdx[bucket.Start(p.UpdatedOn.In(loc))]; ok {
+			a.Buckets[idx].add(p)
+		}
+
+		category := PointCategory(p)
+		if _, ok := categories[category]; !ok {
+			categories[category] = &PointStats{}
+		}
+		categories[category].add(p)
+
+		if p.Request.Decision != "" && !p.CreatedOn.IsZero() && !p.Request.DecidedOn.IsZero() {
+			latency += p.Request.DecidedOn.Sub(p.CreatedOn)
+			latencies++
+		}
+	}
+
+	for category, stats := range categories {
+		a.Categories = append(a.Categories, PointsCategoryStats{Category: category, PointStats: *stats})
+	}
+	slices.SortFunc(a.Categories, func(x, y PointsCategoryStats) int {
+		return cmp.Compare(x.Category, y.Category)
+	})
+
+	if decided := a.Totals.Approved + a.Totals.Denied; decided > 0 {
+		rate := float64(a.Totals.Approved) / float64(decided)
+		a.ApprovalRate = &rate
+	}
+
+	if latencies > 0 {
+		avg := latency.Seconds() / float64(latencies)
+		a.AvgDecisionLatencySeconds = &avg
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AnalyticsBucket_Start(t *testing.T) {
+	type test struct {
+		bucket AnalyticsBucket
+		t      time.Time
+		want   time.Time
+	}
+
+	// 2024-03-13 is a Wednesday
+	wed := time.Date(2024, 3, 13, 15, 30, 0, 0, time.UTC)
+
+	cases := []test{
+		{AnalyticsBucketDay, wed, time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{AnalyticsBucketWeek, wed, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{AnalyticsBucketWeek, time.Date(2024, 3, 17, 23, 0, 0, 0, time.UTC), time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{AnalyticsBucketMonth, wed, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.bucket), func(t *testing.T) {
+			assert.Equal(t, c.want, c.bucket.Start(c.t))
+		})
+	}
+}
+
+func Test_AnalyticsBucket_Exceeds(t *testing.T) {
+	from := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, AnalyticsBucketMonth.Exceeds(from, to, 3))
+	assert.True(t, AnalyticsBucketMonth.Exceeds(from, to, 2))
+	assert.True(t, AnalyticsBucketDay.Exceeds(from, to, 59))
+	assert.False(t, AnalyticsBucketDay.Exceeds(from, to, 60))
+}
+
+func Test_PointsAnalytics_Analyze(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 3, 23, 59, 59, 0, time.UTC)
+	day := func(d, h int) time.Time {
+		return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC)
+	}
+
+	points := []Point{
+		// latest first, like points are returned from storage
+		{Status: PointStatusWaiting, Points: 2, UpdatedOn: day(3, 9), CreatedOn: day(3, 9), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "Homework"}},
+		{Status: PointStatusSettled, Points: -3, UpdatedOn: day(3, 8), CreatedOn: day(3, 8), Request: PointRequest{Type: PointRequestTypeCashout, Reason: "Ice cream"}},
+		{Status: PointStatusSettled, Points: 4, UpdatedOn: day(2, 12), CreatedOn: day(2, 10), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "Clean  room", Decision: PointRequestDecisionDeny, DecidedOn: day(2, 12)}},
+		{Status: PointStatusSettled, Points: -1, UpdatedOn: day(1, 18), CreatedOn: day(1, 18), Request: PointRequest{Type: PointRequestTypeSubtract}},
+		{Status: PointStatusSettled, Points: 5, UpdatedOn: day(1, 10), CreatedOn: day(1, 8), Request: PointRequest{Type: PointRequestTypeAdd, Reason: "clean room", Decision: PointRequestDecisionApprove, DecidedOn: day(1, 10)}},
+		// outside of period
+		{Status: PointStatusSettled, Points: 5, UpdatedOn: day(4, 10), Request: PointRequest{Type: PointRequestTypeAdd}},
+	}
+
+	a := PointsAnalytics{}
+	a.Analyze(from, to, AnalyticsBucketDay, points)
+
+	assert.Equal(t, PointStats{Earned: 5, Lost: 1, CashedOut: 3, Requests: 5, Pending: 1, Approved: 1, Denied: 1}, a.Totals)
+
+	assert.Len(t, a.Buckets, 3)
+	assert.Equal(t, PointsBucketStats{Start: day(1, 0), PointStats: PointStats{Earned: 5, Lost: 1, Requests: 2, Approved: 1}}, a.Buckets[0])
+	assert.Equal(t, PointsBucketStats{Start: day(2, 0), PointStats: PointStats{Requests: 1, Denied: 1}}, a.Buckets[1])
+	assert.Equal(t, PointsBucketStats{Start: day(3, 0), PointStats: PointStats{CashedOut: 3, Requests: 2, Pending: 1}}, a.Buckets[2])
+
+	assert.Equal(t, []PointsCategoryStats{
+		{Category: "clean room", PointStats: PointStats{Earned: 5, Requests: 2, Approved: 1, Denied: 1}},
+		{Category: "homework", PointStats: PointStats{Requests: 1, Pending: 1}},
+		{Category: "ice cream", PointStats: PointStats{CashedOut: 3, Requests: 1}},
+		{Category: UncategorizedCategory, PointStats: PointStats{Lost: 1, Requests: 1}},
+	}, a.Categories)
+
+	assert.Equal(t, 0.5, *a.ApprovalRate)
+	assert.Equal(t, float64(2*60*60), *a.AvgDecisionLatencySeconds)
+}
+
+func Test_PointsAnalytics_Analyze_empty(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	a := PointsAnalytics{}
+	a.Analyze(from, to, AnalyticsBucketMonth, []Point{})
+
+	assert.Len(t, a.Buckets, 3)
+	assert.Empty(t, a.Categories)
+	assert.Nil(t, a.ApprovalRate)
+	assert.Nil(t, a.AvgDecisionLatencySeconds)
+}
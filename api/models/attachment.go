@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+// AttachmentContentTypes are the types of files that can be attached to point requests
+var AttachmentContentTypes = []string{"image/heic", "image/jpeg", "image/png", "image/webp"}
+
+// Largest file that can be attached, in bytes
+const AttachmentMaxSize = 10 * 1024 * 1024
+
+// How many files can be attached to a point request
+const AttachmentsPerPoint = 5
+
+// Attachment is a file (i.e. a photo of a completed chore) attached to a point request. The
+// file itself is kept in an attachment store, and clients up- and download it with pre-signed URLs.
+type Attachment struct {
+	ID               string    `json:"id" dynamodbav:"id"`
+	ContentType      string    `json:"content_type" dynamodbav:"content_type"`
+	Size             int64     `json:"size" dynamodbav:"size"`
+	UploadedByUserID string    `json:"uploaded_by_user_id" dynamodbav:"uploaded_by_user_id"`
+	CreatedOnStr     string    `json:"-" dynamodbav:"created_on"`
+	CreatedOn        time.Time `json:"created_on" dynamodbav:"-"`
+
+	// Pre-signed download URL. Only set in responses, as it expires.
+	URL string `json:"url,omitempty" dynamodbav:"-"`
+}
+
+// AttachmentKey returns the key of the attachment's file in the attachment store
+func (p *Point) AttachmentKey(attachmentID string) string {
+	return fmt.Sprintf("%s/%s/%s", p.UserID, p.ID, attachmentID)
+}
+
+func (a *Attachment) ParseTimes() {
+	if a.CreatedOnStr != "" {
+		a.CreatedOn = util.ParseTime_RFC3339Nano(a.CreatedOnStr)
+	}
+}
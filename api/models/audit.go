@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+type AuditAction string
+
+const AuditActionUserFamily AuditAction = "USER_FAMILY"
+const AuditActionUserLookup AuditAction = "USER_LOOKUP"
+const AuditActionUserPoints AuditAction = "USER_POINTS"
+const AuditActionUserRoles AuditAction = "USER_ROLES"
+const AuditActionUserStatus AuditAction = "USER_STATUS"
+
+// AuditEntry records an action taken by an admin (the actor) on a user's account
+type AuditEntry struct {
+	ID           string            `json:"id" dynamodbav:"id"`
+	UserID       string            `json:"user_id" dynamodbav:"user_id"`
+	ActorUserID  string            `json:"actor_user_id" dynamodbav:"actor_user_id"`
+	Action       AuditAction       `json:"action" dynamodbav:"action"`
+	Details      map[string]string `json:"details" dynamodbav:"details,omitempty"`
+	CreatedOnStr string            `json:"-" dynamodbav:"created_on"`
+	CreatedOn    time.Time         `json:"created_on" dynamodbav:"-"`
+}
+
+func (e *AuditEntry) ParseTimes() {
+	if e.CreatedOnStr != "" {
+		e.CreatedOn = util.ParseTime_RFC3339Nano(e.CreatedOnStr)
+	}
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
 type Family struct {
 	FamilyID string                  `json:"family_id"`
 	Children map[string]FamilyMember `json:"children"`
@@ -20,6 +26,34 @@ type FamilyMember struct {
 	ChildCallName string `json:"child_call_name"`
 }
 
+// FamilySettings are settings parents choose for the whole family
+type FamilySettings struct {
+	FamilyID        string    `json:"family_id" dynamodbav:"family_id"`
+	UpdatedByUserID string    `json:"updated_by_user_id" dynamodbav:"updated_by_user_id,omitempty"`
+	UpdatedOnStr    string    `json:"-" dynamodbav:"updated_on,omitempty"`
+	UpdatedOn       time.Time `json:"updated_on" dynamodbav:"-"`
+
+	// Whether children only see their own place on the family leaderboard
+	HideRankingsFromChildren bool `json:"hide_rankings_from_children" dynamodbav:"hide_rankings_from_children"`
+
+	// Language notifications to the family are written in (i.e. "en"). If not set, every member
+	// gets notifications in their own language.
+	Language string `json:"language" dynamodbav:"language,omitempty"`
+}
+
+// NewFamilySettings returns the default settings of a family
+func NewFamilySettings(familyID string) FamilySettings {
+	return FamilySettings{
+		FamilyID: familyID,
+	}
+}
+
+func (s *FamilySettings) ParseTimes() {
+	if s.UpdatedOnStr != "" {
+		s.UpdatedOn = util.ParseTime_RFC3339Nano(s.UpdatedOnStr)
+	}
+}
+
 func NewFamilyUser(user User) FamilyMember {
 	return FamilyMember{
 		Email:         user.Email,
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+// FamilyEvent is an event of a family member, kept for a while so that family members can catch
+// up on what happened. Events are numbered per family in the order they were published, starting at 1.
+type FamilyEvent struct {
+	FamilyID     string    `json:"family_id" dynamodbav:"family_id"`
+	Seq          int64     `json:"seq" dynamodbav:"seq"`
+	ID           string    `json:"id" dynamodbav:"id"`
+	Type         string    `json:"type" dynamodbav:"type"`
+	UserID       string    `json:"user_id" dynamodbav:"user_id"`
+	CreatedOnStr string    `json:"-" dynamodbav:"created_on"`
+	CreatedOn    time.Time `json:"created_on" dynamodbav:"-"`
+
+	// Data of the event as JSON
+	Data string `json:"data" dynamodbav:"data"`
+
+	// Unix time after which the event is removed
+	ExpiresOn int64 `json:"expires_on" dynamodbav:"expires_on"`
+}
+
+func (e *FamilyEvent) ParseTimes() {
+	if e.CreatedOnStr != "" {
+		e.CreatedOn = util.ParseTime_RFC3339Nano(e.CreatedOnStr)
+	}
+}
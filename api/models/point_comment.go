@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+// PointComment is a comment in the thread of a point request, by the child or one of their parents
+type PointComment struct {
+	UserID       string    `json:"user_id" dynamodbav:"user_id"` // The child the point belongs to
+	ID           string    `json:"id" dynamodbav:"id"`
+	PointID      string    `json:"point_id" dynamodbav:"point_id"`
+	AuthorUserID string    `json:"author_user_id" dynamodbav:"author_user_id"`
+	Text         string    `json:"text" dynamodbav:"text"`
+	CreatedOnStr string    `json:"-" dynamodbav:"created_on"`
+	CreatedOn    time.Time `json:"created_on" dynamodbav:"-"`
+
+	// Whether the author asked the child to amend the request with this comment
+	RequestsChanges bool `json:"requests_changes" dynamodbav:"requests_changes,omitempty"`
+}
+
+func (c *PointComment) ParseTimes() {
+	if c.CreatedOnStr != "" {
+		c.CreatedOn = util.ParseTime_RFC3339Nano(c.CreatedOnStr)
+	}
+}
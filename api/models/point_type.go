@@ -0,0 +1,154 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+// DefaultPointTypeID is the type of points that aren't tagged with a type. Every family has it.
+const DefaultPointTypeID = "points"
+
+// DefaultPointType is used by families that didn't configure the default type themselves
+var DefaultPointType = PointType{ID: DefaultPointTypeID, Name: "Points"}
+
+// CashoutRule describes what points of a type are worth when they are cashed out
+type CashoutRule struct {
+	Points  int `json:"points" dynamodbav:"points"`             // Points are cashed out in multiples of this
+	Value   int `json:"value" dynamodbav:"value"`               // What the multiple of points is worth, in the unit of the type
+	Minimum int `json:"minimum" dynamodbav:"minimum,omitempty"` // Least points that can be cashed out at once
+}
+
+// PointType is a separate bucket of points a family tracks (i.e. screen-time minutes, money or
+// privileges). Each type has its own balance.
+type PointType struct {
+	FamilyID        string    `json:"family_id" dynamodbav:"family_id"`
+	ID              string    `json:"id" dynamodbav:"id"`
+	Name            string    `json:"name" dynamodbav:"name"`
+	Unit            string    `json:"unit" dynamodbav:"unit,omitempty"`
+	CreatedByUserID string    `json:"created_by_user_id" dynamodbav:"created_by_user_id,omitempty"`
+	CreatedOnStr    string    `json:"-" dynamodbav:"created_on,omitempty"`
+	CreatedOn       time.Time `json:"created_on" dynamodbav:"-"`
+	UpdatedOnStr    string    `json:"-" dynamodbav:"updated_on,omitempty"`
+	UpdatedOn       time.Time `json:"updated_on" dynamodbav:"-"`
+
+	// Points are cashed out one for one if not set
+	Cashout *CashoutRule `json:"cashout" dynamodbav:"cashout,omitempty"`
+}
+
+// PointTypeSummary is the summary of the points of a single type
+type PointTypeSummary struct {
+	PointType PointType `json:"point_type"`
+	UserPoints
+}
+
+// IsValid returns true if points can be cashed out by the rule
+func (r CashoutRule) IsValid() bool {
+	return r.Points > 0 && r.Value > 0 && r.Minimum >= 0
+}
+
+func (t *PointType) ParseTimes() {
+	if t.CreatedOnStr != "" {
+		t.CreatedOn = util.ParseTime_RFC3339Nano(t.CreatedOnStr)
+	}
+	if t.UpdatedOnStr != "" {
+		t.UpdatedOn = util.ParseTime_RFC3339Nano(t.UpdatedOnStr)
+	}
+}
+
+// CashoutValue returns what the given points are worth by the type's cashout rule, or an
+// error if the points can't be cashed out by it
+func (t PointType) CashoutValue(points int) (int, error) {
+	if points <= 0 {
+		return 0, fmt.Errorf("points must be a positive integer")
+	}
+
+	if t.Cashout == nil {
+		return points, nil
+	}
+
+	if points < t.Cashout.Minimum {
+		return 0, fmt.Errorf("at least %d %s points must be cashed out", t.Cashout.Minimum, t.Name)
+	}
+
+	if points%t.Cashout.Points != 0 {
+		return 0, fmt.Errorf("%s points must be cashed out in multiples of %d", t.Name, t.Cashout.Points)
+	}
+
+	return points / t.Cashout.Points * t.Cashout.Value, nil
+}
+
+// TypeID returns the ID of the point's type
+func (p Point) TypeID() string {
+	if p.PointTypeID == "" {
+		return DefaultPointTypeID
+	}
+	return p.PointTypeID
+}
+
+// PointsOfType returns the points of the given type
+func PointsOfType(points []Point, typeID string) []Point {
+	filtered := []Point{}
+	for _, p := range points {
+		if p.TypeID() == typeID {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// FindPointType returns the point type with the given ID
+func FindPointType(types []PointType, typeID string) (PointType, bool) {
+	idx := slices.IndexFunc(types, func(t PointType) bool { return t.ID == typeID })
+	if idx < 0 {
+		return PointType{}, false
+	}
+	return types[idx], true
+}
+
+// MergePointTypes returns the default type followed by the types of a family. A family's own
+// default type (i.e. to set a cashout rule) replaces the built-in one.
+func MergePointTypes(familyTypes []PointType) []PointType {
+	types := []PointType{DefaultPointType}
+
+	for _, t := range familyTypes {
+		if t.ID == DefaultPointTypeID {
+			types[0] = t
+		} else {
+			types = append(types, t)
+		}
+	}
+
+	return types
+}
+
+// SummarizeByType summarizes the points (latest first) of each type separately. Points of types
+// that aren't in the given types (i.e. of another family) are summarized after them.
+func SummarizeByType(recentFromDate time.Time, types []PointType, points []Point) []PointTypeSummary {
+	byType := map[string][]Point{}
+	for _, p := range points {
+		byType[p.TypeID()] = append(byType[p.TypeID()], p)
+	}
+
+	unknown := []PointType{}
+	for id := range byType {
+		if _, ok := FindPointType(types, id); !ok {
+			unknown = append(unknown, PointType{ID: id, Name: id})
+		}
+	}
+	slices.SortFunc(unknown, func(a, b PointType) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	summaries := []PointTypeSummary{}
+	for _, t := range append(slices.Clone(types), unknown...) {
+		s := PointTypeSummary{PointType: t}
+		s.Summarize(recentFromDate, byType[t.ID])
+		summaries = append(summaries, s)
+	}
+
+	return summaries
+}
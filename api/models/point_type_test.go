@@ -0,0 +1,103 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PointType_CashoutValue(t *testing.T) {
+	type test struct {
+		name    string
+		cashout *CashoutRule
+		points  int
+		want    int
+		wantErr string
+	}
+
+	screenTime := &CashoutRule{Points: 10, Value: 30, Minimum: 20}
+
+	cases := []test{
+		{"one for one without rule", nil, 7, 7, ""},
+		{"by rule", screenTime, 40, 120, ""},
+		{"by rule - minimum", screenTime, 20, 60, ""},
+		{"fail - not positive", nil, 0, 0, "points must be a positive integer"},
+		{"fail - below minimum", screenTime, 10, 0, "at least 20 Screen time points must be cashed out"},
+		{"fail - not a multiple", screenTime, 25, 0, "Screen time points must be cashed out in multiples of 10"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pt := PointType{ID: "1", Name: "Screen time", Cashout: c.cashout}
+
+			value, err := pt.CashoutValue(c.points)
+			tests.AssertError(t, err, c.wantErr)
+			assert.Equal(t, c.want, value)
+		})
+	}
+}
+
+func Test_CashoutRule_IsValid(t *testing.T) {
+	assert.True(t, CashoutRule{Points: 1, Value: 1}.IsValid())
+	assert.False(t, CashoutRule{Points: 0, Value: 1}.IsValid())
+	assert.False(t, CashoutRule{Points: 1, Value: 0}.IsValid())
+	assert.False(t, CashoutRule{Points: 1, Value: 1, Minimum: -1}.IsValid())
+}
+
+func Test_MergePointTypes(t *testing.T) {
+	screenTime := PointType{FamilyID: "456", ID: "st", Name: "Screen time"}
+
+	types := MergePointTypes([]PointType{screenTime})
+	assert.Equal(t, []PointType{DefaultPointType, screenTime}, types)
+
+	// a family's own default type replaces the built-in one
+	ownDefault := PointType{FamilyID: "456", ID: DefaultPointTypeID, Name: "Stars", Cashout: &CashoutRule{Points: 1, Value: 5}}
+	types = MergePointTypes([]PointType{screenTime, ownDefault})
+	assert.Equal(t, []PointType{ownDefault, screenTime}, types)
+}
+
+func Test_SummarizeByType(t *testing.T) {
+	bal := func(v int) *int {
+		return &v
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -7)
+
+	// latest first
+	points := []Point{
+		{ID: "0", Status: PointStatusSettled, PointTypeID: "st", Points: 30, Balance: bal(50), UpdatedOn: now, Request: PointRequest{Type: PointRequestTypeAdd}},
+		{ID: "1", Status: PointStatusSettled, Points: 2, Balance: bal(12), UpdatedOn: now.AddDate(0, 0, -1), Request: PointRequest{Type: PointRequestTypeAdd}},
+		{ID: "2", Status: PointStatusSettled, PointTypeID: "old", Points: 1, Balance: bal(1), UpdatedOn: now.AddDate(0, 0, -1), Request: PointRequest{Type: PointRequestTypeAdd}},
+		{ID: "3", Status: PointStatusWaiting, PointTypeID: DefaultPointTypeID, Points: 3, UpdatedOn: now.AddDate(0, 0, -2), Request: PointRequest{Type: PointRequestTypeAdd}},
+		{ID: "4", Status: PointStatusSettled, PointTypeID: "st", Points: 20, Balance: bal(20), UpdatedOn: now.AddDate(0, 0, -3), Request: PointRequest{Type: PointRequestTypeAdd}},
+		{ID: "5", Status: PointStatusSettled, Points: 10, Balance: bal(10), UpdatedOn: now.AddDate(0, 0, -10), Request: PointRequest{Type: PointRequestTypeAdd}},
+	}
+
+	types := []PointType{DefaultPointType, {ID: "st", Name: "Screen time"}, {ID: "money", Name: "Money"}}
+
+	summaries := SummarizeByType(from, types, points)
+	assert.Len(t, summaries, 4)
+
+	assert.Equal(t, DefaultPointTypeID, summaries[0].PointType.ID)
+	assert.Equal(t, 12, summaries[0].Balance)
+	assert.Equal(t, 2, summaries[0].PointsLast7Days)
+	assert.Equal(t, 3, summaries[0].PointsPendingLast7Days)
+	assert.Len(t, summaries[0].RecentPoints, 2)
+
+	assert.Equal(t, "st", summaries[1].PointType.ID)
+	assert.Equal(t, 50, summaries[1].Balance)
+	assert.Equal(t, 50, summaries[1].PointsLast7Days)
+	assert.Equal(t, "st", summaries[1].RecentPoints[0].PointTypeID)
+
+	// types without points are still summarized
+	assert.Equal(t, "money", summaries[2].PointType.ID)
+	assert.Equal(t, 0, summaries[2].Balance)
+	assert.Empty(t, summaries[2].RecentPoints)
+
+	// points of types that aren't configured (anymore) come last
+	assert.Equal(t, PointType{ID: "old", Name: "old"}, summaries[3].PointType)
+	assert.Equal(t, 1, summaries[3].Balance)
+}
@@ -22,17 +22,37 @@ type PointStatus string
 const PointStatusWaiting = "WAITING"
 const PointStatusSettled = "SETTLED"
 
+// A parent asked the child to amend the points or reason before they decide on the request
+const PointStatusChangesRequested = "CHANGES_REQUESTED"
+
 type Point struct {
 	ID           string       `json:"id" dynamodbav:"id"`
 	UserID       string       `json:"user_id" dynamodbav:"user_id"`
 	Status       PointStatus  `json:"status" dynamodbav:"status"`
 	Points       int          `json:"points" dynamodbav:"points"`
-	Balance      *int         `json:"balance" dynamodbav:"balance,omitempty"`
+	PointTypeID  string       `json:"point_type_id" dynamodbav:"point_type_id,omitempty"` // Points without a type are of the default type
+	Balance      *int         `json:"balance" dynamodbav:"balance,omitempty"`             // Running balance of the point's type
 	CreatedOnStr string       `json:"-" dynamodbav:"created_on"`
 	UpdatedOnStr string       `json:"-" dynamodbav:"updated_on"`
 	CreatedOn    time.Time    `json:"created_on" dynamodbav:"-"`
 	UpdatedOn    time.Time    `json:"updated_on" dynamodbav:"-"`
 	Request      PointRequest `json:"request" dynamodbav:"request"`
+	Attachments  []Attachment `json:"attachments,omitempty" dynamodbav:"attachments,omitempty"`
+
+	// Incremented on every save, so a point can't be overwritten by someone holding an older version
+	Version int `json:"version" dynamodbav:"version"`
+}
+
+// PointBalance is the balance of a user's points of a type. It's saved along with the settled points
+// that change it, and the save fails if the balance was changed since it was read, so concurrent
+// changes can't overwrite each other.
+type PointBalance struct {
+	UserID      string `json:"user_id" dynamodbav:"user_id"`
+	PointTypeID string `json:"point_type_id" dynamodbav:"point_type_id"`
+	Balance     int    `json:"balance" dynamodbav:"balance"`
+
+	// Incremented on every save. 0 if the balance wasn't saved yet.
+	Version int `json:"version" dynamodbav:"version"`
 }
 
 type PointRequest struct {
@@ -43,6 +63,12 @@ type PointRequest struct {
 	ParentNotes     string               `json:"parent_notes" dynamodbav:"parent_notes,omitempty"`
 	Reason          string               `json:"reason" dynamodbav:"reason,omitempty"`
 	Type            PointRequestType     `json:"type" dynamodbav:"type"`
+
+	// Set on bonus points awarded for earning an achievement
+	AchievementRuleID string `json:"achievement_rule_id,omitempty" dynamodbav:"achievement_rule_id,omitempty"`
+
+	// What cashed out points are worth by the cashout rule of their type at the time of the request
+	CashoutValue int `json:"cashout_value,omitempty" dynamodbav:"cashout_value,omitempty"`
 }
 
 type QueryPointsFilter struct {
@@ -51,6 +77,7 @@ type QueryPointsFilter struct {
 	Statuses   []PointStatus
 	Types      []PointRequestType
 	Attributes []string // Which attributes to project in the query
+	Ascending  bool     // Order by updated_on ascending (oldest first) instead of latest first
 }
 
 type PointSummary struct {
@@ -59,6 +86,7 @@ type PointSummary struct {
 	ParentNotes     string               `json:"parent_notes"`
 	Reason          string               `json:"reason"`
 	Points          int                  `json:"points"`
+	PointTypeID     string               `json:"point_type_id"`
 	Type            PointRequestType     `json:"type"`
 	UpdatedOn       time.Time            `json:"updated_on"`
 	DecidedByUserID string               `json:"decided_by_user_id"`
@@ -66,12 +94,14 @@ type PointSummary struct {
 }
 
 type UserPoints struct {
-	Balance             int            `json:"balance"`
-	PointsLast7Days     int            `json:"points_last_7_days"`
-	PointsLostLast7Days int            `json:"points_lost_last_7_days"`
-	RecentCashouts      []PointSummary `json:"recent_cashouts"`
-	RecentRequests      []PointSummary `json:"recent_requests"`
-	RecentPoints        []PointSummary `json:"recent_points"`
+	Balance                  int            `json:"balance"`
+	PointsLast7Days          int            `json:"points_last_7_days"`
+	PointsLostLast7Days      int            `json:"points_lost_last_7_days"`
+	PointsCashedOutLast7Days int            `json:"points_cashed_out_last_7_days"`
+	PointsPendingLast7Days   int            `json:"points_pending_last_7_days"`
+	RecentCashouts           []PointSummary `json:"recent_cashouts"`
+	RecentRequests           []PointSummary `json:"recent_requests"`
+	RecentPoints             []PointSummary `json:"recent_points"`
 }
 
 func (p *Point) ParseTimes() {
@@ -84,6 +114,9 @@ func (p *Point) ParseTimes() {
 	if p.Request.DecidedOnStr != "" {
 		p.Request.DecidedOn = util.ParseTime_RFC3339Nano(p.Request.DecidedOnStr)
 	}
+	for idx := range p.Attachments {
+		p.Attachments[idx].ParseTimes()
+	}
 }
 
 func (p *Point) ToPointSummary() PointSummary {
@@ -92,6 +125,7 @@ func (p *Point) ToPointSummary() PointSummary {
 		UserID:          p.UserID,
 		ParentNotes:     p.Request.ParentNotes,
 		Points:          p.Points,
+		PointTypeID:     p.TypeID(),
 		Reason:          p.Request.Reason,
 		UpdatedOn:       p.UpdatedOn,
 		Type:            p.Request.Type,
@@ -107,3 +141,63 @@ func ToPointSummaries(points []Point) []PointSummary {
 	}
 	return summaries
 }
+
+// LatestBalance returns the balance of the latest settled point of the points (latest first), or 0
+// if none of them were settled yet. The points must be of a single type (see PointsOfType).
+func LatestBalance(points []Point) int {
+	for _, p := range points {
+		if p.Status == PointStatusSettled && p.Balance != nil {
+			return *p.Balance
+		}
+	}
+
+	return 0
+}
+
+// Summarize maps points (latest first) to the user's balance, recent point summaries and the
+// point amounts since the given recentFromDate
+func (up *UserPoints) Summarize(recentFromDate time.Time, points []Point) {
+
+	unsettled := []PointSummary{}
+	settled := []PointSummary{}
+	cashouts := []PointSummary{}
+
+	up.Balance = LatestBalance(points)
+
+	for _, p := range points {
+		// sum up points after given recentFromDate
+		if p.UpdatedOn.Compare(recentFromDate) >= 0 {
+			switch {
+			case p.Status == PointStatusWaiting:
+				up.PointsPendingLast7Days += p.Points
+			case p.Request.Decision == PointRequestDecisionDeny:
+				// denied requests don't change the balance
+			case p.Status == PointStatusSettled && p.Request.Type == PointRequestTypeCashout:
+				up.PointsCashedOutLast7Days += p.Points
+			case p.Status == PointStatusSettled:
+				up.PointsLast7Days += p.Points
+
+				if p.Points < 0 {
+					up.PointsLostLast7Days += p.Points
+				}
+			}
+		}
+
+		// unsettled points
+		if len(unsettled) < 3 && p.Status == PointStatusWaiting {
+			unsettled = append(unsettled, p.ToPointSummary())
+		}
+		// settled points
+		if len(settled) < 3 && p.Status == PointStatusSettled && p.Request.Type != PointRequestTypeCashout {
+			settled = append(settled, p.ToPointSummary())
+		}
+		// cashouts
+		if len(cashouts) < 3 && p.Status == PointStatusSettled && p.Request.Type == PointRequestTypeCashout {
+			cashouts = append(cashouts, p.ToPointSummary())
+		}
+	}
+
+	up.RecentRequests = unsettled
+	up.RecentPoints = settled
+	up.RecentCashouts = cashouts
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserPoints_Summarize(t *testing.T) {
+	type test struct {
+		name string
+	}
+
+	cases := []test{
+		{"happy path"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			bal := func(v int) *int {
+				return &v
+			}
+
+			// setup mock points
+			now := time.Now()
+			from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -7)
+
+			p0 := Point{ID: "0", Status: "SETTLED", Points: 1, Balance: bal(18), UpdatedOn: now.AddDate(0, 0, 0), Request: PointRequest{Type: "ADD"}}
+			p1 := Point{ID: "1", Status: "SETTLED", Points: 1, Balance: bal(17), UpdatedOn: now.AddDate(0, 0, -1), Request: PointRequest{Type: "ADD"}}
+			p2 := Point{ID: "2", Status: "SETTLED", Points: -1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -2), Request: PointRequest{Type: "SUBTRACT"}}
+			p3 := Point{ID: "3", Status: "SETTLED", Points: 1, Balance: bal(17), UpdatedOn: now.AddDate(0, 0, -3), Request: PointRequest{Type: "ADD"}}
+			p4 := Point{ID: "4", Status: "SETTLED", Points: 1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -4), Request: PointRequest{Type: "ADD"}}
+			p5 := Point{ID: "5", Status: "SETTLED", Points: -1, Balance: bal(15), UpdatedOn: now.AddDate(0, 0, -5), Request: PointRequest{Type: "CASHOUT"}}
+			p6 := Point{ID: "6", Status: "SETTLED", Points: 1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -6), Request: PointRequest{Type: "ADD"}}
+			p7 := Point{ID: "7", Status: "WAITING", Points: 1, UpdatedOn: now.AddDate(0, 0, -7), Request: PointRequest{Type: "ADD"}}
+			p8 := Point{ID: "8", Status: "SETTLED", Points: -1, Balance: bal(15), UpdatedOn: now.AddDate(0, 0, -8), Request: PointRequest{Type: "SUBTRACT"}}
+			p9 := Point{ID: "9", Status: "SETTLED", Points: -1, Balance: bal(16), UpdatedOn: now.AddDate(0, 0, -9), Request: PointRequest{Type: "CASHOUT"}}
+
+			points := []Point{p0, p1, p2, p3, p4, p5, p6, p7, p8, p9}
+
+			up := &UserPoints{}
+			up.Summarize(from, points)
+
+			assert.Equal(t, 18, up.Balance)
+			assert.Equal(t, 4, up.PointsLast7Days)
+			assert.Equal(t, -1, up.PointsLostLast7Days)
+			assert.Equal(t, -1, up.PointsCashedOutLast7Days)
+			assert.Equal(t, 1, up.PointsPendingLast7Days)
+
+			assert.Len(t, up.RecentPoints, 3)
+			assert.Len(t, up.RecentRequests, 1)
+			assert.Len(t, up.RecentCashouts, 2)
+
+			// assert recent points
+			assert.Equal(t, "0", up.RecentPoints[0].ID)
+			assert.Equal(t, "1", up.RecentPoints[1].ID)
+			assert.Equal(t, "2", up.RecentPoints[2].ID)
+
+			// assert open requests
+			assert.Equal(t, "7", up.RecentRequests[0].ID)
+
+			// assert cashouts
+			assert.Equal(t, "5", up.RecentCashouts[0].ID)
+			assert.Equal(t, "9", up.RecentCashouts[1].ID)
+		})
+	}
+}
+
+func Test_LatestBalance(t *testing.T) {
+	bal := func(v int) *int {
+		return &v
+	}
+
+	type test struct {
+		name   string
+		points []Point
+		want   int
+	}
+
+	cases := []test{
+		{"first settled point", []Point{
+			{Status: PointStatusWaiting, Points: 3},
+			{Status: PointStatusSettled, Points: -2, Balance: bal(0)},
+			{Status: PointStatusSettled, Points: 2, Balance: bal(2)},
+		}, 0},
+		{"settled without balance", []Point{
+			{Status: PointStatusSettled, Points: 2},
+			{Status: PointStatusSettled, Points: 5, Balance: bal(5)},
+		}, 5},
+		{"nothing settled", []Point{{Status: PointStatusWaiting, Points: 3}}, 0},
+		{"no points", []Point{}, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, LatestBalance(c.points))
+		})
+	}
+}
@@ -14,6 +14,10 @@ const UserStatusDeleted UserStatus = "DELETED"
 const UserStatusInactive UserStatus = "INACTIVE"
 const UserStatusUnverified UserStatus = "UNVERIFIED"
 
+const RoleAdmin = "admin"
+const RoleChild = "child"
+const RoleParent = "parent"
+
 type User struct {
 	Email        string     `json:"email" dynamodbav:"email"`
 	FamilyIDs    []string   `json:"family_ids" dynamodbav:"family_ids"`
@@ -29,6 +33,59 @@ type User struct {
 
 	// Name used in app and displayed to children (i.e. "Mom")
 	ChildCallName string `json:"child_call_name" dynamodbav:"child_call_name"`
+
+	// Email the user changed to, which replaces Email once the user verifies it
+	PendingEmail string `json:"pending_email,omitempty" dynamodbav:"pending_email,omitempty"`
+
+	// When the last registration confirmation code was sent (used to rate limit resending codes)
+	ConfirmationSentOnStr string `json:"-" dynamodbav:"confirmation_sent_on,omitempty"`
+
+	// How the user wants to be notified about point requests and decisions
+	Notifications NotificationPreferences `json:"notifications" dynamodbav:"notifications"`
+
+	// Incremented on every write, so a user can't be overwritten by someone holding an older version
+	Version int `json:"version" dynamodbav:"version"`
+}
+
+// NotificationPreferences holds the user's notification settings. Email and push are
+// enabled unless turned off.
+type NotificationPreferences struct {
+	Email     *bool  `json:"email" dynamodbav:"email,omitempty"`
+	Push      *bool  `json:"push" dynamodbav:"push,omitempty"`
+	PushToken string `json:"push_token" dynamodbav:"push_token,omitempty"`
+
+	// Parents get a weekly summary of their children's points unless turned off
+	WeeklyDigest *bool `json:"weekly_digest" dynamodbav:"weekly_digest,omitempty"`
+
+	// Language notifications are written in (i.e. "en")
+	Language string `json:"language" dynamodbav:"language,omitempty"`
+}
+
+// UserProfileUpdate holds the profile fields of a user that can be changed after registration.
+// Fields that are nil are left unchanged.
+type UserProfileUpdate struct {
+	ChildCallName *string                  `json:"child_call_name"`
+	Email         *string                  `json:"email"`
+	Name          *string                  `json:"name"`
+	Notifications *NotificationPreferences `json:"notifications"`
+
+	// Set in code, an empty pending email removes it
+	PendingEmail *string `json:"-"`
+}
+
+// EmailEnabled returns true if the user wants to be notified by email
+func (p NotificationPreferences) EmailEnabled() bool {
+	return p.Email == nil || *p.Email
+}
+
+// WeeklyDigestEnabled returns true if the user wants the weekly digest
+func (p NotificationPreferences) WeeklyDigestEnabled() bool {
+	return p.WeeklyDigest == nil || *p.WeeklyDigest
+}
+
+// PushEnabled returns true if the user wants push notifications and has a device to send them to
+func (p NotificationPreferences) PushEnabled() bool {
+	return (p.Push == nil || *p.Push) && p.PushToken != ""
 }
 
 func (u *User) ParseTimes() {
@@ -41,13 +98,23 @@ func (u *User) ParseTimes() {
 }
 
 func (u *User) IsAdmin() bool {
-	return slices.Contains(u.Roles, "admin")
+	return slices.Contains(u.Roles, RoleAdmin)
 }
 
 func (u *User) IsChild() bool {
-	return slices.Contains(u.Roles, "child")
+	return slices.Contains(u.Roles, RoleChild)
 }
 
 func (u *User) IsParent() bool {
-	return slices.Contains(u.Roles, "parent")
+	return slices.Contains(u.Roles, RoleParent)
+}
+
+// HasAnyRole returns true if the user is assigned to at least one of the given roles
+func (u *User) HasAnyRole(roles ...string) bool {
+	for _, r := range roles {
+		if slices.Contains(u.Roles, r) {
+			return true
+		}
+	}
+	return false
 }
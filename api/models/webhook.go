@@ -0,0 +1,64 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util"
+)
+
+type WebhookDeliveryStatus string
+
+const WebhookDeliveryStatusFailed WebhookDeliveryStatus = "FAILED"
+const WebhookDeliveryStatusPending WebhookDeliveryStatus = "PENDING"
+const WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+
+// Webhook is a URL a family's events are sent to
+type Webhook struct {
+	FamilyID        string    `json:"family_id" dynamodbav:"family_id"`
+	ID              string    `json:"id" dynamodbav:"id"`
+	URL             string    `json:"url" dynamodbav:"url"`
+	EventTypes      []string  `json:"event_types" dynamodbav:"event_types"`
+	CreatedByUserID string    `json:"created_by_user_id" dynamodbav:"created_by_user_id"`
+	CreatedOnStr    string    `json:"-" dynamodbav:"created_on"`
+	CreatedOn       time.Time `json:"created_on" dynamodbav:"-"`
+
+	// Key deliveries are signed with. Only shown once, when the webhook is created.
+	Secret string `json:"-" dynamodbav:"secret"`
+}
+
+// WebhookDelivery records the attempts to send an event to a webhook
+type WebhookDelivery struct {
+	WebhookID    string                `json:"webhook_id" dynamodbav:"webhook_id"`
+	ID           string                `json:"id" dynamodbav:"id"`
+	EventID      string                `json:"event_id" dynamodbav:"event_id"`
+	EventType    string                `json:"event_type" dynamodbav:"event_type"`
+	Status       WebhookDeliveryStatus `json:"status" dynamodbav:"status"`
+	Attempts     int                   `json:"attempts" dynamodbav:"attempts"`
+	ResponseCode int                   `json:"response_code" dynamodbav:"response_code,omitempty"`
+	Error        string                `json:"error" dynamodbav:"error,omitempty"`
+	CreatedOnStr string                `json:"-" dynamodbav:"created_on"`
+	UpdatedOnStr string                `json:"-" dynamodbav:"updated_on"`
+	CreatedOn    time.Time             `json:"created_on" dynamodbav:"-"`
+	UpdatedOn    time.Time             `json:"updated_on" dynamodbav:"-"`
+}
+
+func (w *Webhook) ParseTimes() {
+	if w.CreatedOnStr != "" {
+		w.CreatedOn = util.ParseTime_RFC3339Nano(w.CreatedOnStr)
+	}
+}
+
+// HasEventType returns true if the webhook wants to receive events of the given type
+func (w *Webhook) HasEventType(eventType string) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+func (d *WebhookDelivery) ParseTimes() {
+	if d.CreatedOnStr != "" {
+		d.CreatedOn = util.ParseTime_RFC3339Nano(d.CreatedOnStr)
+	}
+	if d.UpdatedOnStr != "" {
+		d.UpdatedOn = util.ParseTime_RFC3339Nano(d.UpdatedOnStr)
+	}
+}
@@ -0,0 +1,176 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/auth"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// Cognito users younger than this are skipped, since their registration may still be in progress
+const minUserAge = 10 * time.Minute
+
+type RepairAction string
+
+const RepairActionCreateUser RepairAction = "CREATE_USER"
+const RepairActionDeleteCognitoUser RepairAction = "DELETE_COGNITO_USER"
+const RepairActionScrubUser RepairAction = "SCRUB_USER"
+
+// Repair describes a single inconsistency between cognito and the user table, and how it was (or would be) fixed
+type Repair struct {
+	Action   RepairAction `json:"action"`
+	UserID   string       `json:"user_id"`
+	Username string       `json:"username"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type Report struct {
+	CognitoUsers int      `json:"cognito_users"`
+	Users        int      `json:"users"`
+	Repairs      []Repair `json:"repairs"`
+}
+
+// Reconciler finds users that only exist in either cognito or the user table and repairs them
+type Reconciler struct {
+	auth   auth.AuthController
+	userDB storage.IUserStorage
+}
+
+func NewReconciler(ctx context.Context, env string) (*Reconciler, error) {
+	storageCfg, err := storage.ConfigFromEnv(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage config: %w", err)
+	}
+
+	userDB, err := storage.NewStorage(storageCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize user db: %w", err)
+	}
+
+	authController, err := auth.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth controller: %w", err)
+	}
+
+	return &Reconciler{
+		auth:   authController,
+		userDB: userDB,
+	}, nil
+}
+
+// Run compares all cognito users with all user records and repairs the differences:
+//   - confirmed cognito users without a user record get a new user record
+//   - unconfirmed cognito users without a user record are deleted, so their username is freed up
+//   - user records without a cognito user are scrubbed
+//
+// If dryRun is true, the repairs are only reported but not applied.
+func (r *Reconciler) Run(ctx context.Context, dryRun bool) (Report, error) {
+	report := Report{Repairs: []Repair{}}
+
+	poolUsers, err := r.auth.ListUsers(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list cognito users: %w", err)
+	}
+
+	users, err := r.userDB.GetAllUsers(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	report.CognitoUsers = len(poolUsers)
+	report.Users = len(users)
+
+	logger := log.Get().WithContext(ctx).AddField("dry_run", dryRun)
+
+	poolUserIDs := map[string]bool{}
+	userIDs := map[string]bool{}
+
+	for _, pu := range poolUsers {
+		poolUserIDs[pu.UserID] = true
+	}
+	for _, u := range users {
+		userIDs[u.UserID] = true
+	}
+
+	for _, pu := range poolUsers {
+		if userIDs[pu.UserID] || time.Since(pu.CreatedOn) < minUserAge {
+			continue
+		}
+
+		repair := Repair{UserID: pu.UserID, Username: pu.Username}
+		if pu.Confirmed {
+			repair.Action = RepairActionCreateUser
+		} else {
+			repair.Action = RepairActionDeleteCognitoUser
+		}
+
+		if !dryRun {
+			if err := r.repairPoolUser(ctx, pu); err != nil {
+				repair.Error = err.Error()
+			}
+		}
+
+		report.Repairs = append(report.Repairs, repair)
+	}
+
+	for _, u := range users {
+		if poolUserIDs[u.UserID] || u.Status == models.UserStatusDeleted {
+			continue
+		}
+
+		repair := Repair{Action: RepairActionScrubUser, UserID: u.UserID, Username: u.Username}
+
+		if !dryRun {
+			if err := r.userDB.ScrubUser(ctx, u.UserID); err != nil {
+				repair.Error = err.Error()
+			}
+		}
+
+		report.Repairs = append(report.Repairs, repair)
+	}
+
+	for _, repair := range report.Repairs {
+		fields := map[string]any{
+			"action":   repair.Action,
+			"user_id":  repair.UserID,
+			"username": repair.Username,
+		}
+
+		if repair.Error != "" {
+			fields["error"] = repair.Error
+			logger.WithFields(fields).Errorf("failed to repair user")
+		} else {
+			logger.WithFields(fields).Infof("repaired user")
+		}
+	}
+
+	return report, nil
+}
+
+func (r *Reconciler) repairPoolUser(ctx context.Context, pu auth.PoolUser) error {
+	if !pu.Confirmed {
+		return r.auth.DeleteUser(ctx, pu.Username)
+	}
+
+	status := models.UserStatusActive
+	if !pu.Enabled {
+		status = models.UserStatusInactive
+	}
+
+	return r.userDB.SaveUser(ctx, models.User{
+		UserID:       pu.UserID,
+		Username:     pu.Username,
+		Email:        pu.Email,
+		Name:         pu.Name,
+		Status:       status,
+		CreatedOnStr: util.ToFormattedUTC(pu.CreatedOn),
+		UpdatedOnStr: util.ToFormattedUTC(time.Now()),
+		FamilyIDs:    []string{},
+		Roles:        []string{},
+	})
+}
@@ -0,0 +1,119 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authmocks "github.com/sebboness/yektaspoints/mocks/auth"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/auth"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+func Test_Reconciler_Run(t *testing.T) {
+	type state struct {
+		dryRun     bool
+		listErr    error
+		getErr     error
+		saveErr    error
+		deleteErr  error
+		scrubErr   error
+		skipRepair bool
+	}
+	type want struct {
+		err     string
+		repairs []Repair
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	repairs := []Repair{
+		{Action: RepairActionCreateUser, UserID: "2", Username: "jane"},
+		{Action: RepairActionDeleteCognitoUser, UserID: "3", Username: "jim"},
+		{Action: RepairActionScrubUser, UserID: "5", Username: "jill"},
+	}
+
+	failedRepairs := []Repair{
+		{Action: RepairActionCreateUser, UserID: "2", Username: "jane", Error: "fail"},
+		{Action: RepairActionDeleteCognitoUser, UserID: "3", Username: "jim", Error: "fail"},
+		{Action: RepairActionScrubUser, UserID: "5", Username: "jill", Error: "fail"},
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", repairs}},
+		{"happy path - dry run", state{dryRun: true, skipRepair: true}, want{"", repairs}},
+		{"happy path - repairs fail", state{saveErr: errFail, deleteErr: errFail, scrubErr: errFail}, want{"", failedRepairs}},
+		{"fail - list cognito users", state{listErr: errFail, skipRepair: true}, want{"failed to list cognito users: fail", nil}},
+		{"fail - get users", state{getErr: errFail, skipRepair: true}, want{"failed to get users: fail", nil}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			ctx := context.Background()
+			mockAuther := authmocks.NewMockAuthController(t)
+			mockUserDB := mocks.NewMockIUserStorage(t)
+
+			created := time.Now().Add(-time.Hour)
+
+			poolUsers := []auth.PoolUser{
+				// in sync
+				{UserID: "1", Username: "john", Confirmed: true, Enabled: true, CreatedOn: created},
+				// confirmed, but missing user record
+				{UserID: "2", Username: "jane", Email: "jane@info.co", Name: "Jane", Confirmed: true, CreatedOn: created},
+				// unconfirmed and missing user record
+				{UserID: "3", Username: "jim", CreatedOn: created},
+				// missing user record, but registration may still be in progress
+				{UserID: "4", Username: "joe", CreatedOn: time.Now()},
+			}
+
+			users := []models.User{
+				{UserID: "1", Username: "john", Status: models.UserStatusActive},
+				// missing cognito user
+				{UserID: "5", Username: "jill", Status: models.UserStatusActive},
+				// missing cognito user, but already deleted
+				{UserID: "6", Status: models.UserStatusDeleted},
+			}
+
+			mockAuther.EXPECT().ListUsers(mock.Anything).Return(poolUsers, c.state.listErr).Once()
+			if c.state.listErr == nil {
+				mockUserDB.EXPECT().GetAllUsers(mock.Anything).Return(users, c.state.getErr).Once()
+			}
+
+			if !c.state.skipRepair {
+				mockUserDB.EXPECT().SaveUser(mock.Anything, mock.MatchedBy(func(u models.User) bool {
+					return u.UserID == "2" && u.Username == "jane" && u.Email == "jane@info.co" && u.Status == models.UserStatusInactive
+				})).Return(c.state.saveErr).Once()
+				mockAuther.EXPECT().DeleteUser(mock.Anything, "jim").Return(c.state.deleteErr).Once()
+				mockUserDB.EXPECT().ScrubUser(mock.Anything, "5").Return(c.state.scrubErr).Once()
+			}
+
+			r := Reconciler{
+				auth:   mockAuther,
+				userDB: mockUserDB,
+			}
+
+			report, err := r.Run(ctx, c.state.dryRun)
+
+			tests.AssertError(t, err, c.want.err)
+			if err == nil {
+				assert.Equal(t, 4, report.CognitoUsers)
+				assert.Equal(t, 3, report.Users)
+				assert.Equal(t, c.want.repairs, report.Repairs)
+			}
+
+			mockAuther.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sebboness/yektaspoints/util/env"
+)
+
+// Tables of the storage, as they're named in table names (i.e. "mypoints-dev-point-comment")
+const (
+	TableAchievement     = "achievement"
+	TableAchievementRule = "achievement-rule"
+	TableAudit           = "audit"
+	TableFamilyEvent     = "family-event"
+	TableFamilySettings  = "family-settings"
+	TableFamilyUser      = "family-user"
+	TablePointBalance    = "point-balance"
+	TablePointComment    = "point-comment"
+	TablePointType       = "point-type"
+	TablePoints          = "points"
+	TableUser            = "user"
+	TableWebhook         = "webhook"
+	TableWebhookDelivery = "webhook-delivery"
+)
+
+// Backends that points, users and families can be stored in
+const (
+	BackendDynamoDb = "dynamodb"
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+)
+
+var Tables = []string{
+	TableAchievement,
+	TableAchievementRule,
+	TableAudit,
+	TableFamilyEvent,
+	TableFamilySettings,
+	TableFamilyUser,
+	TablePointBalance,
+	TablePointComment,
+	TablePointType,
+	TablePoints,
+	TableUser,
+	TableWebhook,
+	TableWebhookDelivery,
+}
+
+type Config struct {
+	Env string
+
+	// Backend of points and their balances, users and families (dynamodb, sqlite or postgres).
+	// Defaults to dynamodb. Everything else (achievements, point comments, point types, webhooks
+	// and their deliveries, the audit trail and family events) is always stored in DynamoDB, so
+	// the SQL backends still need DynamoDB, or DynamoDB Local, next to them. NewStorages fails
+	// if those tables can't be found.
+	Backend string
+
+	// Data source name of the SQL backend (i.e. "file:mypoints.db" or "postgres://user:pw@host/mypoints")
+	DSN string
+
+	// DynamoDB endpoint to use instead of AWS (i.e. "http://localhost:8000" for DynamoDB Local)
+	Endpoint string
+
+	// Region of the tables. Defaults to the region of the AWS config (i.e. AWS_REGION).
+	Region string
+
+	// Prefix of table names. Defaults to "mypoints-<env>-".
+	TablePrefix string
+
+	// Names of tables that don't follow the prefix, by table (i.e. {"points": "legacy-points"})
+	TableNames map[string]string
+
+	// How often a request is attempted before it fails. Defaults to the SDK's standard retries.
+	MaxAttempts int
+
+	// Longest wait between two attempts of a request. Defaults to the SDK's standard retries.
+	MaxBackoff time.Duration
+
+	// How long to wait for a response to a request. Defaults to no timeout.
+	Timeout time.Duration
+}
+
+// ConfigFromEnv returns the storage config of the environment, with overrides from these
+// environment variables:
+//   - STORAGE_BACKEND and STORAGE_DSN to store points, users and families in SQL (the other
+//     tables stay in DynamoDB)
+//   - DYNAMODB_ENDPOINT, DYNAMODB_REGION and DYNAMODB_TABLE_PREFIX
+//   - DYNAMODB_TABLE_<TABLE> for the name of a single table (i.e. DYNAMODB_TABLE_POINT_COMMENT)
+//   - DYNAMODB_MAX_ATTEMPTS, DYNAMODB_MAX_BACKOFF and DYNAMODB_TIMEOUT (durations like "5s")
+func ConfigFromEnv(_env string) (Config, error) {
+	return configFromLookup(_env, env.GetEnv)
+}
+
+func configFromLookup(_env string, lookup func(key string) string) (Config, error) {
+	cfg := Config{
+		Env:         _env,
+		Backend:     lookup("STORAGE_BACKEND"),
+		DSN:         lookup("STORAGE_DSN"),
+		Endpoint:    lookup("DYNAMODB_ENDPOINT"),
+		Region:      lookup("DYNAMODB_REGION"),
+		TablePrefix: lookup("DYNAMODB_TABLE_PREFIX"),
+		TableNames:  map[string]string{},
+	}
+
+	switch cfg.Backend {
+	case "", BackendDynamoDb:
+	case BackendPostgres, BackendSQLite:
+		if cfg.DSN == "" {
+			return cfg, fmt.Errorf("STORAGE_DSN must be set for the %s backend", cfg.Backend)
+		}
+	default:
+		return cfg, fmt.Errorf("STORAGE_BACKEND must be one of dynamodb, sqlite or postgres (value=%s)", cfg.Backend)
+	}
+
+	for _, table := range Tables {
+		key := "DYNAMODB_TABLE_" + strings.ToUpper(strings.ReplaceAll(table, "-", "_"))
+		if name := lookup(key); name != "" {
+			cfg.TableNames[table] = name
+		}
+	}
+
+	if v := lookup("DYNAMODB_MAX_ATTEMPTS"); v != "" {
+		attempts, err := strconv.Atoi(v)
+		if err != nil || attempts < 1 {
+			return cfg, fmt.Errorf("DYNAMODB_MAX_ATTEMPTS must be a positive integer (value=%s)", v)
+		}
+		cfg.MaxAttempts = attempts
+	}
+
+	var err error
+	if cfg.MaxBackoff, err = durationFromLookup(lookup, "DYNAMODB_MAX_BACKOFF"); err != nil {
+		return cfg, err
+	}
+	if cfg.Timeout, err = durationFromLookup(lookup, "DYNAMODB_TIMEOUT"); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func durationFromLookup(lookup func(key string) string, key string) (time.Duration, error) {
+	v := lookup(key)
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a positive duration (value=%s)", key, v)
+	}
+
+	return d, nil
+}
+
+// TableName returns the name of the table (i.e. "mypoints-dev-points")
+func (c Config) TableName(table string) string {
+	if name, ok := c.TableNames[table]; ok {
+		return name
+	}
+
+	prefix := c.TablePrefix
+	if prefix == "" {
+		prefix = fmt.Sprintf("mypoints-%s-", strings.ToLower(c.Env))
+	}
+
+	return prefix + table
+}
+
+// NewDynamoDbClient returns a client of the config's endpoint and region, with the config's
+// retries and timeouts
+func NewDynamoDbClient(ctx context.Context, cfg Config) (*dynamodb.Client, error) {
+	opts := []func(*config.LoadOptions) error{}
+
+	if cfg.Region != "" {
+		opts = append(opts, config.WithRegion(cfg.Region))
+	}
+
+	if cfg.MaxAttempts > 0 || cfg.MaxBackoff > 0 {
+		opts = append(opts, config.WithRetryer(func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				if cfg.MaxAttempts > 0 {
+					o.MaxAttempts = cfg.MaxAttempts
+				}
+				if cfg.MaxBackoff > 0 {
+					o.MaxBackoff = cfg.MaxBackoff
+				}
+			})
+		}))
+	}
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, config.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(cfg.Timeout)))
+	}
+
+	sdkConfig, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	return dynamodb.NewFromConfig(sdkConfig, func(o *dynamodb.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	}), nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_configFromLookup(t *testing.T) {
+	type state struct {
+		vars map[string]string
+	}
+	type want struct {
+		err string
+		cfg Config
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - defaults", state{}, want{"", Config{Env: "dev", TableNames: map[string]string{}}}},
+		{"happy path - all set", state{vars: map[string]string{
+			"DYNAMODB_ENDPOINT":            "http://localhost:8000",
+			"DYNAMODB_REGION":              "us-west-2",
+			"DYNAMODB_TABLE_PREFIX":        "test-",
+			"DYNAMODB_TABLE_POINT_COMMENT": "legacy-comments",
+			"DYNAMODB_MAX_ATTEMPTS":        "5",
+			"DYNAMODB_MAX_BACKOFF":         "2s",
+			"DYNAMODB_TIMEOUT":             "500ms",
+		}}, want{"", Config{
+			Env:         "dev",
+			Endpoint:    "http://localhost:8000",
+			Region:      "us-west-2",
+			TablePrefix: "test-",
+			TableNames:  map[string]string{TablePointComment: "legacy-comments"},
+			MaxAttempts: 5,
+			MaxBackoff:  2 * time.Second,
+			Timeout:     500 * time.Millisecond,
+		}}},
+		{"happy path - sql backend", state{vars: map[string]string{
+			"STORAGE_BACKEND": "sqlite",
+			"STORAGE_DSN":     "file:mypoints.db",
+		}}, want{"", Config{Env: "dev", Backend: BackendSQLite, DSN: "file:mypoints.db", TableNames: map[string]string{}}}},
+		{"fail - unknown backend", state{vars: map[string]string{"STORAGE_BACKEND": "mysql"}}, want{"STORAGE_BACKEND must be one of dynamodb, sqlite or postgres (value=mysql)", Config{}}},
+		{"fail - missing dsn", state{vars: map[string]string{"STORAGE_BACKEND": "postgres"}}, want{"STORAGE_DSN must be set for the postgres backend", Config{}}},
+		{"fail - max attempts not a number", state{vars: map[string]string{"DYNAMODB_MAX_ATTEMPTS": "many"}}, want{"DYNAMODB_MAX_ATTEMPTS must be a positive integer (value=many)", Config{}}},
+		{"fail - max attempts zero", state{vars: map[string]string{"DYNAMODB_MAX_ATTEMPTS": "0"}}, want{"DYNAMODB_MAX_ATTEMPTS must be a positive integer (value=0)", Config{}}},
+		{"fail - max backoff", state{vars: map[string]string{"DYNAMODB_MAX_BACKOFF": "2"}}, want{"DYNAMODB_MAX_BACKOFF must be a positive duration (value=2)", Config{}}},
+		{"fail - timeout", state{vars: map[string]string{"DYNAMODB_TIMEOUT": "-1s"}}, want{"DYNAMODB_TIMEOUT must be a positive duration (value=-1s)", Config{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, err := configFromLookup("dev", func(key string) string {
+				return c.state.vars[key]
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, c.want.cfg, cfg)
+			}
+		})
+	}
+}
+
+func Test_Config_TableName(t *testing.T) {
+	type state struct {
+		cfg Config
+	}
+	type want struct {
+		name string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"default prefix", state{Config{Env: "DEV"}}, want{"mypoints-dev-point-comment"}},
+		{"table prefix", state{Config{Env: "dev", TablePrefix: "test-"}}, want{"test-point-comment"}},
+		{"table name", state{Config{Env: "dev", TablePrefix: "test-", TableNames: map[string]string{TablePointComment: "legacy-comments"}}}, want{"legacy-comments"}},
+		{"other table name", state{Config{Env: "dev", TableNames: map[string]string{TablePoints: "legacy-points"}}}, want{"mypoints-dev-point-comment"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want.name, c.state.cfg.TableName(TablePointComment))
+		})
+	}
+}
@@ -0,0 +1,577 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sebboness/yektaspoints/migrate"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/stretchr/testify/assert"
+)
+
+// Conformance tests run the same tests against every storage backend:
+//   - SQLite, in memory
+//   - PostgreSQL at STORAGE_TEST_POSTGRES_DSN, skipped if not set
+//   - DynamoDB Local (i.e. "docker run -p 8000:8000 amazon/dynamodb-local") at
+//     DYNAMODB_TEST_ENDPOINT, or http://localhost:8000 if not set. Skipped if it isn't running.
+//
+// Every run creates its own tables (or IDs, in PostgreSQL), so runs don't see each other's data.
+const defaultTestEndpoint = "http://localhost:8000"
+
+type backend struct {
+	name string
+	open func(t *testing.T) storage.Storage
+}
+
+var backends = []backend{
+	{"SQLite", sqliteStorage},
+	{"Postgres", postgresStorage},
+	{"DynamoDb", dynamoDbStorage},
+}
+
+func TestConformance_UserStorage(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			testUserStorage(t, b.open(t), testPrefix())
+		})
+	}
+}
+
+func TestConformance_PointsStorage(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			testPointsStorage(t, b.open(t), testPrefix())
+		})
+	}
+}
+
+func TestConformance_FamilyStorage(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			testFamilyStorage(t, b.open(t), testPrefix())
+		})
+	}
+}
+
+// testPrefix returns a prefix of IDs that are unique to the test run
+func testPrefix() string {
+	return fmt.Sprintf("it%d-", time.Now().UnixNano())
+}
+
+func sqliteStorage(t *testing.T) storage.Storage {
+	t.Helper()
+
+	// a named in-memory database, which is shared by the connections of the storage
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	return sqlStorage(t, storage.Config{
+		Backend: storage.BackendSQLite,
+		DSN:     fmt.Sprintf("file:%s?mode=memory&cache=shared", name),
+	})
+}
+
+func postgresStorage(t *testing.T) storage.Storage {
+	t.Helper()
+
+	dsn := os.Getenv("STORAGE_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("STORAGE_TEST_POSTGRES_DSN is not set")
+	}
+
+	return sqlStorage(t, storage.Config{
+		Backend: storage.BackendPostgres,
+		DSN:     dsn,
+	})
+}
+
+func sqlStorage(t *testing.T, cfg storage.Config) storage.Storage {
+	t.Helper()
+
+	s, err := storage.NewSQLStorage(cfg)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	if _, err := migrate.NewSQLMigrator(s, migrate.SQLMigrations).Run(context.Background(), false); err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+
+	return s
+}
+
+// dynamoDbStorage returns storage on new tables of DynamoDB Local, or skips the test
+func dynamoDbStorage(t *testing.T) storage.Storage {
+	t.Helper()
+
+	endpoint := os.Getenv("DYNAMODB_TEST_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultTestEndpoint
+	}
+
+	// DynamoDB Local accepts any credentials, but they must be set
+	for key, value := range map[string]string{
+		"AWS_ACCESS_KEY_ID":     "local",
+		"AWS_SECRET_ACCESS_KEY": "local",
+		"AWS_REGION":            "us-west-2",
+	} {
+		if os.Getenv(key) == "" {
+			t.Setenv(key, value)
+		}
+	}
+
+	ctx := context.Background()
+	cfg := storage.Config{
+		Env:         "test",
+		Endpoint:    endpoint,
+		TablePrefix: testPrefix(),
+		MaxAttempts: 1,
+		Timeout:     2 * time.Second,
+	}
+
+	client, err := storage.NewDynamoDbClient(ctx, cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.ListTables(ctx, &dynamodb.ListTablesInput{Limit: aws.Int32(1)}); err != nil {
+		t.Skipf("DynamoDB Local is not available at %s: %v", endpoint, err)
+	}
+
+	t.Cleanup(func() {
+		deleteTables(t, client, cfg.TablePrefix)
+	})
+
+	m, err := migrate.NewMigrator(ctx, cfg)
+	if err != nil {
+		t.Fatalf("failed to create migrator: %v", err)
+	}
+
+	if _, err := m.Run(ctx, false); err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+
+	s, err := storage.NewDynamoDbStorage(cfg)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	return s
+}
+
+func deleteTables(t *testing.T, client *dynamodb.Client, prefix string) {
+	ctx := context.Background()
+
+	paginator := dynamodb.NewListTablesPaginator(client, &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			t.Logf("failed to list tables: %v", err)
+			return
+		}
+
+		for _, name := range resp.TableNames {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+
+			if _, err := client.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(name)}); err != nil {
+				t.Logf("failed to delete table %s: %v", name, err)
+			}
+		}
+	}
+}
+
+func assertIs(t *testing.T, err, target error) {
+	t.Helper()
+	assert.True(t, errors.Is(err, target), "want %v, got %v", target, err)
+}
+
+func testUserStorage(t *testing.T, s storage.Storage, prefix string) {
+	ctx := context.Background()
+
+	user := models.User{
+		UserID:       prefix + "u1",
+		Email:        prefix + "u1@example.com",
+		Username:     prefix + "u1",
+		Name:         "User One",
+		Roles:        []string{models.RoleParent},
+		Status:       models.UserStatusActive,
+		CreatedOnStr: "2024-01-01T00:00:00Z",
+	}
+
+	err := s.SaveUser(ctx, user)
+	assert.Nil(t, err)
+
+	saved, err := s.GetUserByID(ctx, user.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, user.Email, saved.Email)
+	assert.Equal(t, user.Roles, saved.Roles)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), saved.CreatedOn)
+	assert.Equal(t, 1, saved.Version)
+
+	byEmail, err := s.GetUserByEmail(ctx, user.Email)
+	assert.Nil(t, err)
+	assert.Equal(t, user.UserID, byEmail.UserID)
+
+	byUsername, err := s.GetUserByUsername(ctx, user.Username)
+	assert.Nil(t, err)
+	assert.Equal(t, user.UserID, byUsername.UserID)
+
+	_, err = s.GetUserByID(ctx, prefix+"unknown")
+	assertIs(t, err, apierr.NotFound)
+
+	// a new user never overwrites an existing one
+	err = s.SaveUser(ctx, user)
+	assertIs(t, err, apierr.Conflict)
+
+	err = s.AddUserFamily(ctx, user.UserID, "f1")
+	assert.Nil(t, err)
+	err = s.AddUserFamily(ctx, user.UserID, "f2")
+	assert.Nil(t, err)
+	err = s.RemoveUserFamily(ctx, user.UserID, "f1")
+	assert.Nil(t, err)
+
+	// the family updates changed the version that was read before
+	err = s.SaveUser(ctx, saved)
+	assertIs(t, err, apierr.Conflict)
+
+	name := "Mom"
+	err = s.UpdateUserProfile(ctx, user.UserID, models.UserProfileUpdate{ChildCallName: &name})
+	assert.Nil(t, err)
+
+	// a pending email is set and removed without changing the email
+	pending, cleared := prefix+"new@example.com", ""
+	err = s.UpdateUserProfile(ctx, user.UserID, models.UserProfileUpdate{PendingEmail: &pending})
+	assert.Nil(t, err)
+
+	withPending, err := s.GetUserByID(ctx, user.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, user.Email, withPending.Email)
+	assert.Equal(t, pending, withPending.PendingEmail)
+
+	err = s.UpdateUserProfile(ctx, user.UserID, models.UserProfileUpdate{PendingEmail: &cleared})
+	assert.Nil(t, err)
+
+	withPending, err = s.GetUserByID(ctx, user.UserID)
+	assert.Nil(t, err)
+	assert.Empty(t, withPending.PendingEmail)
+
+	err = s.UpdateUserRoles(ctx, user.UserID, []string{models.RoleParent, models.RoleAdmin})
+	assert.Nil(t, err)
+
+	sentOn := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	err = s.UpdateUserConfirmationSentOn(ctx, user.UserID, sentOn, time.Minute)
+	assert.Nil(t, err)
+
+	err = s.UpdateUserConfirmationSentOn(ctx, user.UserID, sentOn.Add(30*time.Second), time.Minute)
+	assertIs(t, err, apierr.TooManyRequests)
+
+	updated, err := s.GetUserByID(ctx, user.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"f2"}, updated.FamilyIDs)
+	assert.Equal(t, "Mom", updated.ChildCallName)
+	assert.Equal(t, user.Name, updated.Name)
+	assert.Equal(t, []string{models.RoleParent, models.RoleAdmin}, updated.Roles)
+	assert.Equal(t, 9, updated.Version)
+
+	// saving the latest version succeeds
+	updated.Name = "User 1"
+	err = s.SaveUser(ctx, updated)
+	assert.Nil(t, err)
+
+	err = s.ScrubUser(ctx, user.UserID)
+	assert.Nil(t, err)
+
+	scrubbed, err := s.GetUserByID(ctx, user.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.UserStatusDeleted, scrubbed.Status)
+	assert.Empty(t, scrubbed.Email)
+	assert.Empty(t, scrubbed.Name)
+
+	_, err = s.GetUserByEmail(ctx, user.Email)
+	assertIs(t, err, apierr.NotFound)
+
+	all, err := s.GetAllUsers(ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, userIDs(all), user.UserID)
+
+	err = s.UpdateUserStatus(ctx, prefix+"unknown", models.UserStatusActive)
+	assertIs(t, err, apierr.NotFound)
+}
+
+func userIDs(users []models.User) []string {
+	ids := []string{}
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+func testPointsStorage(t *testing.T, s storage.Storage, prefix string) {
+	ctx := context.Background()
+
+	userID := prefix + "u1"
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	on := func(days int) string {
+		return util.ToFormatted(start.AddDate(0, 0, days))
+	}
+	point := func(id string, points int, status models.PointStatus, typ models.PointRequestType, createdOn, updatedOn int) models.Point {
+		return models.Point{
+			UserID:       userID,
+			ID:           id,
+			Points:       points,
+			Status:       status,
+			CreatedOnStr: on(createdOn),
+			UpdatedOnStr: on(updatedOn),
+			Request:      models.PointRequest{Type: typ, Reason: "reason of " + id},
+		}
+	}
+
+	points := []models.Point{
+		point("p1", 5, models.PointStatusSettled, models.PointRequestTypeAdd, 0, 1),
+		point("p2", 3, models.PointStatusWaiting, models.PointRequestTypeAdd, 1, 2),
+		point("p3", -2, models.PointStatusSettled, models.PointRequestTypeSubtract, 2, 3),
+		point("p4", -4, models.PointStatusSettled, models.PointRequestTypeCashout, 3, 4),
+	}
+
+	err := s.SavePoints(ctx, points, nil)
+	assert.Nil(t, err)
+
+	// saved in a single transaction, so none are saved if one already exists
+	err = s.SavePoints(ctx, []models.Point{
+		point("p5", 1, models.PointStatusWaiting, models.PointRequestTypeAdd, 4, 5),
+		points[0],
+	}, nil)
+	assertIs(t, err, apierr.Conflict)
+
+	_, err = s.GetPointByID(ctx, userID, "p5")
+	assertIs(t, err, apierr.NotFound)
+
+	ids := func(filters models.QueryPointsFilter) []string {
+		t.Helper()
+
+		found, err := s.GetPointsByUserID(ctx, userID, filters)
+		assert.Nil(t, err)
+
+		ids := []string{}
+		for _, p := range found {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	from := start.AddDate(0, 0, 2)
+	to := start.AddDate(0, 0, 3)
+
+	assert.Equal(t, []string{"p4", "p3", "p2", "p1"}, ids(models.QueryPointsFilter{}))
+	assert.Equal(t, []string{"p1", "p2", "p3", "p4"}, ids(models.QueryPointsFilter{Ascending: true}))
+	assert.Equal(t, []string{"p4", "p3", "p1"}, ids(models.QueryPointsFilter{Statuses: []models.PointStatus{models.PointStatusSettled}}))
+	assert.Equal(t, []string{"p4", "p3"}, ids(models.QueryPointsFilter{Types: []models.PointRequestType{models.PointRequestTypeSubtract, models.PointRequestTypeCashout}}))
+	assert.Equal(t, []string{"p3", "p2"}, ids(models.QueryPointsFilter{UpdatedOn: models.DateFilter{From: &from, To: &to}}))
+	assert.Equal(t, []string{"p4", "p3"}, ids(models.QueryPointsFilter{UpdatedOn: models.DateFilter{From: &to}}))
+	assert.Equal(t, []string{"p3", "p2", "p1"}, ids(models.QueryPointsFilter{CreatedOn: models.DateFilter{To: &from}}))
+	assert.Equal(t, []string{"p3"}, ids(models.QueryPointsFilter{
+		CreatedOn: models.DateFilter{From: &from},
+		Statuses:  []models.PointStatus{models.PointStatusSettled},
+		Types:     []models.PointRequestType{models.PointRequestTypeSubtract},
+	}))
+
+	// only the attributes are read
+	projected, err := s.GetPointsByUserID(ctx, userID, models.QueryPointsFilter{
+		Attributes: []string{"id", "points", "request.type"},
+		Ascending:  true,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, projected, 4)
+	assert.Equal(t, "p1", projected[0].ID)
+	assert.Equal(t, 5, projected[0].Points)
+	assert.Equal(t, models.PointRequestTypeAdd, projected[0].Request.Type)
+	assert.Empty(t, projected[0].Request.Reason)
+	assert.Empty(t, projected[0].UserID)
+
+	// streamed points are projected just the same
+	err = s.StreamPointsByUserID(ctx, userID, models.QueryPointsFilter{Attributes: []string{"id", "points"}}, func(p models.Point) error {
+		assert.NotEmpty(t, p.ID)
+		assert.NotZero(t, p.Points)
+		assert.Empty(t, p.Request.Reason)
+		assert.Empty(t, p.UserID)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	// streaming stops at the first error
+	streamed := 0
+	errStop := errors.New("stop")
+	err = s.StreamPointsByUserID(ctx, userID, models.QueryPointsFilter{}, func(p models.Point) error {
+		streamed++
+		return errStop
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, 1, streamed)
+
+	p, err := s.GetPointByID(ctx, userID, "p2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, p.Version)
+
+	balance := 8
+	p.Status = models.PointStatusSettled
+	p.Balance = &balance
+	err = s.SavePoint(ctx, p)
+	assert.Nil(t, err)
+
+	// saved again from the same, now outdated, read
+	err = s.SavePoint(ctx, p)
+	assertIs(t, err, apierr.Conflict)
+
+	p, err = s.GetPointByID(ctx, userID, "p2")
+	assert.Nil(t, err)
+	assert.Equal(t, models.PointStatus(models.PointStatusSettled), p.Status)
+	assert.Equal(t, &balance, p.Balance)
+	assert.Equal(t, 2, p.Version)
+
+	err = s.ScrubPoints(ctx, userID)
+	assert.Nil(t, err)
+
+	scrubbed, err := s.GetPointsByUserID(ctx, userID, models.QueryPointsFilter{Ascending: true})
+	assert.Nil(t, err)
+	assert.Len(t, scrubbed, 4)
+	for idx, p := range scrubbed {
+		assert.Empty(t, p.Request.Reason)
+		assert.Equal(t, points[idx].Points, p.Points)
+	}
+
+	// a balance that wasn't saved yet is the one of the latest settled point
+	read, err := s.GetPointBalance(ctx, userID, models.DefaultPointTypeID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.PointBalance{UserID: userID, PointTypeID: models.DefaultPointTypeID, Balance: 8}, read)
+
+	changed := read
+	changed.Balance += 6
+	p6 := point("p6", 6, models.PointStatusSettled, models.PointRequestTypeAdd, 6, 6)
+	p6.Balance = &changed.Balance
+	err = s.SavePoints(ctx, []models.Point{p6}, []models.PointBalance{changed})
+	assert.Nil(t, err)
+
+	saved, err := s.GetPointBalance(ctx, userID, models.DefaultPointTypeID)
+	assert.Nil(t, err)
+	assert.Equal(t, 14, saved.Balance)
+	assert.Equal(t, 1, saved.Version)
+
+	// saved again from the same, now outdated, read, so neither the balance nor the point is saved
+	read.Balance += 1
+	err = s.SavePoints(ctx, []models.Point{point("p7", 1, models.PointStatusSettled, models.PointRequestTypeAdd, 7, 7)}, []models.PointBalance{read})
+	assertIs(t, err, apierr.Conflict)
+
+	_, err = s.GetPointByID(ctx, userID, "p7")
+	assertIs(t, err, apierr.NotFound)
+
+	saved.Balance -= 4
+	err = s.SavePoints(ctx, nil, []models.PointBalance{saved})
+	assert.Nil(t, err)
+
+	saved, err = s.GetPointBalance(ctx, userID, models.DefaultPointTypeID)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, saved.Balance)
+	assert.Equal(t, 2, saved.Version)
+}
+
+func testFamilyStorage(t *testing.T, s storage.Storage, prefix string) {
+	ctx := context.Background()
+
+	familyID := prefix + "f1"
+	parent := models.User{UserID: prefix + "parent", Email: prefix + "parent@example.com", Username: prefix + "parent", Name: "Parent", Roles: []string{models.RoleParent}}
+	child := models.User{UserID: prefix + "child", Email: prefix + "child@example.com", Username: prefix + "child", Name: "Child", Roles: []string{models.RoleChild}}
+
+	for _, u := range []models.User{parent, child} {
+		err := s.SaveUser(ctx, u)
+		assert.Nil(t, err)
+
+		err = s.AddFamilyUser(ctx, models.FamilyUser{FamilyID: familyID, UserID: u.UserID})
+		assert.Nil(t, err)
+	}
+
+	err := s.AddFamilyUser(ctx, models.FamilyUser{FamilyID: familyID})
+	assertIs(t, err, apierr.InvalidInput)
+
+	familyUsers, err := s.GetFamilyUsers(ctx, familyID)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.FamilyUser{
+		{FamilyID: familyID, UserID: child.UserID},
+		{FamilyID: familyID, UserID: parent.UserID},
+	}, familyUsers)
+
+	family, err := s.GetFamilyMembersByUserIDs(ctx, familyID, []string{parent.UserID, child.UserID})
+	assert.Nil(t, err)
+	assert.Equal(t, familyID, family.FamilyID)
+	assert.Equal(t, models.NewFamilyUser(parent), family.Parents[parent.UserID])
+	assert.Equal(t, models.NewFamilyUser(child), family.Children[child.UserID])
+
+	_, err = s.GetFamilyMembersByUserIDs(ctx, familyID, []string{prefix + "unknown"})
+	assertIs(t, err, apierr.NotFound)
+
+	err = s.RemoveFamilyUser(ctx, models.FamilyUser{FamilyID: familyID, UserID: child.UserID})
+	assert.Nil(t, err)
+
+	familyUsers, err = s.GetFamilyUsers(ctx, familyID)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.FamilyUser{{FamilyID: familyID, UserID: parent.UserID}}, familyUsers)
+
+	_, err = s.GetFamilyUsers(ctx, prefix+"unknown")
+	assertIs(t, err, apierr.NotFound)
+
+	// moving updates the user and both families together
+	familyID2 := prefix + "f2"
+	err = s.AddUserFamily(ctx, parent.UserID, familyID)
+	assert.Nil(t, err)
+
+	err = s.MoveFamilyUser(ctx, parent.UserID, familyID, familyID2)
+	assert.Nil(t, err)
+
+	moved, err := s.GetUserByID(ctx, parent.UserID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{familyID2}, moved.FamilyIDs)
+
+	familyUsers, err = s.GetFamilyUsers(ctx, familyID2)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.FamilyUser{{FamilyID: familyID2, UserID: parent.UserID}}, familyUsers)
+
+	_, err = s.GetFamilyUsers(ctx, familyID)
+	assertIs(t, err, apierr.NotFound)
+
+	err = s.MoveFamilyUser(ctx, parent.UserID, familyID, familyID2)
+	assertIs(t, err, apierr.NotFound)
+
+	settings, err := s.GetFamilySettings(ctx, familyID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.NewFamilySettings(familyID), settings)
+
+	settings.HideRankingsFromChildren = true
+	settings.UpdatedByUserID = parent.UserID
+	err = s.SaveFamilySettings(ctx, settings)
+	assert.Nil(t, err)
+
+	settings, err = s.GetFamilySettings(ctx, familyID)
+	assert.Nil(t, err)
+	assert.True(t, settings.HideRankingsFromChildren)
+	assert.Equal(t, parent.UserID, settings.UpdatedByUserID)
+
+	err = s.DeleteFamilySettings(ctx, familyID)
+	assert.Nil(t, err)
+
+	settings, err = s.GetFamilySettings(ctx, familyID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.NewFamilySettings(familyID), settings)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+// How often RetryOnConflict attempts a write before giving up
+const ConflictRetryAttempts = 3
+
+// How long to wait before the first retry of a conflicting write. The wait is doubled after
+// every conflict.
+var ConflictRetryBackoff = 25 * time.Millisecond
+
+// How often BatchGetItem is called for the same keys before the keys DynamoDB left unprocessed
+// fail the read
+const batchGetRetryAttempts = 5
+
+// How long to wait before the first retry of unprocessed keys. The wait is doubled after every
+// retry.
+var batchGetRetryBackoff = 50 * time.Millisecond
+
+// RetryOnConflict calls fn until it doesn't fail with a conflict error, or runs out of attempts.
+// Only use it when fn (re-)reads the items it writes, so that every attempt applies its changes
+// on top of the latest version instead of overwriting someone else's.
+func RetryOnConflict(ctx context.Context, fn func(ctx context.Context) error) error {
+	return RetryOnConflictN(ctx, ConflictRetryAttempts, fn)
+}
+
+// RetryOnConflictN is RetryOnConflict with the given number of attempts
+func RetryOnConflictN(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	var err error
+
+	wait := ConflictRetryBackoff
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+
+		err = fn(ctx)
+		if !errors.Is(err, apierr.Conflict) {
+			return err
+		}
+
+		logger.WithContext(ctx).WithField("attempt", attempt).Warnf("write conflicted")
+	}
+
+	return err
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apierr "github.com/sebboness/yektaspoints/util/error"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RetryOnConflict(t *testing.T) {
+	type state struct {
+		conflicts int
+		err       error
+	}
+	type want struct {
+		calls int
+		err   string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{1, ""}},
+		{"happy path - after conflicts", state{conflicts: 2}, want{3, ""}},
+		{"fail - too many conflicts", state{conflicts: 3}, want{3, "conflict: point (id=1) was changed by someone else"}},
+		{"fail - other error", state{err: errFail}, want{1, "fail"}},
+	}
+
+	backoff := ConflictRetryBackoff
+	ConflictRetryBackoff = time.Millisecond
+	defer func() { ConflictRetryBackoff = backoff }()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+
+			err := RetryOnConflict(context.Background(), func(ctx context.Context) error {
+				calls++
+				if calls <= c.state.conflicts {
+					return fmt.Errorf("failed to save point: %w", conflictError("point", "1"))
+				}
+				return c.state.err
+			})
+
+			tests.AssertError(t, err, c.want.err)
+			assert.Equal(t, c.want.calls, calls)
+		})
+	}
+}
+
+func Test_RetryOnConflictN_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	err := RetryOnConflictN(ctx, 5, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return apierr.New(apierr.Conflict)
+	})
+
+	tests.AssertError(t, err, "conflict")
+	assert.Equal(t, 1, calls)
+}
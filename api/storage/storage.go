@@ -2,57 +2,173 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/sebboness/yektaspoints/models"
 	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
 	"github.com/sebboness/yektaspoints/util/log"
 )
 
 type DynamoDbClient interface {
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
 	ExecuteStatement(ctx context.Context, params *dynamodb.ExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error)
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
 	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
 	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
 
-	// We shouldn't use scan
-	// Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	// We shouldn't use scan in request handlers. It's only meant for maintenance jobs (i.e. reconciliation)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 }
 
 type DynamoDbStorage struct {
 	client          DynamoDbClient
+	tableAudit      string
 	tableFamilyUser string
 	tablePoints     string
 	tableUser       string
-}
 
-type Config struct {
-	Env string
+	// balances are kept with points, in whichever backend points are stored
+	tablePointBalance string
+
+	tableAchievement     string
+	tableAchievementRule string
+	tableFamilyEvent     string
+	tableFamilySettings  string
+	tablePointComment    string
+	tablePointType       string
+
+	tableWebhook         string
+	tableWebhookDelivery string
 }
 
 var logger = log.Get()
 
 func NewDynamoDbStorage(cfg Config) (*DynamoDbStorage, error) {
-	sdkConfig, err := config.LoadDefaultConfig(context.TODO())
+	dynamoClient, err := NewDynamoDbClient(context.TODO(), cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load aws config: %w", err)
+		return nil, err
 	}
 
-	dynamoClient := dynamodb.NewFromConfig(sdkConfig)
-
 	return &DynamoDbStorage{
 		client:          dynamoClient,
-		tablePoints:     fmt.Sprintf("mypoints-%s-points", strings.ToLower(cfg.Env)),
-		tableUser:       fmt.Sprintf("mypoints-%s-user", strings.ToLower(cfg.Env)),
-		tableFamilyUser: fmt.Sprintf("mypoints-%s-family-user", strings.ToLower(cfg.Env)),
+		tableAudit:      cfg.TableName(TableAudit),
+		tablePoints:     cfg.TableName(TablePoints),
+		tableUser:       cfg.TableName(TableUser),
+		tableFamilyUser: cfg.TableName(TableFamilyUser),
+
+		tablePointBalance: cfg.TableName(TablePointBalance),
+
+		tableAchievement:     cfg.TableName(TableAchievement),
+		tableAchievementRule: cfg.TableName(TableAchievementRule),
+		tableFamilyEvent:     cfg.TableName(TableFamilyEvent),
+		tableFamilySettings:  cfg.TableName(TableFamilySettings),
+		tablePointComment:    cfg.TableName(TablePointComment),
+		tablePointType:       cfg.TableName(TablePointType),
+
+		tableWebhook:         cfg.TableName(TableWebhook),
+		tableWebhookDelivery: cfg.TableName(TableWebhookDelivery),
 	}, nil
 }
 
+// Storage is the storage of points, users and families, which can be kept in DynamoDB or SQL
+type Storage interface {
+	IFamilyStorage
+	IPointsStorage
+	IUserStorage
+}
+
+// NewStorage returns the storage of points, users and families of the config's backend
+func NewStorage(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case BackendPostgres, BackendSQLite:
+		return NewSQLStorage(cfg)
+	default:
+		return NewDynamoDbStorage(cfg)
+	}
+}
+
+// Storages are all storages of an environment. Build them once at startup and share them with
+// every controller and service, so they all use the same DynamoDB client and SQL connection pool.
+type Storages struct {
+	// Achievements, point comments, point types, webhooks and their deliveries, the audit trail
+	// and family events. These are always kept in DynamoDB, whichever backend is configured.
+	DynamoDb *DynamoDbStorage
+
+	// Points and their balances, users and families, kept in the backend of the config. The
+	// DynamoDB storage itself unless the backend is SQL.
+	Core Storage
+}
+
+// Tables that are kept in DynamoDB, whichever backend is configured
+var dynamoDbOnlyTables = []string{
+	TableAchievement,
+	TableAchievementRule,
+	TableAudit,
+	TableFamilyEvent,
+	TablePointComment,
+	TablePointType,
+	TableWebhook,
+	TableWebhookDelivery,
+}
+
+// NewStorages returns the storages of the config
+func NewStorages(cfg Config) (Storages, error) {
+	db, err := NewDynamoDbStorage(cfg)
+	if err != nil {
+		return Storages{}, fmt.Errorf("failed to initialize dynamodb storage: %w", err)
+	}
+
+	return newStorages(context.TODO(), cfg, db)
+}
+
+// newStorages returns the storages of the config with the given DynamoDB storage. The SQL backends
+// fail right away if the tables that stay in DynamoDB can't be found, instead of failing the first
+// request of a feature that needs them.
+func newStorages(ctx context.Context, cfg Config, db *DynamoDbStorage) (Storages, error) {
+	switch cfg.Backend {
+	case BackendPostgres, BackendSQLite:
+		if err := db.describeTables(ctx, cfg, dynamoDbOnlyTables); err != nil {
+			return Storages{}, fmt.Errorf("the %s backend still needs DynamoDB for achievements, point comments, "+
+				"point types, webhooks and their deliveries, the audit trail and family events: %w", cfg.Backend, err)
+		}
+
+		core, err := NewSQLStorage(cfg)
+		if err != nil {
+			return Storages{}, fmt.Errorf("failed to initialize %s storage: %w", cfg.Backend, err)
+		}
+		return Storages{DynamoDb: db, Core: core}, nil
+	default:
+		return Storages{DynamoDb: db, Core: db}, nil
+	}
+}
+
+// describeTables fails if one of the tables doesn't exist, or DynamoDB can't be reached
+func (s *DynamoDbStorage) describeTables(ctx context.Context, cfg Config, tables []string) error {
+	for _, table := range tables {
+		_, err := s.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+			TableName: aws.String(cfg.TableName(table)),
+		})
+
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return fmt.Errorf("failed to describe table %s: %w", cfg.TableName(table), apiErr)
+		}
+	}
+
+	return nil
+}
+
 // dateFilterExpression builds a date filter expression based on the given date filter.
 // If both from and to dates are given, returns a "Between" filter.
 // If only from date is given, returns a "greater than or equal to" filter.
@@ -129,3 +245,112 @@ func selectAttributesExpression(attribs []string) expression.ProjectionBuilder {
 
 	return projEx
 }
+
+// versionCondition returns the condition for saving an item that was read at the given version.
+// Version 0 is a new item, or one that was saved before items were versioned.
+func versionCondition(version int) expression.ConditionBuilder {
+	if version == 0 {
+		return expression.AttributeNotExists(expression.Name("version"))
+	}
+
+	return expression.Name("version").Equal(expression.Value(version))
+}
+
+// bumpVersion adds incrementing the item's version to the update, so that anyone holding an
+// older version of the item fails to save it
+func bumpVersion(update expression.UpdateBuilder) expression.UpdateBuilder {
+	return update.Add(expression.Name("version"), expression.Value(1))
+}
+
+// conditionFailed returns whether a write was rejected because its condition expression was
+// false, either on its own or as part of a transaction
+func conditionFailed(err error) bool {
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return true
+	}
+
+	var txErr *types.TransactionCanceledException
+	if errors.As(err, &txErr) {
+		for _, r := range txErr.CancellationReasons {
+			if aws.ToString(r.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// conflictError is returned when an item was changed since it was read
+func conflictError(item, id string) error {
+	return apierr.New(apierr.Conflict).WithError(fmt.Sprintf("%s (id=%s) was changed by someone else, please reload and try again", item, id))
+}
+
+// Most keys a single BatchGetItem request can read
+const maxBatchGetKeys = 100
+
+// batchGetItems reads the items of the keys from the table, projecting only the attributes (or
+// all attributes if none are given). Keys are read in chunks of at most maxBatchGetKeys, and the
+// keys DynamoDB leaves unprocessed (i.e. when throttled) are retried with backoff. Items are
+// returned in no particular order, and keys must be unique.
+func (s *DynamoDbStorage) batchGetItems(ctx context.Context, table string, keys []map[string]types.AttributeValue, attribs []string) ([]map[string]types.AttributeValue, error) {
+	items := []map[string]types.AttributeValue{}
+
+	var projection *string
+	var names map[string]string
+
+	if len(attribs) > 0 {
+		expr, err := expression.NewBuilder().WithProjection(selectAttributesExpression(attribs)).Build()
+		if err != nil {
+			return items, fmt.Errorf("failed to build projection expression: %w", err)
+		}
+		projection = expr.Projection()
+		names = expr.Names()
+	}
+
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		end := min(start+maxBatchGetKeys, len(keys))
+
+		request := map[string]types.KeysAndAttributes{
+			table: {
+				Keys:                     keys[start:end],
+				ProjectionExpression:     projection,
+				ExpressionAttributeNames: names,
+			},
+		}
+
+		wait := batchGetRetryBackoff
+		for attempt := 1; len(request) > 0; attempt++ {
+			if attempt > 1 {
+				unprocessed := len(request[table].Keys)
+				if attempt > batchGetRetryAttempts {
+					return items, fmt.Errorf("failed to read %d keys of table %s after %d attempts", unprocessed, table, batchGetRetryAttempts)
+				}
+
+				logger.WithContext(ctx).WithField("attempt", attempt).Warnf("retrying %d unprocessed keys", unprocessed)
+
+				select {
+				case <-ctx.Done():
+					return items, ctx.Err()
+				case <-time.After(wait):
+				}
+				wait *= 2
+			}
+
+			resp, err := s.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: request,
+			})
+
+			if err != nil {
+				apiErr := apierr.GetAwsError(err)
+				return items, fmt.Errorf("failed to batch get items: %w", apiErr)
+			}
+
+			items = append(items, resp.Responses[table]...)
+			request = resp.UnprocessedKeys
+		}
+	}
+
+	return items, nil
+}
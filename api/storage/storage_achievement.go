@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type IAchievementStorage interface {
+	AddAchievement(ctx context.Context, achievement models.Achievement) (bool, error)
+	DeleteAchievementRule(ctx context.Context, familyId, id string) error
+	GetAchievementRulesByFamilyID(ctx context.Context, familyId string) ([]models.AchievementRule, error)
+	GetAchievementsByUserID(ctx context.Context, userId string) ([]models.Achievement, error)
+	SaveAchievementRule(ctx context.Context, rule models.AchievementRule) error
+}
+
+// AddAchievement adds the achievement to the user. Returns false if the user already earned it.
+func (s *DynamoDbStorage) AddAchievement(ctx context.Context, achievement models.Achievement) (bool, error) {
+
+	if achievement.UserID == "" || achievement.RuleID == "" {
+		return false, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing user_id or rule_id")
+	}
+
+	item, err := attributevalue.MarshalMap(achievement)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal map from achievement: %w", err)
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("rule_id"))).Build()
+	if err != nil {
+		return false, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(s.tableAchievement),
+		Item:                     item,
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+	})
+
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return false, nil
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return false, apiErr
+	}
+
+	return true, nil
+}
+
+func (s *DynamoDbStorage) DeleteAchievementRule(ctx context.Context, familyId, id string) error {
+
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableAchievementRule),
+		Key: map[string]types.AttributeValue{
+			"family_id": &types.AttributeValueMemberS{Value: familyId},
+			"id":        &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// GetAchievementRulesByFamilyID returns the rules the family defined. The default rules aren't included.
+func (s *DynamoDbStorage) GetAchievementRulesByFamilyID(ctx context.Context, familyId string) ([]models.AchievementRule, error) {
+	rules := []models.AchievementRule{}
+
+	keyEx := expression.Key("family_id").Equal(expression.Value(familyId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return rules, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableAchievementRule),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return rules, fmt.Errorf("failed to query next achievement rules page: %w", apiErr)
+		}
+
+		var queriedRules []models.AchievementRule
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedRules)
+		if err != nil {
+			return rules, fmt.Errorf("failed to unmarshal achievement rules from query response: %w", err)
+		}
+
+		for _, r := range queriedRules {
+			r.ParseTimes()
+			rules = append(rules, r)
+		}
+	}
+
+	return rules, nil
+}
+
+func (s *DynamoDbStorage) GetAchievementsByUserID(ctx context.Context, userId string) ([]models.Achievement, error) {
+	achievements := []models.Achievement{}
+
+	keyEx := expression.Key("user_id").Equal(expression.Value(userId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return achievements, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableAchievement),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return achievements, fmt.Errorf("failed to query next achievements page: %w", apiErr)
+		}
+
+		var queriedAchievements []models.Achievement
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedAchievements)
+		if err != nil {
+			return achievements, fmt.Errorf("failed to unmarshal achievements from query response: %w", err)
+		}
+
+		for _, a := range queriedAchievements {
+			a.ParseTimes()
+			achievements = append(achievements, a)
+		}
+	}
+
+	return achievements, nil
+}
+
+func (s *DynamoDbStorage) SaveAchievementRule(ctx context.Context, rule models.AchievementRule) error {
+
+	if rule.FamilyID == "" || rule.ID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id or id")
+	}
+
+	item, err := attributevalue.MarshalMap(rule)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from achievement rule: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableAchievementRule),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
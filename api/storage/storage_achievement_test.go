@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_IAchievementStorage_AddAchievement(t *testing.T) {
+	type state struct {
+		missingRule bool
+		errPut      error
+	}
+	type want struct {
+		err   string
+		added bool
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", true}},
+		{"happy path - already earned", state{errPut: &types.ConditionalCheckFailedException{}}, want{"", false}},
+		{"fail - missing rule", state{missingRule: true}, want{"missing user_id or rule_id", false}},
+		{"fail - put item", state{errPut: errFail}, want{"fail", false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			achievement := models.Achievement{UserID: "1", RuleID: "streak-3", Name: "On a roll"}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingRule {
+				achievement.RuleID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+					return input.ConditionExpression != nil &&
+						input.Item["rule_id"].(*types.AttributeValueMemberS).Value == "streak-3"
+				})).Return(&dynamodb.PutItemOutput{}, c.state.errPut)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			added, err := s.AddAchievement(context.Background(), achievement)
+			tests.AssertError(t, err, c.want.err)
+			assert.Equal(t, c.want.added, added)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IAchievementStorage_DeleteAchievementRule(t *testing.T) {
+	type state struct {
+		errDelete error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - delete", state{errDelete: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+				return input.Key["family_id"].(*types.AttributeValueMemberS).Value == "456" &&
+					input.Key["id"].(*types.AttributeValueMemberS).Value == "1"
+			})).Return(&dynamodb.DeleteItemOutput{}, c.state.errDelete)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.DeleteAchievementRule(context.Background(), "456", "1")
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IAchievementStorage_GetAchievementRulesByFamilyID(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next achievement rules page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal achievement rules from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"family_id":  &types.AttributeValueMemberS{Value: "456"},
+						"id":         &types.AttributeValueMemberS{Value: "1"},
+						"type":       &types.AttributeValueMemberS{Value: "STREAK"},
+						"threshold":  &types.AttributeValueMemberN{Value: "5"},
+						"created_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"threshold": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetAchievementRulesByFamilyID(context.Background(), "456")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res, 1)
+				assert.Equal(t, models.AchievementRuleTypeStreak, res[0].Type)
+				assert.Equal(t, 5, res[0].Threshold)
+				assert.False(t, res[0].CreatedOn.IsZero())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IAchievementStorage_GetAchievementsByUserID(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next achievements page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal achievements from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"user_id":   &types.AttributeValueMemberS{Value: "1"},
+						"rule_id":   &types.AttributeValueMemberS{Value: "streak-3"},
+						"name":      &types.AttributeValueMemberS{Value: "On a roll"},
+						"earned_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"bonus_points": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetAchievementsByUserID(context.Background(), "1")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res, 1)
+				assert.Equal(t, "streak-3", res[0].RuleID)
+				assert.False(t, res[0].EarnedOn.IsZero())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IAchievementStorage_SaveAchievementRule(t *testing.T) {
+	type state struct {
+		missingId bool
+		errSave   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing id", state{missingId: true}, want{"missing family_id or id"}},
+		{"fail - put item", state{errSave: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule := models.AchievementRule{FamilyID: "456", ID: "1", Type: models.AchievementRuleTypeTotal, Threshold: 50}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingId {
+				rule.ID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, c.state.errSave)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SaveAchievementRule(context.Background(), rule)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type IAuditStorage interface {
+	GetAuditEntriesByUserID(ctx context.Context, userId string) ([]models.AuditEntry, error)
+	SaveAuditEntry(ctx context.Context, entry models.AuditEntry) error
+}
+
+// GetAuditEntriesByUserID returns all audit entries recorded for the given user, latest first
+func (s *DynamoDbStorage) GetAuditEntriesByUserID(ctx context.Context, userId string) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+
+	keyEx := expression.Key("user_id").Equal(expression.Value(userId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return entries, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableAudit),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(false), // ids are ksuids, so this orders by creation date descending
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return entries, fmt.Errorf("failed to query next audit entries page: %w", apiErr)
+		}
+
+		var queriedEntries []models.AuditEntry
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedEntries)
+		if err != nil {
+			return entries, fmt.Errorf("failed to unmarshal audit entries from query response: %w", err)
+		}
+
+		for _, e := range queriedEntries {
+			e.ParseTimes()
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *DynamoDbStorage) SaveAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+
+	if entry.UserID == "" || entry.ID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing user_id or id")
+	}
+
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from audit entry: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableAudit),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
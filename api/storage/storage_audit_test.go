@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_IAuditStorage_GetAuditEntriesByUserID(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next audit entries page"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal audit entries from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"id":            &types.AttributeValueMemberS{Value: "2"},
+						"user_id":       &types.AttributeValueMemberS{Value: "456"},
+						"actor_user_id": &types.AttributeValueMemberS{Value: "1"},
+						"action":        &types.AttributeValueMemberS{Value: "USER_STATUS"},
+						"created_on":    &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+					{
+						"id":            &types.AttributeValueMemberS{Value: "1"},
+						"user_id":       &types.AttributeValueMemberS{Value: "456"},
+						"actor_user_id": &types.AttributeValueMemberS{Value: "1"},
+						"action":        &types.AttributeValueMemberS{Value: "USER_ROLES"},
+						"created_on":    &types.AttributeValueMemberS{Value: "2024-03-09T20:00:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"details": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetAuditEntriesByUserID(context.Background(), "456")
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Len(t, res, 2)
+				assert.Equal(t, models.AuditActionUserStatus, res[0].Action)
+				assert.Equal(t, 2024, res[0].CreatedOn.Year())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IAuditStorage_SaveAuditEntry(t *testing.T) {
+	type state struct {
+		missingId bool
+		errSave   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing id", state{missingId: true}, want{"missing user_id or id"}},
+		{"fail - save", state{errSave: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entry := models.AuditEntry{
+				ID:          "1",
+				UserID:      "456",
+				ActorUserID: "1",
+				Action:      models.AuditActionUserStatus,
+				Details:     map[string]string{"status": "ACTIVE"},
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingId {
+				entry.ID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, c.state.errSave)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SaveAuditEntry(context.Background(), entry)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+// How often appending an event is attempted when other events of the family are appended at the same time
+const familyEventAppendAttempts = 5
+
+type IEventStorage interface {
+	AppendFamilyEvent(ctx context.Context, evt models.FamilyEvent) (models.FamilyEvent, error)
+	GetFamilyEvents(ctx context.Context, familyId string, afterSeq int64) ([]models.FamilyEvent, error)
+}
+
+// familyEventCounter is the item at seq 0 of a family. Its version is the seq of the family's last event.
+type familyEventCounter struct {
+	FamilyID string `dynamodbav:"family_id"`
+	Seq      int64  `dynamodbav:"seq"`
+	Version  int    `dynamodbav:"version"`
+}
+
+// AppendFamilyEvent saves the event as the family's next event and returns it with its seq.
+// The family's counter is bumped in the same transaction, so seqs have no gaps and readers
+// never see an event before all earlier events of the family.
+func (s *DynamoDbStorage) AppendFamilyEvent(ctx context.Context, evt models.FamilyEvent) (models.FamilyEvent, error) {
+
+	if evt.FamilyID == "" || evt.ID == "" {
+		return evt, apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id or id")
+	}
+
+	err := RetryOnConflictN(ctx, familyEventAppendAttempts, func(ctx context.Context) error {
+		counter, err := s.getFamilyEventCounter(ctx, evt.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		evt.Seq = int64(counter.Version) + 1
+		return s.appendFamilyEvent(ctx, evt, counter.Version)
+	})
+
+	return evt, err
+}
+
+func (s *DynamoDbStorage) getFamilyEventCounter(ctx context.Context, familyId string) (familyEventCounter, error) {
+	counter := familyEventCounter{FamilyID: familyId}
+
+	key, err := attributevalue.MarshalMap(counter)
+	if err != nil {
+		return counter, fmt.Errorf("failed to marshal key: %w", err)
+	}
+	delete(key, "version")
+
+	resp, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableFamilyEvent),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return counter, fmt.Errorf("failed to get family event counter: %w", apiErr)
+	}
+
+	if resp.Item == nil {
+		return counter, nil
+	}
+
+	err = attributevalue.UnmarshalMap(resp.Item, &counter)
+	if err != nil {
+		return counter, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	return counter, nil
+}
+
+func (s *DynamoDbStorage) appendFamilyEvent(ctx context.Context, evt models.FamilyEvent, version int) error {
+
+	expr, err := expression.NewBuilder().WithUpdate(bumpVersion(expression.UpdateBuilder{})).WithCondition(versionCondition(version)).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	key, err := attributevalue.MarshalMap(familyEventCounter{FamilyID: evt.FamilyID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+	delete(key, "version")
+
+	item, err := attributevalue.MarshalMap(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from family event: %w", err)
+	}
+
+	_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:                 aws.String(s.tableFamilyEvent),
+					Key:                       key,
+					ConditionExpression:       expr.Condition(),
+					ExpressionAttributeNames:  expr.Names(),
+					ExpressionAttributeValues: expr.Values(),
+					UpdateExpression:          expr.Update(),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(s.tableFamilyEvent),
+					Item:      item,
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		if conditionFailed(err) {
+			return conflictError("family event counter", evt.FamilyID)
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// GetFamilyEvents returns the family's events after the given seq that haven't expired, in order
+func (s *DynamoDbStorage) GetFamilyEvents(ctx context.Context, familyId string, afterSeq int64) ([]models.FamilyEvent, error) {
+	events := []models.FamilyEvent{}
+
+	keyEx := expression.Key("family_id").Equal(expression.Value(familyId)).
+		And(expression.Key("seq").GreaterThan(expression.Value(max(afterSeq, 0))))
+
+	// expired events are only removed by DynamoDB some time after they expire
+	filterEx := expression.Name("expires_on").GreaterThan(expression.Value(time.Now().Unix()))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).WithFilter(filterEx).Build()
+	if err != nil {
+		return events, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableFamilyEvent),
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return events, fmt.Errorf("failed to query next family events page: %w", apiErr)
+		}
+
+		var queriedEvents []models.FamilyEvent
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedEvents)
+		if err != nil {
+			return events, fmt.Errorf("failed to unmarshal family events from query response: %w", err)
+		}
+
+		for _, e := range queriedEvents {
+			e.ParseTimes()
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
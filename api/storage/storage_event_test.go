@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_IEventStorage_AppendFamilyEvent(t *testing.T) {
+	type state struct {
+		noCounter bool
+		errGet    error
+		errWrite  error
+		missingID bool
+	}
+	type want struct {
+		err string
+		seq int64
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	conflictErr := &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 5}},
+		{"happy path - first event of family", state{noCounter: true}, want{"", 1}},
+		{"fail - missing id", state{missingID: true}, want{"invalid input: failed to validate request", 0}},
+		{"fail - get counter", state{errGet: errFail}, want{"failed to get family event counter: fail", 0}},
+		{"fail - write", state{errWrite: errFail}, want{"fail", 0}},
+		{"fail - changed every time", state{errWrite: conflictErr}, want{"conflict: family event counter (id=456) was changed by someone else", 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			getOutput := &dynamodb.GetItemOutput{
+				Item: map[string]types.AttributeValue{
+					"family_id": &types.AttributeValueMemberS{Value: "456"},
+					"seq":       &types.AttributeValueMemberN{Value: "0"},
+					"version":   &types.AttributeValueMemberN{Value: "4"},
+				},
+			}
+			if c.state.noCounter {
+				getOutput.Item = nil
+			}
+
+			// conflicts are retried (re-reading the counter) until attempts run out
+			attempts := 1
+			if c.state.errWrite == conflictErr {
+				attempts = familyEventAppendAttempts
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if !c.state.missingID {
+				mockDynamoClient.EXPECT().GetItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+					return *input.TableName == "family_events" &&
+						*input.ConsistentRead &&
+						input.Key["seq"].(*types.AttributeValueMemberN).Value == "0"
+				})).Return(getOutput, c.state.errGet).Times(attempts)
+			}
+
+			if c.state.errGet == nil && !c.state.missingID {
+				mockDynamoClient.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).
+					RunAndReturn(func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, f ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+						if c.state.errWrite != nil {
+							return nil, c.state.errWrite
+						}
+
+						assert.Len(t, input.TransactItems, 2)
+
+						update := input.TransactItems[0].Update
+						assert.Equal(t, "family_events", *update.TableName)
+						assert.NotNil(t, update.ConditionExpression)
+
+						put := input.TransactItems[1].Put
+						assert.Equal(t, &types.AttributeValueMemberN{Value: strconv.FormatInt(c.want.seq, 10)}, put.Item["seq"])
+
+						return &dynamodb.TransactWriteItemsOutput{}, nil
+					}).Times(attempts)
+			}
+
+			s := DynamoDbStorage{
+				client:           mockDynamoClient,
+				tableFamilyEvent: "family_events",
+			}
+
+			evt := models.FamilyEvent{FamilyID: "456", ID: "a", Type: "balance_changed", UserID: "1"}
+			if c.state.missingID {
+				evt.ID = ""
+			}
+
+			res, err := s.AppendFamilyEvent(context.Background(), evt)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, c.want.seq, res.Seq)
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IEventStorage_GetFamilyEvents(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next family events page"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal family events from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"family_id":  &types.AttributeValueMemberS{Value: "456"},
+						"seq":        &types.AttributeValueMemberN{Value: "3"},
+						"id":         &types.AttributeValueMemberS{Value: "a"},
+						"type":       &types.AttributeValueMemberS{Value: "balance_changed"},
+						"user_id":    &types.AttributeValueMemberS{Value: "1"},
+						"data":       &types.AttributeValueMemberS{Value: `{"balance":5}`},
+						"created_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+					{
+						"family_id":  &types.AttributeValueMemberS{Value: "456"},
+						"seq":        &types.AttributeValueMemberN{Value: "4"},
+						"id":         &types.AttributeValueMemberS{Value: "b"},
+						"type":       &types.AttributeValueMemberS{Value: "points_requested"},
+						"user_id":    &types.AttributeValueMemberS{Value: "2"},
+						"data":       &types.AttributeValueMemberS{Value: `{}`},
+						"created_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:01:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"seq": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+				return *input.TableName == "family_events" && *input.ConsistentRead
+			}), mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client:           mockDynamoClient,
+				tableFamilyEvent: "family_events",
+			}
+
+			res, err := s.GetFamilyEvents(context.Background(), "456", 2)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Len(t, res, 2)
+				assert.Equal(t, int64(3), res[0].Seq)
+				assert.Equal(t, int64(4), res[1].Seq)
+				assert.False(t, res[0].CreatedOn.IsZero())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
@@ -3,58 +3,217 @@ package storage
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/sebboness/yektaspoints/models"
 	apierr "github.com/sebboness/yektaspoints/util/error"
 )
 
 type IFamilyStorage interface {
+	AddFamilyUser(ctx context.Context, familyUser models.FamilyUser) error
+	DeleteFamilySettings(ctx context.Context, family_id string) error
 	GetFamilyMembersByUserIDs(ctx context.Context, family_id string, user_ids []string) (models.Family, error)
+	GetFamilySettings(ctx context.Context, family_id string) (models.FamilySettings, error)
 	GetFamilyUsers(ctx context.Context, family_id string) ([]models.FamilyUser, error)
+	MoveFamilyUser(ctx context.Context, userId, fromFamilyId, toFamilyId string) error
+	RemoveFamilyUser(ctx context.Context, familyUser models.FamilyUser) error
+	SaveFamilySettings(ctx context.Context, settings models.FamilySettings) error
 }
 
-func (s *DynamoDbStorage) GetFamilyMembersByUserIDs(ctx context.Context, family_id string, user_ids []string) (models.Family, error) {
-	family := models.Family{
-		FamilyID: family_id,
-		Parents:  map[string]models.FamilyMember{},
-		Children: map[string]models.FamilyMember{},
+// AddFamilyUser adds the user to the family
+func (s *DynamoDbStorage) AddFamilyUser(ctx context.Context, familyUser models.FamilyUser) error {
+
+	if familyUser.FamilyID == "" || familyUser.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id or user_id")
 	}
 
-	paramValues := []interface{}{}
-	paramMarks := []string{}
-	for _, uid := range user_ids {
-		paramMarks = append(paramMarks, "?")
-		paramValues = append(paramValues, uid)
+	item, err := attributevalue.MarshalMap(familyUser)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from family user: %w", err)
 	}
 
-	params, err := attributevalue.MarshalList(paramValues)
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableFamilyUser),
+		Item:      item,
+	})
+
 	if err != nil {
 		apiErr := apierr.GetAwsError(err)
-		return family, fmt.Errorf("failed to marshal params: %w", apiErr)
+		return apiErr
+	}
+
+	return nil
+}
+
+// RemoveFamilyUser removes the user from the family
+func (s *DynamoDbStorage) RemoveFamilyUser(ctx context.Context, familyUser models.FamilyUser) error {
+
+	key, err := attributevalue.MarshalMap(familyUser)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	_, err = s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableFamilyUser),
+		Key:       key,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// MoveFamilyUser moves the user from one family to another in a single transaction, so a failure
+// never leaves the user in neither family. Either family may be empty to only remove the user from
+// a family, or to only add them to one. Fails with not found if the user isn't in the family
+// they're moved from. The user is re-read and the move retried if the user changes in the meantime.
+func (s *DynamoDbStorage) MoveFamilyUser(ctx context.Context, userId, fromFamilyId, toFamilyId string) error {
+	return RetryOnConflict(ctx, func(ctx context.Context) error {
+		return s.moveFamilyUser(ctx, userId, fromFamilyId, toFamilyId)
+	})
+}
+
+func (s *DynamoDbStorage) moveFamilyUser(ctx context.Context, userId, fromFamilyId, toFamilyId string) error {
+
+	user, err := s.GetUserByID(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	resp, err := s.client.ExecuteStatement(ctx, &dynamodb.ExecuteStatementInput{
-		Statement: aws.String(
-			fmt.Sprintf("SELECT * FROM \"%v\" WHERE user_id IN [%s]", s.tableUser, strings.Join(paramMarks, ", "))),
-		Parameters: params,
+	familyIds, err := movedFamilyIDs(user, fromFamilyId, toFamilyId)
+	if err != nil {
+		return err
+	}
+
+	update := expression.Set(expression.Name("family_ids"), expression.Value(familyIds))
+	expr, err := expression.NewBuilder().WithUpdate(bumpVersion(update)).WithCondition(versionCondition(user.Version)).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	keyEx, err := attributevalue.Marshal(userId)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	items := []types.TransactWriteItem{{
+		Update: &types.Update{
+			TableName:                 aws.String(s.tableUser),
+			Key:                       map[string]types.AttributeValue{"user_id": keyEx},
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			UpdateExpression:          expr.Update(),
+		},
+	}}
+
+	if fromFamilyId != "" {
+		key, err := attributevalue.MarshalMap(models.FamilyUser{FamilyID: fromFamilyId, UserID: userId})
+		if err != nil {
+			return fmt.Errorf("failed to marshal key: %w", err)
+		}
+
+		items = append(items, types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName: aws.String(s.tableFamilyUser),
+				Key:       key,
+			},
+		})
+	}
+
+	if toFamilyId != "" {
+		item, err := attributevalue.MarshalMap(models.FamilyUser{FamilyID: toFamilyId, UserID: userId})
+		if err != nil {
+			return fmt.Errorf("failed to marshal map from family user: %w", err)
+		}
+
+		items = append(items, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(s.tableFamilyUser),
+				Item:      item,
+			},
+		})
+	}
+
+	_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
 	})
 
 	if err != nil {
+		if conditionFailed(err) {
+			return conflictError("user", userId)
+		}
+
 		apiErr := apierr.GetAwsError(err)
-		return family, fmt.Errorf("failed to execute statement: %w", apiErr)
+		return apiErr
+	}
+
+	return nil
+}
+
+// movedFamilyIDs returns the user's family IDs after the move. Fails with not found if the user
+// isn't in the family they're moved from.
+func movedFamilyIDs(user models.User, fromFamilyId, toFamilyId string) ([]string, error) {
+	if fromFamilyId != "" && !slices.Contains(user.FamilyIDs, fromFamilyId) {
+		return nil, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("user (id=%s) in family (family_id=%s)", user.UserID, fromFamilyId))
 	}
 
-	if len(resp.Items) == 0 {
+	familyIds := []string{}
+	for _, fid := range user.FamilyIDs {
+		if fid != fromFamilyId && fid != toFamilyId {
+			familyIds = append(familyIds, fid)
+		}
+	}
+
+	if toFamilyId != "" {
+		familyIds = append(familyIds, toFamilyId)
+	}
+
+	return familyIds, nil
+}
+
+func (s *DynamoDbStorage) GetFamilyMembersByUserIDs(ctx context.Context, family_id string, user_ids []string) (models.Family, error) {
+	family := models.Family{
+		FamilyID: family_id,
+		Parents:  map[string]models.FamilyMember{},
+		Children: map[string]models.FamilyMember{},
+	}
+
+	// a batch can't read the same key twice
+	keys := []map[string]types.AttributeValue{}
+	seen := map[string]bool{}
+	for _, uid := range user_ids {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+
+		keys = append(keys, map[string]types.AttributeValue{
+			"user_id": &types.AttributeValueMemberS{Value: uid},
+		})
+	}
+
+	// only what family members and their roles need
+	items, err := s.batchGetItems(ctx, s.tableUser, keys, []string{"user_id", "email", "name", "child_call_name", "roles"})
+	if err != nil {
+		return family, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	if len(items) == 0 {
 		logger.WithContext(ctx).WithField("family_id", user_ids).Warnf("no users found (family_id:%s)", family_id)
 		return family, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("family (family_id=%s)", family_id))
 	}
 
 	apiErr := apierr.New(fmt.Errorf("failed parsing users"))
-	for idx, item := range resp.Items {
+	for idx, item := range items {
 		user := models.User{}
 		if err = attributevalue.UnmarshalMap(item, &user); err != nil {
 			apiErr.AppendErrorf("user[%d] unmarshal error: %s", idx, err.Error())
@@ -84,26 +243,113 @@ func (s *DynamoDbStorage) GetFamilyUsers(ctx context.Context, family_id string)
 		return familyUsers, fmt.Errorf("failed to marshal params: %w", apiErr)
 	}
 
-	resp, err := s.client.ExecuteStatement(ctx, &dynamodb.ExecuteStatementInput{
-		Statement: aws.String(
-			fmt.Sprintf("SELECT * FROM \"%v\" WHERE family_id=?", s.tableFamilyUser)),
-		Parameters: params,
-	})
+	items := []map[string]types.AttributeValue{}
 
-	if err != nil {
-		apiErr := apierr.GetAwsError(err)
-		return familyUsers, fmt.Errorf("failed to execute statement: %w", apiErr)
+	// a statement reads up to 1 MB of items at once, the rest is read with the next token
+	var nextToken *string
+	for {
+		resp, err := s.client.ExecuteStatement(ctx, &dynamodb.ExecuteStatementInput{
+			Statement: aws.String(
+				fmt.Sprintf("SELECT * FROM \"%v\" WHERE family_id=?", s.tableFamilyUser)),
+			Parameters: params,
+			NextToken:  nextToken,
+		})
+
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return familyUsers, fmt.Errorf("failed to execute statement: %w", apiErr)
+		}
+
+		items = append(items, resp.Items...)
+
+		nextToken = resp.NextToken
+		if nextToken == nil {
+			break
+		}
 	}
 
-	if len(resp.Items) == 0 {
+	if len(items) == 0 {
 		logger.WithContext(ctx).WithField("family_id", family_id).Warnf("no family users found (family_id:%s)", family_id)
 		return familyUsers, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("family users (family_id=%s)", family_id))
 	}
 
-	err = attributevalue.UnmarshalListOfMaps(resp.Items, &familyUsers)
+	err = attributevalue.UnmarshalListOfMaps(items, &familyUsers)
 	if err != nil {
 		return familyUsers, fmt.Errorf("failed to unmarshal family users from query response: %w", err)
 	}
 
 	return familyUsers, nil
 }
+
+// GetFamilySettings returns the settings of the family, or the default settings if the
+// family's parents haven't changed any
+func (s *DynamoDbStorage) GetFamilySettings(ctx context.Context, family_id string) (models.FamilySettings, error) {
+	settings := models.NewFamilySettings(family_id)
+
+	resp, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableFamilySettings),
+		Key: map[string]types.AttributeValue{
+			"family_id": &types.AttributeValueMemberS{Value: family_id},
+		},
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return settings, apiErr
+	}
+
+	if len(resp.Item) == 0 {
+		return settings, nil
+	}
+
+	err = attributevalue.UnmarshalMap(resp.Item, &settings)
+	if err != nil {
+		return settings, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	settings.ParseTimes()
+	return settings, nil
+}
+
+// SaveFamilySettings saves the settings of the family
+func (s *DynamoDbStorage) SaveFamilySettings(ctx context.Context, settings models.FamilySettings) error {
+
+	if settings.FamilyID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id")
+	}
+
+	item, err := attributevalue.MarshalMap(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from family settings: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableFamilySettings),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// DeleteFamilySettings deletes the settings of the family
+func (s *DynamoDbStorage) DeleteFamilySettings(ctx context.Context, family_id string) error {
+
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableFamilySettings),
+		Key: map[string]types.AttributeValue{
+			"family_id": &types.AttributeValueMemberS{Value: family_id},
+		},
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
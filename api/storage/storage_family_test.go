@@ -2,11 +2,16 @@ package storage
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
 	"github.com/sebboness/yektaspoints/util/tests"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,9 +22,11 @@ func Test_IFamilyStorage_GetFamilyMembersByUserIDs(t *testing.T) {
 		errGetItem    error
 		failUnmarshal bool
 		itemNotFound  bool
+		unprocessed   int // number of calls that leave the second user unprocessed
 	}
 	type want struct {
-		err string
+		err   string
+		calls int
 	}
 	type test struct {
 		name string
@@ -30,55 +37,89 @@ func Test_IFamilyStorage_GetFamilyMembersByUserIDs(t *testing.T) {
 	throughputErr := &types.ProvisionedThroughputExceededException{}
 
 	cases := []test{
-		{"happy path", state{}, want{}},
-		{"fail - get item", state{errGetItem: errFail}, want{"fail"}},
-		{"fail - get item - exceeded throughput", state{errGetItem: throughputErr}, want{"ProvisionedThroughputExceededException"}},
-		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed parsing users: user[0] unmarshal error"}},
-		{"fail - not found", state{itemNotFound: true}, want{"resource not found: family (family_id=456)"}},
+		{"happy path", state{}, want{"", 1}},
+		{"happy path - unprocessed keys retried", state{unprocessed: 2}, want{"", 3}},
+		{"fail - get item", state{errGetItem: errFail}, want{"fail", 1}},
+		{"fail - get item - exceeded throughput", state{errGetItem: throughputErr}, want{"ProvisionedThroughputExceededException", 1}},
+		{"fail - unprocessed keys", state{unprocessed: batchGetRetryAttempts}, want{"failed to read 1 keys of table users after 5 attempts", batchGetRetryAttempts}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed parsing users: user[0] unmarshal error", 1}},
+		{"fail - not found", state{itemNotFound: true}, want{"resource not found: family (family_id=456)", 1}},
 	}
 
+	backoff := batchGetRetryBackoff
+	batchGetRetryBackoff = time.Millisecond
+	defer func() { batchGetRetryBackoff = backoff }()
+
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			output := &dynamodb.ExecuteStatementOutput{
-				Items: []map[string]types.AttributeValue{
-					{
-						"family_id": &types.AttributeValueMemberS{Value: "123"},
-						"user_id":   &types.AttributeValueMemberS{Value: "1"},
-						"email":     &types.AttributeValueMemberN{Value: "john@info.co"},
-						"name":      &types.AttributeValueMemberN{Value: "John"},
-						"roles":     &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "parent"}}},
-					},
-					{
-						"family_id": &types.AttributeValueMemberS{Value: "123"},
-						"user_id":   &types.AttributeValueMemberS{Value: "2"},
-						"email":     &types.AttributeValueMemberN{Value: "kid@info.co"},
-						"name":      &types.AttributeValueMemberN{Value: "Kid"},
-						"roles":     &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "child"}}},
-					},
+			users := map[string]map[string]types.AttributeValue{
+				"1": {
+					"user_id": &types.AttributeValueMemberS{Value: "1"},
+					"email":   &types.AttributeValueMemberN{Value: "john@info.co"},
+					"name":    &types.AttributeValueMemberN{Value: "John"},
+					"roles":   &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "parent"}}},
+				},
+				"2": {
+					"user_id": &types.AttributeValueMemberS{Value: "2"},
+					"email":   &types.AttributeValueMemberN{Value: "kid@info.co"},
+					"name":    &types.AttributeValueMemberN{Value: "Kid"},
+					"roles":   &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "child"}}},
 				},
 			}
 
 			if c.state.failUnmarshal {
-				output.Items = []map[string]types.AttributeValue{
-					{
-						"roles": &types.AttributeValueMemberS{Value: "abc"},
-					},
+				users["1"] = map[string]types.AttributeValue{
+					"roles": &types.AttributeValueMemberS{Value: "abc"},
 				}
 			}
 
-			if c.state.itemNotFound {
-				output.Items = []map[string]types.AttributeValue{}
-			}
+			calls := 0
 
 			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
-			mockDynamoClient.EXPECT().ExecuteStatement(mock.Anything, mock.Anything).Return(output, c.state.errGetItem)
+			mockDynamoClient.EXPECT().BatchGetItem(mock.Anything, mock.Anything).
+				RunAndReturn(func(ctx context.Context, in *dynamodb.BatchGetItemInput, f ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+					calls++
+
+					request := in.RequestItems["users"]
+					assert.NotNil(t, request.ProjectionExpression)
+					if calls == 1 {
+						assert.Len(t, request.Keys, 2, "duplicate user IDs are read once")
+					}
+
+					if c.state.errGetItem != nil {
+						return nil, c.state.errGetItem
+					}
+
+					out := &dynamodb.BatchGetItemOutput{
+						Responses:       map[string][]map[string]types.AttributeValue{"users": {}},
+						UnprocessedKeys: map[string]types.KeysAndAttributes{},
+					}
+
+					for _, key := range request.Keys {
+						id := key["user_id"].(*types.AttributeValueMemberS).Value
+
+						if id == "2" && calls <= c.state.unprocessed {
+							request.Keys = []map[string]types.AttributeValue{key}
+							out.UnprocessedKeys["users"] = request
+							continue
+						}
+
+						if !c.state.itemNotFound {
+							out.Responses["users"] = append(out.Responses["users"], users[id])
+						}
+					}
+
+					return out, nil
+				})
 
 			s := DynamoDbStorage{
-				client: mockDynamoClient,
+				client:    mockDynamoClient,
+				tableUser: "users",
 			}
 
-			res, err := s.GetFamilyMembersByUserIDs(context.Background(), "456", []string{"1", "2"})
+			res, err := s.GetFamilyMembersByUserIDs(context.Background(), "456", []string{"1", "2", "1"})
 			tests.AssertError(t, err, c.want.err)
+			assert.Equal(t, c.want.calls, calls)
 
 			if c.want.err == "" {
 				assert.Equal(t, "456", res.FamilyID)
@@ -97,11 +138,45 @@ func Test_IFamilyStorage_GetFamilyMembersByUserIDs(t *testing.T) {
 	}
 }
 
+func Test_DynamoDbStorage_batchGetItems(t *testing.T) {
+	keys := []map[string]types.AttributeValue{}
+	for idx := 0; idx < 250; idx++ {
+		keys = append(keys, map[string]types.AttributeValue{
+			"user_id": &types.AttributeValueMemberS{Value: strconv.Itoa(idx)},
+		})
+	}
+
+	chunks := []int{}
+
+	mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+	mockDynamoClient.EXPECT().BatchGetItem(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx context.Context, in *dynamodb.BatchGetItemInput, f ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+			request := in.RequestItems["users"]
+			assert.Nil(t, request.ProjectionExpression)
+
+			chunks = append(chunks, len(request.Keys))
+			return &dynamodb.BatchGetItemOutput{
+				Responses: map[string][]map[string]types.AttributeValue{"users": request.Keys},
+			}, nil
+		}).Times(3)
+
+	s := DynamoDbStorage{
+		client: mockDynamoClient,
+	}
+
+	items, err := s.batchGetItems(context.Background(), "users", keys, nil)
+	assert.Nil(t, err)
+	assert.Len(t, items, 250)
+	assert.Equal(t, []int{100, 100, 50}, chunks)
+	mockDynamoClient.AssertExpectations(t)
+}
+
 func Test_IFamilyStorage_GetFamilyUsers(t *testing.T) {
 	type state struct {
 		errGetItem    error
 		failUnmarshal bool
 		itemNotFound  bool
+		paged         bool
 	}
 	type want struct {
 		err string
@@ -116,6 +191,7 @@ func Test_IFamilyStorage_GetFamilyUsers(t *testing.T) {
 
 	cases := []test{
 		{"happy path", state{}, want{}},
+		{"happy path - paged", state{paged: true}, want{}},
 		{"fail - get item", state{errGetItem: errFail}, want{"fail"}},
 		{"fail - get item - exceeded throughput", state{errGetItem: throughputErr}, want{"ProvisionedThroughputExceededException"}},
 		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal family users from query response"}},
@@ -150,7 +226,20 @@ func Test_IFamilyStorage_GetFamilyUsers(t *testing.T) {
 			}
 
 			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
-			mockDynamoClient.EXPECT().ExecuteStatement(mock.Anything, mock.Anything).Return(output, c.state.errGetItem)
+
+			if c.state.paged {
+				page1 := &dynamodb.ExecuteStatementOutput{Items: output.Items[:1], NextToken: aws.String("next")}
+				page2 := &dynamodb.ExecuteStatementOutput{Items: output.Items[1:]}
+
+				mockDynamoClient.EXPECT().ExecuteStatement(mock.Anything, mock.MatchedBy(func(input *dynamodb.ExecuteStatementInput) bool {
+					return input.NextToken == nil
+				})).Return(page1, nil).Once()
+				mockDynamoClient.EXPECT().ExecuteStatement(mock.Anything, mock.MatchedBy(func(input *dynamodb.ExecuteStatementInput) bool {
+					return input.NextToken != nil && *input.NextToken == "next"
+				})).Return(page2, nil).Once()
+			} else {
+				mockDynamoClient.EXPECT().ExecuteStatement(mock.Anything, mock.Anything).Return(output, c.state.errGetItem)
+			}
 
 			s := DynamoDbStorage{
 				client: mockDynamoClient,
@@ -172,6 +261,309 @@ func Test_IFamilyStorage_GetFamilyUsers(t *testing.T) {
 	}
 }
 
+func Test_IFamilyStorage_AddFamilyUser(t *testing.T) {
+	type state struct {
+		missingUser bool
+		errPut      error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing user", state{missingUser: true}, want{"missing family_id or user_id"}},
+		{"fail - put item", state{errPut: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			familyUser := models.FamilyUser{FamilyID: "456", UserID: "1"}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingUser {
+				familyUser.UserID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, c.state.errPut)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.AddFamilyUser(context.Background(), familyUser)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IFamilyStorage_RemoveFamilyUser(t *testing.T) {
+	type state struct {
+		errDelete error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - delete item", state{errDelete: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, c.state.errDelete)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.RemoveFamilyUser(context.Background(), models.FamilyUser{FamilyID: "456", UserID: "1"})
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IFamilyStorage_MoveFamilyUser(t *testing.T) {
+	type state struct {
+		from     string
+		to       string
+		errGet   error
+		errWrite error
+	}
+	type want struct {
+		familyIds []string
+		writes    int // items written in the transaction
+		err       string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	conflictErr := &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+	}
+
+	cases := []test{
+		{"happy path - move", state{from: "1", to: "3"}, want{familyIds: []string{"2", "3"}, writes: 3}},
+		{"happy path - remove only", state{from: "1"}, want{familyIds: []string{"2"}, writes: 2}},
+		{"happy path - add only", state{to: "3"}, want{familyIds: []string{"1", "2", "3"}, writes: 2}},
+		{"fail - not in from family", state{from: "4", to: "3"}, want{err: "resource not found: user (id=1) in family (family_id=4)"}},
+		{"fail - get user", state{from: "1", to: "3", errGet: errFail}, want{err: "failed to get user: fail"}},
+		{"fail - write", state{from: "1", to: "3", errWrite: errFail}, want{err: "fail"}},
+		{"fail - changed every time", state{from: "1", to: "3", errWrite: conflictErr}, want{err: "conflict: user (id=1) was changed by someone else"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			queryOutput := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"user_id":    &types.AttributeValueMemberS{Value: "1"},
+						"family_ids": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "1"}, &types.AttributeValueMemberS{Value: "2"}}},
+						"version":    &types.AttributeValueMemberN{Value: "4"},
+					},
+				},
+			}
+
+			// conflicts are retried (re-reading the user) until attempts run out
+			attempts := 1
+			if c.state.errWrite == conflictErr {
+				attempts = ConflictRetryAttempts
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything).Return(queryOutput, c.state.errGet).Times(attempts)
+
+			if c.state.errGet == nil && (c.want.writes > 0 || c.state.errWrite != nil) {
+				mockDynamoClient.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).
+					RunAndReturn(func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, f ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+						if c.state.errWrite != nil {
+							return nil, c.state.errWrite
+						}
+
+						assert.Len(t, input.TransactItems, c.want.writes)
+
+						update := input.TransactItems[0].Update
+						assert.Equal(t, "users", *update.TableName)
+						assert.NotNil(t, update.ConditionExpression)
+
+						var familyIds []string
+						for _, v := range update.ExpressionAttributeValues {
+							if _, ok := v.(*types.AttributeValueMemberL); ok {
+								_ = attributevalue.Unmarshal(v, &familyIds)
+							}
+						}
+						assert.Equal(t, c.want.familyIds, familyIds)
+
+						for _, item := range input.TransactItems[1:] {
+							if item.Delete != nil {
+								assert.Equal(t, &types.AttributeValueMemberS{Value: c.state.from}, item.Delete.Key["family_id"])
+							}
+							if item.Put != nil {
+								assert.Equal(t, &types.AttributeValueMemberS{Value: c.state.to}, item.Put.Item["family_id"])
+							}
+						}
+
+						return &dynamodb.TransactWriteItemsOutput{}, nil
+					}).Times(attempts)
+			}
+
+			s := DynamoDbStorage{
+				client:          mockDynamoClient,
+				tableFamilyUser: "family_users",
+				tableUser:       "users",
+			}
+
+			err := s.MoveFamilyUser(context.Background(), "1", c.state.from, c.state.to)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IFamilyStorage_GetFamilySettings(t *testing.T) {
+	type state struct {
+		errGetItem    error
+		failUnmarshal bool
+		itemNotFound  bool
+	}
+	type want struct {
+		err  string
+		hide bool
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", true}},
+		{"happy path - defaults if not found", state{itemNotFound: true}, want{"", false}},
+		{"fail - get item", state{errGetItem: errFail}, want{"fail", false}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal item", false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.GetItemOutput{
+				Item: map[string]types.AttributeValue{
+					"family_id":                   &types.AttributeValueMemberS{Value: "456"},
+					"hide_rankings_from_children": &types.AttributeValueMemberBOOL{Value: true},
+					"updated_on":                  &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Item["hide_rankings_from_children"] = &types.AttributeValueMemberS{Value: "yes"}
+			}
+
+			if c.state.itemNotFound {
+				output.Item = nil
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().GetItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+				return input.Key["family_id"].(*types.AttributeValueMemberS).Value == "456"
+			})).Return(output, c.state.errGetItem)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetFamilySettings(context.Background(), "456")
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, "456", res.FamilyID)
+				assert.Equal(t, c.want.hide, res.HideRankingsFromChildren)
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IFamilyStorage_SaveFamilySettings(t *testing.T) {
+	type state struct {
+		missingFamily bool
+		errPut        error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing family", state{missingFamily: true}, want{"missing family_id"}},
+		{"fail - put item", state{errPut: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settings := models.FamilySettings{FamilyID: "456", HideRankingsFromChildren: true}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingFamily {
+				settings.FamilyID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+					return input.Item["hide_rankings_from_children"].(*types.AttributeValueMemberBOOL).Value
+				})).Return(&dynamodb.PutItemOutput{}, c.state.errPut)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SaveFamilySettings(context.Background(), settings)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IFamilyStorage_DeleteFamilySettings(t *testing.T) {
+	mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+	mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, errFail).Once()
+
+	s := DynamoDbStorage{
+		client: mockDynamoClient,
+	}
+
+	err := s.DeleteFamilySettings(context.Background(), "456")
+	tests.AssertError(t, err, "fail")
+
+	mockDynamoClient.AssertExpectations(t)
+}
+
 // Tests against real db
 
 func TestReal_IFamilyStorage_GetFamilyUsers(t *testing.T) {
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type IPointCommentStorage interface {
+	DeletePointComments(ctx context.Context, userId string) error
+	GetPointComments(ctx context.Context, userId, pointId string) ([]models.PointComment, error)
+	SavePointComment(ctx context.Context, comment models.PointComment) error
+}
+
+// DeletePointComments deletes the comments on all of the user's points
+func (s *DynamoDbStorage) DeletePointComments(ctx context.Context, userId string) error {
+
+	comments, err := s.queryPointComments(ctx, expression.Key("user_id").Equal(expression.Value(userId)), "")
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(s.tablePointComment),
+			Key: map[string]types.AttributeValue{
+				"user_id": &types.AttributeValueMemberS{Value: comment.UserID},
+				"id":      &types.AttributeValueMemberS{Value: comment.ID},
+			},
+		})
+
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return fmt.Errorf("failed to delete point comment (id=%s): %w", comment.ID, apiErr)
+		}
+	}
+
+	return nil
+}
+
+// GetPointComments returns the comments on the user's point, oldest first
+func (s *DynamoDbStorage) GetPointComments(ctx context.Context, userId, pointId string) ([]models.PointComment, error) {
+	keyEx := expression.Key("user_id").Equal(expression.Value(userId)).
+		And(expression.Key("point_id").Equal(expression.Value(pointId)))
+
+	comments, err := s.queryPointComments(ctx, keyEx, "point_id-index")
+	if err != nil {
+		return comments, err
+	}
+
+	// comment IDs are ksuids, which sort by creation time
+	slices.SortFunc(comments, func(a, b models.PointComment) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return comments, nil
+}
+
+func (s *DynamoDbStorage) queryPointComments(ctx context.Context, keyEx expression.KeyConditionBuilder, index string) ([]models.PointComment, error) {
+	comments := []models.PointComment{}
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		return comments, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tablePointComment),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	}
+	if index != "" {
+		input.IndexName = aws.String(index)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, input)
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return comments, fmt.Errorf("failed to query next point comments page: %w", apiErr)
+		}
+
+		var queriedComments []models.PointComment
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedComments)
+		if err != nil {
+			return comments, fmt.Errorf("failed to unmarshal point comments from query response: %w", err)
+		}
+
+		for _, c := range queriedComments {
+			c.ParseTimes()
+			comments = append(comments, c)
+		}
+	}
+
+	return comments, nil
+}
+
+func (s *DynamoDbStorage) SavePointComment(ctx context.Context, comment models.PointComment) error {
+
+	if comment.UserID == "" || comment.ID == "" || comment.PointID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing user_id, id or point_id")
+	}
+
+	item, err := attributevalue.MarshalMap(comment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from point comment: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tablePointComment),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_IPointCommentStorage_DeletePointComments(t *testing.T) {
+	type state struct {
+		errQuery  error
+		errDelete error
+	}
+	type want struct {
+		err     string
+		deletes int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 2}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next point comments page: fail", 0}},
+		{"fail - delete", state{errDelete: errFail}, want{"failed to delete point comment (id=1): fail", 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{"user_id": &types.AttributeValueMemberS{Value: "a"}, "id": &types.AttributeValueMemberS{Value: "1"}, "point_id": &types.AttributeValueMemberS{Value: "p1"}},
+					{"user_id": &types.AttributeValueMemberS{Value: "a"}, "id": &types.AttributeValueMemberS{Value: "2"}, "point_id": &types.AttributeValueMemberS{Value: "p2"}},
+				},
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+				return input.IndexName == nil
+			}), mock.Anything).Return(output, c.state.errQuery).Once()
+
+			if c.want.deletes > 0 {
+				mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+					return input.Key["user_id"].(*types.AttributeValueMemberS).Value == "a"
+				})).Return(&dynamodb.DeleteItemOutput{}, c.state.errDelete).Times(c.want.deletes)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.DeletePointComments(context.Background(), "a")
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IPointCommentStorage_GetPointComments(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next point comments page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal point comments from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"user_id":    &types.AttributeValueMemberS{Value: "a"},
+						"id":         &types.AttributeValueMemberS{Value: "2"},
+						"point_id":   &types.AttributeValueMemberS{Value: "p1"},
+						"text":       &types.AttributeValueMemberS{Value: "done!"},
+						"created_on": &types.AttributeValueMemberS{Value: "2024-03-10T21:00:00.0000000Z"},
+					},
+					{
+						"user_id":          &types.AttributeValueMemberS{Value: "a"},
+						"id":               &types.AttributeValueMemberS{Value: "1"},
+						"point_id":         &types.AttributeValueMemberS{Value: "p1"},
+						"text":             &types.AttributeValueMemberS{Value: "send a photo first"},
+						"requests_changes": &types.AttributeValueMemberBOOL{Value: true},
+						"created_on":       &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"requests_changes": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+				return *input.IndexName == "point_id-index"
+			}), mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetPointComments(context.Background(), "a", "p1")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res, 2)
+				assert.Equal(t, "1", res[0].ID)
+				assert.True(t, res[0].RequestsChanges)
+				assert.False(t, res[0].CreatedOn.IsZero())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IPointCommentStorage_SavePointComment(t *testing.T) {
+	type state struct {
+		missingPointId bool
+		errSave        error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing point id", state{missingPointId: true}, want{"missing user_id, id or point_id"}},
+		{"fail - put item", state{errSave: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			comment := models.PointComment{UserID: "a", ID: "1", PointID: "p1", AuthorUserID: "b", Text: "Nice!"}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingPointId {
+				comment.PointID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, c.state.errSave)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SavePointComment(context.Background(), comment)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type IPointTypeStorage interface {
+	DeletePointType(ctx context.Context, familyId, id string) error
+	GetPointTypesByFamilyID(ctx context.Context, familyId string) ([]models.PointType, error)
+	SavePointType(ctx context.Context, pointType models.PointType) error
+}
+
+func (s *DynamoDbStorage) DeletePointType(ctx context.Context, familyId, id string) error {
+
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tablePointType),
+		Key: map[string]types.AttributeValue{
+			"family_id": &types.AttributeValueMemberS{Value: familyId},
+			"id":        &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// GetPointTypesByFamilyID returns the point types the family configured. The built-in default type isn't included.
+func (s *DynamoDbStorage) GetPointTypesByFamilyID(ctx context.Context, familyId string) ([]models.PointType, error) {
+	pointTypes := []models.PointType{}
+
+	keyEx := expression.Key("family_id").Equal(expression.Value(familyId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return pointTypes, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tablePointType),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return pointTypes, fmt.Errorf("failed to query next point types page: %w", apiErr)
+		}
+
+		var queriedTypes []models.PointType
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedTypes)
+		if err != nil {
+			return pointTypes, fmt.Errorf("failed to unmarshal point types from query response: %w", err)
+		}
+
+		for _, t := range queriedTypes {
+			t.ParseTimes()
+			pointTypes = append(pointTypes, t)
+		}
+	}
+
+	return pointTypes, nil
+}
+
+func (s *DynamoDbStorage) SavePointType(ctx context.Context, pointType models.PointType) error {
+
+	if pointType.FamilyID == "" || pointType.ID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id or id")
+	}
+
+	item, err := attributevalue.MarshalMap(pointType)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from point type: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tablePointType),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
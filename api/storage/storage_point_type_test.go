@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_IPointTypeStorage_DeletePointType(t *testing.T) {
+	type state struct {
+		errDelete error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - delete", state{errDelete: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+				return input.Key["family_id"].(*types.AttributeValueMemberS).Value == "456" &&
+					input.Key["id"].(*types.AttributeValueMemberS).Value == "1"
+			})).Return(&dynamodb.DeleteItemOutput{}, c.state.errDelete)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.DeletePointType(context.Background(), "456", "1")
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IPointTypeStorage_GetPointTypesByFamilyID(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next point types page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal point types from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"family_id": &types.AttributeValueMemberS{Value: "456"},
+						"id":        &types.AttributeValueMemberS{Value: "1"},
+						"name":      &types.AttributeValueMemberS{Value: "Screen time"},
+						"unit":      &types.AttributeValueMemberS{Value: "minutes"},
+						"cashout": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+							"points": &types.AttributeValueMemberN{Value: "10"},
+							"value":  &types.AttributeValueMemberN{Value: "30"},
+						}},
+						"created_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"cashout": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetPointTypesByFamilyID(context.Background(), "456")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res, 1)
+				assert.Equal(t, "minutes", res[0].Unit)
+				assert.Equal(t, &models.CashoutRule{Points: 10, Value: 30}, res[0].Cashout)
+				assert.False(t, res[0].CreatedOn.IsZero())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IPointTypeStorage_SavePointType(t *testing.T) {
+	type state struct {
+		missingId bool
+		errSave   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing id", state{missingId: true}, want{"missing family_id or id"}},
+		{"fail - put item", state{errSave: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pointType := models.PointType{FamilyID: "456", ID: "1", Name: "Screen time", Cashout: &models.CashoutRule{Points: 10, Value: 30}}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingId {
+				pointType.ID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+					_, hasCashout := input.Item["cashout"].(*types.AttributeValueMemberM)
+					return hasCashout
+				})).Return(&dynamodb.PutItemOutput{}, c.state.errSave)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SavePointType(context.Background(), pointType)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
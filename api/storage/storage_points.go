@@ -8,14 +8,23 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/sebboness/yektaspoints/models"
 	apierr "github.com/sebboness/yektaspoints/util/error"
 )
 
+// DynamoDB transactions write at most 100 items
+const maxTransactItems = 100
+
 type IPointsStorage interface {
 	GetPointByID(ctx context.Context, userId, id string) (models.Point, error)
 	GetPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter) ([]models.Point, error)
+	StreamPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter, fn func(models.Point) error) error
 	SavePoint(ctx context.Context, point models.Point) error
+	SavePoints(ctx context.Context, points []models.Point, balances []models.PointBalance) error
+	ScrubPoints(ctx context.Context, userId string) error
+
+	GetPointBalance(ctx context.Context, userId, typeId string) (models.PointBalance, error)
 }
 
 func (s *DynamoDbStorage) GetPointByID(ctx context.Context, userId, id string) (models.Point, error) {
@@ -59,6 +68,17 @@ func (s *DynamoDbStorage) GetPointByID(ctx context.Context, userId, id string) (
 func (s *DynamoDbStorage) GetPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter) ([]models.Point, error) {
 	points := []models.Point{}
 
+	err := s.StreamPointsByUserID(ctx, userId, filters, func(p models.Point) error {
+		points = append(points, p)
+		return nil
+	})
+
+	return points, err
+}
+
+// StreamPointsByUserID queries the user's points page by page and calls fn for each point, so
+// callers don't have to keep all points in memory. Stops at the first error returned by fn.
+func (s *DynamoDbStorage) StreamPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter, fn func(models.Point) error) error {
 	keyEx := expression.Key("user_id").Equal(expression.Value(userId))
 	var filterExpr expression.ConditionBuilder
 
@@ -100,12 +120,12 @@ func (s *DynamoDbStorage) GetPointsByUserID(ctx context.Context, userId string,
 	}
 
 	if len(filters.Attributes) > 0 {
-		exprBuilder.WithProjection(selectAttributesExpression(filters.Attributes))
+		exprBuilder = exprBuilder.WithProjection(selectAttributesExpression(filters.Attributes))
 	}
 
 	expr, err := exprBuilder.Build()
 	if err != nil {
-		return points, fmt.Errorf("failed to build expression for query: %w", err)
+		return fmt.Errorf("failed to build expression for query: %w", err)
 	}
 
 	// setup query paginator
@@ -116,52 +136,140 @@ func (s *DynamoDbStorage) GetPointsByUserID(ctx context.Context, userId string,
 		ExpressionAttributeValues: expr.Values(),
 		KeyConditionExpression:    expr.KeyCondition(),
 		FilterExpression:          expr.Filter(),
-		ScanIndexForward:          aws.Bool(false), // order by updated_on descending (latest first)
+		ScanIndexForward:          aws.Bool(filters.Ascending), // order by updated_on descending (latest first) by default
 		ProjectionExpression:      expr.Projection(),
 	})
 
 	// fetch items from each page
 	for queryPaginator.HasMorePages() {
-		qctx := context.TODO()
-		resp, err := queryPaginator.NextPage(qctx)
+		resp, err := queryPaginator.NextPage(ctx)
 
 		if err != nil {
 			apiErr := apierr.GetAwsError(err)
-			return points, fmt.Errorf("failed to query next points page: %w", apiErr)
-		} else {
-			var queriedPoints []models.Point
-			err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedPoints)
-			if err != nil {
-				return points, fmt.Errorf("failed to unmarshal points from query response: %w", err)
-			} else {
-				for _, p := range queriedPoints {
-					p.ParseTimes()
-					points = append(points, p)
-				}
+			return fmt.Errorf("failed to query next points page: %w", apiErr)
+		}
+
+		var queriedPoints []models.Point
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedPoints)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal points from query response: %w", err)
+		}
+
+		for _, p := range queriedPoints {
+			p.ParseTimes()
+			if err := fn(p); err != nil {
+				return err
 			}
 		}
 	}
 
-	return points, nil
+	return nil
 }
 
+// SavePoint saves the point, unless it was changed since it was read (then the save fails with a
+// conflict error). The point's version is incremented with every save.
 func (s *DynamoDbStorage) SavePoint(ctx context.Context, point models.Point) error {
 
-	if err := s.validateNewPoint(point); err != nil {
+	if err := validateNewPoint(point); err != nil {
 		return err
 	}
 
+	expr, err := expression.NewBuilder().WithCondition(versionCondition(point.Version)).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	point.Version++
 	item, err := attributevalue.MarshalMap(point)
 	if err != nil {
 		return fmt.Errorf("failed to marshal map from point: %w", err)
 	}
 
 	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(s.tablePoints),
-		Item:      item,
+		TableName:                 aws.String(s.tablePoints),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	if err != nil {
+		if conditionFailed(err) {
+			return conflictError("point", point.ID)
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// SavePoints saves points and the balances they change in a single transaction, so either all or
+// none of them are saved. Like SavePoint, points and balances are only saved if they weren't
+// changed since they were read (new ones have version 0), otherwise the save fails with a conflict
+// error. Their versions are incremented with every save.
+func (s *DynamoDbStorage) SavePoints(ctx context.Context, points []models.Point, balances []models.PointBalance) error {
+
+	if err := validateSavePoints(points, balances); err != nil {
+		return err
+	}
+
+	items := []types.TransactWriteItem{}
+	for _, point := range points {
+		expr, err := expression.NewBuilder().WithCondition(versionCondition(point.Version)).Build()
+		if err != nil {
+			return fmt.Errorf("failed to build expression: %w", err)
+		}
+
+		point.Version++
+		item, err := attributevalue.MarshalMap(point)
+		if err != nil {
+			return fmt.Errorf("failed to marshal map from point: %w", err)
+		}
+
+		items = append(items, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName:                 aws.String(s.tablePoints),
+				Item:                      item,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+		})
+	}
+
+	for _, balance := range balances {
+		update := bumpVersion(expression.Set(expression.Name("balance"), expression.Value(balance.Balance)))
+		expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(versionCondition(balance.Version)).Build()
+		if err != nil {
+			return fmt.Errorf("failed to build expression: %w", err)
+		}
+
+		items = append(items, types.TransactWriteItem{
+			Update: &types.Update{
+				TableName: aws.String(s.tablePointBalance),
+				Key: map[string]types.AttributeValue{
+					"user_id":       &types.AttributeValueMemberS{Value: balance.UserID},
+					"point_type_id": &types.AttributeValueMemberS{Value: balance.PointTypeID},
+				},
+				UpdateExpression:          expr.Update(),
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+		})
+	}
+
+	_, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
 	})
 
 	if err != nil {
+		if conditionFailed(err) {
+			return pointsConflictError()
+		}
+
 		apiErr := apierr.GetAwsError(err)
 		return apiErr
 	}
@@ -169,7 +277,130 @@ func (s *DynamoDbStorage) SavePoint(ctx context.Context, point models.Point) err
 	return nil
 }
 
-func (s *DynamoDbStorage) validateNewPoint(point models.Point) error {
+// GetPointBalance returns the balance of the user's points of the type
+func (s *DynamoDbStorage) GetPointBalance(ctx context.Context, userId, typeId string) (models.PointBalance, error) {
+	balance := models.PointBalance{}
+
+	// a consistent read, because the balance is read to be changed
+	resp, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tablePointBalance),
+		Key: map[string]types.AttributeValue{
+			"user_id":       &types.AttributeValueMemberS{Value: userId},
+			"point_type_id": &types.AttributeValueMemberS{Value: typeId},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return balance, apiErr
+	}
+
+	if len(resp.Item) == 0 {
+		return unsavedBalance(ctx, s, userId, typeId)
+	}
+
+	err = attributevalue.UnmarshalMap(resp.Item, &balance)
+	if err != nil {
+		return balance, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ScrubPoints removes the free text (reasons and parent notes) from all of the user's points.
+// Amounts, balances and statuses are kept, so the anonymized ledger still adds up.
+func (s *DynamoDbStorage) ScrubPoints(ctx context.Context, userId string) error {
+
+	points, err := s.GetPointsByUserID(ctx, userId, models.QueryPointsFilter{
+		Attributes: []string{"id", "user_id"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get points: %w", err)
+	}
+
+	update := expression.Remove(expression.Name("request.reason")).
+		Remove(expression.Name("request.parent_notes"))
+	cond := expression.AttributeExists(expression.Name("id"))
+	expr, err := expression.NewBuilder().WithUpdate(bumpVersion(update)).WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	for _, point := range points {
+		key, err := attributevalue.MarshalMap(map[string]string{
+			"user_id": point.UserID,
+			"id":      point.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal key: %w", err)
+		}
+
+		_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(s.tablePoints),
+			Key:                       key,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			UpdateExpression:          expr.Update(),
+		})
+
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return fmt.Errorf("failed to scrub point (id=%s): %w", point.ID, apiErr)
+		}
+	}
+
+	return nil
+}
+
+// unsavedBalance returns the balance of a user whose balance of the type wasn't saved yet, which is
+// the balance of their latest settled point of the type (if points were settled before balances
+// were saved). The balance has version 0, so it's saved as a new balance.
+func unsavedBalance(ctx context.Context, s IPointsStorage, userId, typeId string) (models.PointBalance, error) {
+	balance := models.PointBalance{UserID: userId, PointTypeID: typeId}
+
+	points, err := s.GetPointsByUserID(ctx, userId, models.QueryPointsFilter{
+		Statuses:   []models.PointStatus{models.PointStatusSettled},
+		Attributes: []string{"id", "updated_on", "balance", "point_type_id", "status"},
+	})
+	if err != nil {
+		return balance, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	balance.Balance = models.LatestBalance(models.PointsOfType(points, typeId))
+	return balance, nil
+}
+
+// pointsConflictError is returned when points or balances were changed since they were read, or new
+// points already exist
+func pointsConflictError() error {
+	return apierr.New(apierr.Conflict).WithError("one or more of the points or balances were changed by someone else, please reload and try again")
+}
+
+func validateSavePoints(points []models.Point, balances []models.PointBalance) error {
+	if len(points)+len(balances) > maxTransactItems {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+			WithError(fmt.Sprintf("cannot save more than %d points and balances at once", maxTransactItems))
+	}
+
+	for _, point := range points {
+		if err := validateNewPoint(point); err != nil {
+			return err
+		}
+	}
+
+	for _, balance := range balances {
+		if balance.UserID == "" || balance.PointTypeID == "" {
+			return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).
+				WithError("balance must have a user_id and point_type_id")
+		}
+	}
+
+	return nil
+}
+
+func validateNewPoint(point models.Point) error {
 	apierr := apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput))
 
 	if point.UserID == "" {
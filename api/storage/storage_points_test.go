@@ -3,9 +3,12 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	mocks "github.com/sebboness/yektaspoints/mocks/storage"
@@ -176,6 +179,70 @@ func Test_DynamoDbStorage_GetPointsByUserID(t *testing.T) {
 	}
 }
 
+func Test_DynamoDbStorage_StreamPointsByUserID(t *testing.T) {
+	type state struct {
+		attributes []string
+		errFn      error
+	}
+	type want struct {
+		calls      int
+		projection bool
+		err        string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{3, false, ""}},
+		{"happy path - projected attributes", state{attributes: []string{"id", "updated_on"}}, want{3, true, ""}},
+		{"fail - callback error stops streaming", state{errFn: errFail}, want{1, false, "fail"}},
+	}
+
+	for _, c := range cases {
+
+		page1 := &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{"id": &types.AttributeValueMemberS{Value: "1"}, "updated_on": &types.AttributeValueMemberS{Value: "2024-03-18T10:00:00Z"}},
+				{"id": &types.AttributeValueMemberS{Value: "2"}},
+			},
+			LastEvaluatedKey: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "2"}},
+		}
+		page2 := &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{"id": &types.AttributeValueMemberS{Value: "3"}},
+			},
+		}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.ScanIndexForward && input.ExclusiveStartKey == nil &&
+				(input.ProjectionExpression != nil) == c.want.projection
+		}), mock.Anything).Return(page1, nil).Once()
+		if c.state.errFn == nil {
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+				return input.ExclusiveStartKey != nil
+			}), mock.Anything).Return(page2, nil).Once()
+		}
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		ids := []string{}
+		err := s.StreamPointsByUserID(context.Background(), "456", models.QueryPointsFilter{Ascending: true, Attributes: c.state.attributes}, func(p models.Point) error {
+			ids = append(ids, p.ID)
+			return c.state.errFn
+		})
+		tests.AssertError(t, err, c.want.err)
+		assert.Len(t, ids, c.want.calls)
+
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
 func Test_DynamoDbStorage_SavePoint(t *testing.T) {
 	type state struct {
 		missingID        bool
@@ -198,6 +265,7 @@ func Test_DynamoDbStorage_SavePoint(t *testing.T) {
 		{"fail - validation error - missing user_id", state{missingUserID: true}, want{"missing user_id"}},
 		{"fail - validation error - missing updated_on", state{missingUpdatedOn: true}, want{"missing updated_on"}},
 		{"fail - save point", state{errSaveItem: errFail}, want{"fail"}},
+		{"fail - changed since read", state{errSaveItem: &types.ConditionalCheckFailedException{}}, want{"conflict: point (id=1) was changed by someone else"}},
 	}
 
 	for _, c := range cases {
@@ -214,6 +282,7 @@ func Test_DynamoDbStorage_SavePoint(t *testing.T) {
 			UserID:       "a",
 			ID:           "1",
 			UpdatedOnStr: util.ToFormatted(time.Now()),
+			Version:      2,
 		}
 
 		hasValidationErr := false
@@ -232,7 +301,11 @@ func Test_DynamoDbStorage_SavePoint(t *testing.T) {
 		}
 
 		if !hasValidationErr {
-			mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errSaveItem)
+			mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.MatchedBy(func(in *dynamodb.PutItemInput) bool {
+				var saved models.Point
+				_ = attributevalue.UnmarshalMap(in.Item, &saved)
+				return *in.ConditionExpression == "#0 = :0" && saved.Version == 3
+			}), mock.Anything).Return(output, c.state.errSaveItem)
 		}
 
 		err := s.SavePoint(context.Background(), point)
@@ -241,9 +314,230 @@ func Test_DynamoDbStorage_SavePoint(t *testing.T) {
 	}
 }
 
+func Test_DynamoDbStorage_SavePoints(t *testing.T) {
+	type state struct {
+		count          int
+		missingID      bool
+		missingBalType bool
+		errTransact    error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{count: 3}, want{}},
+		{"fail - too many points and balances", state{count: 51}, want{"cannot save more than 100 points and balances at once"}},
+		{"fail - validation error - missing id", state{count: 2, missingID: true}, want{"missing id"}},
+		{"fail - validation error - missing balance type", state{count: 2, missingBalType: true}, want{"balance must have a user_id and point_type_id"}},
+		{"fail - transaction", state{count: 2, errTransact: errFail}, want{"fail"}},
+		{"fail - changed by someone else", state{count: 2, errTransact: &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("ConditionalCheckFailed")},
+		}}}, want{"conflict: one or more of the points or balances were changed by someone else"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			s := DynamoDbStorage{
+				client:            mockDynamoClient,
+				tablePoints:       "points",
+				tablePointBalance: "point-balance",
+			}
+
+			points := make([]models.Point, c.state.count)
+			balances := make([]models.PointBalance, c.state.count)
+			for idx := range points {
+				points[idx] = models.Point{
+					UserID:       fmt.Sprintf("u%d", idx),
+					ID:           fmt.Sprintf("%d", idx),
+					UpdatedOnStr: util.ToFormatted(time.Now()),
+				}
+				balances[idx] = models.PointBalance{
+					UserID:      fmt.Sprintf("u%d", idx),
+					PointTypeID: models.DefaultPointTypeID,
+					Balance:     idx,
+					Version:     idx,
+				}
+			}
+
+			if c.state.missingID {
+				points[1].ID = ""
+			}
+			if c.state.missingBalType {
+				balances[1].PointTypeID = ""
+			}
+
+			if c.want.err == "" || c.state.errTransact != nil {
+				mockDynamoClient.EXPECT().TransactWriteItems(mock.Anything, mock.MatchedBy(func(in *dynamodb.TransactWriteItemsInput) bool {
+					var saved models.Point
+					_ = attributevalue.UnmarshalMap(in.TransactItems[0].Put.Item, &saved)
+
+					// new balances must not exist yet, the others must still have the version they were read with
+					newBalance := in.TransactItems[c.state.count].Update
+					oldBalance := in.TransactItems[c.state.count+1].Update
+
+					return len(in.TransactItems) == c.state.count*2 &&
+						*in.TransactItems[0].Put.TableName == "points" &&
+						*in.TransactItems[0].Put.ConditionExpression == "attribute_not_exists (#0)" &&
+						saved.Version == 1 &&
+						*newBalance.TableName == "point-balance" &&
+						*newBalance.ConditionExpression == "attribute_not_exists (#0)" &&
+						*oldBalance.ConditionExpression == "#0 = :0"
+				})).Return(&dynamodb.TransactWriteItemsOutput{}, c.state.errTransact).Once()
+			}
+
+			err := s.SavePoints(context.Background(), points, balances)
+			tests.AssertError(t, err, c.want.err)
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_DynamoDbStorage_GetPointBalance(t *testing.T) {
+	type state struct {
+		errGetItem error
+		errQuery   error
+		unsaved    bool
+	}
+	type want struct {
+		err     string
+		balance int
+		version int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 25, 4}},
+		{"happy path - balance not saved yet", state{unsaved: true}, want{"", 12, 0}},
+		{"fail - get item", state{errGetItem: errFail}, want{"fail", 0, 0}},
+		{"fail - query points of unsaved balance", state{unsaved: true, errQuery: errFail}, want{"failed to get points: failed to query next points page: fail", 0, 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			item := map[string]types.AttributeValue{
+				"user_id":       &types.AttributeValueMemberS{Value: "456"},
+				"point_type_id": &types.AttributeValueMemberS{Value: models.DefaultPointTypeID},
+				"balance":       &types.AttributeValueMemberN{Value: "25"},
+				"version":       &types.AttributeValueMemberN{Value: "4"},
+			}
+			if c.state.unsaved {
+				item = nil
+			}
+
+			mockDynamoClient.EXPECT().GetItem(mock.Anything, mock.MatchedBy(func(in *dynamodb.GetItemInput) bool {
+				return *in.TableName == "point-balance" && *in.ConsistentRead
+			})).Return(&dynamodb.GetItemOutput{Item: item}, c.state.errGetItem).Once()
+
+			// balances that weren't saved yet are in the latest settled point of the type
+			if c.state.unsaved {
+				mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"id":            &types.AttributeValueMemberS{Value: "2"},
+							"point_type_id": &types.AttributeValueMemberS{Value: "st"},
+							"status":        &types.AttributeValueMemberS{Value: models.PointStatusSettled},
+							"balance":       &types.AttributeValueMemberN{Value: "60"},
+						},
+						{
+							"id":      &types.AttributeValueMemberS{Value: "1"},
+							"status":  &types.AttributeValueMemberS{Value: models.PointStatusSettled},
+							"balance": &types.AttributeValueMemberN{Value: "12"},
+						},
+					},
+				}, c.state.errQuery).Once()
+			}
+
+			s := DynamoDbStorage{
+				client:            mockDynamoClient,
+				tablePoints:       "points",
+				tablePointBalance: "point-balance",
+			}
+
+			res, err := s.GetPointBalance(context.Background(), "456", models.DefaultPointTypeID)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				assert.Equal(t, models.PointBalance{UserID: "456", PointTypeID: models.DefaultPointTypeID, Balance: c.want.balance, Version: c.want.version}, res)
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
 // Unit tests against real dev environment
 // These tests should be skipped unless debugging with real services
 
+func Test_DynamoDbStorage_ScrubPoints(t *testing.T) {
+	type state struct {
+		errQuery  error
+		errUpdate error
+	}
+	type want struct {
+		err     string
+		updates int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 2}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to get points: failed to query next points page: fail", 0}},
+		{"fail - update", state{errUpdate: errFail}, want{"failed to scrub point (id=1): fail", 1}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"id":      &types.AttributeValueMemberS{Value: "1"},
+					"user_id": &types.AttributeValueMemberS{Value: "a"},
+				},
+				{
+					"id":      &types.AttributeValueMemberS{Value: "2"},
+					"user_id": &types.AttributeValueMemberS{Value: "a"},
+				},
+			},
+		}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+		if c.want.updates > 0 {
+			mockDynamoClient.EXPECT().UpdateItem(mock.Anything, mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, c.state.errUpdate).Times(c.want.updates)
+		}
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		err := s.ScrubPoints(context.Background(), "a")
+		tests.AssertError(t, err, c.want.err)
+
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
 func TestReal_DynamoDbStorage_GetPointByID(t *testing.T) {
 	t.Skip("Skip real test")
 
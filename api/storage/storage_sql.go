@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+
+	// SQL drivers of the backends. Both are pure Go, so builds don't need cgo.
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// SQLStorage stores points, users and families in PostgreSQL or SQLite, for self-hosting without
+// AWS. Rows have columns for their keys and the attributes that are queried, and keep the whole
+// item in the data column, in the same format as DynamoDB items. The tables are created by the
+// migrate command.
+type SQLStorage struct {
+	db      *sql.DB
+	dialect string
+}
+
+// execer runs statements on the database, or in a transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func NewSQLStorage(cfg Config) (*SQLStorage, error) {
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("missing dsn of %s storage", cfg.Backend)
+	}
+
+	// the drivers are registered with the names of the backends
+	db, err := sql.Open(cfg.Backend, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", cfg.Backend, err)
+	}
+
+	return &SQLStorage{
+		db:      db,
+		dialect: cfg.Backend,
+	}, nil
+}
+
+// DB returns the database of the storage
+func (s *SQLStorage) DB() *sql.DB {
+	return s.db
+}
+
+// Dialect returns the backend of the storage (postgres or sqlite)
+func (s *SQLStorage) Dialect() string {
+	return s.dialect
+}
+
+// Rebind replaces the "?" placeholders of the query with the placeholders of the storage's
+// dialect (i.e. "$1" in PostgreSQL)
+func (s *SQLStorage) Rebind(query string) string {
+	if s.dialect != BackendPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Close closes the database of the storage
+func (s *SQLStorage) Close() error {
+	return s.db.Close()
+}
+
+// withTx runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise
+func (s *SQLStorage) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// exec runs the statement and returns how many rows it changed
+func (s *SQLStorage) exec(ctx context.Context, e execer, query string, args ...any) (int64, error) {
+	res, err := e.ExecContext(ctx, s.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// marshalDocument returns the item as JSON of its DynamoDB attributes, so rows keep the same
+// attributes (and attribute names) as items in DynamoDB
+func marshalDocument(in any) (string, error) {
+	item, err := attributevalue.MarshalMap(in)
+	if err != nil {
+		return "", err
+	}
+
+	var doc map[string]any
+	if err := attributevalue.UnmarshalMap(item, &doc); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// unmarshalDocument unmarshals a document of marshalDocument. If attribs are given, only those
+// attributes are unmarshalled, like a projection expression in DynamoDB.
+func unmarshalDocument(data string, attribs []string, out any) error {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return err
+	}
+
+	if len(attribs) > 0 {
+		doc = projectDocument(doc, attribs)
+	}
+
+	item, err := attributevalue.MarshalMap(doc)
+	if err != nil {
+		return err
+	}
+
+	return attributevalue.UnmarshalMap(item, out)
+}
+
+// projectDocument returns the attributes of the document. Attributes of nested documents are
+// selected by their path (i.e. "request.type").
+func projectDocument(doc map[string]any, attribs []string) map[string]any {
+	out := map[string]any{}
+
+	for _, a := range attribs {
+		src, dst := doc, out
+		names := strings.Split(a, ".")
+
+		for idx, name := range names {
+			v, ok := src[name]
+			if !ok {
+				break
+			}
+
+			if idx == len(names)-1 {
+				dst[name] = v
+				break
+			}
+
+			next, ok := v.(map[string]any)
+			if !ok {
+				break
+			}
+
+			nextDst, ok := dst[name].(map[string]any)
+			if !ok {
+				nextDst = map[string]any{}
+				dst[name] = nextDst
+			}
+
+			src, dst = next, nextDst
+		}
+	}
+
+	return out
+}
+
+// dateFilterSQL returns the condition of the date filter on the column, like dateFilterExpression
+func dateFilterSQL(column string, f models.DateFilter) (string, []any) {
+	if f.From != nil && f.To != nil {
+		return column + " BETWEEN ? AND ?", []any{util.ToFormatted(*f.From), util.ToFormatted(*f.To)}
+	} else if f.From != nil {
+		return column + " >= ?", []any{util.ToFormatted(*f.From)}
+	} else if f.To != nil {
+		return column + " <= ?", []any{util.ToFormatted(*f.To)}
+	}
+
+	return "", nil
+}
+
+// valueInListSQL returns the condition that the column is one of the values, like valueInListExpression
+func valueInListSQL[K comparable](column string, values []K) (string, []any) {
+	marks := make([]string, len(values))
+	args := make([]any, len(values))
+	for idx, v := range values {
+		marks[idx] = "?"
+		args[idx] = v
+	}
+
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(marks, ", ")), args
+}
+
+// nullString returns NULL for empty strings, so removed attributes (i.e. the email of a scrubbed
+// user) aren't found by queries for empty values
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+// AddFamilyUser adds the user to the family
+func (s *SQLStorage) AddFamilyUser(ctx context.Context, familyUser models.FamilyUser) error {
+
+	if familyUser.FamilyID == "" || familyUser.UserID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id or user_id")
+	}
+
+	_, err := s.exec(ctx, s.db, `INSERT INTO family_users (family_id, user_id) VALUES (?, ?)
+		ON CONFLICT (family_id, user_id) DO NOTHING`,
+		familyUser.FamilyID, familyUser.UserID)
+
+	if err != nil {
+		return fmt.Errorf("failed to add family user: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveFamilyUser removes the user from the family
+func (s *SQLStorage) RemoveFamilyUser(ctx context.Context, familyUser models.FamilyUser) error {
+
+	_, err := s.exec(ctx, s.db, "DELETE FROM family_users WHERE family_id = ? AND user_id = ?",
+		familyUser.FamilyID, familyUser.UserID)
+
+	if err != nil {
+		return fmt.Errorf("failed to remove family user: %w", err)
+	}
+
+	return nil
+}
+
+// MoveFamilyUser moves the user from one family to another in a single transaction, so a failure
+// never leaves the user in neither family. Either family may be empty to only remove the user from
+// a family, or to only add them to one. Fails with not found if the user isn't in the family
+// they're moved from. The user is re-read and the move retried if the user changes in the meantime.
+func (s *SQLStorage) MoveFamilyUser(ctx context.Context, userId, fromFamilyId, toFamilyId string) error {
+	return RetryOnConflict(ctx, func(ctx context.Context) error {
+		user, err := s.GetUserByID(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		familyIds, err := movedFamilyIDs(user, fromFamilyId, toFamilyId)
+		if err != nil {
+			return err
+		}
+
+		version := user.Version
+		user.FamilyIDs = familyIds
+		user.Version++
+
+		data, err := marshalDocument(user)
+		if err != nil {
+			return fmt.Errorf("failed to marshal map from user: %w", err)
+		}
+
+		return s.withTx(ctx, func(tx *sql.Tx) error {
+			n, err := s.exec(ctx, tx, "UPDATE users SET version = ?, data = ? WHERE user_id = ? AND version = ?",
+				user.Version, data, user.UserID, version)
+			if err != nil {
+				return fmt.Errorf("failed to save user (id=%s): %w", user.UserID, err)
+			}
+
+			if n == 0 {
+				return conflictError("user", user.UserID)
+			}
+
+			if fromFamilyId != "" {
+				_, err := s.exec(ctx, tx, "DELETE FROM family_users WHERE family_id = ? AND user_id = ?",
+					fromFamilyId, userId)
+				if err != nil {
+					return fmt.Errorf("failed to remove family user: %w", err)
+				}
+			}
+
+			if toFamilyId != "" {
+				_, err := s.exec(ctx, tx, `INSERT INTO family_users (family_id, user_id) VALUES (?, ?)
+					ON CONFLICT (family_id, user_id) DO NOTHING`,
+					toFamilyId, userId)
+				if err != nil {
+					return fmt.Errorf("failed to add family user: %w", err)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+func (s *SQLStorage) GetFamilyMembersByUserIDs(ctx context.Context, family_id string, user_ids []string) (models.Family, error) {
+	family := models.Family{
+		FamilyID: family_id,
+		Parents:  map[string]models.FamilyMember{},
+		Children: map[string]models.FamilyMember{},
+	}
+
+	users := []models.User{}
+
+	if len(user_ids) > 0 {
+		cond, args := valueInListSQL("user_id", user_ids)
+
+		rows, err := s.db.QueryContext(ctx, s.Rebind("SELECT data FROM users WHERE "+cond), args...)
+		if err != nil {
+			return family, fmt.Errorf("failed to query users: %w", err)
+		}
+		defer rows.Close()
+
+		apiErr := apierr.New(fmt.Errorf("failed parsing users"))
+		for idx := 0; rows.Next(); idx++ {
+			var data string
+			if err := rows.Scan(&data); err != nil {
+				return family, fmt.Errorf("failed to scan user: %w", err)
+			}
+
+			user := models.User{}
+			if err := unmarshalDocument(data, nil, &user); err != nil {
+				apiErr.AppendErrorf("user[%d] unmarshal error: %s", idx, err.Error())
+				continue
+			}
+
+			users = append(users, user)
+		}
+
+		if err := rows.Err(); err != nil {
+			return family, fmt.Errorf("failed to query users: %w", err)
+		}
+
+		if len(apiErr.Errors()) > 0 {
+			return family, apiErr
+		}
+	}
+
+	if len(users) == 0 {
+		logger.WithContext(ctx).WithField("family_id", user_ids).Warnf("no users found (family_id:%s)", family_id)
+		return family, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("family (family_id=%s)", family_id))
+	}
+
+	for _, user := range users {
+		if user.IsParent() {
+			family.Parents[user.UserID] = models.NewFamilyUser(user)
+		} else if user.IsChild() {
+			family.Children[user.UserID] = models.NewFamilyUser(user)
+		}
+	}
+
+	return family, nil
+}
+
+func (s *SQLStorage) GetFamilyUsers(ctx context.Context, family_id string) ([]models.FamilyUser, error) {
+	familyUsers := []models.FamilyUser{}
+
+	rows, err := s.db.QueryContext(ctx, s.Rebind("SELECT family_id, user_id FROM family_users WHERE family_id = ? ORDER BY user_id"), family_id)
+	if err != nil {
+		return familyUsers, fmt.Errorf("failed to query family users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fu := models.FamilyUser{}
+		if err := rows.Scan(&fu.FamilyID, &fu.UserID); err != nil {
+			return familyUsers, fmt.Errorf("failed to scan family user: %w", err)
+		}
+
+		familyUsers = append(familyUsers, fu)
+	}
+
+	if err := rows.Err(); err != nil {
+		return familyUsers, fmt.Errorf("failed to query family users: %w", err)
+	}
+
+	if len(familyUsers) == 0 {
+		logger.WithContext(ctx).WithField("family_id", family_id).Warnf("no family users found (family_id:%s)", family_id)
+		return familyUsers, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("family users (family_id=%s)", family_id))
+	}
+
+	return familyUsers, nil
+}
+
+// GetFamilySettings returns the settings of the family, or the default settings if the
+// family's parents haven't changed any
+func (s *SQLStorage) GetFamilySettings(ctx context.Context, family_id string) (models.FamilySettings, error) {
+	settings := models.NewFamilySettings(family_id)
+
+	var data string
+	err := s.db.QueryRowContext(ctx, s.Rebind("SELECT data FROM family_settings WHERE family_id = ?"), family_id).Scan(&data)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+
+	if err != nil {
+		return settings, fmt.Errorf("failed to query family settings: %w", err)
+	}
+
+	if err := unmarshalDocument(data, nil, &settings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	settings.ParseTimes()
+	return settings, nil
+}
+
+// SaveFamilySettings saves the settings of the family
+func (s *SQLStorage) SaveFamilySettings(ctx context.Context, settings models.FamilySettings) error {
+
+	if settings.FamilyID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id")
+	}
+
+	data, err := marshalDocument(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from family settings: %w", err)
+	}
+
+	_, err = s.exec(ctx, s.db, `INSERT INTO family_settings (family_id, data) VALUES (?, ?)
+		ON CONFLICT (family_id) DO UPDATE SET data = excluded.data`,
+		settings.FamilyID, data)
+
+	if err != nil {
+		return fmt.Errorf("failed to save family settings: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFamilySettings deletes the settings of the family
+func (s *SQLStorage) DeleteFamilySettings(ctx context.Context, family_id string) error {
+
+	_, err := s.exec(ctx, s.db, "DELETE FROM family_settings WHERE family_id = ?", family_id)
+	if err != nil {
+		return fmt.Errorf("failed to delete family settings: %w", err)
+	}
+
+	return nil
+}
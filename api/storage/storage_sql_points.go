@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+func (s *SQLStorage) GetPointByID(ctx context.Context, userId, id string) (models.Point, error) {
+	point := models.Point{}
+
+	var data string
+	err := s.db.QueryRowContext(ctx, s.Rebind("SELECT data FROM points WHERE user_id = ? AND id = ?"), userId, id).Scan(&data)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.WithContext(ctx).WithFields(map[string]any{"userId": userId, "id": id}).Warnf("item (id:%s) not found", id)
+		return point, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("point (id=%s)", id))
+	}
+
+	if err != nil {
+		return point, fmt.Errorf("failed to query point: %w", err)
+	}
+
+	if err := unmarshalDocument(data, nil, &point); err != nil {
+		return point, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	return point, nil
+}
+
+func (s *SQLStorage) GetPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter) ([]models.Point, error) {
+	points := []models.Point{}
+
+	err := s.StreamPointsByUserID(ctx, userId, filters, func(p models.Point) error {
+		points = append(points, p)
+		return nil
+	})
+
+	return points, err
+}
+
+// StreamPointsByUserID queries the user's points and calls fn for each point, so callers don't
+// have to keep all points in memory. Stops at the first error returned by fn.
+func (s *SQLStorage) StreamPointsByUserID(ctx context.Context, userId string, filters models.QueryPointsFilter, fn func(models.Point) error) error {
+	where := []string{"user_id = ?"}
+	args := []any{userId}
+
+	if filters.CreatedOn.IsSet() {
+		cond, condArgs := dateFilterSQL("created_on", filters.CreatedOn)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	if filters.UpdatedOn.IsSet() {
+		cond, condArgs := dateFilterSQL("updated_on", filters.UpdatedOn)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	if len(filters.Statuses) > 0 {
+		cond, condArgs := valueInListSQL("status", filters.Statuses)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	if len(filters.Types) > 0 {
+		cond, condArgs := valueInListSQL("request_type", filters.Types)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	// order by updated_on descending (latest first) by default
+	order := "DESC"
+	if filters.Ascending {
+		order = "ASC"
+	}
+
+	query := fmt.Sprintf("SELECT data FROM points WHERE %s ORDER BY updated_on %s, id %s", strings.Join(where, " AND "), order, order)
+
+	rows, err := s.db.QueryContext(ctx, s.Rebind(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to query points: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return fmt.Errorf("failed to scan point: %w", err)
+		}
+
+		p := models.Point{}
+		if err := unmarshalDocument(data, filters.Attributes, &p); err != nil {
+			return fmt.Errorf("failed to unmarshal points from query response: %w", err)
+		}
+
+		p.ParseTimes()
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query points: %w", err)
+	}
+
+	return nil
+}
+
+// SavePoint saves the point, unless it was changed since it was read (then the save fails with a
+// conflict error). The point's version is incremented with every save.
+func (s *SQLStorage) SavePoint(ctx context.Context, point models.Point) error {
+
+	if err := validateNewPoint(point); err != nil {
+		return err
+	}
+
+	saved, err := s.savePoint(ctx, s.db, point)
+	if err != nil {
+		return err
+	}
+
+	if !saved {
+		return conflictError("point", point.ID)
+	}
+
+	return nil
+}
+
+// SavePoints saves points and the balances they change in a single transaction, so either all or
+// none of them are saved. Like SavePoint, points and balances are only saved if they weren't
+// changed since they were read (new ones have version 0), otherwise the save fails with a conflict
+// error. Their versions are incremented with every save.
+func (s *SQLStorage) SavePoints(ctx context.Context, points []models.Point, balances []models.PointBalance) error {
+
+	if err := validateSavePoints(points, balances); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, point := range points {
+			saved, err := s.savePoint(ctx, tx, point)
+			if err != nil {
+				return err
+			}
+
+			if !saved {
+				return pointsConflictError()
+			}
+		}
+
+		for _, balance := range balances {
+			saved, err := s.savePointBalance(ctx, tx, balance)
+			if err != nil {
+				return err
+			}
+
+			if !saved {
+				return pointsConflictError()
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetPointBalance returns the balance of the user's points of the type
+func (s *SQLStorage) GetPointBalance(ctx context.Context, userId, typeId string) (models.PointBalance, error) {
+	balance := models.PointBalance{UserID: userId, PointTypeID: typeId}
+
+	err := s.db.QueryRowContext(ctx, s.Rebind("SELECT balance, version FROM point_balances WHERE user_id = ? AND point_type_id = ?"), userId, typeId).
+		Scan(&balance.Balance, &balance.Version)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return unsavedBalance(ctx, s, userId, typeId)
+	}
+
+	if err != nil {
+		return balance, fmt.Errorf("failed to query point balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// savePointBalance inserts the balance if its version is 0, or updates it if its version is the
+// saved version. Returns false if neither is the case.
+func (s *SQLStorage) savePointBalance(ctx context.Context, e execer, balance models.PointBalance) (bool, error) {
+	var n int64
+	var err error
+
+	if balance.Version == 0 {
+		n, err = s.exec(ctx, e, `INSERT INTO point_balances (user_id, point_type_id, balance, version)
+			VALUES (?, ?, ?, 1)
+			ON CONFLICT (user_id, point_type_id) DO NOTHING`,
+			balance.UserID, balance.PointTypeID, balance.Balance)
+	} else {
+		n, err = s.exec(ctx, e, `UPDATE point_balances SET balance = ?, version = version + 1
+			WHERE user_id = ? AND point_type_id = ? AND version = ?`,
+			balance.Balance, balance.UserID, balance.PointTypeID, balance.Version)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to save balance of user %s: %w", balance.UserID, err)
+	}
+
+	return n > 0, nil
+}
+
+// savePoint inserts the point if its version is 0, or updates it if its version is the saved
+// version. Returns false if neither is the case.
+func (s *SQLStorage) savePoint(ctx context.Context, e execer, point models.Point) (bool, error) {
+	version := point.Version
+	point.Version++
+
+	data, err := marshalDocument(point)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal map from point: %w", err)
+	}
+
+	var n int64
+	if version == 0 {
+		n, err = s.exec(ctx, e, `INSERT INTO points (user_id, id, status, request_type, created_on, updated_on, version, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, id) DO NOTHING`,
+			point.UserID, point.ID, point.Status, point.Request.Type, point.CreatedOnStr, point.UpdatedOnStr, point.Version, data)
+	} else {
+		n, err = s.exec(ctx, e, `UPDATE points SET status = ?, request_type = ?, created_on = ?, updated_on = ?, version = ?, data = ?
+			WHERE user_id = ? AND id = ? AND version = ?`,
+			point.Status, point.Request.Type, point.CreatedOnStr, point.UpdatedOnStr, point.Version, data,
+			point.UserID, point.ID, version)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to save point (id=%s): %w", point.ID, err)
+	}
+
+	return n > 0, nil
+}
+
+// ScrubPoints removes the free text (reasons and parent notes) from all of the user's points.
+// Amounts, balances and statuses are kept, so the anonymized ledger still adds up. The points are
+// re-read and scrubbed again if any of them change in the meantime.
+func (s *SQLStorage) ScrubPoints(ctx context.Context, userId string) error {
+	return RetryOnConflict(ctx, func(ctx context.Context) error {
+		return s.scrubPoints(ctx, userId)
+	})
+}
+
+func (s *SQLStorage) scrubPoints(ctx context.Context, userId string) error {
+
+	points, err := s.GetPointsByUserID(ctx, userId, models.QueryPointsFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to get points: %w", err)
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, point := range points {
+			point.Request.Reason = ""
+			point.Request.ParentNotes = ""
+
+			saved, err := s.savePoint(ctx, tx, point)
+			if err != nil {
+				return err
+			}
+
+			if !saved {
+				return conflictError("point", point.ID)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SQLStorage_Rebind(t *testing.T) {
+	type state struct {
+		dialect string
+	}
+	type want struct {
+		query string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"sqlite", state{BackendSQLite}, want{"SELECT data FROM points WHERE user_id = ? AND status IN (?, ?)"}},
+		{"postgres", state{BackendPostgres}, want{"SELECT data FROM points WHERE user_id = $1 AND status IN ($2, $3)"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &SQLStorage{dialect: c.state.dialect}
+			assert.Equal(t, c.want.query, s.Rebind("SELECT data FROM points WHERE user_id = ? AND status IN (?, ?)"))
+		})
+	}
+}
+
+func Test_unmarshalDocument(t *testing.T) {
+	type state struct {
+		attribs []string
+	}
+	type want struct {
+		point models.Point
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	balance := 12
+	point := models.Point{
+		ID:           "p1",
+		UserID:       "u1",
+		Points:       5,
+		Balance:      &balance,
+		Status:       models.PointStatusSettled,
+		UpdatedOnStr: "2024-01-01T00:00:00Z",
+		Request:      models.PointRequest{Type: models.PointRequestTypeAdd, Reason: "chores"},
+		Version:      3,
+	}
+
+	cases := []test{
+		{"all attributes", state{}, want{point}},
+		{"some attributes", state{[]string{"id", "balance", "request.type", "unknown", "id.unknown"}}, want{models.Point{
+			ID:      "p1",
+			Balance: &balance,
+			Request: models.PointRequest{Type: models.PointRequestTypeAdd},
+		}}},
+	}
+
+	data, err := marshalDocument(point)
+	assert.Nil(t, err)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got models.Point
+			err := unmarshalDocument(data, c.state.attribs, &got)
+			assert.Nil(t, err)
+			assert.Equal(t, c.want.point, got)
+		})
+	}
+}
+
+func Test_dateFilterSQL(t *testing.T) {
+	type state struct {
+		from *time.Time
+		to   *time.Time
+	}
+	type want struct {
+		cond string
+		args int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	cases := []test{
+		{"between", state{from: &from, to: &to}, want{"updated_on BETWEEN ? AND ?", 2}},
+		{"from", state{from: &from}, want{"updated_on >= ?", 1}},
+		{"to", state{to: &to}, want{"updated_on <= ?", 1}},
+		{"none", state{}, want{"", 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cond, args := dateFilterSQL("updated_on", models.DateFilter{From: c.state.from, To: c.state.to})
+			assert.Equal(t, c.want.cond, cond)
+			assert.Len(t, args, c.want.args)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+// GetAllUsers returns all users.
+// This is expensive and only meant for maintenance jobs, never for request handlers.
+func (s *SQLStorage) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	users := []models.User{}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT data FROM users ORDER BY user_id")
+	if err != nil {
+		return users, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return users, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		u := models.User{}
+		if err := unmarshalDocument(data, nil, &u); err != nil {
+			return users, fmt.Errorf("failed to unmarshal users from query response: %w", err)
+		}
+
+		u.ParseTimes()
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return users, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (s *SQLStorage) GetUserByID(ctx context.Context, userId string) (models.User, error) {
+	return s.getUserByColumn(ctx, "user_id", userId)
+}
+
+// GetUserByEmail returns the user with the given email
+func (s *SQLStorage) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	return s.getUserByColumn(ctx, "email", email)
+}
+
+// GetUserByUsername returns the user with the given username
+func (s *SQLStorage) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	return s.getUserByColumn(ctx, "username", username)
+}
+
+// getUserByColumn returns the first user whose column matches the value
+func (s *SQLStorage) getUserByColumn(ctx context.Context, column, value string) (models.User, error) {
+	user := models.User{}
+
+	var data string
+	query := fmt.Sprintf("SELECT data FROM users WHERE %s = ? ORDER BY user_id LIMIT 1", column)
+	err := s.db.QueryRowContext(ctx, s.Rebind(query), value).Scan(&data)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.WithContext(ctx).WithField(column, value).Warnf("item (%s:%s) not found", column, value)
+		return user, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("user (%s=%s)", column, value))
+	}
+
+	if err != nil {
+		return user, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	if err := unmarshalDocument(data, nil, &user); err != nil {
+		return user, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	user.ParseTimes()
+	return user, nil
+}
+
+// SaveUser saves the user, unless it was changed since it was read (then the save fails with a
+// conflict error). A new user (version 0) never overwrites an existing one.
+func (s *SQLStorage) SaveUser(ctx context.Context, user models.User) error {
+	version := user.Version
+	user.Version++
+
+	data, err := marshalDocument(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from user: %w", err)
+	}
+
+	var n int64
+	if version == 0 {
+		n, err = s.exec(ctx, s.db, `INSERT INTO users (user_id, email, username, version, data)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id) DO NOTHING`,
+			user.UserID, nullString(user.Email), nullString(user.Username), user.Version, data)
+	} else {
+		n, err = s.exec(ctx, s.db, `UPDATE users SET email = ?, username = ?, version = ?, data = ?
+			WHERE user_id = ? AND version = ?`,
+			nullString(user.Email), nullString(user.Username), user.Version, data,
+			user.UserID, version)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to save user (id=%s): %w", user.UserID, err)
+	}
+
+	if n == 0 {
+		return conflictError("user", user.UserID)
+	}
+
+	return nil
+}
+
+// AddUserFamily adds the family to the user's family IDs
+func (s *SQLStorage) AddUserFamily(ctx context.Context, userId, familyId string) error {
+	return s.UpdateUserFamily(ctx, UpdateUserFamilyRequest{UserID: userId, FamilyID: familyId, Add: true})
+}
+
+// RemoveUserFamily removes the family from the user's family IDs
+func (s *SQLStorage) RemoveUserFamily(ctx context.Context, userId, familyId string) error {
+	return s.UpdateUserFamily(ctx, UpdateUserFamilyRequest{UserID: userId, FamilyID: familyId, Add: false})
+}
+
+// UpdateUserFamily adds the family to (or removes it from) the user's family IDs. The user is
+// re-read and the update retried if the user changes in the meantime.
+func (s *SQLStorage) UpdateUserFamily(ctx context.Context, req UpdateUserFamilyRequest) error {
+	return s.updateExistingUser(ctx, req.UserID, func(user *models.User) error {
+		familyIds := []string{}
+		for _, fid := range user.FamilyIDs {
+			if fid != req.FamilyID {
+				familyIds = append(familyIds, fid)
+			}
+		}
+
+		if req.Add {
+			familyIds = append(familyIds, req.FamilyID)
+		}
+
+		user.FamilyIDs = familyIds
+		return nil
+	})
+}
+
+// UpdateUserConfirmationSentOn records when a registration confirmation code was sent to the user.
+// Returns a too many requests error if the previous code was sent less than minInterval before sentOn.
+func (s *SQLStorage) UpdateUserConfirmationSentOn(ctx context.Context, userId string, sentOn time.Time, minInterval time.Duration) error {
+	return s.updateExistingUser(ctx, userId, func(user *models.User) error {
+		if user.ConfirmationSentOnStr != "" && user.ConfirmationSentOnStr > util.ToFormattedUTC(sentOn.Add(-minInterval)) {
+			return apierr.New(apierr.TooManyRequests).WithError("a confirmation code was sent recently, please try again later")
+		}
+
+		user.ConfirmationSentOnStr = util.ToFormattedUTC(sentOn)
+		return nil
+	})
+}
+
+// UpdateUserProfile updates the user's profile fields that are set in the given profile update
+func (s *SQLStorage) UpdateUserProfile(ctx context.Context, userId string, profile models.UserProfileUpdate) error {
+	return s.updateExistingUser(ctx, userId, func(user *models.User) error {
+		user.UpdatedOnStr = util.ToFormattedUTC(time.Now())
+
+		if profile.ChildCallName != nil {
+			user.ChildCallName = *profile.ChildCallName
+		}
+		if profile.Email != nil {
+			user.Email = *profile.Email
+		}
+		if profile.Name != nil {
+			user.Name = *profile.Name
+		}
+		if profile.Notifications != nil {
+			user.Notifications = *profile.Notifications
+		}
+		if profile.PendingEmail != nil {
+			user.PendingEmail = *profile.PendingEmail
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLStorage) UpdateUserRoles(ctx context.Context, userId string, roles []string) error {
+	return s.updateExistingUser(ctx, userId, func(user *models.User) error {
+		user.Roles = roles
+		return nil
+	})
+}
+
+func (s *SQLStorage) UpdateUserStatus(ctx context.Context, userId string, status models.UserStatus) error {
+	return s.updateExistingUser(ctx, userId, func(user *models.User) error {
+		user.Status = status
+		return nil
+	})
+}
+
+// ScrubUser marks the user as deleted and removes their personally identifiable information.
+// The user ID is kept, so the user's points remain attributable to an anonymous account.
+func (s *SQLStorage) ScrubUser(ctx context.Context, userId string) error {
+	return s.updateExistingUser(ctx, userId, func(user *models.User) error {
+		user.Status = models.UserStatusDeleted
+		user.UpdatedOnStr = util.ToFormattedUTC(time.Now())
+		user.ChildCallName = ""
+		user.Email = ""
+		user.Name = ""
+		user.Username = ""
+		return nil
+	})
+}
+
+// updateExistingUser reads the user, applies update to it and saves it. The user is re-read and
+// the update applied again if the user changes in the meantime. Fails with not found if the user
+// doesn't exist.
+func (s *SQLStorage) updateExistingUser(ctx context.Context, userId string, update func(user *models.User) error) error {
+	return RetryOnConflict(ctx, func(ctx context.Context) error {
+		user, err := s.GetUserByID(ctx, userId)
+		if errors.Is(err, apierr.NotFound) {
+			return apierr.New(apierr.NotFound).WithError(fmt.Sprintf("user (id=%s)", userId))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := update(&user); err != nil {
+			return err
+		}
+
+		return s.SaveUser(ctx, user)
+	})
+}
@@ -1,13 +1,20 @@
 package storage
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
 	"github.com/sebboness/yektaspoints/models"
 	"github.com/sebboness/yektaspoints/util/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_dateFilterExpression(t *testing.T) {
@@ -101,3 +108,112 @@ func Test_selectAttributesExpression(t *testing.T) {
 		})
 	}
 }
+
+func Test_versionCondition(t *testing.T) {
+	type test struct {
+		name    string
+		version int
+		cond    string
+	}
+
+	cases := []test{
+		{"new item", 0, "attribute_not_exists (#0)"},
+		{"existing item", 3, "#0 = :0"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := expression.NewBuilder().WithCondition(versionCondition(c.version)).Build()
+			assert.Nil(t, err)
+			assert.Equal(t, c.cond, *expr.Condition())
+			assert.Equal(t, "version", expr.Names()["#0"])
+		})
+	}
+}
+
+func Test_conditionFailed(t *testing.T) {
+	type test struct {
+		name   string
+		err    error
+		result bool
+	}
+
+	cases := []test{
+		{"condition failed", &types.ConditionalCheckFailedException{}, true},
+		{"wrapped condition failed", fmt.Errorf("put: %w", &types.ConditionalCheckFailedException{}), true},
+		{"transaction condition failed", &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("None")}, {Code: aws.String("ConditionalCheckFailed")},
+		}}, true},
+		{"transaction conflict", &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("TransactionConflict")},
+		}}, false},
+		{"other error", errFail, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.result, conditionFailed(c.err))
+		})
+	}
+}
+
+func Test_NewStorages(t *testing.T) {
+	type state struct {
+		cfg         Config
+		errDescribe error
+	}
+	type want struct {
+		err string
+		sql bool
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	sqlite := Config{Env: "local", Backend: BackendSQLite, DSN: "file::memory:"}
+	notFound := &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+
+	cases := []test{
+		{"happy path - dynamodb", state{cfg: Config{Env: "local"}}, want{"", false}},
+		{"happy path - sqlite", state{cfg: sqlite}, want{"", true}},
+		{"fail - dynamodb tables are missing", state{cfg: sqlite, errDescribe: notFound}, want{"the sqlite backend still needs DynamoDB for achievements, " +
+			"point comments, point types, webhooks and their deliveries, the audit trail and family events: " +
+			"failed to describe table mypoints-local-achievement: ResourceNotFoundException", false}},
+		{"fail - dynamodb can't be reached", state{cfg: sqlite, errDescribe: errFail}, want{"failed to describe table mypoints-local-achievement: fail", false}},
+		{"fail - sql storage", state{cfg: Config{Env: "local", Backend: BackendSQLite}}, want{"failed to initialize sqlite storage: missing dsn of sqlite storage", false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.cfg.Backend == BackendSQLite {
+				if c.state.errDescribe != nil {
+					mockDynamoClient.EXPECT().DescribeTable(mock.Anything, mock.Anything).Return(nil, c.state.errDescribe).Once()
+				} else {
+					mockDynamoClient.EXPECT().DescribeTable(mock.Anything, mock.Anything).Return(&dynamodb.DescribeTableOutput{}, nil).Times(len(dynamoDbOnlyTables))
+				}
+			}
+
+			db := &DynamoDbStorage{client: mockDynamoClient}
+
+			stores, err := newStorages(context.Background(), c.state.cfg, db)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err != "" {
+				return
+			}
+
+			assert.NotNil(t, stores.DynamoDb)
+
+			// the dynamodb backend shares the storage, instead of creating another client
+			if c.want.sql {
+				assert.IsType(t, &SQLStorage{}, stores.Core)
+			} else {
+				assert.Same(t, stores.DynamoDb, stores.Core)
+			}
+		})
+	}
+}
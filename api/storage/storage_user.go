@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -10,15 +12,56 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util"
 	apierr "github.com/sebboness/yektaspoints/util/error"
 )
 
 type IUserStorage interface {
+	GetAllUsers(ctx context.Context) ([]models.User, error)
 	GetUserByID(ctx context.Context, userId string) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	AddUserFamily(ctx context.Context, userId, familyId string) error
+	RemoveUserFamily(ctx context.Context, userId, familyId string) error
 	SaveUser(ctx context.Context, user models.User) error
+	ScrubUser(ctx context.Context, userId string) error
+	UpdateUserConfirmationSentOn(ctx context.Context, userId string, sentOn time.Time, minInterval time.Duration) error
+	UpdateUserProfile(ctx context.Context, userId string, profile models.UserProfileUpdate) error
+	UpdateUserRoles(ctx context.Context, userId string, roles []string) error
 	UpdateUserStatus(ctx context.Context, userId string, status models.UserStatus) error
 }
 
+// GetAllUsers returns all users by scanning the whole user table.
+// This is expensive and only meant for maintenance jobs, never for request handlers.
+func (s *DynamoDbStorage) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	users := []models.User{}
+
+	scanPaginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.tableUser),
+	})
+
+	for scanPaginator.HasMorePages() {
+		resp, err := scanPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return users, fmt.Errorf("failed to scan next users page: %w", apiErr)
+		}
+
+		var scannedUsers []models.User
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &scannedUsers)
+		if err != nil {
+			return users, fmt.Errorf("failed to unmarshal users from scan response: %w", err)
+		}
+
+		for _, u := range scannedUsers {
+			u.ParseTimes()
+			users = append(users, u)
+		}
+	}
+
+	return users, nil
+}
+
 func (s *DynamoDbStorage) GetUserByID(ctx context.Context, userId string) (models.User, error) {
 	user := models.User{}
 
@@ -57,19 +100,82 @@ func (s *DynamoDbStorage) GetUserByID(ctx context.Context, userId string) (model
 	return user, nil
 }
 
+// GetUserByEmail returns the user with the given email using the email index
+func (s *DynamoDbStorage) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	return s.getUserByIndex(ctx, "email-index", "email", email)
+}
+
+// GetUserByUsername returns the user with the given username using the username index
+func (s *DynamoDbStorage) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	return s.getUserByIndex(ctx, "username-index", "username", username)
+}
+
+// getUserByIndex returns the first user found in the given index whose key attribute matches the value
+func (s *DynamoDbStorage) getUserByIndex(ctx context.Context, index, key, value string) (models.User, error) {
+	user := models.User{}
+
+	keyEx := expression.Key(key).Equal(expression.Value(value))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return user, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	resp, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableUser),
+		IndexName:                 aws.String(index),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return user, apiErr
+	}
+
+	if len(resp.Items) == 0 {
+		logger.WithContext(ctx).WithField(key, value).Warnf("item (%s:%s) not found", key, value)
+		return user, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("user (%s=%s)", key, value))
+	}
+
+	err = attributevalue.UnmarshalMap(resp.Items[0], &user)
+	if err != nil {
+		return user, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	user.ParseTimes()
+	return user, nil
+}
+
+// SaveUser saves the user, unless it was changed since it was read (then the save fails with a
+// conflict error). A new user (version 0) never overwrites an existing one.
 func (s *DynamoDbStorage) SaveUser(ctx context.Context, user models.User) error {
 
+	expr, err := expression.NewBuilder().WithCondition(versionCondition(user.Version)).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	user.Version++
 	item, err := attributevalue.MarshalMap(user)
 	if err != nil {
 		return fmt.Errorf("failed to marshal map from point: %w", err)
 	}
 
 	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(s.tableUser),
-		Item:      item,
+		TableName:                 aws.String(s.tableUser),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
 	})
 
 	if err != nil {
+		if conditionFailed(err) {
+			return conflictError("user", user.UserID)
+		}
+
 		apiErr := apierr.GetAwsError(err)
 		return apiErr
 	}
@@ -83,13 +189,44 @@ type UpdateUserFamilyRequest struct {
 	Add      bool
 }
 
-// TODO need to finish this when ready to implement (-_o)
+// AddUserFamily adds the family to the user's family IDs
+func (s *DynamoDbStorage) AddUserFamily(ctx context.Context, userId, familyId string) error {
+	return s.UpdateUserFamily(ctx, UpdateUserFamilyRequest{UserID: userId, FamilyID: familyId, Add: true})
+}
+
+// RemoveUserFamily removes the family from the user's family IDs
+func (s *DynamoDbStorage) RemoveUserFamily(ctx context.Context, userId, familyId string) error {
+	return s.UpdateUserFamily(ctx, UpdateUserFamilyRequest{UserID: userId, FamilyID: familyId, Add: false})
+}
+
+// UpdateUserFamily adds the family to (or removes it from) the user's family IDs. The user is
+// re-read and the update retried if the user changes in the meantime.
 func (s *DynamoDbStorage) UpdateUserFamily(ctx context.Context, req UpdateUserFamilyRequest) error {
+	return RetryOnConflict(ctx, func(ctx context.Context) error {
+		return s.updateUserFamily(ctx, req)
+	})
+}
+
+func (s *DynamoDbStorage) updateUserFamily(ctx context.Context, req UpdateUserFamilyRequest) error {
+
+	user, err := s.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
 
 	familyIds := []string{}
+	for _, fid := range user.FamilyIDs {
+		if fid != req.FamilyID {
+			familyIds = append(familyIds, fid)
+		}
+	}
+
+	if req.Add {
+		familyIds = append(familyIds, req.FamilyID)
+	}
 
 	update := expression.Set(expression.Name("family_ids"), expression.Value(familyIds))
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	expr, err := expression.NewBuilder().WithUpdate(bumpVersion(update)).WithCondition(versionCondition(user.Version)).Build()
 	if err != nil {
 		return fmt.Errorf("failed to build expression: %w", err)
 	}
@@ -102,12 +239,61 @@ func (s *DynamoDbStorage) UpdateUserFamily(ctx context.Context, req UpdateUserFa
 	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(s.tableUser),
 		Key:                       map[string]types.AttributeValue{"user_id": keyEx},
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+
+	if err != nil {
+		if conditionFailed(err) {
+			return conflictError("user", req.UserID)
+		}
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// UpdateUserConfirmationSentOn records when a registration confirmation code was sent to the user.
+// Returns a too many requests error if the previous code was sent less than minInterval before sentOn.
+func (s *DynamoDbStorage) UpdateUserConfirmationSentOn(ctx context.Context, userId string, sentOn time.Time, minInterval time.Duration) error {
+
+	update := expression.Set(expression.Name("confirmation_sent_on"), expression.Value(util.ToFormattedUTC(sentOn)))
+	cond := expression.AttributeExists(expression.Name("user_id")).And(
+		expression.Or(
+			expression.AttributeNotExists(expression.Name("confirmation_sent_on")),
+			expression.Name("confirmation_sent_on").LessThanEqual(expression.Value(util.ToFormattedUTC(sentOn.Add(-minInterval)))),
+		),
+	)
+
+	expr, err := expression.NewBuilder().WithUpdate(bumpVersion(update)).WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	keyEx, err := attributevalue.Marshal(userId)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.tableUser),
+		Key:                       map[string]types.AttributeValue{"user_id": keyEx},
+		ConditionExpression:       expr.Condition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
 	})
 
 	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return apierr.New(apierr.TooManyRequests).WithError("a confirmation code was sent recently, please try again later")
+		}
+
 		apiErr := apierr.GetAwsError(err)
 		return apiErr
 	}
@@ -115,10 +301,69 @@ func (s *DynamoDbStorage) UpdateUserFamily(ctx context.Context, req UpdateUserFa
 	return nil
 }
 
+// UpdateUserProfile updates the user's profile fields that are set in the given profile update
+func (s *DynamoDbStorage) UpdateUserProfile(ctx context.Context, userId string, profile models.UserProfileUpdate) error {
+
+	update := expression.Set(expression.Name("updated_on"), expression.Value(util.ToFormattedUTC(time.Now())))
+	if profile.ChildCallName != nil {
+		update = update.Set(expression.Name("child_call_name"), expression.Value(*profile.ChildCallName))
+	}
+	if profile.Email != nil {
+		update = update.Set(expression.Name("email"), expression.Value(*profile.Email))
+	}
+	if profile.Name != nil {
+		update = update.Set(expression.Name("name"), expression.Value(*profile.Name))
+	}
+	if profile.PendingEmail != nil {
+		if *profile.PendingEmail == "" {
+			update = update.Remove(expression.Name("pending_email"))
+		} else {
+			update = update.Set(expression.Name("pending_email"), expression.Value(*profile.PendingEmail))
+		}
+	}
+	if profile.Notifications != nil {
+		notifications, err := attributevalue.Marshal(profile.Notifications)
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification preferences: %w", err)
+		}
+		update = update.Set(expression.Name("notifications"), expression.Value(notifications))
+	}
+
+	return s.updateExistingUser(ctx, userId, update)
+}
+
+func (s *DynamoDbStorage) UpdateUserRoles(ctx context.Context, userId string, roles []string) error {
+
+	update := expression.Set(expression.Name("roles"), expression.Value(roles))
+	return s.updateExistingUser(ctx, userId, update)
+}
+
 func (s *DynamoDbStorage) UpdateUserStatus(ctx context.Context, userId string, status models.UserStatus) error {
 
 	update := expression.Set(expression.Name("status"), expression.Value(status))
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	return s.updateExistingUser(ctx, userId, update)
+}
+
+// ScrubUser marks the user as deleted and removes their personally identifiable information.
+// The user ID is kept, so the user's points remain attributable to an anonymous account.
+func (s *DynamoDbStorage) ScrubUser(ctx context.Context, userId string) error {
+
+	update := expression.Set(expression.Name("status"), expression.Value(models.UserStatusDeleted)).
+		Set(expression.Name("updated_on"), expression.Value(util.ToFormattedUTC(time.Now()))).
+		Remove(expression.Name("child_call_name")).
+		Remove(expression.Name("email")).
+		Remove(expression.Name("name")).
+		Remove(expression.Name("username"))
+
+	return s.updateExistingUser(ctx, userId, update)
+}
+
+// updateExistingUser applies the update to the user and increments the user's version, so that
+// saving an older version of the user fails. Fails with not found if the user doesn't exist.
+func (s *DynamoDbStorage) updateExistingUser(ctx context.Context, userId string, update expression.UpdateBuilder) error {
+
+	cond := expression.AttributeExists(expression.Name("user_id"))
+	expr, err := expression.NewBuilder().WithUpdate(bumpVersion(update)).WithCondition(cond).Build()
 	if err != nil {
 		return fmt.Errorf("failed to build expression: %w", err)
 	}
@@ -131,12 +376,17 @@ func (s *DynamoDbStorage) UpdateUserStatus(ctx context.Context, userId string, s
 	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(s.tableUser),
 		Key:                       map[string]types.AttributeValue{"user_id": keyEx},
+		ConditionExpression:       expr.Condition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
 	})
 
 	if err != nil {
+		if conditionFailed(err) {
+			return apierr.New(apierr.NotFound).WithError(fmt.Sprintf("user (id=%s)", userId))
+		}
+
 		apiErr := apierr.GetAwsError(err)
 		return apiErr
 	}
@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	mocks "github.com/sebboness/yektaspoints/mocks/storage"
@@ -14,6 +17,69 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+func Test_IUserStorage_GetAllUsers(t *testing.T) {
+	type state struct {
+		errScan       error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - scan", state{errScan: errFail}, want{"failed to scan next users page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal users from scan response: unmarshal failed"}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.ScanOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"user_id":  &types.AttributeValueMemberS{Value: "1"},
+					"username": &types.AttributeValueMemberS{Value: "john"},
+				},
+				{
+					"user_id":  &types.AttributeValueMemberS{Value: "2"},
+					"username": &types.AttributeValueMemberS{Value: "jane"},
+				},
+			},
+		}
+
+		if c.state.failUnmarshal {
+			output.Items = []map[string]types.AttributeValue{
+				{
+					"roles": &types.AttributeValueMemberS{Value: "abc"},
+				},
+			}
+		}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errScan)
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		res, err := s.GetAllUsers(context.Background())
+		tests.AssertError(t, err, c.want.err)
+
+		if err == nil {
+			assert.Len(t, res, 2)
+			assert.Equal(t, "john", res[0].Username)
+			assert.Equal(t, "jane", res[1].Username)
+		}
+
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
 func Test_IUserStorage_GetUserByID(t *testing.T) {
 	type state struct {
 		errGetItem    error
@@ -91,6 +157,128 @@ func Test_IUserStorage_GetUserByID(t *testing.T) {
 	}
 }
 
+func Test_IUserStorage_GetUserByEmail(t *testing.T) {
+	type state struct {
+		errQuery     error
+		itemNotFound bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"fail"}},
+		{"fail - not found", state{itemNotFound: true}, want{"resource not found: user (email=john@info.co)"}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"user_id": &types.AttributeValueMemberS{Value: "456"},
+					"email":   &types.AttributeValueMemberS{Value: "john@info.co"},
+				},
+			},
+		}
+
+		if c.state.itemNotFound {
+			output.Items = []map[string]types.AttributeValue{}
+		}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.IndexName == "email-index"
+		})).Return(output, c.state.errQuery)
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		res, err := s.GetUserByEmail(context.Background(), "john@info.co")
+		tests.AssertError(t, err, c.want.err)
+
+		if err == nil {
+			assert.Equal(t, "456", res.UserID)
+			assert.Equal(t, "john@info.co", res.Email)
+		}
+
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
+func Test_IUserStorage_GetUserByUsername(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+		itemNotFound  bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal item: unmarshal failed"}},
+		{"fail - not found", state{itemNotFound: true}, want{"resource not found: user (username=john)"}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"user_id":  &types.AttributeValueMemberS{Value: "456"},
+					"username": &types.AttributeValueMemberS{Value: "john"},
+				},
+			},
+		}
+
+		if c.state.failUnmarshal {
+			output.Items = []map[string]types.AttributeValue{
+				{
+					"roles": &types.AttributeValueMemberS{Value: "abc"},
+				},
+			}
+		}
+
+		if c.state.itemNotFound {
+			output.Items = []map[string]types.AttributeValue{}
+		}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.IndexName == "username-index"
+		})).Return(output, c.state.errQuery)
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		res, err := s.GetUserByUsername(context.Background(), "john")
+		tests.AssertError(t, err, c.want.err)
+
+		if err == nil {
+			assert.Equal(t, "456", res.UserID)
+			assert.Equal(t, "john", res.Username)
+		}
+
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
 func Test_IUserStorage_SaveUser(t *testing.T) {
 	type state struct {
 		errSaveItem error
@@ -107,6 +295,7 @@ func Test_IUserStorage_SaveUser(t *testing.T) {
 	cases := []test{
 		{"happy path", state{}, want{}},
 		{"fail - save user", state{errSaveItem: errFail}, want{"fail"}},
+		{"fail - user exists", state{errSaveItem: &types.ConditionalCheckFailedException{}}, want{"conflict: user (id=1) was changed by someone else"}},
 	}
 
 	for _, c := range cases {
@@ -114,19 +303,190 @@ func Test_IUserStorage_SaveUser(t *testing.T) {
 		output := &dynamodb.PutItemOutput{}
 
 		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
-		mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errSaveItem)
+		mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.MatchedBy(func(in *dynamodb.PutItemInput) bool {
+			// a new user must not overwrite an existing one
+			return *in.ConditionExpression == "attribute_not_exists (#0)"
+		}), mock.Anything).Return(output, c.state.errSaveItem)
 
 		s := DynamoDbStorage{
 			client: mockDynamoClient,
 		}
 
-		err := s.SaveUser(context.Background(), models.User{})
+		err := s.SaveUser(context.Background(), models.User{UserID: "1"})
 		tests.AssertError(t, err, c.want.err)
 		mockDynamoClient.AssertExpectations(t)
 	}
 }
 
 func Test_IUserStorage_UpdateUserFamily(t *testing.T) {
+	type state struct {
+		add       bool
+		errGet    error
+		errUpdate error
+	}
+	type want struct {
+		familyIds []string
+		err       string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path - add", state{add: true}, want{familyIds: []string{"1", "2", "3"}}},
+		{"happy path - remove", state{}, want{familyIds: []string{"1"}}},
+		{"fail - get user", state{errGet: errFail}, want{err: "failed to get user: fail"}},
+		{"fail - update family", state{errUpdate: errFail}, want{err: "fail"}},
+		{"fail - changed every time", state{errUpdate: &types.ConditionalCheckFailedException{}}, want{err: "conflict: user (id=1) was changed by someone else"}},
+	}
+
+	for _, c := range cases {
+
+		queryOutput := &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"user_id":    &types.AttributeValueMemberS{Value: "1"},
+					"family_ids": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "1"}, &types.AttributeValueMemberS{Value: "2"}}},
+				},
+			},
+		}
+
+		familyId := "2"
+		if c.state.add {
+			familyId = "3"
+		}
+
+		// conflicts are retried (re-reading the user) until attempts run out
+		attempts := 1
+		if _, ok := c.state.errUpdate.(*types.ConditionalCheckFailedException); ok {
+			attempts = ConflictRetryAttempts
+		}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything).Return(queryOutput, c.state.errGet).Times(attempts)
+
+		if c.state.errGet == nil {
+			mockDynamoClient.EXPECT().UpdateItem(mock.Anything, mock.Anything, mock.Anything).
+				RunAndReturn(func(ctx context.Context, input *dynamodb.UpdateItemInput, f ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+					var familyIds []string
+					for _, v := range input.ExpressionAttributeValues {
+						_ = attributevalue.Unmarshal(v, &familyIds)
+					}
+					if c.state.errUpdate == nil {
+						assert.Equal(t, c.want.familyIds, familyIds)
+					}
+					return &dynamodb.UpdateItemOutput{}, c.state.errUpdate
+				}).Times(attempts)
+		}
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		err := s.UpdateUserFamily(context.Background(), UpdateUserFamilyRequest{UserID: "1", FamilyID: familyId, Add: c.state.add})
+		tests.AssertError(t, err, c.want.err)
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
+func Test_IUserStorage_UpdateUserConfirmationSentOn(t *testing.T) {
+	type state struct {
+		errUpdate error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - sent recently", state{errUpdate: &types.ConditionalCheckFailedException{}}, want{"too many requests: a confirmation code was sent recently"}},
+		{"fail - update", state{errUpdate: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.UpdateItemOutput{}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().UpdateItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return input.ConditionExpression != nil
+		}), mock.Anything).Return(output, c.state.errUpdate)
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		err := s.UpdateUserConfirmationSentOn(context.Background(), "1", time.Now(), time.Minute)
+		tests.AssertError(t, err, c.want.err)
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
+func Test_IUserStorage_UpdateUserProfile(t *testing.T) {
+	type state struct {
+		notifications bool
+		pendingEmail  *string
+		errUpdate     error
+	}
+	type want struct {
+		names int
+		err   string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{5, ""}},
+		{"happy path - with notification preferences", state{notifications: true}, want{6, ""}},
+		{"happy path - set pending email", state{pendingEmail: aws.String("new@info.co")}, want{6, ""}},
+		{"happy path - remove pending email", state{pendingEmail: aws.String("")}, want{6, ""}},
+		{"fail - update profile", state{errUpdate: errFail}, want{5, "fail"}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.UpdateItemOutput{}
+
+		name := "Jane"
+		childCallName := "Mom"
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().UpdateItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			// updated_on, name and child_call_name (and notifications), but not email. Plus user_id
+			// and version for the condition and version increment.
+			return len(input.ExpressionAttributeNames) == c.want.names
+		}), mock.Anything).Return(output, c.state.errUpdate)
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		profile := models.UserProfileUpdate{
+			ChildCallName: &childCallName,
+			Name:          &name,
+		}
+		if c.state.notifications {
+			profile.Notifications = &models.NotificationPreferences{Language: "en"}
+		}
+		profile.PendingEmail = c.state.pendingEmail
+
+		err := s.UpdateUserProfile(context.Background(), "1", profile)
+		tests.AssertError(t, err, c.want.err)
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
+func Test_IUserStorage_UpdateUserRoles(t *testing.T) {
 	type state struct {
 		errUpdate error
 	}
@@ -141,7 +501,7 @@ func Test_IUserStorage_UpdateUserFamily(t *testing.T) {
 
 	cases := []test{
 		{"happy path", state{}, want{}},
-		{"fail - update family", state{errUpdate: errFail}, want{"fail"}},
+		{"fail - update roles", state{errUpdate: errFail}, want{"fail"}},
 	}
 
 	for _, c := range cases {
@@ -155,7 +515,7 @@ func Test_IUserStorage_UpdateUserFamily(t *testing.T) {
 			client: mockDynamoClient,
 		}
 
-		err := s.UpdateUserFamily(context.Background(), UpdateUserFamilyRequest{})
+		err := s.UpdateUserRoles(context.Background(), "1", []string{models.RoleParent})
 		tests.AssertError(t, err, c.want.err)
 		mockDynamoClient.AssertExpectations(t)
 	}
@@ -177,6 +537,7 @@ func Test_IUserStorage_UpdateUserStatus(t *testing.T) {
 	cases := []test{
 		{"happy path", state{}, want{}},
 		{"fail - update status", state{errUpdate: errFail}, want{"fail"}},
+		{"fail - user not found", state{errUpdate: &types.ConditionalCheckFailedException{}}, want{"resource not found: user (id=1)"}},
 	}
 
 	for _, c := range cases {
@@ -196,6 +557,43 @@ func Test_IUserStorage_UpdateUserStatus(t *testing.T) {
 	}
 }
 
+func Test_IUserStorage_ScrubUser(t *testing.T) {
+	type state struct {
+		errUpdate error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - update user", state{errUpdate: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+
+		output := &dynamodb.UpdateItemOutput{}
+
+		mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+		mockDynamoClient.EXPECT().UpdateItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return strings.Contains(*input.UpdateExpression, "REMOVE")
+		}), mock.Anything).Return(output, c.state.errUpdate)
+
+		s := DynamoDbStorage{
+			client: mockDynamoClient,
+		}
+
+		err := s.ScrubUser(context.Background(), "1")
+		tests.AssertError(t, err, c.want.err)
+		mockDynamoClient.AssertExpectations(t)
+	}
+}
+
 // Tests against real db
 
 func TestReal_IUserStorage_UpdateUserStatus(t *testing.T) {
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sebboness/yektaspoints/models"
+	apierr "github.com/sebboness/yektaspoints/util/error"
+)
+
+type IWebhookStorage interface {
+	DeleteWebhook(ctx context.Context, familyId, id string) error
+	DeleteWebhookDeliveries(ctx context.Context, webhookId string) error
+	GetWebhookByID(ctx context.Context, familyId, id string) (models.Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, webhookId string) ([]models.WebhookDelivery, error)
+	GetWebhooksByFamilyID(ctx context.Context, familyId string) ([]models.Webhook, error)
+	SaveWebhook(ctx context.Context, webhook models.Webhook) error
+	SaveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+func (s *DynamoDbStorage) DeleteWebhook(ctx context.Context, familyId, id string) error {
+
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableWebhook),
+		Key: map[string]types.AttributeValue{
+			"family_id": &types.AttributeValueMemberS{Value: familyId},
+			"id":        &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// DeleteWebhookDeliveries deletes the delivery log of the given webhook
+func (s *DynamoDbStorage) DeleteWebhookDeliveries(ctx context.Context, webhookId string) error {
+
+	deliveries, err := s.GetWebhookDeliveries(ctx, webhookId)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(s.tableWebhookDelivery),
+			Key: map[string]types.AttributeValue{
+				"webhook_id": &types.AttributeValueMemberS{Value: delivery.WebhookID},
+				"id":         &types.AttributeValueMemberS{Value: delivery.ID},
+			},
+		})
+
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return fmt.Errorf("failed to delete webhook delivery (id=%s): %w", delivery.ID, apiErr)
+		}
+	}
+
+	return nil
+}
+
+func (s *DynamoDbStorage) GetWebhookByID(ctx context.Context, familyId, id string) (models.Webhook, error) {
+	webhook := models.Webhook{}
+
+	keyEx := expression.Key("family_id").Equal(expression.Value(familyId)).
+		And(expression.Key("id").Equal(expression.Value(id)))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return webhook, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	resp, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableWebhook),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return webhook, apiErr
+	}
+
+	if len(resp.Items) == 0 {
+		logger.WithContext(ctx).AddFields(map[string]any{"familyId": familyId, "id": id}).Warnf("item (id:%s) not found", id)
+		return webhook, apierr.New(apierr.NotFound).WithError(fmt.Sprintf("webhook (id=%s)", id))
+	}
+
+	err = attributevalue.UnmarshalMap(resp.Items[0], &webhook)
+	if err != nil {
+		return webhook, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	webhook.ParseTimes()
+	return webhook, nil
+}
+
+// GetWebhookDeliveries returns the deliveries of the given webhook, latest first
+func (s *DynamoDbStorage) GetWebhookDeliveries(ctx context.Context, webhookId string) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+
+	keyEx := expression.Key("webhook_id").Equal(expression.Value(webhookId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return deliveries, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableWebhookDelivery),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(false), // ids are ksuids, so this orders by creation date descending
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return deliveries, fmt.Errorf("failed to query next webhook deliveries page: %w", apiErr)
+		}
+
+		var queriedDeliveries []models.WebhookDelivery
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedDeliveries)
+		if err != nil {
+			return deliveries, fmt.Errorf("failed to unmarshal webhook deliveries from query response: %w", err)
+		}
+
+		for _, d := range queriedDeliveries {
+			d.ParseTimes()
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (s *DynamoDbStorage) GetWebhooksByFamilyID(ctx context.Context, familyId string) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+
+	keyEx := expression.Key("family_id").Equal(expression.Value(familyId))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return webhooks, fmt.Errorf("failed to build query expression: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableWebhook),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			apiErr := apierr.GetAwsError(err)
+			return webhooks, fmt.Errorf("failed to query next webhooks page: %w", apiErr)
+		}
+
+		var queriedWebhooks []models.Webhook
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &queriedWebhooks)
+		if err != nil {
+			return webhooks, fmt.Errorf("failed to unmarshal webhooks from query response: %w", err)
+		}
+
+		for _, w := range queriedWebhooks {
+			w.ParseTimes()
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (s *DynamoDbStorage) SaveWebhook(ctx context.Context, webhook models.Webhook) error {
+
+	if webhook.FamilyID == "" || webhook.ID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing family_id or id")
+	}
+
+	item, err := attributevalue.MarshalMap(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from webhook: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableWebhook),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+func (s *DynamoDbStorage) SaveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+
+	if delivery.WebhookID == "" || delivery.ID == "" {
+		return apierr.New(fmt.Errorf("%w: failed to validate request", apierr.InvalidInput)).WithError("missing webhook_id or id")
+	}
+
+	item, err := attributevalue.MarshalMap(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map from webhook delivery: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableWebhookDelivery),
+		Item:      item,
+	})
+
+	if err != nil {
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
@@ -0,0 +1,404 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_IWebhookStorage_DeleteWebhook(t *testing.T) {
+	type state struct {
+		errDelete error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - delete", state{errDelete: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+				return input.Key["family_id"].(*types.AttributeValueMemberS).Value == "456" &&
+					input.Key["id"].(*types.AttributeValueMemberS).Value == "1"
+			})).Return(&dynamodb.DeleteItemOutput{}, c.state.errDelete)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.DeleteWebhook(context.Background(), "456", "1")
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IWebhookStorage_DeleteWebhookDeliveries(t *testing.T) {
+	type state struct {
+		errQuery  error
+		errDelete error
+	}
+	type want struct {
+		err     string
+		deletes int
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 2}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next webhook deliveries page: fail", 0}},
+		{"fail - delete", state{errDelete: errFail}, want{"failed to delete webhook delivery (id=2): fail", 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{"webhook_id": &types.AttributeValueMemberS{Value: "w1"}, "id": &types.AttributeValueMemberS{Value: "2"}},
+					{"webhook_id": &types.AttributeValueMemberS{Value: "w1"}, "id": &types.AttributeValueMemberS{Value: "1"}},
+				},
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery).Once()
+
+			if c.want.deletes > 0 {
+				mockDynamoClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+					return *input.TableName == "webhook-delivery" &&
+						input.Key["webhook_id"].(*types.AttributeValueMemberS).Value == "w1"
+				})).Return(&dynamodb.DeleteItemOutput{}, c.state.errDelete).Times(c.want.deletes)
+			}
+
+			s := DynamoDbStorage{
+				client:               mockDynamoClient,
+				tableWebhookDelivery: "webhook-delivery",
+			}
+
+			err := s.DeleteWebhookDeliveries(context.Background(), "w1")
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IWebhookStorage_GetWebhookByID(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+		itemNotFound  bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal item: unmarshal failed"}},
+		{"fail - not found", state{itemNotFound: true}, want{"resource not found: webhook (id=1)"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"family_id":  &types.AttributeValueMemberS{Value: "456"},
+						"id":         &types.AttributeValueMemberS{Value: "1"},
+						"url":        &types.AttributeValueMemberS{Value: "https://example.com/hook"},
+						"secret":     &types.AttributeValueMemberS{Value: "shh"},
+						"created_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"event_types": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			if c.state.itemNotFound {
+				output.Items = []map[string]types.AttributeValue{}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetWebhookByID(context.Background(), "456", "1")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, "https://example.com/hook", res.URL)
+				assert.Equal(t, "shh", res.Secret)
+				assert.Equal(t, 2024, res.CreatedOn.Year())
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IWebhookStorage_GetWebhookDeliveries(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next webhook deliveries page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal webhook deliveries from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"webhook_id": &types.AttributeValueMemberS{Value: "1"},
+						"id":         &types.AttributeValueMemberS{Value: "2"},
+						"status":     &types.AttributeValueMemberS{Value: "SUCCEEDED"},
+						"attempts":   &types.AttributeValueMemberN{Value: "1"},
+						"updated_on": &types.AttributeValueMemberS{Value: "2024-03-10T20:00:00.0000000Z"},
+					},
+					{
+						"webhook_id": &types.AttributeValueMemberS{Value: "1"},
+						"id":         &types.AttributeValueMemberS{Value: "1"},
+						"status":     &types.AttributeValueMemberS{Value: "FAILED"},
+						"attempts":   &types.AttributeValueMemberN{Value: "5"},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"attempts": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetWebhookDeliveries(context.Background(), "1")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res, 2)
+				assert.Equal(t, models.WebhookDeliveryStatusSucceeded, res[0].Status)
+				assert.Equal(t, 2024, res[0].UpdatedOn.Year())
+				assert.Equal(t, 5, res[1].Attempts)
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IWebhookStorage_GetWebhooksByFamilyID(t *testing.T) {
+	type state struct {
+		errQuery      error
+		failUnmarshal bool
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - query", state{errQuery: errFail}, want{"failed to query next webhooks page: fail"}},
+		{"fail - unmarshal", state{failUnmarshal: true}, want{"failed to unmarshal webhooks from query response"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output := &dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{
+						"family_id": &types.AttributeValueMemberS{Value: "456"},
+						"id":        &types.AttributeValueMemberS{Value: "1"},
+						"event_types": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: "points_requested"},
+						}},
+					},
+				},
+			}
+
+			if c.state.failUnmarshal {
+				output.Items = []map[string]types.AttributeValue{
+					{
+						"event_types": &types.AttributeValueMemberS{Value: "abc"},
+					},
+				}
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+			mockDynamoClient.EXPECT().Query(mock.Anything, mock.Anything, mock.Anything).Return(output, c.state.errQuery)
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			res, err := s.GetWebhooksByFamilyID(context.Background(), "456")
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Len(t, res, 1)
+				assert.True(t, res[0].HasEventType("points_requested"))
+			}
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IWebhookStorage_SaveWebhook(t *testing.T) {
+	type state struct {
+		missingId bool
+		errSave   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing id", state{missingId: true}, want{"missing family_id or id"}},
+		{"fail - save", state{errSave: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			webhook := models.Webhook{
+				FamilyID:   "456",
+				ID:         "1",
+				URL:        "https://example.com/hook",
+				EventTypes: []string{"points_requested"},
+				Secret:     "shh",
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingId {
+				webhook.ID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, c.state.errSave)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SaveWebhook(context.Background(), webhook)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_IWebhookStorage_SaveWebhookDelivery(t *testing.T) {
+	type state struct {
+		missingId bool
+		errSave   error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - missing id", state{missingId: true}, want{"missing webhook_id or id"}},
+		{"fail - save", state{errSave: errFail}, want{"fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			delivery := models.WebhookDelivery{
+				WebhookID: "1",
+				ID:        "2",
+				Status:    models.WebhookDeliveryStatusPending,
+			}
+
+			mockDynamoClient := mocks.NewMockDynamoDbClient(t)
+
+			if c.state.missingId {
+				delivery.ID = ""
+			} else {
+				mockDynamoClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, c.state.errSave)
+			}
+
+			s := DynamoDbStorage{
+				client: mockDynamoClient,
+			}
+
+			err := s.SaveWebhookDelivery(context.Background(), delivery)
+			tests.AssertError(t, err, c.want.err)
+
+			mockDynamoClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Package attachment stores files attached to point requests, i.e. photos of a completed chore.
+// Clients upload and download files directly from the store with pre-signed URLs.
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util/env"
+)
+
+// How long pre-signed upload and download URLs are valid
+const URLExpiry = 15 * time.Minute
+
+// Store issues pre-signed URLs for attachments and deletes them. Keys are paths like
+// "<user_id>/<point_id>/<attachment_id>".
+type Store interface {
+	// PresignUpload returns a URL the file can be PUT to. The upload must have the given
+	// content type and size.
+	PresignUpload(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, error)
+
+	// PresignDownload returns a URL the file can be fetched from
+	PresignDownload(ctx context.Context, key string, expires time.Duration) (string, error)
+
+	// Delete deletes the file. Deleting a file that doesn't exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStoreFromEnv returns an S3 store if ATTACHMENTS_BUCKET is set. ATTACHMENTS_ENDPOINT can point
+// it to an S3-compatible service. Without a bucket, files are stored in ATTACHMENTS_DIR (a temp
+// directory by default) and served by the API at ATTACHMENTS_BASE_URL (http://localhost:8080 by default).
+func NewStoreFromEnv(ctx context.Context) (Store, error) {
+	if bucket := env.GetEnv("ATTACHMENTS_BUCKET"); bucket != "" {
+		s, err := NewS3Store(ctx, bucket, env.GetEnv("ATTACHMENTS_ENDPOINT"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize s3 attachment store: %w", err)
+		}
+		return s, nil
+	}
+
+	dir := env.GetEnv("ATTACHMENTS_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "mypoints-attachments")
+	}
+
+	baseURL := env.GetEnv("ATTACHMENTS_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	return NewLocalStore(dir, baseURL)
+}
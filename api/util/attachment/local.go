@@ -0,0 +1,163 @@
+package attachment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Path the local store's files are served at
+const LocalPathPrefix = "/attachments/"
+
+// LocalStore keeps attachments in a directory and serves them itself (see ServeHTTP). URLs are
+// signed like S3's, so clients work the same with both stores. Meant for development and tests.
+type LocalStore struct {
+	baseURL string
+	dir     string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocalStore returns a store keeping files in dir, with URLs starting with baseURL. URLs are
+// signed with a random key, so they aren't valid anymore once the process restarts.
+func NewLocalStore(dir, baseURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return &LocalStore{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		dir:     dir,
+		secret:  secret,
+		now:     time.Now,
+	}, nil
+}
+
+func (s *LocalStore) PresignUpload(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, contentType, size, expires)
+}
+
+func (s *LocalStore) PresignDownload(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, "", 0, expires)
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	return nil
+}
+
+// ServeHTTP handles uploads (PUT) and downloads (GET) of pre-signed URLs
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, LocalPathPrefix)
+	query := r.URL.Query()
+
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	size, _ := strconv.ParseInt(query.Get("size"), 10, 64)
+	contentType := query.Get("content_type")
+
+	want := s.signature(r.Method, key, contentType, size, expires)
+	if !hmac.Equal([]byte(want), []byte(query.Get("signature"))) || s.now().Unix() > expires {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	path, err := s.path(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		http.ServeFile(w, r, path)
+	case http.MethodPut:
+		if r.Header.Get("Content-Type") != contentType || r.ContentLength != size {
+			http.Error(w, "content type or size doesn't match the signed upload", http.StatusForbidden)
+			return
+		}
+
+		if err := s.write(path, io.LimitReader(r.Body, size)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *LocalStore) write(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write attachment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) presign(method, key, contentType string, size int64, expires time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	expiresAt := s.now().Add(expires).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	if method == http.MethodPut {
+		query.Set("content_type", contentType)
+		query.Set("size", strconv.FormatInt(size, 10))
+	}
+	query.Set("signature", s.signature(method, key, contentType, size, expiresAt))
+
+	return s.baseURL + LocalPathPrefix + key + "?" + query.Encode(), nil
+}
+
+func (s *LocalStore) signature(method, key, contentType string, size, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d\n%d", method, key, contentType, size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path returns the file path of the key, making sure it stays within the store's directory
+func (s *LocalStore) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if key == "" || !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid attachment key '%s'", key)
+	}
+	return path, nil
+}
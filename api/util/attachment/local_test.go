@@ -0,0 +1,96 @@
+package attachment_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util/attachment"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LocalStore(t *testing.T) {
+	dir := t.TempDir()
+
+	var s *attachment.LocalStore
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	s, err := attachment.NewLocalStore(dir, server.URL)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	photo := []byte("not really a png")
+
+	put := func(url, contentType string, body []byte) int {
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	uploadURL, err := s.PresignUpload(ctx, "a/p1/1", "image/png", int64(len(photo)), time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(uploadURL, server.URL+"/attachments/a/p1/1?"))
+
+	// uploads must match the signed content type and size
+	assert.Equal(t, http.StatusForbidden, put(uploadURL, "image/jpeg", photo))
+	assert.Equal(t, http.StatusForbidden, put(uploadURL, "image/png", append(photo, '!')))
+	assert.Equal(t, http.StatusForbidden, put(strings.Replace(uploadURL, "p1", "p2", 1), "image/png", photo))
+	assert.Equal(t, http.StatusOK, put(uploadURL, "image/png", photo))
+
+	content, err := os.ReadFile(filepath.Join(dir, "a", "p1", "1"))
+	assert.Nil(t, err)
+	assert.Equal(t, photo, content)
+
+	downloadURL, err := s.PresignDownload(ctx, "a/p1/1", time.Minute)
+	assert.Nil(t, err)
+
+	resp, err := http.Get(downloadURL)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, photo, body)
+
+	// an upload URL can't be used to download
+	resp, err = http.Get(uploadURL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// expired URLs are rejected
+	expiredURL, err := s.PresignDownload(ctx, "a/p1/1", -time.Minute)
+	assert.Nil(t, err)
+	resp, err = http.Get(expiredURL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	assert.Nil(t, s.Delete(ctx, "a/p1/1"))
+	assert.Nil(t, s.Delete(ctx, "a/p1/1"))
+	_, err = os.Stat(filepath.Join(dir, "a", "p1", "1"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_LocalStore_invalidKey(t *testing.T) {
+	s, err := attachment.NewLocalStore(t.TempDir(), "http://localhost")
+	assert.Nil(t, err)
+
+	_, err = s.PresignDownload(context.Background(), "../../etc/passwd", time.Minute)
+	assert.ErrorContains(t, err, "invalid attachment key '../../etc/passwd'")
+
+	err = s.Delete(context.Background(), "")
+	assert.ErrorContains(t, err, "invalid attachment key ''")
+}
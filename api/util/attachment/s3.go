@@ -0,0 +1,145 @@
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// S3 doesn't hash presigned payloads, they're only known once the client uploads
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Store keeps attachments in an S3 bucket, or a bucket of an S3-compatible service
+type S3Store struct {
+	bucket    string
+	client    *http.Client
+	endpoint  string
+	sdkConfig aws.Config
+	signer    *v4.Signer
+}
+
+// NewS3Store returns a store for the given bucket, using the default aws config. Buckets are
+// addressed by path, so the endpoint of any S3-compatible service can be given. The regional
+// AWS endpoint is used if endpoint is empty.
+func NewS3Store(ctx context.Context, bucket, endpoint string) (*S3Store, error) {
+	sdkConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", sdkConfig.Region)
+	}
+
+	return NewS3StoreWithEndpoint(sdkConfig, endpoint, bucket), nil
+}
+
+// NewS3StoreWithEndpoint returns a store for the bucket at the given endpoint
+func NewS3StoreWithEndpoint(sdkConfig aws.Config, endpoint, bucket string) *S3Store {
+	return &S3Store{
+		bucket:    bucket,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		sdkConfig: sdkConfig,
+		signer:    v4.NewSigner(),
+	}
+}
+
+func (s *S3Store) PresignUpload(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, error) {
+	req, err := s.newRequest(ctx, http.MethodPut, key, expires)
+	if err != nil {
+		return "", err
+	}
+
+	// both are signed, so S3 rejects uploads of another type or size
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = size
+
+	return s.presign(ctx, req)
+}
+
+func (s *S3Store) PresignDownload(ctx context.Context, key string, expires time.Duration) (string, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, expires)
+	if err != nil {
+		return "", err
+	}
+
+	return s.presign(ctx, req)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create delete request: %w", err)
+	}
+
+	creds, err := s.sdkConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve aws credentials: %w", err)
+	}
+
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	err = s.signer.SignHTTP(ctx, creds, req, unsignedPayload, "s3", s.sdkConfig.Region, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to sign delete request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// S3 responds with no content whether or not the object existed
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to delete attachment: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, expires time.Duration) (*http.Request, error) {
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse attachment url: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (s *S3Store) presign(ctx context.Context, req *http.Request) (string, error) {
+	creds, err := s.sdkConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve aws credentials: %w", err)
+	}
+
+	signed, _, err := s.signer.PresignHTTP(ctx, creds, req, unsignedPayload, "s3", s.sdkConfig.Region, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to presign attachment url: %w", err)
+	}
+
+	return signed, nil
+}
+
+func (s *S3Store) objectURL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, key)
+}
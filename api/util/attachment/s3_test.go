@@ -0,0 +1,98 @@
+package attachment_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sebboness/yektaspoints/util/attachment"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSdkConfig = aws.Config{
+	Region: "us-west-2",
+	Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+	}),
+}
+
+func Test_S3Store_PresignUpload(t *testing.T) {
+	s := attachment.NewS3StoreWithEndpoint(testSdkConfig, "https://minio.local/", "photos")
+
+	signed, err := s.PresignUpload(context.Background(), "a/p1/1", "image/png", 1024, 15*time.Minute)
+	assert.Nil(t, err)
+
+	u, err := url.Parse(signed)
+	assert.Nil(t, err)
+	assert.Equal(t, "minio.local", u.Host)
+	assert.Equal(t, "/photos/a/p1/1", u.Path)
+
+	query := u.Query()
+	assert.Equal(t, "900", query.Get("X-Amz-Expires"))
+	assert.Contains(t, query.Get("X-Amz-Credential"), "AKID/")
+	assert.Contains(t, query.Get("X-Amz-Credential"), "/us-west-2/s3/aws4_request")
+	assert.Equal(t, "content-length;content-type;host", query.Get("X-Amz-SignedHeaders"))
+	assert.NotEmpty(t, query.Get("X-Amz-Signature"))
+}
+
+func Test_S3Store_PresignDownload(t *testing.T) {
+	s := attachment.NewS3StoreWithEndpoint(testSdkConfig, "https://minio.local", "photos")
+
+	signed, err := s.PresignDownload(context.Background(), "a/p1/1", time.Minute)
+	assert.Nil(t, err)
+
+	u, err := url.Parse(signed)
+	assert.Nil(t, err)
+	assert.Equal(t, "/photos/a/p1/1", u.Path)
+	assert.Equal(t, "60", u.Query().Get("X-Amz-Expires"))
+	assert.Equal(t, "host", u.Query().Get("X-Amz-SignedHeaders"))
+}
+
+func Test_S3Store_Delete(t *testing.T) {
+	type state struct {
+		status int
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{http.StatusNoContent}, want{""}},
+		{"happy path - not found", state{http.StatusNotFound}, want{""}},
+		{"fail - s3 error", state{http.StatusForbidden}, want{"failed to delete attachment: status 403: denied"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var req *http.Request
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req = r
+				w.WriteHeader(c.state.status)
+				w.Write([]byte("denied"))
+			}))
+			defer server.Close()
+
+			s := attachment.NewS3StoreWithEndpoint(testSdkConfig, server.URL, "photos")
+
+			err := s.Delete(context.Background(), "a/p1/1")
+			tests.AssertError(t, err, c.want.err)
+
+			assert.Equal(t, http.MethodDelete, req.Method)
+			assert.Equal(t, "/photos/a/p1/1", req.URL.Path)
+			assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		})
+	}
+}
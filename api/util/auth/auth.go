@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"time"
 	"unicode"
 
 	"github.com/sebboness/yektaspoints/util/log"
@@ -12,22 +13,46 @@ import (
 
 const (
 	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeMfaCode           = "mfa_code"
 	GrantTypePassword          = "password"
 	GrantTypeRefreshToken      = "refresh_token"
 )
 
+const (
+	MfaChallengeSms           = "SMS_MFA"
+	MfaChallengeSoftwareToken = "SOFTWARE_TOKEN_MFA"
+)
+
 var SupportedGrantTypes = map[string]bool{
+	GrantTypeMfaCode:      true,
 	GrantTypePassword:     true,
 	GrantTypeRefreshToken: true,
 }
 
+var SupportedMfaChallenges = map[string]bool{
+	MfaChallengeSms:           true,
+	MfaChallengeSoftwareToken: true,
+}
+
 type AuthController interface {
 	Authenticate(ctx context.Context, username, password string) (AuthResult, error)
 	AssignUserToRole(ctx context.Context, username, role string) error
+	AssociateSoftwareToken(ctx context.Context, accessToken string) (SoftwareTokenResult, error)
 	ConfirmRegistration(ctx context.Context, username, code string) error
+	DeleteUser(ctx context.Context, username string) error
+	DisableUser(ctx context.Context, username string) error
+	EnableUser(ctx context.Context, username string) error
+	ListUserIDsByAttribute(ctx context.Context, attribute, value string) ([]string, error)
+	ListUsers(ctx context.Context) ([]PoolUser, error)
 	RefreshToken(ctx context.Context, username, token string) (AuthResult, error)
 	Register(ctx context.Context, ur UserRegisterRequest) (UserRegisterResult, error)
+	RemoveUserFromRole(ctx context.Context, username, role string) error
+	ResendConfirmationCode(ctx context.Context, username string) (CodeDeliveryResult, error)
+	RespondToMfaChallenge(ctx context.Context, req MfaChallengeRequest) (AuthResult, error)
 	UpdatePassword(ctx context.Context, session, username, password string) error
+	UpdateUserAttributes(ctx context.Context, username string, attributes map[string]string) error
+	VerifySoftwareToken(ctx context.Context, accessToken, code, deviceName string) error
+	VerifyUserAttribute(ctx context.Context, accessToken, attribute, code string) error
 }
 
 type AuthResult struct {
@@ -37,9 +62,43 @@ type AuthResult struct {
 	RefreshToken        string `json:"refresh_token"`
 	ExpiresIn           int32  `json:"expires_in"`
 	NewPasswordRequired bool   `json:"new_password_required"`
+	MfaRequired         bool   `json:"mfa_required"`
+	ChallengeName       string `json:"challenge_name,omitempty"`
 	Session             string `json:"session"`
 }
 
+// CodeDeliveryResult describes where a confirmation code was sent to
+type CodeDeliveryResult struct {
+	ConfirmationType   string `json:"confirmation_type"`
+	ConfirmationSentTo string `json:"confirmation_sent_to"`
+}
+
+// MfaChallengeRequest holds the values needed to answer an MFA challenge
+// that was returned from a previous call to Authenticate
+type MfaChallengeRequest struct {
+	ChallengeName string
+	Code          string
+	Session       string
+	Username      string
+}
+
+// PoolUser is a user as stored in the user pool
+type PoolUser struct {
+	UserID    string
+	Username  string
+	Email     string
+	Name      string
+	Confirmed bool
+	Enabled   bool
+	CreatedOn time.Time
+}
+
+// SoftwareTokenResult holds the secret used to set up a TOTP authenticator app
+type SoftwareTokenResult struct {
+	SecretCode string `json:"secret_code"`
+	Session    string `json:"session"`
+}
+
 type UserRegisterRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -8,10 +8,21 @@ import (
 
 type AuthClient interface {
 	AdminAddUserToGroup(ctx context.Context, params *cognito.AdminAddUserToGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminAddUserToGroupOutput, error)
+	AdminDeleteUser(ctx context.Context, params *cognito.AdminDeleteUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminDeleteUserOutput, error)
+	AdminDisableUser(ctx context.Context, params *cognito.AdminDisableUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminDisableUserOutput, error)
+	AdminEnableUser(ctx context.Context, params *cognito.AdminEnableUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminEnableUserOutput, error)
+	AdminRemoveUserFromGroup(ctx context.Context, params *cognito.AdminRemoveUserFromGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminRemoveUserFromGroupOutput, error)
+	AdminUpdateUserAttributes(ctx context.Context, params *cognito.AdminUpdateUserAttributesInput, optFns ...func(*cognito.Options)) (*cognito.AdminUpdateUserAttributesOutput, error)
+	AssociateSoftwareToken(ctx context.Context, params *cognito.AssociateSoftwareTokenInput, optFns ...func(*cognito.Options)) (*cognito.AssociateSoftwareTokenOutput, error)
 	ConfirmSignUp(ctx context.Context, params *cognito.ConfirmSignUpInput, optFns ...func(*cognito.Options)) (*cognito.ConfirmSignUpOutput, error)
 	GetUser(ctx context.Context, params *cognito.GetUserInput, optFns ...func(*cognito.Options)) (*cognito.GetUserOutput, error)
 	InitiateAuth(ctx context.Context, params *cognito.InitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.InitiateAuthOutput, error)
+	ListUsers(ctx context.Context, params *cognito.ListUsersInput, optFns ...func(*cognito.Options)) (*cognito.ListUsersOutput, error)
+	ResendConfirmationCode(ctx context.Context, params *cognito.ResendConfirmationCodeInput, optFns ...func(*cognito.Options)) (*cognito.ResendConfirmationCodeOutput, error)
 	RespondToAuthChallenge(ctx context.Context, params *cognito.RespondToAuthChallengeInput, optFns ...func(*cognito.Options)) (*cognito.RespondToAuthChallengeOutput, error)
+	SetUserMFAPreference(ctx context.Context, params *cognito.SetUserMFAPreferenceInput, optFns ...func(*cognito.Options)) (*cognito.SetUserMFAPreferenceOutput, error)
 	SignUp(ctx context.Context, params *cognito.SignUpInput, optFns ...func(*cognito.Options)) (*cognito.SignUpOutput, error)
 	UpdateUserAttributes(ctx context.Context, params *cognito.UpdateUserAttributesInput, optFns ...func(*cognito.Options)) (*cognito.UpdateUserAttributesOutput, error)
+	VerifySoftwareToken(ctx context.Context, params *cognito.VerifySoftwareTokenInput, optFns ...func(*cognito.Options)) (*cognito.VerifySoftwareTokenOutput, error)
+	VerifyUserAttribute(ctx context.Context, params *cognito.VerifyUserAttributeInput, optFns ...func(*cognito.Options)) (*cognito.VerifyUserAttributeOutput, error)
 }
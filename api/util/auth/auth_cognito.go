@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -73,24 +74,46 @@ func (c *CognitoController) Authenticate(ctx context.Context, username, password
 		return result, nil
 	}
 
+	// Users with MFA enabled have to respond to the challenge with a code
+	// before we receive any tokens (see RespondToMfaChallenge)
+	if resp.ChallengeName == types.ChallengeNameTypeSoftwareTokenMfa ||
+		resp.ChallengeName == types.ChallengeNameTypeSmsMfa {
+		result.MfaRequired = true
+		result.ChallengeName = string(resp.ChallengeName)
+		result.Session = aws.ToString(resp.Session)
+		result.Username = username
+		return result, nil
+	}
+
+	return c.toAuthResult(ctx, resp.AuthenticationResult)
+}
+
+// toAuthResult maps the tokens of a successful authentication to an AuthResult.
+func (c *CognitoController) toAuthResult(ctx context.Context, authResult *types.AuthenticationResultType) (AuthResult, error) {
+	result := AuthResult{}
+
+	if authResult == nil {
+		return result, apierr.New(apierr.Unauthorized).WithError("missing authentication result")
+	}
+
 	accessToken := ""
 	idToken := ""
 	refreshToken := ""
 
-	if resp.AuthenticationResult.AccessToken != nil {
-		accessToken = *resp.AuthenticationResult.AccessToken
+	if authResult.AccessToken != nil {
+		accessToken = *authResult.AccessToken
 	}
-	if resp.AuthenticationResult.IdToken != nil {
-		idToken = *resp.AuthenticationResult.IdToken
+	if authResult.IdToken != nil {
+		idToken = *authResult.IdToken
 	}
-	if resp.AuthenticationResult.RefreshToken != nil {
-		refreshToken = *resp.AuthenticationResult.RefreshToken
+	if authResult.RefreshToken != nil {
+		refreshToken = *authResult.RefreshToken
 	}
 
 	result.AccessToken = accessToken
 	result.IdToken = idToken
 	result.RefreshToken = refreshToken
-	result.ExpiresIn = resp.AuthenticationResult.ExpiresIn
+	result.ExpiresIn = authResult.ExpiresIn
 
 	// We need to grab the user record after authentication in order to store the "username" (aka the "sub") value
 	// which we need for token refreshes later
@@ -128,6 +151,30 @@ func (c *CognitoController) AssignUserToRole(ctx context.Context, username, role
 	return nil
 }
 
+// AssociateSoftwareToken starts the setup of a TOTP authenticator app for the user of the given access token.
+// The returned secret code is entered (or scanned) into the authenticator app and then confirmed with VerifySoftwareToken.
+func (c *CognitoController) AssociateSoftwareToken(ctx context.Context, accessToken string) (SoftwareTokenResult, error) {
+	result := SoftwareTokenResult{}
+
+	resp, err := c.authClient.AssociateSoftwareToken(ctx, &cognito.AssociateSoftwareTokenInput{
+		AccessToken: aws.String(accessToken),
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"error": err.Error(),
+		}).Infof("failed to associate software token")
+
+		apiErr := apierr.GetAwsError(err)
+		return result, apiErr
+	}
+
+	result.SecretCode = aws.ToString(resp.SecretCode)
+	result.Session = aws.ToString(resp.Session)
+
+	return result, nil
+}
+
 func (c *CognitoController) ConfirmRegistration(ctx context.Context, username, code string) error {
 
 	resp, err := c.authClient.ConfirmSignUp(ctx, &cognito.ConfirmSignUpInput{
@@ -151,6 +198,153 @@ func (c *CognitoController) ConfirmRegistration(ctx context.Context, username, c
 	return nil
 }
 
+// DeleteUser permanently deletes the user from the user pool
+func (c *CognitoController) DeleteUser(ctx context.Context, username string) error {
+
+	resp, err := c.authClient.AdminDeleteUser(ctx, &cognito.AdminDeleteUserInput{
+		Username:   aws.String(username),
+		UserPoolId: aws.String(c.userPoolID),
+	})
+
+	if err != nil {
+		logger.WithFields(map[string]any{
+			"error":    err.Error(),
+			"resp":     resp,
+			"username": username,
+		}).Infof("failed to delete user")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// DisableUser disables the user in the user pool, which prevents them from signing in
+func (c *CognitoController) DisableUser(ctx context.Context, username string) error {
+
+	resp, err := c.authClient.AdminDisableUser(ctx, &cognito.AdminDisableUserInput{
+		Username:   aws.String(username),
+		UserPoolId: aws.String(c.userPoolID),
+	})
+
+	if err != nil {
+		logger.WithFields(map[string]any{
+			"error":    err.Error(),
+			"resp":     resp,
+			"username": username,
+		}).Infof("failed to disable user")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// EnableUser re-enables a previously disabled user in the user pool
+func (c *CognitoController) EnableUser(ctx context.Context, username string) error {
+
+	resp, err := c.authClient.AdminEnableUser(ctx, &cognito.AdminEnableUserInput{
+		Username:   aws.String(username),
+		UserPoolId: aws.String(c.userPoolID),
+	})
+
+	if err != nil {
+		logger.WithFields(map[string]any{
+			"error":    err.Error(),
+			"resp":     resp,
+			"username": username,
+		}).Infof("failed to enable user")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// ListUserIDsByAttribute returns the user IDs (the "sub" attribute) of all users
+// whose attribute (i.e. "username" or "email") exactly matches the given value
+func (c *CognitoController) ListUserIDsByAttribute(ctx context.Context, attribute, value string) ([]string, error) {
+	userIds := []string{}
+
+	filter := fmt.Sprintf("%s = \"%s\"", attribute, strings.ReplaceAll(value, `"`, `\"`))
+
+	paginator := cognito.NewListUsersPaginator(c.authClient, &cognito.ListUsersInput{
+		UserPoolId: aws.String(c.userPoolID),
+		Filter:     aws.String(filter),
+	})
+
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			logger.WithFields(map[string]any{
+				"error":  err.Error(),
+				"filter": filter,
+			}).Infof("failed to list users")
+
+			apiErr := apierr.GetAwsError(err)
+			return userIds, apiErr
+		}
+
+		for _, u := range resp.Users {
+			for _, attr := range u.Attributes {
+				if aws.ToString(attr.Name) == "sub" {
+					userIds = append(userIds, aws.ToString(attr.Value))
+				}
+			}
+		}
+	}
+
+	return userIds, nil
+}
+
+// ListUsers returns all users in the user pool
+func (c *CognitoController) ListUsers(ctx context.Context) ([]PoolUser, error) {
+	users := []PoolUser{}
+
+	paginator := cognito.NewListUsersPaginator(c.authClient, &cognito.ListUsersInput{
+		UserPoolId: aws.String(c.userPoolID),
+	})
+
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Infof("failed to list users")
+
+			apiErr := apierr.GetAwsError(err)
+			return users, apiErr
+		}
+
+		for _, u := range resp.Users {
+			user := PoolUser{
+				Username:  aws.ToString(u.Username),
+				Confirmed: u.UserStatus == types.UserStatusTypeConfirmed,
+				Enabled:   u.Enabled,
+				CreatedOn: aws.ToTime(u.UserCreateDate),
+			}
+
+			for _, attr := range u.Attributes {
+				switch aws.ToString(attr.Name) {
+				case "email":
+					user.Email = aws.ToString(attr.Value)
+				case "name":
+					user.Name = aws.ToString(attr.Value)
+				case "sub":
+					user.UserID = aws.ToString(attr.Value)
+				}
+			}
+
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
 func (c *CognitoController) RefreshToken(ctx context.Context, username, refreshToken string) (AuthResult, error) {
 
 	resp, err := c.authClient.InitiateAuth(ctx, &cognito.InitiateAuthInput{
@@ -235,6 +429,91 @@ func (c *CognitoController) Register(ctx context.Context, req UserRegisterReques
 	return result, nil
 }
 
+func (c *CognitoController) RemoveUserFromRole(ctx context.Context, username, role string) error {
+
+	resp, err := c.authClient.AdminRemoveUserFromGroup(ctx, &cognito.AdminRemoveUserFromGroupInput{
+		GroupName:  aws.String(role),
+		Username:   aws.String(username),
+		UserPoolId: aws.String(c.userPoolID),
+	})
+
+	if err != nil {
+		logger.WithFields(map[string]any{
+			"error":    err.Error(),
+			"resp":     resp,
+			"role":     role,
+			"username": username,
+		}).Infof("failed to remove user from role")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// ResendConfirmationCode sends a new registration confirmation code to a user that has not confirmed their account yet
+func (c *CognitoController) ResendConfirmationCode(ctx context.Context, username string) (CodeDeliveryResult, error) {
+	result := CodeDeliveryResult{}
+
+	resp, err := c.authClient.ResendConfirmationCode(ctx, &cognito.ResendConfirmationCodeInput{
+		ClientId:   aws.String(c.cognitoClientID),
+		SecretHash: aws.String(c.computeSecretHash(username)),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"error":    err.Error(),
+			"resp":     resp,
+			"username": username,
+		}).Infof("failed to resend confirmation code")
+
+		apiErr := apierr.GetAwsError(err)
+		return result, apiErr
+	}
+
+	if resp.CodeDeliveryDetails != nil {
+		result.ConfirmationType = string(resp.CodeDeliveryDetails.DeliveryMedium)
+		result.ConfirmationSentTo = aws.ToString(resp.CodeDeliveryDetails.Destination)
+	}
+
+	return result, nil
+}
+
+// RespondToMfaChallenge answers an SMS_MFA or SOFTWARE_TOKEN_MFA challenge with the code provided by the user
+func (c *CognitoController) RespondToMfaChallenge(ctx context.Context, req MfaChallengeRequest) (AuthResult, error) {
+	codeKey := "SOFTWARE_TOKEN_MFA_CODE"
+	if req.ChallengeName == MfaChallengeSms {
+		codeKey = "SMS_MFA_CODE"
+	}
+
+	resp, err := c.authClient.RespondToAuthChallenge(ctx, &cognito.RespondToAuthChallengeInput{
+		Session:       aws.String(req.Session),
+		ChallengeName: types.ChallengeNameType(req.ChallengeName),
+		ClientId:      aws.String(c.cognitoClientID),
+		ChallengeResponses: map[string]string{
+			"USERNAME":    req.Username,
+			codeKey:       req.Code,
+			"SECRET_HASH": c.computeSecretHash(req.Username),
+		},
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"challenge": req.ChallengeName,
+			"error":     err.Error(),
+			"resp":      resp,
+			"username":  req.Username,
+		}).Infof("failed to respond to mfa challenge")
+
+		apiErr := apierr.GetAwsError(err)
+		return AuthResult{}, apiErr
+	}
+
+	return c.toAuthResult(ctx, resp.AuthenticationResult)
+}
+
 func (c *CognitoController) UpdatePassword(ctx context.Context, session, username, password string) error {
 	// accessToken := ""
 	// attribPwName := ""
@@ -268,6 +547,102 @@ func (c *CognitoController) UpdatePassword(ctx context.Context, session, usernam
 	return nil
 }
 
+// UpdateUserAttributes updates the given attributes (i.e. "name" or "email") of the user
+func (c *CognitoController) UpdateUserAttributes(ctx context.Context, username string, attributes map[string]string) error {
+
+	userAttributes := []types.AttributeType{}
+	for name, value := range attributes {
+		userAttributes = append(userAttributes, types.AttributeType{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+
+	resp, err := c.authClient.AdminUpdateUserAttributes(ctx, &cognito.AdminUpdateUserAttributesInput{
+		UserAttributes: userAttributes,
+		Username:       aws.String(username),
+		UserPoolId:     aws.String(c.userPoolID),
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"error":    err.Error(),
+			"resp":     resp,
+			"username": username,
+		}).Infof("failed to update user attributes")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// VerifySoftwareToken verifies the first code of a newly associated TOTP authenticator app
+// and, if valid, enables it as the user's preferred MFA method
+func (c *CognitoController) VerifySoftwareToken(ctx context.Context, accessToken, code, deviceName string) error {
+	resp, err := c.authClient.VerifySoftwareToken(ctx, &cognito.VerifySoftwareTokenInput{
+		AccessToken:        aws.String(accessToken),
+		UserCode:           aws.String(code),
+		FriendlyDeviceName: aws.String(deviceName),
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"error": err.Error(),
+		}).Infof("failed to verify software token")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	if resp.Status != types.VerifySoftwareTokenResponseTypeSuccess {
+		return apierr.New(apierr.InvalidInput).WithError("invalid software token code")
+	}
+
+	_, err = c.authClient.SetUserMFAPreference(ctx, &cognito.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(accessToken),
+		SoftwareTokenMfaSettings: &types.SoftwareTokenMfaSettingsType{
+			Enabled:      true,
+			PreferredMfa: true,
+		},
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"error": err.Error(),
+		}).Infof("failed to set user mfa preference")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
+// VerifyUserAttribute verifies an attribute (i.e. "email") of the currently logged-in user
+// with the code that was sent to them after the attribute changed
+func (c *CognitoController) VerifyUserAttribute(ctx context.Context, accessToken, attribute, code string) error {
+	resp, err := c.authClient.VerifyUserAttribute(ctx, &cognito.VerifyUserAttributeInput{
+		AccessToken:   aws.String(accessToken),
+		AttributeName: aws.String(attribute),
+		Code:          aws.String(code),
+	})
+
+	if err != nil {
+		logger.WithContext(ctx).WithFields(map[string]any{
+			"attribute": attribute,
+			"error":     err.Error(),
+			"resp":      resp,
+		}).Infof("failed to verify user attribute")
+
+		apiErr := apierr.GetAwsError(err)
+		return apiErr
+	}
+
+	return nil
+}
+
 func (c *CognitoController) computeSecretHash(username string) string {
 	return computeSecretHash(username, c.cognitoClientID, c.cognitoClientSecret)
 }
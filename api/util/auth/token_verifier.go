@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util/env"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenVerifier verifies cognito ID tokens and returns their claims.
+// In lambda this is done by the API gateway authorizer, so it's only needed when running the API as a standalone server.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (map[string]any, error)
+}
+
+// CognitoTokenVerifier verifies ID tokens against the public keys (JWKS) of the cognito user pool
+type CognitoTokenVerifier struct {
+	clientID   string
+	issuer     string
+	httpClient *http.Client
+	keysURL    string
+	keys       map[string]*rsa.PublicKey
+	mu         sync.RWMutex
+	now        func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func NewTokenVerifier() *CognitoTokenVerifier {
+	return NewTokenVerifierWithClient(env.GetEnv("COGNITO_CLIENT_ID"), env.GetEnv("COGNITO_USER_POOL_ID"))
+}
+
+func NewTokenVerifierWithClient(cognitoClientID, userPoolID string) *CognitoTokenVerifier {
+	// user pool IDs are prefixed with their region (i.e. "us-west-2_abc123")
+	region, _, _ := strings.Cut(userPoolID, "_")
+	issuer := fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", region, userPoolID)
+
+	return &CognitoTokenVerifier{
+		clientID:   cognitoClientID,
+		issuer:     issuer,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keysURL:    issuer + "/.well-known/jwks.json",
+		keys:       map[string]*rsa.PublicKey{},
+		now:        time.Now,
+	}
+}
+
+// Verify checks the token's signature, expiry, issuer and audience and returns its claims
+func (v *CognitoTokenVerifier) Verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode header: %w", ErrInvalidToken, err)
+	}
+
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidToken, header.Alg)
+	}
+
+	key, err := v.getKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode signature: %w", ErrInvalidToken, err)
+	}
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode claims: %w", ErrInvalidToken, err)
+	}
+
+	exp, _ := claims["exp"].(float64)
+	if v.now().Unix() >= int64(exp) {
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+
+	if claims["iss"] != v.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+
+	if claims["token_use"] != "id" {
+		return nil, fmt.Errorf("%w: not an id token", ErrInvalidToken)
+	}
+
+	if claims["aud"] != v.clientID {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+// getKey returns the public key with the given ID. Keys are (re)loaded when an unknown key ID shows up,
+// since cognito may rotate its keys.
+func (v *CognitoTokenVerifier) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	v.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if err := v.loadKeys(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load token keys: %w", err)
+	}
+
+	v.mu.RLock()
+	key, ok = v.keys[kid]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrInvalidToken, kid)
+	}
+
+	return key, nil
+}
+
+func (v *CognitoTokenVerifier) loadKeys(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.keysURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("failed to decode keys: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return fmt.Errorf("failed to decode modulus of key %s: %w", k.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return fmt.Errorf("failed to decode exponent of key %s: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
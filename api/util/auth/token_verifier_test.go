@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CognitoTokenVerifier_Verify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	keysRequested := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keysRequested++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []jwk{
+				{
+					Kid: "key1",
+					Kty: "RSA",
+					N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"sub":       "123",
+			"aud":       "client",
+			"iss":       "https://cognito-idp.us-west-2.amazonaws.com/us-west-2_pool",
+			"token_use": "id",
+			"exp":       now.Add(time.Hour).Unix(),
+		}
+	}
+
+	type state struct {
+		alg    string
+		kid    string
+		key    *rsa.PrivateKey
+		claims map[string]any
+		token  string
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	expired := validClaims()
+	expired["exp"] = now.Add(-time.Minute).Unix()
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://example.com"
+
+	accessToken := validClaims()
+	accessToken["token_use"] = "access"
+
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "other"
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - malformed", state{token: "abc"}, want{"invalid token: malformed token"}},
+		{"fail - algorithm", state{alg: "HS256"}, want{"invalid token: unsupported algorithm HS256"}},
+		{"fail - unknown key", state{kid: "key2"}, want{"invalid token: unknown key key2"}},
+		{"fail - signature", state{key: otherKey}, want{"invalid token: invalid signature"}},
+		{"fail - expired", state{claims: expired}, want{"invalid token: token expired"}},
+		{"fail - issuer", state{claims: wrongIssuer}, want{"invalid token: unexpected issuer"}},
+		{"fail - token use", state{claims: accessToken}, want{"invalid token: not an id token"}},
+		{"fail - audience", state{claims: wrongAudience}, want{"invalid token: unexpected audience"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := NewTokenVerifierWithClient("client", "us-west-2_pool")
+			v.keysURL = srv.URL
+			v.now = func() time.Time { return now }
+
+			token := c.state.token
+			if token == "" {
+				alg := c.state.alg
+				if alg == "" {
+					alg = "RS256"
+				}
+				kid := c.state.kid
+				if kid == "" {
+					kid = "key1"
+				}
+				signingKey := c.state.key
+				if signingKey == nil {
+					signingKey = key
+				}
+				claims := c.state.claims
+				if claims == nil {
+					claims = validClaims()
+				}
+
+				token = signToken(t, signingKey, jwtHeader{Alg: alg, Kid: kid}, claims)
+			}
+
+			claims, err := v.Verify(context.Background(), token)
+			tests.AssertError(t, err, c.want.err)
+
+			if err == nil {
+				assert.Equal(t, "123", claims["sub"])
+			}
+		})
+	}
+
+	// known keys are cached
+	keysRequested = 0
+	v := NewTokenVerifierWithClient("client", "us-west-2_pool")
+	v.keysURL = srv.URL
+
+	for i := 0; i < 2; i++ {
+		_, err := v.Verify(context.Background(), signToken(t, key, jwtHeader{Alg: "RS256", Kid: "key1"}, validClaims()))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, keysRequested)
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, header jwtHeader, claims map[string]any) string {
+	headerJson, _ := json.Marshal(header)
+	claimsJson, _ := json.Marshal(claims)
+
+	unsigned := base64.RawURLEncoding.EncodeToString(headerJson) + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	assert.NoError(t, err)
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
@@ -15,6 +15,8 @@ var (
 	NotFound            = errors.New("resource not found")
 	InternalServerError = errors.New("internal server error")
 	AccessDenied        = errors.New("access denied")
+	TooManyRequests     = errors.New("too many requests")
+	Conflict            = errors.New("conflict")
 )
 
 type ApiError struct {
@@ -129,6 +131,10 @@ func (e *ApiError) determineStatusCode() int {
 		return http.StatusNotFound
 	} else if e.Is(AccessDenied) {
 		return http.StatusForbidden
+	} else if e.Is(TooManyRequests) {
+		return http.StatusTooManyRequests
+	} else if e.Is(Conflict) {
+		return http.StatusConflict
 	} else if e.Is(InternalServerError) {
 		return http.StatusInternalServerError
 	} else if e.Err != nil || len(e.errors) > 0 {
@@ -118,6 +118,8 @@ func Test_Error_determineStatusCode(t *testing.T) {
 		{"invalid input", state{err: InvalidInput}, want{http.StatusBadRequest}},
 		{"unauthorized", state{err: Unauthorized}, want{http.StatusUnauthorized}},
 		{"not found", state{err: NotFound}, want{http.StatusNotFound}},
+		{"too many requests", state{err: TooManyRequests}, want{http.StatusTooManyRequests}},
+		{"conflict", state{err: Conflict}, want{http.StatusConflict}},
 		{"internal server error", state{err: InternalServerError}, want{http.StatusInternalServerError}},
 		{"non-nil error", state{err: errors.New("fail")}, want{http.StatusBadRequest}},
 		{"non-empty errors", state{errors: []string{"fail!"}}, want{http.StatusBadRequest}},
@@ -0,0 +1,143 @@
+package eventbus
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+type EventType string
+
+const EventTypeAchievementEarned EventType = "achievement_earned"
+const EventTypeBalanceChanged EventType = "balance_changed"
+const EventTypePointCommented EventType = "point_commented"
+const EventTypePointsDecided EventType = "points_decided"
+const EventTypePointsRequested EventType = "points_requested"
+
+// EventTypes are all types of events that are published
+var EventTypes = []EventType{EventTypeAchievementEarned, EventTypeBalanceChanged, EventTypePointCommented, EventTypePointsDecided, EventTypePointsRequested}
+
+// How many events are kept around for clients that reconnect or long-poll
+const defaultHistorySize = 500
+
+// Event is something that happened to a user that other members of the user's families may want to know about
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	UserID    string    `json:"user_id"`
+	Data      any       `json:"data"`
+	CreatedOn time.Time `json:"created_on"`
+
+	// Number of the event within the family it was read from, if it was read from a Store
+	Seq int64 `json:"seq,omitempty"`
+}
+
+// Prepare fills in the event's ID and creation time, unless they're already set
+func (e *Event) Prepare() {
+	if e.ID == "" {
+		e.ID = ksuid.New().String()
+	}
+	if e.CreatedOn.IsZero() {
+		e.CreatedOn = time.Now().UTC()
+	}
+}
+
+// Cursor returns what is passed to Since to get the events after this one
+func (e Event) Cursor() string {
+	if e.Seq > 0 {
+		return strconv.FormatInt(e.Seq, 10)
+	}
+	return e.ID
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, evt Event)
+}
+
+type Subscriber interface {
+	// Since returns the events of the family's members (userIDs) after the event with the given cursor.
+	// If the cursor is empty or no longer known, all remembered events of the family are returned.
+	Since(ctx context.Context, familyID string, userIDs []string, cursor string) ([]Event, error)
+}
+
+// Broker keeps published events for subscribers
+type Broker interface {
+	Publisher
+	Subscriber
+}
+
+// Bus is an in-memory event bus. Events are only seen within the same process, so it's only
+// meant for a single server. Use a Store when the API runs in more than one process (i.e. lambda).
+type Bus struct {
+	mu          sync.Mutex
+	history     []Event
+	historySize int
+}
+
+var bus = New(defaultHistorySize)
+
+// Get returns the default event bus
+func Get() *Bus {
+	return bus
+}
+
+// New returns a new event bus that keeps the last historySize events
+func New(historySize int) *Bus {
+	return &Bus{
+		history:     []Event{},
+		historySize: historySize,
+	}
+}
+
+// Publish remembers the event, so that subscribers get it with Since
+func (b *Bus) Publish(ctx context.Context, evt Event) {
+	evt.Prepare()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.history = append(b.history, evt)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+}
+
+// Since returns the events of the given users that were published after the event with ID lastEventID.
+// If lastEventID is empty or no longer known, all remembered events of the users are returned.
+// Events aren't kept by family, so the family ID isn't needed.
+func (b *Bus) Since(ctx context.Context, familyID string, userIDs []string, lastEventID string) ([]Event, error) {
+	ids := toSet(userIDs)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start := 0
+	if lastEventID != "" {
+		for idx, evt := range b.history {
+			if evt.ID == lastEventID {
+				start = idx + 1
+				break
+			}
+		}
+	}
+
+	events := []Event{}
+	for _, evt := range b.history[start:] {
+		if ids[evt.UserID] {
+			events = append(events, evt)
+		}
+	}
+
+	return events, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Bus_Publish(t *testing.T) {
+	ctx := context.Background()
+	b := New(10)
+
+	b.Publish(ctx, Event{Type: EventTypePointsRequested, UserID: "1"})
+	b.Publish(ctx, Event{Type: EventTypePointsRequested, UserID: "3"})
+	b.Publish(ctx, Event{Type: EventTypeBalanceChanged, UserID: "2"})
+
+	events, err := b.Since(ctx, "456", []string{"1", "2"}, "")
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+
+	assert.Equal(t, "1", events[0].UserID)
+	assert.NotEmpty(t, events[0].ID)
+	assert.False(t, events[0].CreatedOn.IsZero())
+	assert.Equal(t, events[0].ID, events[0].Cursor())
+
+	assert.Equal(t, "2", events[1].UserID)
+	assert.Equal(t, EventTypeBalanceChanged, events[1].Type)
+}
+
+func Test_Bus_Since(t *testing.T) {
+	ctx := context.Background()
+	b := New(3)
+
+	b.Publish(ctx, Event{ID: "a", UserID: "1"})
+	b.Publish(ctx, Event{ID: "b", UserID: "1"})
+	b.Publish(ctx, Event{ID: "c", UserID: "2"})
+	b.Publish(ctx, Event{ID: "d", UserID: "1"})
+
+	type test struct {
+		name        string
+		userIDs     []string
+		lastEventID string
+		want        []string
+	}
+
+	cases := []test{
+		{"all events", []string{"1", "2"}, "", []string{"b", "c", "d"}},
+		{"events of user", []string{"1"}, "", []string{"b", "d"}},
+		{"events after id", []string{"1", "2"}, "c", []string{"d"}},
+		{"events after latest id", []string{"1", "2"}, "d", []string{}},
+		{"unknown id", []string{"1"}, "a", []string{"b", "d"}},
+		{"no matching users", []string{"3"}, "", []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			events, err := b.Since(ctx, "456", c.userIDs, c.lastEventID)
+			assert.Nil(t, err)
+
+			ids := []string{}
+			for _, evt := range events {
+				ids = append(ids, evt.ID)
+			}
+
+			assert.Equal(t, c.want, ids)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// How long events are kept in the store
+var eventTTL = 24 * time.Hour
+
+// Store keeps events in the event storage, so that they're seen by every process of the API.
+// Events are saved once for every family of the event's user, and numbered within the family.
+type Store struct {
+	eventDB storage.IEventStorage
+	userDB  storage.IUserStorage
+}
+
+// NewStore returns a store that keeps events in the given event storage
+func NewStore(eventDB storage.IEventStorage, userDB storage.IUserStorage) *Store {
+	return &Store{
+		eventDB: eventDB,
+		userDB:  userDB,
+	}
+}
+
+// Publish saves the event for all families of the event's user. Failures are logged, since
+// events are only a notice of changes that were already saved.
+func (s *Store) Publish(ctx context.Context, evt Event) {
+	evt.Prepare()
+
+	logger := log.Get().WithContext(ctx).WithFields(map[string]any{
+		"event_id":   evt.ID,
+		"event_type": evt.Type,
+		"user_id":    evt.UserID,
+	})
+
+	data, err := json.Marshal(evt.Data)
+	if err != nil {
+		logger.WithField("error", err.Error()).Errorf("failed to marshal event data")
+		return
+	}
+
+	user, err := s.userDB.GetUserByID(ctx, evt.UserID)
+	if err != nil {
+		logger.WithField("error", err.Error()).Errorf("failed to get user of event")
+		return
+	}
+
+	for _, familyID := range user.FamilyIDs {
+		_, err := s.eventDB.AppendFamilyEvent(ctx, models.FamilyEvent{
+			FamilyID:     familyID,
+			ID:           evt.ID,
+			Type:         string(evt.Type),
+			UserID:       evt.UserID,
+			Data:         string(data),
+			CreatedOnStr: util.ToFormattedUTC(evt.CreatedOn),
+			ExpiresOn:    evt.CreatedOn.Add(eventTTL).Unix(),
+		})
+
+		if err != nil {
+			logger.WithFields(map[string]any{
+				"error":     err.Error(),
+				"family_id": familyID,
+			}).Errorf("failed to save family event")
+		}
+	}
+}
+
+// Since returns the family's events of the given users after the event with the given seq. Events
+// are saved for the families their user was part of at the time, but only the given users' events
+// are returned, so callers decide whose events a subscriber may see.
+func (s *Store) Since(ctx context.Context, familyID string, userIDs []string, cursor string) ([]Event, error) {
+	ids := toSet(userIDs)
+
+	// cursors that aren't seqs (i.e. event IDs of the in-memory bus) start over, like unknown IDs do on the bus
+	afterSeq, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil {
+		afterSeq = 0
+	}
+
+	familyEvents, err := s.eventDB.GetFamilyEvents(ctx, familyID, afterSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(familyEvents) > defaultHistorySize {
+		familyEvents = familyEvents[len(familyEvents)-defaultHistorySize:]
+	}
+
+	events := []Event{}
+	for _, fe := range familyEvents {
+		if !ids[fe.UserID] {
+			continue
+		}
+
+		events = append(events, Event{
+			ID:        fe.ID,
+			Type:      EventType(fe.Type),
+			UserID:    fe.UserID,
+			Data:      json.RawMessage(fe.Data),
+			CreatedOn: fe.CreatedOn,
+			Seq:       fe.Seq,
+		})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+func Test_Store_Publish(t *testing.T) {
+	type state struct {
+		errUser   error
+		errAppend error
+	}
+	type test struct {
+		name string
+		state
+	}
+
+	cases := []test{
+		{"happy path", state{}},
+		{"fail - get user", state{errUser: errFail}},
+		{"fail - append", state{errAppend: errFail}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			eventDB := mocks.NewMockIEventStorage(t)
+			userDB := mocks.NewMockIUserStorage(t)
+
+			createdOn := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+			userDB.EXPECT().GetUserByID(mock.Anything, "1").Return(models.User{UserID: "1", FamilyIDs: []string{"456", "789"}}, c.state.errUser).Once()
+
+			if c.state.errUser == nil {
+				for _, familyID := range []string{"456", "789"} {
+					eventDB.EXPECT().AppendFamilyEvent(mock.Anything, models.FamilyEvent{
+						FamilyID:     familyID,
+						ID:           "a",
+						Type:         string(EventTypeBalanceChanged),
+						UserID:       "1",
+						Data:         `{"balance":5}`,
+						CreatedOnStr: "2024-03-01T12:00:00Z",
+						ExpiresOn:    createdOn.Add(eventTTL).Unix(),
+					}).Return(models.FamilyEvent{}, c.state.errAppend).Once()
+				}
+			}
+
+			s := NewStore(eventDB, userDB)
+			s.Publish(context.Background(), Event{
+				ID:        "a",
+				Type:      EventTypeBalanceChanged,
+				UserID:    "1",
+				Data:      map[string]int{"balance": 5},
+				CreatedOn: createdOn,
+			})
+
+			eventDB.AssertExpectations(t)
+			userDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Store_Since(t *testing.T) {
+	type state struct {
+		errEvents error
+	}
+	type want struct {
+		err  string
+		seqs []int64
+	}
+	type test struct {
+		name    string
+		cursor  string
+		after   int64
+		userIDs []string
+		state
+		want
+	}
+
+	both := []string{"1", "2"}
+
+	cases := []test{
+		{"happy path - all events", "", 0, both, state{}, want{"", []int64{1, 2}}},
+		{"happy path - events after seq", "1", 1, both, state{}, want{"", []int64{2}}},
+		{"happy path - unknown cursor", "2Vp8ksuid", 0, both, state{}, want{"", []int64{1, 2}}},
+		{"happy path - only events of the users", "", 0, []string{"2"}, state{}, want{"", []int64{2}}},
+		{"fail - get events", "", 0, both, state{errEvents: errFail}, want{"fail", nil}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			eventDB := mocks.NewMockIEventStorage(t)
+
+			familyEvents := []models.FamilyEvent{
+				{FamilyID: "456", Seq: 1, ID: "a", Type: string(EventTypePointsRequested), UserID: "1", Data: `{"id":"p1"}`},
+				{FamilyID: "456", Seq: 2, ID: "b", Type: string(EventTypeBalanceChanged), UserID: "2", Data: `{"balance":5}`},
+			}
+			familyEvents = familyEvents[c.after:]
+
+			eventDB.EXPECT().GetFamilyEvents(mock.Anything, "456", c.after).Return(familyEvents, c.state.errEvents).Once()
+
+			s := NewStore(eventDB, nil)
+			events, err := s.Since(context.Background(), "456", c.userIDs, c.cursor)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.err == "" {
+				seqs := []int64{}
+				for _, evt := range events {
+					seqs = append(seqs, evt.Seq)
+					assert.Equal(t, familyEvents[evt.Seq-1-c.after].Data, string(evt.Data.(json.RawMessage)))
+				}
+				assert.Equal(t, c.want.seqs, seqs)
+				assert.Equal(t, "2", events[len(events)-1].Cursor())
+			}
+
+			eventDB.AssertExpectations(t)
+		})
+	}
+}
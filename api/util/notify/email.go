@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// SESNotifier sends emails through the SES v2 SendEmail API
+type SESNotifier struct {
+	client    *http.Client
+	endpoint  string
+	from      string
+	sdkConfig aws.Config
+	signer    *v4.Signer
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesSendEmailInput struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text sesContent  `json:"Text"`
+				Html *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+// NewSESNotifier returns an email notifier sending from the given address, using the
+// default aws config
+func NewSESNotifier(ctx context.Context, from string) (*SESNotifier, error) {
+	sdkConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://email.%s.amazonaws.com", sdkConfig.Region)
+	return NewSESNotifierWithEndpoint(sdkConfig, endpoint, from), nil
+}
+
+// NewSESNotifierWithEndpoint returns an email notifier that sends to the given SES endpoint
+func NewSESNotifierWithEndpoint(sdkConfig aws.Config, endpoint, from string) *SESNotifier {
+	return &SESNotifier{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		endpoint:  endpoint,
+		from:      from,
+		sdkConfig: sdkConfig,
+		signer:    v4.NewSigner(),
+	}
+}
+
+func (n *SESNotifier) Notify(ctx context.Context, msg Message) error {
+	if !msg.Recipient.Notifications.EmailEnabled() || msg.Recipient.Email == "" {
+		return nil
+	}
+
+	input := sesSendEmailInput{FromEmailAddress: n.from}
+	input.Destination.ToAddresses = []string{msg.Recipient.Email}
+	input.Content.Simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	input.Content.Simple.Body.Text = sesContent{Data: msg.Text, Charset: "UTF-8"}
+	if msg.HTML != "" {
+		input.Content.Simple.Body.Html = &sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create email request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds, err := n.sdkConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve aws credentials: %w", err)
+	}
+
+	hash := sha256.Sum256(body)
+	err = n.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "ses", n.sdkConfig.Region, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to sign email request: %w", err)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send email: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSdkConfig = aws.Config{
+	Region: "us-west-2",
+	Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+	}),
+}
+
+func Test_SESNotifier_Notify(t *testing.T) {
+	disabled := false
+
+	type state struct {
+		recipient models.User
+		status    int
+	}
+	type want struct {
+		sent bool
+		err  string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{models.User{Email: "mom@example.com"}, http.StatusOK}, want{true, ""}},
+		{"happy path - email turned off", state{models.User{Email: "mom@example.com", Notifications: models.NotificationPreferences{Email: &disabled}}, http.StatusOK}, want{false, ""}},
+		{"happy path - no email address", state{models.User{}, http.StatusOK}, want{false, ""}},
+		{"fail - ses error", state{models.User{Email: "mom@example.com"}, http.StatusBadRequest}, want{true, "failed to send email: status 400: bad"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var req *http.Request
+			var body map[string]any
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req = r
+				b, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(b, &body)
+
+				w.WriteHeader(c.state.status)
+				if c.state.status != http.StatusOK {
+					w.Write([]byte("bad"))
+				}
+			}))
+			defer server.Close()
+
+			n := notify.NewSESNotifierWithEndpoint(testSdkConfig, server.URL, "noreply@example.com")
+			err := n.Notify(context.Background(), notify.Message{
+				Recipient: c.state.recipient,
+				Subject:   "subject",
+				Text:      "text",
+				HTML:      "<p>html</p>",
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if !c.want.sent {
+				assert.Nil(t, req)
+				return
+			}
+
+			assert.Equal(t, "/v2/email/outbound-emails", req.URL.Path)
+			assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+			assert.Contains(t, req.Header.Get("Authorization"), "/us-west-2/ses/aws4_request")
+			assert.Equal(t, "noreply@example.com", body["FromEmailAddress"])
+			assert.Equal(t, []any{"mom@example.com"}, body["Destination"].(map[string]any)["ToAddresses"])
+		})
+	}
+}
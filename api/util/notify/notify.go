@@ -0,0 +1,109 @@
+// Package notify sends notifications to users by email and push, depending on the
+// user's notification preferences
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// Message is a rendered notification for a single recipient
+type Message struct {
+	Recipient models.User
+	Subject   string
+	Text      string
+	HTML      string
+
+	// Extra data sent along with push notifications (i.e. the point ID)
+	Data map[string]string
+}
+
+// Notifier sends messages through one channel. Notifiers skip recipients that
+// turned the channel off.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// NewNotifierFromEnv returns a notifier for all channels that are configured in the environment:
+// email if NOTIFY_EMAIL_FROM is set and push if PUSH_PROVIDER_URL is set. Without either,
+// messages are only logged.
+func NewNotifierFromEnv(ctx context.Context) (Notifier, error) {
+	notifiers := []Notifier{}
+
+	if from := env.GetEnv("NOTIFY_EMAIL_FROM"); from != "" {
+		n, err := NewSESNotifier(ctx, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize email notifier: %w", err)
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	if url := env.GetEnv("PUSH_PROVIDER_URL"); url != "" {
+		notifiers = append(notifiers, NewPushNotifier(url, env.GetEnv("PUSH_PROVIDER_API_KEY")))
+	}
+
+	if len(notifiers) == 0 {
+		return &LogNotifier{}, nil
+	}
+
+	return Multi(notifiers...), nil
+}
+
+type multiNotifier []Notifier
+
+// Multi returns a notifier that sends messages through all given notifiers
+func Multi(notifiers ...Notifier) Notifier {
+	return multiNotifier(notifiers)
+}
+
+func (m multiNotifier) Notify(ctx context.Context, msg Message) error {
+	errs := []error{}
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LogNotifier only logs messages. It's used when no channel is configured (i.e. locally).
+type LogNotifier struct{}
+
+func (n *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Get().WithContext(ctx).WithFields(map[string]any{
+		"subject": msg.Subject,
+		"text":    msg.Text,
+		"user_id": msg.Recipient.UserID,
+	}).Infof("notification")
+
+	return nil
+}
+
+// MemoryNotifier keeps all messages in memory, so tests can check what was sent
+type MemoryNotifier struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (n *MemoryNotifier) Notify(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// Messages returns all messages sent so far
+func (n *MemoryNotifier) Messages() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]Message{}, n.messages...)
+}
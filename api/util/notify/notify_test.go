@@ -0,0 +1,33 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFail = errors.New("fail")
+
+type failingNotifier struct{}
+
+func (n *failingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	return errFail
+}
+
+func Test_Multi(t *testing.T) {
+	first := &notify.MemoryNotifier{}
+	second := &notify.MemoryNotifier{}
+
+	msg := notify.Message{Recipient: models.User{UserID: "1"}, Subject: "hi"}
+
+	// all notifiers get the message, even if one fails
+	err := notify.Multi(first, &failingNotifier{}, second).Notify(context.Background(), msg)
+	assert.ErrorIs(t, err, errFail)
+
+	assert.Equal(t, []notify.Message{msg}, first.Messages())
+	assert.Equal(t, []notify.Message{msg}, second.Messages())
+}
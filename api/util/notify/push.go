@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// PushNotifier sends push notifications through a push provider's HTTP API. The provider
+// receives the device token, title, body and data as JSON.
+type PushNotifier struct {
+	apiKey string
+	client *http.Client
+	url    string
+}
+
+type pushRequest struct {
+	To    string            `json:"to"`
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// NewPushNotifier returns a push notifier for the provider at the given URL. The API key is
+// sent as bearer token if set.
+func NewPushNotifier(url, apiKey string) *PushNotifier {
+	return &PushNotifier{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    url,
+	}
+}
+
+func (n *PushNotifier) Notify(ctx context.Context, msg Message) error {
+	if !msg.Recipient.Notifications.PushEnabled() {
+		return nil
+	}
+
+	body, err := json.Marshal(pushRequest{
+		To:    msg.Recipient.Notifications.PushToken,
+		Title: msg.Subject,
+		Body:  msg.Text,
+		Data:  msg.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal push notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+n.apiKey)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send push notification: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PushNotifier_Notify(t *testing.T) {
+	disabled := false
+
+	type state struct {
+		prefs  models.NotificationPreferences
+		status int
+	}
+	type want struct {
+		sent bool
+		err  string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{models.NotificationPreferences{PushToken: "token"}, http.StatusOK}, want{true, ""}},
+		{"happy path - push turned off", state{models.NotificationPreferences{PushToken: "token", Push: &disabled}, http.StatusOK}, want{false, ""}},
+		{"happy path - no device", state{models.NotificationPreferences{}, http.StatusOK}, want{false, ""}},
+		{"fail - provider error", state{models.NotificationPreferences{PushToken: "token"}, http.StatusInternalServerError}, want{true, "failed to send push notification: status 500"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var req *http.Request
+			var body map[string]any
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req = r
+				b, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(b, &body)
+
+				w.WriteHeader(c.state.status)
+			}))
+			defer server.Close()
+
+			n := notify.NewPushNotifier(server.URL, "key")
+			err := n.Notify(context.Background(), notify.Message{
+				Recipient: models.User{Notifications: c.state.prefs},
+				Subject:   "subject",
+				Text:      "text",
+				Data:      map[string]string{"point_id": "1"},
+			})
+			tests.AssertError(t, err, c.want.err)
+
+			if !c.want.sent {
+				assert.Nil(t, req)
+				return
+			}
+
+			assert.Equal(t, "Bearer key", req.Header.Get("Authorization"))
+			assert.Equal(t, "token", body["to"])
+			assert.Equal(t, "subject", body["title"])
+			assert.Equal(t, "text", body["body"])
+			assert.Equal(t, map[string]any{"point_id": "1"}, body["data"])
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
+)
+
+// Service notifies parents when a child requests points, and the child when a parent decides
+type Service struct {
+	familyDB storage.IFamilyStorage
+	notifier Notifier
+	userDB   storage.IUserStorage
+}
+
+func NewService(userDB storage.IUserStorage, familyDB storage.IFamilyStorage, notifier Notifier) *Service {
+	return &Service{
+		familyDB: familyDB,
+		notifier: notifier,
+		userDB:   userDB,
+	}
+}
+
+// HandleEvent sends the notifications for the event. Events that nobody is notified about are ignored.
+func (s *Service) HandleEvent(ctx context.Context, evt eventbus.Event) {
+	var err error
+
+	switch evt.Type {
+	case eventbus.EventTypePointsRequested:
+		err = s.notifyPointsRequested(ctx, evt)
+	case eventbus.EventTypePointsDecided:
+		err = s.notifyPointsDecided(ctx, evt)
+	default:
+		return
+	}
+
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":      err.Error(),
+			"event_id":   evt.ID,
+			"event_type": evt.Type,
+			"user_id":    evt.UserID,
+		}).Errorf("failed to send notifications")
+	}
+}
+
+// notifyPointsRequested notifies all parents in the child's families about the request
+func (s *Service) notifyPointsRequested(ctx context.Context, evt eventbus.Event) error {
+	summary, err := pointSummaryOf(evt)
+	if err != nil {
+		return err
+	}
+
+	child, err := s.userDB.GetUserByID(ctx, evt.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+
+	errs := []error{}
+	notified := map[string]bool{}
+
+	for _, familyID := range child.FamilyIDs {
+		familyUsers, err := s.familyDB.GetFamilyUsers(ctx, familyID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get family users: %w", err))
+			continue
+		}
+
+		for _, fu := range familyUsers {
+			if fu.UserID == child.UserID || notified[fu.UserID] {
+				continue
+			}
+
+			parent, err := s.userDB.GetUserByID(ctx, fu.UserID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get parent: %w", err))
+				continue
+			}
+
+			if !parent.IsParent() {
+				continue
+			}
+			notified[parent.UserID] = true
+
+			err = s.send(ctx, familyID, parent, TemplatePointsRequested, summary, TemplateData{
+				ChildName:     child.Name,
+				ChildCallName: callName(parent),
+				Points:        summary.Points,
+				Reason:        summary.Reason,
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifyPointsDecided notifies the child about the parent's decision
+func (s *Service) notifyPointsDecided(ctx context.Context, evt eventbus.Event) error {
+	summary, err := pointSummaryOf(evt)
+	if err != nil {
+		return err
+	}
+
+	child, err := s.userDB.GetUserByID(ctx, evt.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+
+	parent, err := s.userDB.GetUserByID(ctx, summary.DecidedByUserID)
+	if err != nil {
+		return fmt.Errorf("failed to get parent: %w", err)
+	}
+
+	// the family the parent decided in, if the child is in several
+	familyID := ""
+	if len(child.FamilyIDs) > 0 {
+		familyID = child.FamilyIDs[0]
+	}
+	for _, fid := range child.FamilyIDs {
+		if slices.Contains(parent.FamilyIDs, fid) {
+			familyID = fid
+			break
+		}
+	}
+
+	return s.send(ctx, familyID, child, TemplatePointsDecided, summary, TemplateData{
+		ChildName:     child.Name,
+		ChildCallName: callName(parent),
+		Approved:      summary.Decision == models.PointRequestDecisionApprove,
+		ParentNotes:   summary.ParentNotes,
+		Points:        summary.Points,
+		Reason:        summary.Reason,
+	})
+}
+
+func (s *Service) send(ctx context.Context, familyID string, recipient models.User, name Template, summary models.PointSummary, data TemplateData) error {
+	msg, err := Render(name, s.language(ctx, familyID, recipient), data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s notification: %w", name, err)
+	}
+
+	msg.Recipient = recipient
+	msg.Data = map[string]string{
+		"point_id": summary.ID,
+		"type":     string(name),
+	}
+
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		return fmt.Errorf("failed to notify user %s: %w", recipient.UserID, err)
+	}
+
+	return nil
+}
+
+// language returns the language of the family's notifications, or the recipient's own language if
+// the family didn't choose one
+func (s *Service) language(ctx context.Context, familyID string, recipient models.User) string {
+	if familyID == "" {
+		return recipient.Notifications.Language
+	}
+
+	settings, err := s.familyDB.GetFamilySettings(ctx, familyID)
+	if err != nil {
+		log.Get().WithContext(ctx).WithFields(map[string]any{
+			"error":     err.Error(),
+			"family_id": familyID,
+		}).Warnf("failed to get family settings, using the recipient's language")
+		return recipient.Notifications.Language
+	}
+
+	if settings.Language != "" {
+		return settings.Language
+	}
+	return recipient.Notifications.Language
+}
+
+// callName returns the name the child calls the parent, or the parent's name if it isn't set
+func callName(parent models.User) string {
+	if parent.ChildCallName != "" {
+		return parent.ChildCallName
+	}
+	return parent.Name
+}
+
+func pointSummaryOf(evt eventbus.Event) (models.PointSummary, error) {
+	switch data := evt.Data.(type) {
+	case models.PointSummary:
+		return data, nil
+	case *models.PointSummary:
+		return *data, nil
+	}
+
+	return models.PointSummary{}, fmt.Errorf("unexpected data of %s event: %T", evt.Type, evt.Data)
+}
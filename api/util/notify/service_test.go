@@ -0,0 +1,165 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	child  = models.User{UserID: "1", Name: "Yekta", FamilyIDs: []string{"456"}, Roles: []string{models.RoleChild}}
+	mom    = models.User{UserID: "2", Name: "Jane", ChildCallName: "Mom", Email: "mom@example.com", Roles: []string{models.RoleParent}}
+	dad    = models.User{UserID: "3", Name: "John", Email: "dad@example.com", Roles: []string{models.RoleParent}, Notifications: models.NotificationPreferences{Language: "es"}}
+	sister = models.User{UserID: "4", Name: "Sara", Roles: []string{models.RoleChild}}
+)
+
+func Test_Service_HandleEvent_PointsRequested(t *testing.T) {
+	type state struct {
+		errGetChild    error
+		errGetFamily   error
+		errGetParent   error
+		errGetSettings error
+		language       string
+	}
+	type want struct {
+		subjects []string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{[]string{"Yekta requested 10 points", "Yekta pidió 10 puntos"}}},
+		{"happy path - family language", state{language: "es"}, want{[]string{"Yekta pidió 10 puntos", "Yekta pidió 10 puntos"}}},
+		{"happy path - get settings fails, member's language", state{language: "es", errGetSettings: errFail}, want{[]string{"Yekta requested 10 points", "Yekta pidió 10 puntos"}}},
+		{"fail - get child", state{errGetChild: errFail}, want{[]string{}}},
+		{"fail - get family users", state{errGetFamily: errFail}, want{[]string{}}},
+		{"fail - get parent", state{errGetParent: errFail}, want{[]string{"Yekta pidió 10 puntos"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockFamilyDB := mocks.NewMockIFamilyStorage(t)
+			notifier := &notify.MemoryNotifier{}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(child, c.state.errGetChild).Once()
+			if c.state.errGetChild == nil {
+				mockFamilyDB.EXPECT().GetFamilyUsers(mock.Anything, "456").Return([]models.FamilyUser{
+					{FamilyID: "456", UserID: "1"},
+					{FamilyID: "456", UserID: "2"},
+					{FamilyID: "456", UserID: "3"},
+					{FamilyID: "456", UserID: "4"},
+				}, c.state.errGetFamily).Once()
+			}
+			if c.state.errGetChild == nil && c.state.errGetFamily == nil {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "2").Return(mom, c.state.errGetParent).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "3").Return(dad, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "4").Return(sister, nil).Once()
+
+				// once for each parent that gets notified
+				mockFamilyDB.EXPECT().GetFamilySettings(mock.Anything, "456").
+					Return(models.FamilySettings{FamilyID: "456", Language: c.state.language}, c.state.errGetSettings).
+					Times(len(c.want.subjects))
+			}
+
+			s := notify.NewService(mockUserDB, mockFamilyDB, notifier)
+			s.HandleEvent(context.Background(), eventbus.Event{
+				Type:   eventbus.EventTypePointsRequested,
+				UserID: "1",
+				Data:   models.PointSummary{ID: "p1", UserID: "1", Points: 10, Reason: "cleaned my room"},
+			})
+
+			subjects := []string{}
+			for _, msg := range notifier.Messages() {
+				subjects = append(subjects, msg.Subject)
+				assert.Equal(t, "p1", msg.Data["point_id"])
+				assert.True(t, msg.Recipient.IsParent())
+			}
+			assert.Equal(t, c.want.subjects, subjects)
+
+			mockUserDB.AssertExpectations(t)
+			mockFamilyDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Service_HandleEvent_PointsDecided(t *testing.T) {
+	type state struct {
+		data         any
+		errGetParent error
+		language     string
+	}
+	type want struct {
+		subjects []string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	approved := models.PointSummary{ID: "p1", UserID: "1", Points: 10, Reason: "cleaned my room",
+		DecidedByUserID: "2", Decision: models.PointRequestDecisionApprove}
+	denied := models.PointSummary{ID: "p1", UserID: "1", Points: 10, Reason: "cleaned my room",
+		DecidedByUserID: "3", Decision: models.PointRequestDecisionDeny}
+
+	cases := []test{
+		{"happy path - approved", state{data: approved}, want{[]string{"Mom approved your request"}}},
+		{"happy path - denied by parent without call name", state{data: &denied}, want{[]string{"John denied your request"}}},
+		{"happy path - family language", state{data: approved, language: "es"}, want{[]string{"Mom aprobó tu solicitud"}}},
+		{"fail - get parent", state{data: approved, errGetParent: errFail}, want{[]string{}}},
+		{"fail - unexpected data", state{data: "blah"}, want{[]string{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockFamilyDB := mocks.NewMockIFamilyStorage(t)
+			notifier := &notify.MemoryNotifier{}
+
+			if _, ok := c.state.data.(string); !ok {
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, "1").Return(child, nil).Once()
+				mockUserDB.EXPECT().GetUserByID(mock.Anything, mock.MatchedBy(func(id string) bool {
+					return id == "2" || id == "3"
+				})).RunAndReturn(func(ctx context.Context, id string) (models.User, error) {
+					if id == "2" {
+						return mom, c.state.errGetParent
+					}
+					return dad, c.state.errGetParent
+				}).Once()
+			}
+			if len(c.want.subjects) > 0 {
+				mockFamilyDB.EXPECT().GetFamilySettings(mock.Anything, "456").
+					Return(models.FamilySettings{FamilyID: "456", Language: c.state.language}, nil).Once()
+			}
+
+			s := notify.NewService(mockUserDB, mockFamilyDB, notifier)
+			s.HandleEvent(context.Background(), eventbus.Event{
+				Type:   eventbus.EventTypePointsDecided,
+				UserID: "1",
+				Data:   c.state.data,
+			})
+
+			subjects := []string{}
+			for _, msg := range notifier.Messages() {
+				subjects = append(subjects, msg.Subject)
+				assert.Equal(t, "1", msg.Recipient.UserID)
+			}
+			assert.Equal(t, c.want.subjects, subjects)
+
+			mockUserDB.AssertExpectations(t)
+			mockFamilyDB.AssertExpectations(t)
+		})
+	}
+}
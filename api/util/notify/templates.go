@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"text/template"
+)
+
+type Template string
+
+const TemplatePointsDecided Template = "points_decided"
+const TemplatePointsRequested Template = "points_requested"
+
+// DefaultLanguage is used for users without a language, or with a language we have no templates for
+const DefaultLanguage = "en"
+
+// Languages are the languages notifications can be written in
+var Languages = []string{"en", "es"}
+
+// TemplateData is the data templates are rendered with
+type TemplateData struct {
+	// Name of the child the point request is from
+	ChildName string
+
+	// Name the child calls the parent (i.e. "Mom")
+	ChildCallName string
+
+	Approved    bool
+	ParentNotes string
+	Points      int
+	Reason      string
+}
+
+type templateSet struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+type templateSource struct {
+	subject string
+	text    string
+	html    string
+}
+
+var templateSources = map[string]map[Template]templateSource{
+	"en": {
+		TemplatePointsRequested: {
+			subject: `{{.ChildName}} requested {{.Points}} points`,
+			text:    "Hi {{.ChildCallName}},\n\n{{.ChildName}} requested {{.Points}} points for: {{.Reason}}\n\nOpen the app to approve or deny the request.",
+			html:    `<p>Hi {{.ChildCallName}},</p><p>{{.ChildName}} requested <b>{{.Points}} points</b> for: {{.Reason}}</p><p>Open the app to approve or deny the request.</p>`,
+		},
+		TemplatePointsDecided: {
+			subject: `{{.ChildCallName}} {{if .Approved}}approved{{else}}denied{{end}} your request`,
+			text:    "{{.ChildCallName}} {{if .Approved}}approved{{else}}denied{{end}} your request for {{.Points}} points ({{.Reason}}).{{if .ParentNotes}}\n\n{{.ChildCallName}} says: {{.ParentNotes}}{{end}}",
+			html:    `<p>{{.ChildCallName}} {{if .Approved}}approved{{else}}denied{{end}} your request for <b>{{.Points}} points</b> ({{.Reason}}).</p>{{if .ParentNotes}}<p>{{.ChildCallName}} says: {{.ParentNotes}}</p>{{end}}`,
+		},
+	},
+	"es": {
+		TemplatePointsRequested: {
+			subject: `{{.ChildName}} pidió {{.Points}} puntos`,
+			text:    "Hola {{.ChildCallName}},\n\n{{.ChildName}} pidió {{.Points}} puntos por: {{.Reason}}\n\nAbre la app para aprobar o rechazar la solicitud.",
+			html:    `<p>Hola {{.ChildCallName}},</p><p>{{.ChildName}} pidió <b>{{.Points}} puntos</b> por: {{.Reason}}</p><p>Abre la app para aprobar o rechazar la solicitud.</p>`,
+		},
+		TemplatePointsDecided: {
+			subject: `{{.ChildCallName}} {{if .Approved}}aprobó{{else}}rechazó{{end}} tu solicitud`,
+			text:    "{{.ChildCallName}} {{if .Approved}}aprobó{{else}}rechazó{{end}} tu solicitud de {{.Points}} puntos ({{.Reason}}).{{if .ParentNotes}}\n\n{{.ChildCallName}} dice: {{.ParentNotes}}{{end}}",
+			html:    `<p>{{.ChildCallName}} {{if .Approved}}aprobó{{else}}rechazó{{end}} tu solicitud de <b>{{.Points}} puntos</b> ({{.Reason}}).</p>{{if .ParentNotes}}<p>{{.ChildCallName}} dice: {{.ParentNotes}}</p>{{end}}`,
+		},
+	},
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[string]map[Template]templateSet {
+	sets := map[string]map[Template]templateSet{}
+	for lang, sources := range templateSources {
+		sets[lang] = map[Template]templateSet{}
+		for name, src := range sources {
+			sets[lang][name] = templateSet{
+				subject: template.Must(template.New("subject").Parse(src.subject)),
+				text:    template.Must(template.New("text").Parse(src.text)),
+				html:    htmltemplate.Must(htmltemplate.New("html").Parse(src.html)),
+			}
+		}
+	}
+	return sets
+}
+
+// IsSupportedLanguage returns true if there are templates for the language
+func IsSupportedLanguage(lang string) bool {
+	return slices.Contains(Languages, lang)
+}
+
+// Render renders the template in the given language into a message. Unsupported languages
+// fall back to the default language.
+func Render(name Template, lang string, data TemplateData) (Message, error) {
+	if !IsSupportedLanguage(lang) {
+		lang = DefaultLanguage
+	}
+
+	msg := Message{}
+	set, ok := templates[lang][name]
+	if !ok {
+		return msg, fmt.Errorf("unknown template '%s'", name)
+	}
+
+	var buf bytes.Buffer
+	if err := set.subject.Execute(&buf, data); err != nil {
+		return msg, err
+	}
+	msg.Subject = buf.String()
+
+	buf.Reset()
+	if err := set.text.Execute(&buf, data); err != nil {
+		return msg, err
+	}
+	msg.Text = buf.String()
+
+	buf.Reset()
+	if err := set.html.Execute(&buf, data); err != nil {
+		return msg, err
+	}
+	msg.HTML = buf.String()
+
+	return msg, nil
+}
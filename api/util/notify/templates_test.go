@@ -0,0 +1,74 @@
+package notify_test
+
+import (
+	"testing"
+
+	"github.com/sebboness/yektaspoints/util/notify"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Render(t *testing.T) {
+	type state struct {
+		template notify.Template
+		lang     string
+		data     notify.TemplateData
+	}
+	type want struct {
+		subject string
+		text    string
+		html    string
+		err     string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	requested := notify.TemplateData{ChildName: "Yekta", ChildCallName: "Mom", Points: 10, Reason: "cleaned my room"}
+	approved := notify.TemplateData{ChildName: "Yekta", ChildCallName: "Mom", Points: 10, Reason: "cleaned my room", Approved: true, ParentNotes: "great job"}
+	denied := notify.TemplateData{ChildName: "Yekta", ChildCallName: "Mom", Points: 10, Reason: "<b>cleaned</b>"}
+
+	cases := []test{
+		{"happy path - points requested", state{notify.TemplatePointsRequested, "en", requested}, want{
+			subject: "Yekta requested 10 points",
+			text:    "Hi Mom,\n\nYekta requested 10 points for: cleaned my room\n\nOpen the app to approve or deny the request.",
+		}},
+		{"happy path - points requested in spanish", state{notify.TemplatePointsRequested, "es", requested}, want{
+			subject: "Yekta pidió 10 puntos",
+		}},
+		{"happy path - unsupported language falls back to english", state{notify.TemplatePointsRequested, "xx", requested}, want{
+			subject: "Yekta requested 10 points",
+		}},
+		{"happy path - no language", state{notify.TemplatePointsRequested, "", requested}, want{
+			subject: "Yekta requested 10 points",
+		}},
+		{"happy path - approved", state{notify.TemplatePointsDecided, "en", approved}, want{
+			subject: "Mom approved your request",
+			text:    "Mom approved your request for 10 points (cleaned my room).\n\nMom says: great job",
+		}},
+		{"happy path - denied escapes html", state{notify.TemplatePointsDecided, "en", denied}, want{
+			subject: "Mom denied your request",
+			html:    "<p>Mom denied your request for <b>10 points</b> (&lt;b&gt;cleaned&lt;/b&gt;).</p>",
+		}},
+		{"fail - unknown template", state{"blah", "en", requested}, want{err: "unknown template 'blah'"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			msg, err := notify.Render(c.state.template, c.state.lang, c.state.data)
+			tests.AssertError(t, err, c.want.err)
+
+			if c.want.subject != "" {
+				assert.Equal(t, c.want.subject, msg.Subject)
+			}
+			if c.want.text != "" {
+				assert.Equal(t, c.want.text, msg.Text)
+			}
+			if c.want.html != "" {
+				assert.Equal(t, c.want.html, msg.HTML)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed is returned when a webhook points to an address that isn't public
+var ErrAddressNotAllowed = errors.New("url must not point to a local or private address")
+
+// shared address space of carrier-grade NAT, which net.IP doesn't consider private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP returns whether webhooks may be sent to the IP. Loopback, private, shared, link-local
+// (which includes the metadata service at 169.254.169.254), multicast and unspecified addresses
+// are not public.
+func IsPublicIP(ip net.IP) bool {
+	return ip != nil &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// ValidateURL returns an error unless the URL is an https URL of a public host. Host names are
+// resolved when events are sent, where the dispatcher checks the addresses they resolve to.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return errors.New("url must be a valid https url")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return ErrAddressNotAllowed
+		}
+		return nil
+	}
+
+	// names that only resolve within the host or its network (i.e. metadata.google.internal)
+	if host == "localhost" || !strings.Contains(host, ".") ||
+		strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") ||
+		strings.HasSuffix(host, ".internal") {
+		return ErrAddressNotAllowed
+	}
+
+	return nil
+}
+
+// newClient returns the http client of the dispatcher. Unless private addresses are allowed, it
+// refuses to connect to addresses that aren't public, after host names were resolved, so a name
+// can't be pointed to a private address once the webhook was created.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if !cfg.AllowPrivateAddresses {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return fmt.Errorf("failed to split address %s: %w", address, err)
+			}
+
+			if !IsPublicIP(net.ParseIP(host)) {
+				return ErrAddressNotAllowed
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	// a proxy would be checked instead of the webhook's address
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+	}
+}
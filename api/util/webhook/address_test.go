@@ -0,0 +1,62 @@
+package webhook_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/sebboness/yektaspoints/util/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsPublicIP(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.216.34":   true,
+		"2606:2800:220::": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"100.64.0.1":      false,
+		"169.254.169.254": false,
+		"fd00:ec2::254":   false,
+		"fe80::1":         false,
+		"0.0.0.0":         false,
+		"224.0.0.1":       false,
+	}
+
+	for ip, public := range cases {
+		assert.Equal(t, public, webhook.IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func Test_ValidateURL(t *testing.T) {
+	type test struct {
+		name string
+		url  string
+		err  string
+	}
+
+	notAllowed := "url must not point to a local or private address"
+
+	cases := []test{
+		{"happy path", "https://example.com/hook", ""},
+		{"happy path - public ip", "https://93.184.216.34/hook", ""},
+		{"fail - not a url", "example", "url must be a valid https url"},
+		{"fail - http", "http://example.com/hook", "url must be a valid https url"},
+		{"fail - localhost", "https://localhost/hook", notAllowed},
+		{"fail - loopback", "https://127.0.0.1:8443/hook", notAllowed},
+		{"fail - ipv6 loopback", "https://[::1]/hook", notAllowed},
+		{"fail - private", "https://10.0.0.5/hook", notAllowed},
+		{"fail - metadata", "https://169.254.169.254/latest/meta-data", notAllowed},
+		{"fail - internal name", "https://metadata.google.internal/", notAllowed},
+		{"fail - single label name", "https://intranet/hook", notAllowed},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tests.AssertError(t, webhook.ValidateURL(c.url), c.err)
+		})
+	}
+}
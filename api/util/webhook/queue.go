@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/sebboness/yektaspoints/util/env"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+)
+
+// Queue takes events that are delivered to webhooks (and notified about) later, so that requests
+// don't wait for webhooks or notifications
+type Queue interface {
+	Enqueue(ctx context.Context, evt eventbus.Event) error
+}
+
+type SQSClient interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// SQSQueue sends events to an SQS queue. The webhook worker lambda (cmd/webhook) receives them,
+// delivers them to webhooks and sends their notifications.
+type SQSQueue struct {
+	client   SQSClient
+	queueURL string
+}
+
+func NewSQSQueue(ctx context.Context, queueURL string) (*SQSQueue, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	return NewSQSQueueWithClient(sqs.NewFromConfig(cfg), queueURL), nil
+}
+
+func NewSQSQueueWithClient(client SQSClient, queueURL string) *SQSQueue {
+	return &SQSQueue{
+		client:   client,
+		queueURL: queueURL,
+	}
+}
+
+func (q *SQSQueue) Enqueue(ctx context.Context, evt eventbus.Event) error {
+	evt.Prepare()
+
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	_, err = q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
+		MessageBody: aws.String(string(body)),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send event to queue: %w", err)
+	}
+
+	return nil
+}
+
+// NewQueueFromEnv returns an SQS queue if WEBHOOK_QUEUE_URL is set. Without a queue, events are
+// processed in the background by the given dispatcher, which only works in a long-running server.
+func NewQueueFromEnv(ctx context.Context, dispatcher *Dispatcher) (Queue, error) {
+	if queueURL := env.GetEnv("WEBHOOK_QUEUE_URL"); queueURL != "" {
+		q, err := NewSQSQueue(ctx, queueURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize webhook queue: %w", err)
+		}
+		return q, nil
+	}
+
+	return dispatcher, nil
+}
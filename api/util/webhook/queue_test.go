@@ -0,0 +1,55 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	mocks "github.com/sebboness/yektaspoints/mocks/webhook"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/sebboness/yektaspoints/util/webhook"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_SQSQueue_Enqueue(t *testing.T) {
+	type state struct {
+		errSend error
+	}
+	type want struct {
+		err string
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{}},
+		{"fail - send", state{errSend: errFail}, want{"failed to send event to queue: fail"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockClient := mocks.NewMockSQSClient(t)
+			mockClient.EXPECT().SendMessage(mock.Anything, mock.MatchedBy(func(input *sqs.SendMessageInput) bool {
+				var evt eventbus.Event
+				_ = json.Unmarshal([]byte(aws.ToString(input.MessageBody)), &evt)
+
+				return aws.ToString(input.QueueUrl) == "https://sqs/webhooks" &&
+					evt.ID != "" &&
+					evt.UserID == "123" &&
+					evt.Type == eventbus.EventTypePointsRequested
+			})).Return(&sqs.SendMessageOutput{}, c.state.errSend).Once()
+
+			q := webhook.NewSQSQueueWithClient(mockClient, "https://sqs/webhooks")
+			err := q.Enqueue(context.Background(), eventbus.Event{Type: eventbus.EventTypePointsRequested, UserID: "123"})
+			tests.AssertError(t, err, c.want.err)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/storage"
+	"github.com/sebboness/yektaspoints/util"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/log"
+	"github.com/segmentio/ksuid"
+)
+
+const HeaderDelivery = "X-Webhook-Delivery"
+const HeaderEvent = "X-Webhook-Event"
+const HeaderID = "X-Webhook-ID"
+const HeaderSignature = "X-Webhook-Signature"
+const HeaderTimestamp = "X-Webhook-Timestamp"
+
+type Config struct {
+	// How often a delivery is attempted before it's marked as failed
+	MaxAttempts int
+
+	// How long to wait before the first retry. The wait is doubled after every failed attempt.
+	Backoff time.Duration
+
+	// How long to wait for a webhook to respond
+	Timeout time.Duration
+
+	// Whether events may be delivered to loopback and private addresses. Only meant for tests and
+	// local development, since webhooks are created by users.
+	AllowPrivateAddresses bool
+}
+
+var DefaultConfig = Config{
+	MaxAttempts: 5,
+	Backoff:     500 * time.Millisecond,
+	Timeout:     5 * time.Second,
+}
+
+// Payload is the JSON body that is sent to webhooks
+type Payload struct {
+	DeliveryID string         `json:"delivery_id"`
+	FamilyID   string         `json:"family_id"`
+	Event      eventbus.Event `json:"event"`
+}
+
+// EventHandler handles queued events besides their webhooks (i.e. sends notifications), so that
+// requests don't wait for it either
+type EventHandler interface {
+	HandleEvent(ctx context.Context, evt eventbus.Event)
+}
+
+// Dispatcher sends events to the webhooks of the families of the user the event is about
+type Dispatcher struct {
+	cfg       Config
+	client    *http.Client
+	handlers  []EventHandler
+	userDB    storage.IUserStorage
+	webhookDB storage.IWebhookStorage
+	wg        sync.WaitGroup
+}
+
+func NewDispatcher(userDB storage.IUserStorage, webhookDB storage.IWebhookStorage, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		cfg:       cfg,
+		client:    newClient(cfg),
+		userDB:    userDB,
+		webhookDB: webhookDB,
+	}
+}
+
+// UseEventHandler sets a handler that processed events are passed to once they were delivered to webhooks
+func (d *Dispatcher) UseEventHandler(h EventHandler) {
+	d.handlers = append(d.handlers, h)
+}
+
+// Sign returns the signature of a delivery: the base64 encoded HMAC_SHA256 of "<timestamp>.<body>"
+// using the webhook's secret as key
+func Sign(secret, timestamp string, body []byte) string {
+	hmac := hmac.New(sha256.New, []byte(secret))
+	hmac.Write([]byte(timestamp + "."))
+	hmac.Write(body)
+
+	return base64.StdEncoding.EncodeToString(hmac.Sum(nil))
+}
+
+// Verify returns true if the signature matches the delivery's timestamp and body
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Enqueue processes the event in the background of this process. It's only meant for a long-running
+// server, since lambda freezes the process once the response is sent. Use Wait to wait for the
+// deliveries to finish.
+func (d *Dispatcher) Enqueue(ctx context.Context, evt eventbus.Event) error {
+	evt.Prepare()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		// deliveries outlive the request that published the event
+		if err := d.Process(context.WithoutCancel(ctx), evt); err != nil {
+			log.Get().WithContext(ctx).AddFields(map[string]any{
+				"error":    err.Error(),
+				"event_id": evt.ID,
+			}).Errorf("failed to deliver event to webhooks")
+		}
+	}()
+
+	return nil
+}
+
+// Wait blocks until all enqueued events are delivered (or failed to be delivered)
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Process delivers the event to webhooks, and then passes it to the event handlers. Handlers only
+// get events whose webhooks could be looked up, so an event that is delivered again (i.e. a
+// message the queue retries) isn't handled twice.
+func (d *Dispatcher) Process(ctx context.Context, evt eventbus.Event) error {
+	if err := d.Deliver(ctx, evt); err != nil {
+		return err
+	}
+
+	for _, h := range d.handlers {
+		h.HandleEvent(ctx, evt)
+	}
+
+	return nil
+}
+
+// Deliver sends the event to all webhooks of the user's families that subscribed to it, and waits
+// for the deliveries to finish. Webhooks that keep failing are recorded as failed deliveries.
+// Only fails if the webhooks can't be looked up, before anything was sent, so the event can be
+// delivered again.
+func (d *Dispatcher) Deliver(ctx context.Context, evt eventbus.Event) error {
+	user, err := d.userDB.GetUserByID(ctx, evt.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user of event: %w", err)
+	}
+
+	familyWebhooks := map[string][]models.Webhook{}
+	for _, familyID := range user.FamilyIDs {
+		webhooks, err := d.webhookDB.GetWebhooksByFamilyID(ctx, familyID)
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks of family (id=%s): %w", familyID, err)
+		}
+
+		familyWebhooks[familyID] = webhooks
+	}
+
+	var wg sync.WaitGroup
+	for familyID, webhooks := range familyWebhooks {
+		for _, w := range webhooks {
+			if !w.HasEventType(string(evt.Type)) {
+				continue
+			}
+
+			wg.Add(1)
+			go func(w models.Webhook, familyID string) {
+				defer wg.Done()
+				d.deliver(ctx, familyID, w, evt)
+			}(w, familyID)
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// deliver sends the event to the webhook, retrying with exponential backoff until it succeeds
+// or runs out of attempts. The delivery is saved after every attempt. Webhooks of addresses that
+// aren't allowed, and deliveries whose context is done, fail without further attempts.
+func (d *Dispatcher) deliver(ctx context.Context, familyID string, w models.Webhook, evt eventbus.Event) {
+	now := util.ToFormattedUTC(time.Now())
+
+	delivery := models.WebhookDelivery{
+		WebhookID:    w.ID,
+		ID:           ksuid.New().String(),
+		EventID:      evt.ID,
+		EventType:    string(evt.Type),
+		Status:       models.WebhookDeliveryStatusPending,
+		CreatedOnStr: now,
+		UpdatedOnStr: now,
+	}
+
+	logger := log.Get().WithContext(ctx).AddFields(map[string]any{
+		"delivery_id": delivery.ID,
+		"event_id":    evt.ID,
+		"family_id":   familyID,
+		"webhook_id":  w.ID,
+	})
+
+	body, err := json.Marshal(Payload{
+		DeliveryID: delivery.ID,
+		FamilyID:   familyID,
+		Event:      evt,
+	})
+	if err != nil {
+		logger.WithField("error", err.Error()).Errorf("failed to marshal webhook payload")
+		return
+	}
+
+	wait := d.cfg.Backoff
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				// the delivery isn't left pending, even though the context can't be used to save it
+				delivery.Status = models.WebhookDeliveryStatusFailed
+				delivery.Error = ctx.Err().Error()
+				delivery.UpdatedOnStr = util.ToFormattedUTC(time.Now())
+				if err := d.webhookDB.SaveWebhookDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+					logger.WithField("error", err.Error()).Errorf("failed to save webhook delivery")
+				}
+
+				logger.WithField("error", delivery.Error).Warnf("stopped delivering event to webhook after %d attempts", delivery.Attempts)
+				return
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+
+		code, err := d.send(ctx, w, delivery.ID, evt, body)
+
+		delivery.Attempts = attempt
+		delivery.ResponseCode = code
+		delivery.UpdatedOnStr = util.ToFormattedUTC(time.Now())
+
+		if err == nil {
+			delivery.Status = models.WebhookDeliveryStatusSucceeded
+			delivery.Error = ""
+		} else {
+			delivery.Error = err.Error()
+			if attempt == d.cfg.MaxAttempts || errors.Is(err, ErrAddressNotAllowed) {
+				delivery.Status = models.WebhookDeliveryStatusFailed
+			}
+		}
+
+		if err := d.webhookDB.SaveWebhookDelivery(ctx, delivery); err != nil {
+			logger.WithField("error", err.Error()).Errorf("failed to save webhook delivery")
+		}
+
+		if delivery.Status != models.WebhookDeliveryStatusPending {
+			break
+		}
+	}
+
+	if delivery.Status == models.WebhookDeliveryStatusFailed {
+		logger.WithField("error", delivery.Error).Warnf("failed to deliver event to webhook after %d attempts", delivery.Attempts)
+	}
+}
+
+// send posts the signed payload to the webhook and returns the response's status code
+func (d *Dispatcher) send(ctx context.Context, w models.Webhook, deliveryID string, evt eventbus.Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderEvent, string(evt.Type))
+	req.Header.Set(HeaderID, w.ID)
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, body))
+	req.Header.Set(HeaderTimestamp, timestamp)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Publisher publishes events to the next publisher (i.e. the event bus) and queues them for delivery
+// to webhooks and the dispatcher's event handlers
+type Publisher struct {
+	next  eventbus.Publisher
+	queue Queue
+}
+
+func NewPublisher(next eventbus.Publisher, queue Queue) *Publisher {
+	return &Publisher{
+		next:  next,
+		queue: queue,
+	}
+}
+
+func (p *Publisher) Publish(ctx context.Context, evt eventbus.Event) {
+	// the event must have the same ID on the bus and in webhook deliveries
+	evt.Prepare()
+
+	p.next.Publish(ctx, evt)
+
+	if err := p.queue.Enqueue(ctx, evt); err != nil {
+		log.Get().WithContext(ctx).AddFields(map[string]any{
+			"error":      err.Error(),
+			"event_id":   evt.ID,
+			"event_type": evt.Type,
+		}).Errorf("failed to queue event for webhooks")
+	}
+}
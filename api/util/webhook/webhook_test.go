@@ -0,0 +1,309 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	evtmocks "github.com/sebboness/yektaspoints/mocks/eventbus"
+	mocks "github.com/sebboness/yektaspoints/mocks/storage"
+	webhookmocks "github.com/sebboness/yektaspoints/mocks/webhook"
+	"github.com/sebboness/yektaspoints/models"
+	"github.com/sebboness/yektaspoints/util/eventbus"
+	"github.com/sebboness/yektaspoints/util/tests"
+	"github.com/sebboness/yektaspoints/util/webhook"
+	"github.com/sebboness/yektaspoints/util/webhook/webhooktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errFail = errors.New("fail")
+
+var testConfig = webhook.Config{
+	MaxAttempts: 3,
+	Backoff:     time.Millisecond,
+	Timeout:     time.Second,
+
+	// the receiver listens on a loopback address
+	AllowPrivateAddresses: true,
+}
+
+func Test_Sign_Verify(t *testing.T) {
+	body := []byte(`{"event":{}}`)
+	signature := webhook.Sign("secret", "1700000000", body)
+
+	assert.True(t, webhook.Verify("secret", "1700000000", body, signature))
+	assert.False(t, webhook.Verify("other", "1700000000", body, signature))
+	assert.False(t, webhook.Verify("secret", "1700000001", body, signature))
+	assert.False(t, webhook.Verify("secret", "1700000000", []byte(`{}`), signature))
+}
+
+func Test_Dispatcher_Deliver(t *testing.T) {
+	type state struct {
+		failures   int
+		errGetUser error
+		errGetHook error
+		errSave    error
+	}
+	type want struct {
+		err      string
+		requests int
+		statuses []models.WebhookDeliveryStatus
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", 1, []models.WebhookDeliveryStatus{"SUCCEEDED"}}},
+		{"happy path - succeeds after retry", state{failures: 2}, want{"", 3, []models.WebhookDeliveryStatus{"PENDING", "PENDING", "SUCCEEDED"}}},
+		{"happy path - save delivery fails", state{errSave: errFail}, want{"", 1, []models.WebhookDeliveryStatus{"SUCCEEDED"}}},
+		{"happy path - out of attempts", state{failures: 5}, want{"", 3, []models.WebhookDeliveryStatus{"PENDING", "PENDING", "FAILED"}}},
+		{"fail - get user", state{errGetUser: errFail}, want{"failed to get user of event: fail", 0, []models.WebhookDeliveryStatus{}}},
+		{"fail - get webhooks", state{errGetHook: errFail}, want{"failed to get webhooks of family (id=456): fail", 0, []models.WebhookDeliveryStatus{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			receiver := webhooktest.NewReceiver("secret")
+			defer receiver.Close()
+			receiver.FailNext(c.state.failures)
+
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockWebhookDB := mocks.NewMockIWebhookStorage(t)
+
+			webhooks := []models.Webhook{
+				{FamilyID: "456", ID: "1", URL: receiver.URL, Secret: "secret", EventTypes: []string{"points_requested"}},
+				{FamilyID: "456", ID: "2", URL: receiver.URL, Secret: "secret", EventTypes: []string{"balance_changed"}},
+			}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{FamilyIDs: []string{"456"}}, c.state.errGetUser).Once()
+			if c.state.errGetUser == nil {
+				mockWebhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return(webhooks, c.state.errGetHook).Once()
+			}
+
+			mu := sync.Mutex{}
+			statuses := []models.WebhookDeliveryStatus{}
+			if c.want.requests > 0 {
+				mockWebhookDB.EXPECT().SaveWebhookDelivery(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, d models.WebhookDelivery) error {
+					mu.Lock()
+					defer mu.Unlock()
+
+					assert.Equal(t, "1", d.WebhookID)
+					assert.Equal(t, "evt1", d.EventID)
+					assert.Equal(t, len(statuses)+1, d.Attempts)
+
+					statuses = append(statuses, d.Status)
+					return c.state.errSave
+				}).Times(c.want.requests)
+			}
+
+			d := webhook.NewDispatcher(mockUserDB, mockWebhookDB, testConfig)
+			err := d.Deliver(context.Background(), eventbus.Event{ID: "evt1", Type: eventbus.EventTypePointsRequested, UserID: "123"})
+			tests.AssertError(t, err, c.want.err)
+
+			requests := receiver.Requests()
+			assert.Len(t, requests, c.want.requests)
+			assert.Equal(t, c.want.statuses, statuses)
+
+			for _, req := range requests {
+				assert.True(t, req.ValidSignature)
+				assert.Equal(t, "1", req.Header.Get(webhook.HeaderID))
+				assert.Equal(t, "points_requested", req.Header.Get(webhook.HeaderEvent))
+				assert.Equal(t, "456", req.Payload.FamilyID)
+				assert.Equal(t, "evt1", req.Payload.Event.ID)
+				assert.Equal(t, req.Payload.DeliveryID, req.Header.Get(webhook.HeaderDelivery))
+			}
+
+			mockUserDB.AssertExpectations(t)
+			mockWebhookDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Dispatcher_Deliver_privateAddress(t *testing.T) {
+	receiver := webhooktest.NewReceiver("secret")
+	defer receiver.Close()
+
+	mockUserDB := mocks.NewMockIUserStorage(t)
+	mockWebhookDB := mocks.NewMockIWebhookStorage(t)
+
+	mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{FamilyIDs: []string{"456"}}, nil).Once()
+	mockWebhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{
+		{FamilyID: "456", ID: "1", URL: receiver.URL, Secret: "secret", EventTypes: []string{"points_requested"}},
+	}, nil).Once()
+
+	// fails right away, there's no point in retrying
+	mockWebhookDB.EXPECT().SaveWebhookDelivery(mock.Anything, mock.MatchedBy(func(d models.WebhookDelivery) bool {
+		return d.Attempts == 1 && d.Status == models.WebhookDeliveryStatusFailed &&
+			strings.Contains(d.Error, webhook.ErrAddressNotAllowed.Error())
+	})).Return(nil).Once()
+
+	cfg := testConfig
+	cfg.AllowPrivateAddresses = false
+
+	d := webhook.NewDispatcher(mockUserDB, mockWebhookDB, cfg)
+	err := d.Deliver(context.Background(), eventbus.Event{ID: "evt1", Type: eventbus.EventTypePointsRequested, UserID: "123"})
+
+	assert.Nil(t, err)
+	assert.Empty(t, receiver.Requests())
+
+	mockUserDB.AssertExpectations(t)
+	mockWebhookDB.AssertExpectations(t)
+}
+
+func Test_Dispatcher_Deliver_contextDone(t *testing.T) {
+	receiver := webhooktest.NewReceiver("secret")
+	defer receiver.Close()
+	receiver.FailNext(5)
+
+	mockUserDB := mocks.NewMockIUserStorage(t)
+	mockWebhookDB := mocks.NewMockIWebhookStorage(t)
+
+	mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{FamilyIDs: []string{"456"}}, nil).Once()
+	mockWebhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{
+		{FamilyID: "456", ID: "1", URL: receiver.URL, Secret: "secret", EventTypes: []string{"points_requested"}},
+	}, nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the context is done while waiting for the retry, which would otherwise take an hour
+	statuses := []models.WebhookDeliveryStatus{}
+	mockWebhookDB.EXPECT().SaveWebhookDelivery(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, d models.WebhookDelivery) error {
+		statuses = append(statuses, d.Status)
+		cancel()
+		return nil
+	}).Twice()
+
+	cfg := testConfig
+	cfg.Backoff = time.Hour
+
+	d := webhook.NewDispatcher(mockUserDB, mockWebhookDB, cfg)
+	err := d.Deliver(ctx, eventbus.Event{ID: "evt1", Type: eventbus.EventTypePointsRequested, UserID: "123"})
+
+	assert.Nil(t, err)
+	assert.Len(t, receiver.Requests(), 1)
+	assert.Equal(t, []models.WebhookDeliveryStatus{"PENDING", "FAILED"}, statuses)
+
+	mockUserDB.AssertExpectations(t)
+	mockWebhookDB.AssertExpectations(t)
+}
+
+func Test_Dispatcher_Enqueue(t *testing.T) {
+	receiver := webhooktest.NewReceiver("secret")
+	defer receiver.Close()
+
+	mockUserDB := mocks.NewMockIUserStorage(t)
+	mockWebhookDB := mocks.NewMockIWebhookStorage(t)
+
+	mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{FamilyIDs: []string{"456"}}, nil).Once()
+	mockWebhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{
+		{FamilyID: "456", ID: "1", URL: receiver.URL, Secret: "secret", EventTypes: []string{"points_requested"}},
+	}, nil).Once()
+	mockWebhookDB.EXPECT().SaveWebhookDelivery(mock.Anything, mock.Anything).Return(nil).Once()
+
+	// the request that publishes the event may be done before the event is delivered
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := webhook.NewDispatcher(mockUserDB, mockWebhookDB, testConfig)
+	err := d.Enqueue(ctx, eventbus.Event{Type: eventbus.EventTypePointsRequested, UserID: "123"})
+	cancel()
+	d.Wait()
+
+	assert.Nil(t, err)
+	assert.Len(t, receiver.Requests(), 1)
+	assert.NotEmpty(t, receiver.Requests()[0].Payload.Event.ID)
+
+	mockUserDB.AssertExpectations(t)
+	mockWebhookDB.AssertExpectations(t)
+}
+
+func Test_Dispatcher_Process(t *testing.T) {
+	type state struct {
+		errUser error
+	}
+	type want struct {
+		err     string
+		handled bool
+	}
+	type test struct {
+		name string
+		state
+		want
+	}
+
+	cases := []test{
+		{"happy path", state{}, want{"", true}},
+		{"fail - get user", state{errUser: errFail}, want{"failed to get user of event: fail", false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			receiver := webhooktest.NewReceiver("secret")
+			defer receiver.Close()
+
+			mockUserDB := mocks.NewMockIUserStorage(t)
+			mockWebhookDB := mocks.NewMockIWebhookStorage(t)
+			mockHandler := webhookmocks.NewMockEventHandler(t)
+
+			evt := eventbus.Event{ID: "a", Type: eventbus.EventTypePointsRequested, UserID: "123"}
+
+			mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{FamilyIDs: []string{"456"}}, c.state.errUser).Once()
+
+			if c.state.errUser == nil {
+				mockWebhookDB.EXPECT().GetWebhooksByFamilyID(mock.Anything, "456").Return([]models.Webhook{
+					{FamilyID: "456", ID: "1", URL: receiver.URL, Secret: "secret", EventTypes: []string{"points_requested"}},
+				}, nil).Once()
+				mockWebhookDB.EXPECT().SaveWebhookDelivery(mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			if c.want.handled {
+				// handlers get the event once it was delivered
+				mockHandler.EXPECT().HandleEvent(mock.Anything, evt).Run(func(ctx context.Context, evt eventbus.Event) {
+					assert.Len(t, receiver.Requests(), 1)
+				}).Once()
+			}
+
+			d := webhook.NewDispatcher(mockUserDB, mockWebhookDB, testConfig)
+			d.UseEventHandler(mockHandler)
+
+			err := d.Process(context.Background(), evt)
+			tests.AssertError(t, err, c.want.err)
+
+			mockHandler.AssertExpectations(t)
+			mockUserDB.AssertExpectations(t)
+			mockWebhookDB.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Publisher_Publish(t *testing.T) {
+	mockNext := evtmocks.NewMockPublisher(t)
+	mockUserDB := mocks.NewMockIUserStorage(t)
+	mockWebhookDB := mocks.NewMockIWebhookStorage(t)
+
+	eventID := ""
+	mockNext.EXPECT().Publish(mock.Anything, mock.Anything).Run(func(ctx context.Context, evt eventbus.Event) {
+		eventID = evt.ID
+	}).Once()
+
+	// the queue gets the same event as the bus
+	mockUserDB.EXPECT().GetUserByID(mock.Anything, "123").Return(models.User{}, errFail).Once()
+
+	d := webhook.NewDispatcher(mockUserDB, mockWebhookDB, testConfig)
+	p := webhook.NewPublisher(mockNext, d)
+
+	p.Publish(context.Background(), eventbus.Event{Type: eventbus.EventTypePointsRequested, UserID: "123"})
+	d.Wait()
+
+	assert.NotEmpty(t, eventID)
+
+	mockNext.AssertExpectations(t)
+	mockUserDB.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+// Package webhooktest provides a local webhook receiver, so webhook deliveries can be tested without network access
+package webhooktest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/sebboness/yektaspoints/util/webhook"
+)
+
+// Request is a delivery the receiver got
+type Request struct {
+	Header         http.Header
+	Body           []byte
+	Payload        webhook.Payload
+	ValidSignature bool
+}
+
+// Receiver is a webhook endpoint listening on a local address. It records all deliveries and
+// can be told to fail, to test retries.
+type Receiver struct {
+	URL string
+
+	failures int
+	mu       sync.Mutex
+	requests []Request
+	secret   string
+	server   *httptest.Server
+}
+
+// NewReceiver starts a receiver that verifies signatures with the given secret.
+// Close it when done.
+func NewReceiver(secret string) *Receiver {
+	r := &Receiver{
+		requests: []Request{},
+		secret:   secret,
+	}
+
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	r.URL = r.server.URL
+
+	return r
+}
+
+// FailNext makes the receiver respond with an internal server error to the next n deliveries
+func (r *Receiver) FailNext(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = n
+}
+
+// Requests returns all deliveries received so far, including failed ones
+func (r *Receiver) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Request{}, r.requests...)
+}
+
+func (r *Receiver) Close() {
+	r.server.Close()
+}
+
+func (r *Receiver) handle(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	received := Request{
+		Header: req.Header.Clone(),
+		Body:   body,
+		ValidSignature: webhook.Verify(r.secret, req.Header.Get(webhook.HeaderTimestamp), body,
+			req.Header.Get(webhook.HeaderSignature)),
+	}
+	_ = json.Unmarshal(body, &received.Payload)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, received)
+	fail := r.failures > 0
+	if fail {
+		r.failures--
+	}
+	r.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !received.ValidSignature {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -19,4 +19,29 @@ resource "aws_s3_bucket_public_access_block" "lambda_bucket" {
   block_public_policy     = true
   ignore_public_acls      = true
   restrict_public_buckets = true
-}
\ No newline at end of file
+}
+# Files attached to point requests. Clients up- and download them with pre-signed URLs.
+resource "aws_s3_bucket" "attachments_bucket" {
+  bucket        = "hexonite-${local.app}-${local.env}-attachments-${random_id.suffix.hex}"
+  force_destroy = true
+}
+
+resource "aws_s3_bucket_public_access_block" "attachments_bucket" {
+  bucket = aws_s3_bucket.attachments_bucket.id
+
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_cors_configuration" "attachments_bucket" {
+  bucket = aws_s3_bucket.attachments_bucket.id
+
+  cors_rule {
+    allowed_headers = ["*"]
+    allowed_methods = ["GET", "PUT"]
+    allowed_origins = ["*"]
+    max_age_seconds = 3000
+  }
+}
@@ -37,15 +37,29 @@ resource "aws_iam_policy" "policy" {
                   "dynamodb:PartiQLUpdate",
                   "dynamodb:PutItem",
                   "dynamodb:Query",
+                  "dynamodb:Scan",
                   "dynamodb:UpdateItem",
                   "logs:*",
                   "s3:*"
               ],
               "Resource": [
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-achievement",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-achievement-rule",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-audit",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-family-event",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-family-settings",
                 "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-family-user",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-point-balance",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-point-comment",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-point-comment/index/point_id-index",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-point-type",
                 "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-points",
                 "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-points/index/updated_on-index",
                 "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-user",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-user/index/email-index",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-user/index/username-index",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-webhook",
+                "arn:aws:dynamodb:*:*:table/${local.app}-${local.env}-webhook-delivery",
                 "arn:aws:logs:*:*:*",
                 "arn:aws:s3:::*"
               ]
@@ -54,8 +68,33 @@ resource "aws_iam_policy" "policy" {
               "Effect": "Allow",
               "Action": [
                   "cognito-idp:AdminAddUserToGroup",
+                  "cognito-idp:AdminDeleteUser",
+                  "cognito-idp:AdminDisableUser",
+                  "cognito-idp:AdminEnableUser",
+                  "cognito-idp:AdminRemoveUserFromGroup",
+                  "cognito-idp:AdminUpdateUserAttributes",
+                  "cognito-idp:ListUsers",
               ],
               "Resource": tolist(data.aws_cognito_user_pools.pools.arns)
+          },
+          {
+              "Effect": "Allow",
+              "Action": [
+                  "ses:SendEmail",
+              ],
+              "Resource": "*"
+          },
+          {
+              "Effect": "Allow",
+              "Action": [
+                  "sqs:DeleteMessage",
+                  "sqs:GetQueueAttributes",
+                  "sqs:ReceiveMessage",
+                  "sqs:SendMessage",
+              ],
+              "Resource": [
+                aws_sqs_queue.webhook.arn,
+              ]
           }
       ]
     } 
@@ -90,6 +129,9 @@ resource "aws_lambda_function" "main" {
   handler = "bootstrap"
   architectures = ["x86_64"]
 
+  # long-polling for events waits up to 25 seconds, and API gateway gives up after 29
+  timeout = 29
+
   source_code_hash = data.external.output_hash.result.filebase64sha256
 
   role = aws_iam_role.lambda_exec.arn
@@ -97,6 +139,7 @@ resource "aws_lambda_function" "main" {
   environment {
     variables = {
       APPNAME  = local.app
+      ATTACHMENTS_BUCKET = aws_s3_bucket.attachments_bucket.id
       BUILT_AT = "${timestamp()}"
       COGNITO_USER_POOL_ID  = local.ssm_secrets["COGNITO_USER_POOL_ID"]
       COGNITO_CLIENT_ID     = local.ssm_secrets["COGNITO_CLIENT_ID"]
@@ -104,6 +147,7 @@ resource "aws_lambda_function" "main" {
       ENV      = local.env
       GIN_MODE = local.env == "prod" ? "release" : "debug" 
       VERSION  = file(var.lambda_version)
+      WEBHOOK_QUEUE_URL     = aws_sqs_queue.webhook.url
     }
   }
 }
@@ -0,0 +1,70 @@
+variable "digest_output_path" {
+  default = "../../api/cmd/digest/bootstrap.zip"
+}
+
+data "external" "digest_output_hash" {
+  program = ["/bin/sh", "${path.module}/compute_file_hash.sh", "${var.digest_output_path}"]
+}
+
+# Sends the weekly family digest to parents every Sunday evening
+resource "aws_lambda_function" "digest" {
+  function_name = "${local.app}-${local.env}-digest"
+
+  s3_bucket = aws_s3_bucket.lambda_bucket.id
+  s3_key    = aws_s3_object.lambda_digest.key
+
+  package_type = "Zip"
+  runtime = "provided.al2023"
+  handler = "bootstrap"
+  architectures = ["x86_64"]
+  timeout = 300
+
+  source_code_hash = data.external.digest_output_hash.result.filebase64sha256
+
+  role = aws_iam_role.lambda_exec.arn
+
+  environment {
+    variables = {
+      APPNAME  = local.app
+      ENV      = local.env
+      NOTIFY_EMAIL_FROM     = lookup(local.ssm_secrets, "NOTIFY_EMAIL_FROM", "")
+      PUSH_PROVIDER_URL     = lookup(local.ssm_secrets, "PUSH_PROVIDER_URL", "")
+      PUSH_PROVIDER_API_KEY = lookup(local.ssm_secrets, "PUSH_PROVIDER_API_KEY", "")
+      VERSION  = file(var.lambda_version)
+    }
+  }
+}
+
+resource "aws_cloudwatch_log_group" "digest" {
+  name = "/aws/lambda/${aws_lambda_function.digest.function_name}"
+
+  retention_in_days = 14
+}
+
+resource "aws_s3_object" "lambda_digest" {
+  bucket = aws_s3_bucket.lambda_bucket.id
+
+  key    = "${local.app}-${local.env}-digest.zip"
+  source = var.digest_output_path
+
+  etag = filemd5(var.digest_output_path)
+}
+
+resource "aws_cloudwatch_event_rule" "digest" {
+  name                = "${local.app}-${local.env}-weekly-digest"
+  schedule_expression = "cron(0 17 ? * SUN *)"
+}
+
+resource "aws_cloudwatch_event_target" "digest" {
+  rule = aws_cloudwatch_event_rule.digest.name
+  arn  = aws_lambda_function.digest.arn
+}
+
+resource "aws_lambda_permission" "digest_schedule" {
+  statement_id  = "AllowExecutionFromEventBridge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.digest.function_name
+  principal     = "events.amazonaws.com"
+
+  source_arn = aws_cloudwatch_event_rule.digest.arn
+}
@@ -0,0 +1,80 @@
+variable "webhook_output_path" {
+  default = "../../api/cmd/webhook/bootstrap.zip"
+}
+
+data "external" "webhook_output_hash" {
+  program = ["/bin/sh", "${path.module}/compute_file_hash.sh", "${var.webhook_output_path}"]
+}
+
+# Events the main lambda publishes, waiting to be delivered to family webhooks and notified about
+resource "aws_sqs_queue" "webhook" {
+  name = "${local.app}-${local.env}-webhook"
+
+  # AWS recommends at least 6 times the timeout of the lambda that receives the messages
+  visibility_timeout_seconds = 360
+
+  redrive_policy = jsonencode({
+    deadLetterTargetArn = aws_sqs_queue.webhook_dlq.arn
+    maxReceiveCount     = 5
+  })
+}
+
+# Events whose webhooks couldn't be looked up after all receives
+resource "aws_sqs_queue" "webhook_dlq" {
+  name = "${local.app}-${local.env}-webhook-dlq"
+
+  message_retention_seconds = 1209600
+}
+
+# Delivers queued events to webhooks and sends their notifications (email and push). A webhook that
+# doesn't respond takes up to ~35 seconds (5 attempts with a 5 second timeout, and backoff in between)
+resource "aws_lambda_function" "webhook" {
+  function_name = "${local.app}-${local.env}-webhook"
+
+  s3_bucket = aws_s3_bucket.lambda_bucket.id
+  s3_key    = aws_s3_object.lambda_webhook.key
+
+  package_type = "Zip"
+  runtime = "provided.al2023"
+  handler = "bootstrap"
+  architectures = ["x86_64"]
+  timeout = 60
+
+  source_code_hash = data.external.webhook_output_hash.result.filebase64sha256
+
+  role = aws_iam_role.lambda_exec.arn
+
+  environment {
+    variables = {
+      APPNAME  = local.app
+      ENV      = local.env
+      NOTIFY_EMAIL_FROM     = lookup(local.ssm_secrets, "NOTIFY_EMAIL_FROM", "")
+      PUSH_PROVIDER_URL     = lookup(local.ssm_secrets, "PUSH_PROVIDER_URL", "")
+      PUSH_PROVIDER_API_KEY = lookup(local.ssm_secrets, "PUSH_PROVIDER_API_KEY", "")
+      VERSION  = file(var.lambda_version)
+    }
+  }
+}
+
+resource "aws_cloudwatch_log_group" "webhook" {
+  name = "/aws/lambda/${aws_lambda_function.webhook.function_name}"
+
+  retention_in_days = 14
+}
+
+resource "aws_s3_object" "lambda_webhook" {
+  bucket = aws_s3_bucket.lambda_bucket.id
+
+  key    = "${local.app}-${local.env}-webhook.zip"
+  source = var.webhook_output_path
+
+  etag = filemd5(var.webhook_output_path)
+}
+
+resource "aws_lambda_event_source_mapping" "webhook" {
+  event_source_arn = aws_sqs_queue.webhook.arn
+  function_name    = aws_lambda_function.webhook.arn
+  batch_size       = 1
+
+  function_response_types = ["ReportBatchItemFailures"]
+}
@@ -2,6 +2,9 @@ resource "aws_api_gateway_rest_api" "api" {
   name = "${local.app}-${local.env}-api"
   description = "${local.app} ${local.env} api"
 
+  # family data exports can be downloaded as zip archives
+  binary_media_types = ["application/zip"]
+
   endpoint_configuration {
     types = ["REGIONAL"]
   }
@@ -159,6 +162,17 @@ module "apigw_user_register_confirm_options" {
   ]
 }
 
+# options for /v1/user/register/resend
+module "apigw_user_register_resend_options" {
+  source = "./apigw-options"
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.user_register_resend.id
+  lambda_invoke_arn = aws_lambda_function.main.invoke_arn
+  depends_on = [
+    aws_api_gateway_resource.user_register_resend
+  ]
+}
+
 # /auth
 resource "aws_api_gateway_resource" "auth" {
   rest_api_id = aws_api_gateway_rest_api.api.id
@@ -398,6 +412,61 @@ resource "aws_api_gateway_integration_response" "post_user_register_confirm" {
   ]
 }
 
+# resource /v1/user/register/resend
+resource "aws_api_gateway_resource" "user_register_resend" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  parent_id   = aws_api_gateway_resource.user_register.id
+  path_part   = "resend"
+  depends_on = [
+    aws_api_gateway_resource.user_register
+  ]
+}
+
+# method POST /v1/user/register/resend
+resource "aws_api_gateway_method" "post_user_register_resend" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.user_register_resend.id
+  http_method = "POST"
+  authorization = "NONE"
+  depends_on = [
+    aws_api_gateway_resource.user_register_resend
+  ]
+}
+
+resource "aws_api_gateway_integration" "user_register_resend_integration" {
+  rest_api_id             = aws_api_gateway_rest_api.api.id
+  resource_id             = aws_api_gateway_resource.user_register_resend.id
+  http_method             = aws_api_gateway_method.post_user_register_resend.http_method
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.main.invoke_arn
+  depends_on = [
+    aws_api_gateway_method.post_user_register_resend
+  ]
+
+}
+
+resource "aws_api_gateway_method_response" "post_user_register_resend" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.user_register_resend.id
+  http_method = aws_api_gateway_method.post_user_register_resend.http_method
+  status_code = "200"
+  depends_on = [
+    aws_api_gateway_method.post_user_register_resend
+  ]
+}
+
+resource "aws_api_gateway_integration_response" "post_user_register_resend" {
+  rest_api_id = aws_api_gateway_rest_api.api.id
+  resource_id = aws_api_gateway_resource.user_register_resend.id
+  http_method = aws_api_gateway_method.post_user_register_resend.http_method
+  status_code = aws_api_gateway_method_response.post_user_register_resend.status_code
+  depends_on = [
+    aws_api_gateway_method.post_user_register_resend,
+    aws_api_gateway_method_response.post_user_register_resend
+  ]
+}
+
 # Deployment and domain
 resource "aws_api_gateway_deployment" "deployment" {
   rest_api_id = aws_api_gateway_rest_api.api.id
@@ -413,6 +482,7 @@ resource "aws_api_gateway_deployment" "deployment" {
     aws_api_gateway_integration.health_integration,
     aws_api_gateway_integration.user_register_integration,
     aws_api_gateway_integration.user_register_confirm_integration,
+    aws_api_gateway_integration.user_register_resend_integration,
     # module.apigw_root_options,
   ]
 }
@@ -40,7 +40,33 @@ resource "aws_dynamodb_table" "user" {
         type = "S"
     }
 
+    attribute {
+        name = "username"
+        type = "S"
+    }
+
+    attribute {
+        name = "email"
+        type = "S"
+    }
+
     hash_key = "user_id"
+
+    global_secondary_index {
+        name               = "email-index"
+        hash_key           = "email"
+        read_capacity      = "5"
+        write_capacity     = "5"
+        projection_type    = "ALL"
+    }
+
+    global_secondary_index {
+        name               = "username-index"
+        hash_key           = "username"
+        read_capacity      = "5"
+        write_capacity     = "5"
+        projection_type    = "ALL"
+    }
 }
 
 resource "aws_dynamodb_table" "family-user" {
@@ -61,4 +87,196 @@ resource "aws_dynamodb_table" "family-user" {
 
     hash_key = "family_id"
     range_key = "user_id"
-}
\ No newline at end of file
+}
+
+resource "aws_dynamodb_table" "family_settings" {
+    name = "${local.app}-${local.env}-family-settings"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "family_id"
+        type = "S"
+    }
+
+    hash_key = "family_id"
+}
+
+resource "aws_dynamodb_table" "achievement" {
+    name = "${local.app}-${local.env}-achievement"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "user_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "rule_id"
+        type = "S"
+    }
+
+    hash_key = "user_id"
+    range_key = "rule_id"
+}
+
+resource "aws_dynamodb_table" "achievement_rule" {
+    name = "${local.app}-${local.env}-achievement-rule"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "family_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "id"
+        type = "S"
+    }
+
+    hash_key = "family_id"
+    range_key = "id"
+}
+
+resource "aws_dynamodb_table" "point_comment" {
+    name = "${local.app}-${local.env}-point-comment"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "user_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "id"
+        type = "S"
+    }
+
+    attribute {
+        name = "point_id"
+        type = "S"
+    }
+
+    hash_key = "user_id"
+    range_key = "id"
+
+    local_secondary_index {
+        name               = "point_id-index"
+        range_key          = "point_id"
+        projection_type    = "ALL"
+    }
+}
+
+resource "aws_dynamodb_table" "point_type" {
+    name = "${local.app}-${local.env}-point-type"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "family_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "id"
+        type = "S"
+    }
+
+    hash_key = "family_id"
+    range_key = "id"
+}
+
+resource "aws_dynamodb_table" "audit" {
+    name = "${local.app}-${local.env}-audit"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "user_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "id"
+        type = "S"
+    }
+
+    hash_key = "user_id"
+    range_key = "id"
+}
+
+resource "aws_dynamodb_table" "webhook" {
+    name = "${local.app}-${local.env}-webhook"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "family_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "id"
+        type = "S"
+    }
+
+    hash_key = "family_id"
+    range_key = "id"
+}
+
+resource "aws_dynamodb_table" "webhook_delivery" {
+    name = "${local.app}-${local.env}-webhook-delivery"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "webhook_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "id"
+        type = "S"
+    }
+
+    hash_key = "webhook_id"
+    range_key = "id"
+}
+
+# Events of family members, numbered per family. Item 0 of each family counts its events.
+resource "aws_dynamodb_table" "family_event" {
+    name = "${local.app}-${local.env}-family-event"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "family_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "seq"
+        type = "N"
+    }
+
+    hash_key = "family_id"
+    range_key = "seq"
+
+    ttl {
+        attribute_name = "expires_on"
+        enabled        = true
+    }
+}
+
+# Balances of each user and point type, saved in the same transaction as the points that change them
+resource "aws_dynamodb_table" "point_balance" {
+    name = "${local.app}-${local.env}-point-balance"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "user_id"
+        type = "S"
+    }
+
+    attribute {
+        name = "point_type_id"
+        type = "S"
+    }
+
+    hash_key = "user_id"
+    range_key = "point_type_id"
+}